@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+func runAccounts(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError("accounts: missing subcommand")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "list":
+		return accountsList(ctx, a, args)
+	case "get":
+		return accountsGet(ctx, a, args)
+	case "freeze":
+		return accountsSetStatus(ctx, a, "freeze", args, models.AccountStatusFrozen)
+	case "unfreeze":
+		return accountsSetStatus(ctx, a, "unfreeze", args, models.AccountStatusActive)
+	case "close":
+		return accountsSetStatus(ctx, a, "close", args, models.AccountStatusClosed)
+	default:
+		return usageError("accounts: unknown subcommand %q", sub)
+	}
+}
+
+func accountsList(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("accounts list", flag.ContinueOnError)
+	status := fset.String("status", "", "only list accounts with this status")
+	if _, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	}
+
+	repo, err := a.accounts(ctx)
+	if err != nil {
+		return err
+	}
+	all, err := repo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	accounts := []models.Account{}
+	for _, account := range all {
+		if *status == "" || accountStatus(account) == *status {
+			accounts = append(accounts, account)
+		}
+	}
+	return a.print(accountsTable(accounts, accounts))
+}
+
+func accountsGet(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return usageError("accounts get: expected one account ID")
+	}
+
+	account, err := getAccount(ctx, a, args[0])
+	if err != nil {
+		return err
+	}
+	return a.print(accountsTable(account, []models.Account{*account}))
+}
+
+func accountsSetStatus(ctx context.Context, a *app, name string, args []string, status string) error {
+	fset := flag.NewFlagSet("accounts "+name, flag.ContinueOnError)
+	actor := fset.String("actor", os.Getenv("USER"), "who is making the change, for the account history")
+	force := new(bool)
+	if status == models.AccountStatusClosed {
+		force = fset.Bool("force", false, "close even if the balance is not zero")
+	}
+	ids, err := parseFlags(fset, args, a.errOut)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return usageError("accounts %s: expected one account ID", name)
+	}
+	if *actor == "" {
+		return usageError("accounts %s: -actor is required when $USER is not set", name)
+	}
+
+	account, err := getAccount(ctx, a, ids[0])
+	if err != nil {
+		return err
+	}
+
+	current := accountStatus(*account)
+	switch {
+	case current == status:
+		return fmt.Errorf("account %s is already %s", account.ID, status)
+	case current == models.AccountStatusClosed:
+		return fmt.Errorf("account %s is closed", account.ID)
+	case status == models.AccountStatusActive && current != models.AccountStatusFrozen:
+		return fmt.Errorf("account %s is not frozen", account.ID)
+	case status == models.AccountStatusClosed && account.Balance != 0 && !*force:
+		return fmt.Errorf("account %s has a balance of %s; settle it first or use -force", account.ID, formatAmount(account.Balance))
+	}
+
+	repo, err := a.accounts(ctx)
+	if err != nil {
+		return err
+	}
+	account.Status = current
+	if err := repo.SetStatus(ctx, account, status, *actor); err != nil {
+		return err
+	}
+	slog.Info("account status changed", "account_id", account.ID, "from", current, "to", status, "actor", *actor)
+
+	account, err = getAccount(ctx, a, account.ID)
+	if err != nil {
+		return err
+	}
+	bus, err := a.events(ctx)
+	if err != nil {
+		return err
+	}
+	event := events.NewAccountUpdated(account, []models.AccountChange{{
+		Field: "status", Old: current, New: status, Actor: *actor, ChangedAt: account.UpdatedAt,
+	}})
+	event.Actor = *actor
+	bus.Publish(ctx, event)
+	return a.print(accountsTable(account, []models.Account{*account}))
+}
+
+func getAccount(ctx context.Context, a *app, id string) (*models.Account, error) {
+	repo, err := a.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account %s: %w", id, repository.ErrNotFound)
+	}
+	return account, nil
+}
+
+// accountStatus reports accounts stored before statuses existed as active.
+func accountStatus(account models.Account) string {
+	if account.Status == "" {
+		return models.AccountStatusActive
+	}
+	return account.Status
+}
+
+func accountsTable(value any, accounts []models.Account) table {
+	t := table{
+		value:   value,
+		headers: []string{"ID", "OWNER", "TYPE", "STATUS", "BALANCE", "CREATED", "UPDATED"},
+	}
+	for _, account := range accounts {
+		t.rows = append(t.rows, []string{
+			account.ID,
+			orDash(account.Owner),
+			orDash(account.AccountType),
+			accountStatus(account),
+			formatAmount(account.Balance),
+			formatTime(account.CreatedAt),
+			formatTime(account.UpdatedAt),
+		})
+	}
+	return t
+}
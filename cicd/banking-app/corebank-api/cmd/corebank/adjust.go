@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/corebank-api/internal/models"
+)
+
+func runAdjust(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("adjust", flag.ContinueOnError)
+	accountID := fset.String("account", "", "account to adjust")
+	amount := fset.Float64("amount", 0, "amount to credit; negative to debit")
+	reason := fset.String("reason", "", "why the adjustment is needed, kept with the outbox entry")
+	actor := fset.String("actor", os.Getenv("USER"), "who is posting the adjustment")
+	if rest, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError("adjust: unexpected arguments %q", rest)
+	}
+
+	switch {
+	case *accountID == "":
+		return usageError("adjust: -account is required")
+	case *amount == 0 || math.IsNaN(*amount) || math.IsInf(*amount, 0):
+		return usageError("adjust: -amount must be a non-zero number")
+	case *reason == "":
+		return usageError("adjust: -reason is required")
+	case *actor == "":
+		return usageError("adjust: -actor is required when $USER is not set")
+	}
+
+	account, err := getAccount(ctx, a, *accountID)
+	if err != nil {
+		return err
+	}
+	if accountStatus(*account) == models.AccountStatusClosed {
+		return fmt.Errorf("account %s is closed", account.ID)
+	}
+
+	txnType := "deposit"
+	if *amount < 0 {
+		txnType = "withdrawal"
+	}
+	entry := &models.OutboxEntry{
+		Source: models.OutboxSourceAdjustment,
+		Transaction: models.Transaction{
+			AccountID:   account.ID,
+			Amount:      math.Abs(*amount),
+			Type:        txnType,
+			Description: "adjustment: " + *reason,
+			Status:      "pending",
+		},
+		Reason: *reason,
+		Actor:  *actor,
+	}
+
+	ob, err := a.outbox(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ob.Submit(ctx, entry); err != nil {
+		return err
+	}
+	if err := a.print(outboxTable(entry, []models.OutboxEntry{*entry})); err != nil {
+		return err
+	}
+	if entry.Status != models.OutboxDelivered {
+		return fmt.Errorf("adjustment %s was recorded but not delivered (%s); retry with `corebank outbox redrive %s`",
+			entry.ID, entry.LastError, entry.ID)
+	}
+	return nil
+}
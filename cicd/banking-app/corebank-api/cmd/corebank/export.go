@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/corebank-api/internal/handlers"
+)
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("export", flag.ContinueOnError)
+	what := fset.String("what", "accounts", "accounts or outbox")
+	format := fset.String("format", "ndjson", "ndjson or csv")
+	outPath := fset.String("out", "", "file to write; stdout if empty")
+	if rest, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError("export: unexpected arguments %q", rest)
+	}
+	if *format != "ndjson" && *format != "csv" {
+		return usageError("export: -format must be ndjson or csv")
+	}
+
+	var records []any
+	var header []string
+	var row func(i int) []string
+
+	switch *what {
+	case "accounts":
+		repo, err := a.accounts(ctx)
+		if err != nil {
+			return err
+		}
+		accounts, err := repo.ListAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			records = append(records, account)
+		}
+		// Same columns as GET /accounts:export
+		header = handlers.AccountCSVHeader
+		row = func(i int) []string { return handlers.AccountCSVRecord(accounts[i]) }
+	case "outbox":
+		ob, err := a.outbox(ctx)
+		if err != nil {
+			return err
+		}
+		entries, err := ob.List(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			records = append(records, e)
+		}
+		header = []string{"id", "source", "status", "account_id", "type", "amount", "transaction_id",
+			"attempts", "actor", "reason", "last_error", "created_at", "updated_at"}
+		row = func(i int) []string {
+			e := entries[i]
+			return []string{e.ID, e.Source, e.Status, e.Transaction.AccountID, e.Transaction.Type,
+				formatAmount(e.Transaction.Amount), e.Transaction.ID, strconv.Itoa(e.Attempts),
+				e.Actor, e.Reason, e.LastError, formatTime(e.CreatedAt), formatTime(e.UpdatedAt)}
+		}
+	default:
+		return usageError("export: -what must be accounts or outbox")
+	}
+
+	out := a.out
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeExport(out, *format, records, header, row); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if *outPath != "" {
+		fmt.Fprintf(a.errOut, "exported %d %s to %s\n", len(records), *what, *outPath)
+	}
+	return nil
+}
+
+func writeExport(out io.Writer, format string, records []any, header []string, row func(i int) []string) error {
+	if format == "ndjson" {
+		enc := json.NewEncoder(out)
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := csv.NewWriter(out)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i := range records {
+		if err := w.Write(row(i)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
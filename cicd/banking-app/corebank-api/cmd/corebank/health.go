@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/corebank-api/internal/health"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// runHealth runs the API's readiness checks from here, so ops can tell
+// whether a failure is in the dependencies or in the API pods.
+func runHealth(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return usageError("health: unexpected arguments %q", args)
+	}
+
+	checker := health.NewChecker(a.cfg.Health.CheckTimeout, 0)
+	checker.Register("dynamodb", func(ctx context.Context) error {
+		repo, err := a.accounts(ctx)
+		if err != nil {
+			return err
+		}
+		return repo.Ping(ctx)
+	})
+	checker.Register("transaction_service", func(ctx context.Context) error {
+		client := upstream.NewClient(a.cfg.TransactionService.Timeout)
+		return upstream.CheckHealth(ctx, client, a.cfg.TransactionService.URL)
+	})
+
+	report := checker.Ready(ctx)
+
+	names := make([]string, 0, len(report.Checks))
+	for name := range report.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := table{value: report, headers: []string{"CHECK", "STATUS", "LATENCY_MS", "ERROR"}}
+	for _, name := range names {
+		r := report.Checks[name]
+		t.rows = append(t.rows, []string{name, r.Status, strconv.FormatInt(r.LatencyMS, 10), orDash(r.Error)})
+	}
+	if err := a.print(t); err != nil {
+		return err
+	}
+
+	if report.Status != health.StatusUp {
+		return errors.New("one or more checks are down")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/corebank-api/internal/interest"
+	"github.com/corebank-api/internal/models"
+)
+
+func runInterest(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError("interest: missing subcommand")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "show":
+		return interestShow(ctx, a, args)
+	case "replay":
+		return interestReplay(ctx, a, args)
+	default:
+		return usageError("interest: unknown subcommand %q", sub)
+	}
+}
+
+func interestShow(ctx context.Context, a *app, args []string) error {
+	loc, err := time.LoadLocation(a.cfg.Statements.Timezone)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	fset := flag.NewFlagSet("interest show", flag.ContinueOnError)
+	accountID := fset.String("account", "", "account to show accruals for")
+	fromFlag := fset.String("from", today.AddDate(0, 0, 1-today.Day()).Format(interest.DateLayout), "first day, YYYY-MM-DD")
+	toFlag := fset.String("to", today.AddDate(0, 0, -1).Format(interest.DateLayout), "last day, YYYY-MM-DD")
+	if rest, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError("interest show: unexpected arguments %q", rest)
+	}
+	if *accountID == "" {
+		return usageError("interest show: -account is required")
+	}
+	from, to, err := parseDays(*fromFlag, *toFlag, loc)
+	if err != nil {
+		return usageError("interest show: %v", err)
+	}
+
+	repo, err := a.interestStore(ctx)
+	if err != nil {
+		return err
+	}
+	var days []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(interest.DateLayout))
+	}
+	accruals, err := repo.Accruals(ctx, *accountID, days)
+	if err != nil {
+		return err
+	}
+	if accruals == nil {
+		accruals = []models.InterestAccrual{}
+	}
+
+	t := table{value: accruals, headers: []string{"DATE", "PERIOD", "TYPE", "BALANCE", "INTEREST"}}
+	total := 0.0
+	for _, accrual := range accruals {
+		total += accrual.Amount
+		t.rows = append(t.rows, []string{
+			accrual.Date,
+			accrual.Period,
+			accrual.AccountType,
+			formatAmount(accrual.Balance),
+			fmt.Sprintf("%.6f", accrual.Amount),
+		})
+	}
+	t.rows = append(t.rows, []string{"total", "", "", "", fmt.Sprintf("%.6f", total)})
+	return a.print(t)
+}
+
+func interestReplay(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("interest replay", flag.ContinueOnError)
+	accountID := fset.String("account", "", "account to replay; all accounts with an interest product if empty")
+	fromFlag := fset.String("from", "", "first day to recompute, YYYY-MM-DD")
+	toFlag := fset.String("to", "", "last day to recompute, YYYY-MM-DD; defaults to -from")
+	if rest, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError("interest replay: unexpected arguments %q", rest)
+	}
+	if *fromFlag == "" {
+		return usageError("interest replay: -from is required")
+	}
+	if *toFlag == "" {
+		*toFlag = *fromFlag
+	}
+
+	engine, err := a.interest(ctx)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseDays(*fromFlag, *toFlag, engine.Location())
+	if err != nil {
+		return usageError("interest replay: %v", err)
+	}
+
+	results, err := engine.Replay(ctx, *accountID, from, to)
+	if results == nil {
+		results = []interest.ReplayResult{}
+	}
+	t := table{value: results, headers: []string{"ACCOUNT", "DAYS", "PAID"}}
+	for _, r := range results {
+		t.rows = append(t.rows, []string{r.AccountID, fmt.Sprint(r.Days), formatAmount(r.Paid)})
+	}
+	if perr := a.print(t); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// parseDays parses an inclusive range of YYYY-MM-DD days in loc.
+func parseDays(from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(interest.DateLayout, from, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("-from must be YYYY-MM-DD: %q", from)
+	}
+	end, err := time.ParseInLocation(interest.DateLayout, to, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("-to must be YYYY-MM-DD: %q", to)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("-to is before -from")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,318 @@
+// Command corebank is the operations CLI for corebank-api. It works directly
+// against DynamoDB and the transaction service, using the same configuration
+// as the API: defaults, then -config or CONFIG_FILE, then the environment.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/holds"
+	"github.com/corebank-api/internal/interest"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/outbox"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/statements"
+	"github.com/corebank-api/internal/upstream"
+	"github.com/corebank-api/internal/webhooks"
+)
+
+const usage = `Usage: corebank [-config file] [-o table|json] <command> [arguments]
+
+Commands:
+  accounts list [-status s]          list accounts
+  accounts get <id>                  show one account
+  accounts freeze [-actor name] <id> block new transactions on an account
+  accounts unfreeze [-actor name] <id>
+                                     reactivate a frozen account
+  accounts close [-force] [-actor name] <id>
+                                     close an account; it must have a zero balance unless -force
+  adjust -account id -amount n -reason text [-actor name]
+                                     post a balancing deposit (n > 0) or withdrawal (n < 0)
+  export [-what accounts|outbox] [-format ndjson|csv] [-out file]
+                                     write all accounts or outbox entries
+  interest show -account id [-from day] [-to day]
+                                     list the interest accrued per day, this month by default
+  interest replay -from day [-to day] [-account id]
+                                     recompute accruals and pay or correct periods that have ended
+  migrate [-status]                  create missing tables and apply data migrations
+  outbox list [-status pending|delivered|all]
+                                     list transactions queued for the transaction service
+  outbox redrive [id...]             retry pending outbox entries, all of them if no IDs are given
+  reconcile [-correct] [-actor name] [id...]
+                                     compare balances with the transaction ledger, all accounts if no IDs are given
+  health                             check DynamoDB and the transaction service
+  webhook-receiver [-addr host:port] [-secret s]
+                                     print webhook deliveries, verifying their signatures, for development
+`
+
+// errUsage marks errors caused by bad arguments; main exits with status 2.
+var errUsage = errors.New("usage")
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]command{
+	"accounts":         runAccounts,
+	"adjust":           runAdjust,
+	"export":           runExport,
+	"interest":         runInterest,
+	"migrate":          runMigrate,
+	"outbox":           runOutbox,
+	"reconcile":        runReconcile,
+	"health":           runHealth,
+	"webhook-receiver": runWebhookReceiver,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fset := flag.NewFlagSet("corebank", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.Usage = func() { fmt.Fprint(stderr, usage) }
+	configFile := fset.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	format := fset.String("o", "table", "output format: table or json")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "json" {
+		return usageError("-o must be table or json")
+	}
+	if fset.NArg() == 0 {
+		return usageError("no command given")
+	}
+
+	name, cmdArgs := fset.Arg(0), fset.Args()[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return usageError("unknown command %q, want one of %s", name, strings.Join(names, ", "))
+	}
+
+	var loadArgs []string
+	if *configFile != "" {
+		loadArgs = []string{"-config", *configFile}
+	}
+	cfg, err := config.Load(loadArgs)
+	if err != nil {
+		return err
+	}
+
+	// Logs go to stderr so they never mix with command output
+	slog.SetDefault(logging.New(stderr, logging.Options{
+		Level:     cfg.Log.Level,
+		RedactPII: cfg.Log.RedactPII,
+	}))
+
+	a := &app{cfg: cfg, out: stdout, errOut: stderr, json: *format == "json"}
+	return cmd(ctx, a, cmdArgs)
+}
+
+// app holds the configuration and the dependencies commands share, created
+// on first use so that e.g. health can report a failure instead of exiting.
+type app struct {
+	cfg    *config.Config
+	out    io.Writer
+	errOut io.Writer
+	json   bool
+
+	client *dynamodb.Client
+	tables repository.Tables
+	bus    *events.Bus
+}
+
+func (a *app) dynamo(ctx context.Context) (*dynamodb.Client, error) {
+	if a.client == nil {
+		client, err := repository.NewDynamoDBClient(ctx, a.cfg.AWS, a.cfg.DynamoDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create DynamoDB client: %w", err)
+		}
+		a.client = client
+		a.tables = repository.TablesFromConfig(a.cfg.DynamoDB)
+	}
+	return a.client, nil
+}
+
+func (a *app) accounts(ctx context.Context) (*repository.AccountRepository, error) {
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewAccountRepository(client, a.tables.Accounts, a.tables.AccountHistory, a.tables.BalancePostings), nil
+}
+
+func (a *app) outbox(ctx context.Context) (*outbox.Outbox, error) {
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bus, err := a.events(ctx)
+	if err != nil {
+		return nil, err
+	}
+	poster, err := a.poster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ob := outbox.New(repository.NewOutboxRepository(client, a.tables.Outbox), a.transactions())
+	ob.SetPoster(poster)
+	ob.OnDelivered(func(ctx context.Context, entry *models.OutboxEntry) {
+		bus.Publish(ctx, events.NewTransactionSubmitted(&entry.Transaction))
+	})
+	return ob, nil
+}
+
+// events returns the bus changes made by commands are published to. Like
+// the API's, it records webhook deliveries, writes the audit log and
+// appends to the configured event log; the initial deposit and metrics
+// subscribers are left out, as commands neither create accounts nor serve
+// metrics.
+func (a *app) events(ctx context.Context) (*events.Bus, error) {
+	if a.bus != nil {
+		return a.bus, nil
+	}
+	dispatcher, err := a.webhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bus := events.NewBus()
+	bus.Subscribe("webhooks", dispatcher.HandleEvent)
+	bus.Subscribe("audit", events.AuditLog(slog.Default()))
+	if path := a.cfg.Events.NDJSONPath; path != "" {
+		// Closed when the command exits
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open event log: %w", err)
+		}
+		bus.Subscribe("ndjson", events.NDJSONSink(file))
+	}
+	a.bus = bus
+	return bus, nil
+}
+
+// webhooks returns a dispatcher for recording events. The API's dispatcher
+// worker sends them.
+func (a *app) webhooks(ctx context.Context) (*webhooks.Dispatcher, error) {
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.cfg.Webhooks
+	store := repository.NewWebhookRepository(client, a.tables.Webhooks, a.tables.WebhookDeliveries)
+	return webhooks.NewDispatcher(store, cfg.Timeout, cfg.Interval, cfg.InitialBackoff, cfg.MaxBackoff, cfg.MaxAttempts), nil
+}
+
+// poster returns a poster applying changes to stored balances as the API
+// does, with withdrawals checked against the configured products and
+// active holds.
+func (a *app) poster(ctx context.Context) (*posting.Poster, error) {
+	accounts, err := a.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(a.cfg.Statements.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	balances := holds.NewBalances(statements.NewGenerator(a.transactions(), loc), repository.NewHoldRepository(a.client, a.tables.Holds))
+	return posting.NewPoster(accounts, balances, products.NewCatalog(a.cfg.Products)), nil
+}
+
+func (a *app) transactions() *upstream.TransactionService {
+	return upstream.NewTransactionService(a.cfg.TransactionService.URL, upstream.NewClient(a.cfg.TransactionService.Timeout))
+}
+
+func (a *app) interestStore(ctx context.Context) (*repository.InterestRepository, error) {
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewInterestRepository(client, a.tables.InterestAccruals, a.tables.InterestPostings), nil
+}
+
+// interest returns an engine for the configured products, computing
+// balances like the API's statements do.
+func (a *app) interest(ctx context.Context) (*interest.Engine, error) {
+	products := interest.ProductsFromConfig(a.cfg.Interest.Products)
+	if len(products) == 0 {
+		return nil, fmt.Errorf("no interest products are configured")
+	}
+	accounts, err := a.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store, err := a.interestStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ob, err := a.outbox(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(a.cfg.Statements.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	balances := statements.NewGenerator(a.transactions(), loc)
+	return interest.NewEngine(products, accounts, store, balances, ob, a.cfg.Interest.Interval), nil
+}
+
+// parseFlags parses a subcommand's flags, allowing them before or after its
+// positional arguments.
+func parseFlags(fset *flag.FlagSet, args []string, stderr io.Writer) ([]string, error) {
+	fset.SetOutput(stderr)
+	var positional []string
+	for {
+		if err := fset.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError("%v", err)
+		}
+		args = fset.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
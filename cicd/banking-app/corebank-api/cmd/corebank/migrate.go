@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/corebank-api/internal/repository"
+)
+
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	statusOnly := fset.Bool("status", false, "list migrations without applying any")
+	if rest, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError("migrate: unexpected arguments %q", rest)
+	}
+
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !*statusOnly {
+		applied, err := repository.Migrate(ctx, client, a.tables)
+		for _, m := range applied {
+			fmt.Fprintf(a.errOut, "applied %s\n", m.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	states, err := repository.MigrationStatus(ctx, client, a.tables)
+	if err != nil {
+		return err
+	}
+	t := table{value: states, headers: []string{"ID", "APPLIED", "DESCRIPTION"}}
+	for _, s := range states {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = formatTime(*s.AppliedAt)
+		}
+		t.rows = append(t.rows, []string{s.ID, applied, s.Description})
+	}
+	return a.print(t)
+}
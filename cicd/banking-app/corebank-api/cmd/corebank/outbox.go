@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/corebank-api/internal/models"
+)
+
+func runOutbox(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 {
+		return usageError("outbox: missing subcommand")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "list":
+		return outboxList(ctx, a, args)
+	case "redrive":
+		return outboxRedrive(ctx, a, args)
+	default:
+		return usageError("outbox: unknown subcommand %q", sub)
+	}
+}
+
+func outboxList(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("outbox list", flag.ContinueOnError)
+	status := fset.String("status", models.OutboxPending, "pending, delivered or all")
+	if _, err := parseFlags(fset, args, a.errOut); err != nil {
+		return err
+	}
+
+	filter := *status
+	switch filter {
+	case models.OutboxPending, models.OutboxDelivered:
+	case "all":
+		filter = ""
+	default:
+		return usageError("outbox list: -status must be pending, delivered or all")
+	}
+
+	ob, err := a.outbox(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := ob.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []models.OutboxEntry{}
+	}
+	return a.print(outboxTable(entries, entries))
+}
+
+func outboxRedrive(ctx context.Context, a *app, ids []string) error {
+	ob, err := a.outbox(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := ob.Redrive(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []models.OutboxEntry{}
+	}
+	if err := a.print(outboxTable(entries, entries)); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, e := range entries {
+		if e.Status != models.OutboxDelivered {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d outbox entries are still pending", failed, len(entries))
+	}
+	return nil
+}
+
+func outboxTable(value any, entries []models.OutboxEntry) table {
+	t := table{
+		value: value,
+		headers: []string{"ID", "SOURCE", "STATUS", "ACCOUNT", "TYPE", "AMOUNT", "TRANSACTION",
+			"ATTEMPTS", "ACTOR", "REASON", "LAST ERROR", "CREATED"},
+	}
+	for _, e := range entries {
+		t.rows = append(t.rows, []string{
+			e.ID,
+			e.Source,
+			e.Status,
+			e.Transaction.AccountID,
+			e.Transaction.Type,
+			formatAmount(e.Transaction.Amount),
+			orDash(e.Transaction.ID),
+			strconv.Itoa(e.Attempts),
+			orDash(e.Actor),
+			orDash(e.Reason),
+			orDash(e.LastError),
+			formatTime(e.CreatedAt),
+		})
+	}
+	return t
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/reconcile"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/statements"
+)
+
+func runReconcile(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	correct := fset.Bool("correct", false, "adjust differing balances to their ledger balance")
+	actor := fset.String("actor", os.Getenv("USER"), "who is running the reconciliation")
+	ids, err := parseFlags(fset, args, a.errOut)
+	if err != nil {
+		return err
+	}
+	if *correct && *actor == "" {
+		return usageError("reconcile: -actor is required with -correct when $USER is not set")
+	}
+
+	accounts, err := a.accounts(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := a.dynamo(ctx)
+	if err != nil {
+		return err
+	}
+	bus, err := a.events(ctx)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(a.cfg.Statements.Timezone)
+	if err != nil {
+		return err
+	}
+	ledger := statements.NewGenerator(a.transactions(), loc)
+	store := repository.NewReconciliationRepository(client, a.tables.Reconciliations)
+	poster, err := a.poster(ctx)
+	if err != nil {
+		return err
+	}
+	reconciler := reconcile.NewReconciler(accounts, ledger, poster, store, bus, a.cfg.Reconciliation.Interval, false)
+
+	report, err := reconciler.Reconcile(ctx, reconcile.Options{AccountIDs: ids, Correct: *correct, Actor: *actor})
+	if report == nil {
+		return err
+	}
+	if perr := a.print(reconciliationTable(report)); perr != nil {
+		return perr
+	}
+	if err != nil {
+		return fmt.Errorf("reconciliation %s ran but its report was not saved: %w", report.ID, err)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("reconciliation %s could not read the ledger of %d accounts", report.ID, report.Failed)
+	}
+	return nil
+}
+
+func reconciliationTable(report *models.Reconciliation) table {
+	t := table{
+		value:   report,
+		headers: []string{"ACCOUNT", "RECORDED", "EXPECTED", "DIFFERENCE", "PENDING", "CORRECTED", "COVERED", "ERROR"},
+	}
+	for _, d := range report.Discrepancies {
+		t.rows = append(t.rows, []string{
+			d.AccountID,
+			formatAmount(d.Recorded),
+			formatAmount(d.Expected),
+			formatAmount(d.Difference),
+			formatAmount(d.Pending),
+			strconv.FormatBool(d.Corrected),
+			strconv.Itoa(len(d.Covered)),
+			orDash(d.Error),
+		})
+	}
+	t.rows = append(t.rows, []string{
+		fmt.Sprintf("%d checked", report.Checked), "", "", "", "", "", "", fmt.Sprintf("%d failed", report.Failed),
+	})
+	return t
+}
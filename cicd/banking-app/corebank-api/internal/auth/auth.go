@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/corebank-api/internal/config"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Role    string
+}
+
+// IsAdmin reports whether the principal may act on any account.
+func (p Principal) IsAdmin() bool {
+	return p.Role == config.RoleAdmin
+}
+
+type contextKey struct{}
+
+// WithPrincipal stores the authenticated caller in ctx.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the authenticated caller, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// CanAccess reports whether the caller in ctx may act on an account owned by
+// owner. Requests without a principal are only possible when auth is
+// disabled, so they are allowed.
+func CanAccess(ctx context.Context, owner string) bool {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return p.IsAdmin() || p.Subject == owner
+}
+
+// IsAdmin reports whether the caller in ctx may act on every account, as
+// CanAccess does when auth is disabled.
+func IsAdmin(ctx context.Context) bool {
+	p, ok := FromContext(ctx)
+	return !ok || p.IsAdmin()
+}
@@ -1,35 +1,582 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
-	// "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// Config is the complete runtime configuration of corebank-api. It is built
+// from defaults, then an optional YAML file, then environment variables, then
+// command line flags, each layer overriding the previous one.
+type Config struct {
+	Server             ServerConfig             `yaml:"server"`
+	Health             HealthConfig             `yaml:"health"`
+	AWS                AWSConfig                `yaml:"aws"`
+	DynamoDB           DynamoDBConfig           `yaml:"dynamodb"`
+	TransactionService TransactionServiceConfig `yaml:"transaction_service"`
+	CORS               CORSConfig               `yaml:"cors"`
+	Auth               AuthConfig               `yaml:"auth"`
+	Idempotency        IdempotencyConfig        `yaml:"idempotency"`
+	Statements         StatementsConfig         `yaml:"statements"`
+	Interest           InterestConfig           `yaml:"interest"`
+	Products           []ProductConfig          `yaml:"products"`
+	Fees               FeesConfig               `yaml:"fees"`
+	Holds              HoldsConfig              `yaml:"holds"`
+	Schedules          SchedulesConfig          `yaml:"schedules"`
+	Webhooks           WebhooksConfig           `yaml:"webhooks"`
+	Events             EventsConfig             `yaml:"events"`
+	Stream             StreamConfig             `yaml:"stream"`
+	Reconciliation     ReconciliationConfig     `yaml:"reconciliation"`
+	Risk               RiskConfig               `yaml:"risk"`
+	KYC                KYCConfig                `yaml:"kyc"`
+	Log                LogConfig                `yaml:"log"`
+	Tracing            TracingConfig            `yaml:"tracing"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout"`
+	CacheTTL     time.Duration `yaml:"cache_ttl"`
+}
+
 type AWSConfig struct {
-	Region          string
-	AccessKeyID     string
-	SecretAccessKey string
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+}
+
+type DynamoDBConfig struct {
+	// Endpoint overrides the AWS endpoint, e.g. for DynamoDB Local.
+	Endpoint string `yaml:"endpoint"`
+	// TablePrefix and TableSuffix are added to every table name, so several
+	// environments can share one account or DynamoDB Local instance.
+	TablePrefix     string `yaml:"table_prefix"`
+	TableSuffix     string `yaml:"table_suffix"`
+	AccountsTable   string `yaml:"accounts_table"`
+	OutboxTable     string `yaml:"outbox_table"`
+	MigrationsTable string `yaml:"migrations_table"`
+	StatementsTable string `yaml:"statements_table"`
+	// AccountHistoryTable holds each account's log of field changes.
+	AccountHistoryTable string `yaml:"account_history_table"`
+	// InterestAccrualsTable holds the interest accrued per account and day,
+	// InterestPostingsTable the interest paid per account and period.
+	InterestAccrualsTable string `yaml:"interest_accruals_table"`
+	InterestPostingsTable string `yaml:"interest_postings_table"`
+	HoldsTable            string `yaml:"holds_table"`
+	// SchedulesTable holds scheduled transfers, ScheduleExecutionsTable
+	// every attempt to execute one.
+	SchedulesTable          string `yaml:"schedules_table"`
+	ScheduleExecutionsTable string `yaml:"schedule_executions_table"`
+	// WebhooksTable holds webhook subscriptions, WebhookDeliveriesTable
+	// the log of every event sent to one.
+	WebhooksTable          string `yaml:"webhooks_table"`
+	WebhookDeliveriesTable string `yaml:"webhook_deliveries_table"`
+	// ReconciliationsTable holds the report of every reconciliation run.
+	ReconciliationsTable string `yaml:"reconciliations_table"`
+	// BalancePostingsTable records each completed transaction applied to
+	// an account's balance.
+	BalancePostingsTable string `yaml:"balance_postings_table"`
+	// RiskDecisionsTable logs the risk rules' decision on each transaction.
+	RiskDecisionsTable string `yaml:"risk_decisions_table"`
+	// LimitCountersTable holds each account's outflow per day and month.
+	LimitCountersTable string `yaml:"limit_counters_table"`
+	// KYCRecordsTable holds each account's identity submission and the
+	// decision on it.
+	KYCRecordsTable string `yaml:"kyc_records_table"`
+}
+
+// Table returns the full name of the table with the given base name.
+func (d DynamoDBConfig) Table(base string) string {
+	return d.TablePrefix + base + d.TableSuffix
+}
+
+type TransactionServiceConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+type AuthConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Tokens  []TokenConfig `yaml:"tokens"`
+}
+
+// TokenConfig maps a static bearer token to the caller it identifies.
+type TokenConfig struct {
+	Token   string `yaml:"token"`
+	Subject string `yaml:"subject"`
+	Role    string `yaml:"role"`
+}
+
+// IdempotencyConfig controls how long responses to requests with an
+// Idempotency-Key are kept for replay.
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// StatementsConfig controls the job that stores each account's statement
+// for the previous month once the month has ended.
+type StatementsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often the job looks for missing statements.
+	Interval time.Duration `yaml:"interval"`
+	// Timezone is the IANA zone that month and day boundaries are taken in.
+	Timezone string `yaml:"timezone"`
+}
+
+// InterestConfig controls the job that accrues interest every day on accounts
+// whose type has an interest product, and pays it at the end of each period.
+// Days are taken in statements.timezone, the zone balances are reported in.
+type InterestConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often the job looks for days to accrue.
+	Interval time.Duration           `yaml:"interval"`
+	Products []InterestProductConfig `yaml:"products"`
+}
+
+// InterestProductConfig is the interest paid on one account type.
+type InterestProductConfig struct {
+	AccountType string `yaml:"account_type"`
+	// Rate is the annual rate as a fraction, e.g. 0.025 for 2.5%. With
+	// tiers it applies to the part of the balance below the first tier.
+	Rate  float64              `yaml:"rate"`
+	Tiers []InterestTierConfig `yaml:"tiers"`
+	// Compounding is daily, monthly, quarterly or annually. Interest is
+	// paid at the end of each compounding period, except that daily
+	// compounding is paid monthly.
+	Compounding string `yaml:"compounding"`
+	// DayCount is actual/365, actual/360, actual/actual or 30/360.
+	DayCount string `yaml:"day_count"`
+}
+
+// InterestTierConfig pays Rate on the part of the balance above From.
+type InterestTierConfig struct {
+	From float64 `yaml:"from"`
+	Rate float64 `yaml:"rate"`
+}
+
+// DefaultAccountType is the type of accounts created without one. The
+// product catalog must include it.
+const DefaultAccountType = "checking"
+
+// ProductConfig is an account type and the rules accounts of that type
+// follow. Amounts of zero disable the rule.
+type ProductConfig struct {
+	AccountType string `yaml:"account_type"`
+	// OverdraftLimit is how far below MinimumBalance a withdrawal or
+	// transfer may take the balance.
+	OverdraftLimit float64 `yaml:"overdraft_limit"`
+	MinimumBalance float64 `yaml:"minimum_balance"`
+	// InitialDeposit is credited to every new account.
+	InitialDeposit float64 `yaml:"initial_deposit"`
+	// MonthlyFee is charged once each month has ended.
+	MonthlyFee float64 `yaml:"monthly_fee"`
+	// MaxTransactionAmount caps a single transaction or transfer.
+	MaxTransactionAmount float64 `yaml:"max_transaction_amount"`
+	// DailyOutflowLimit and MonthlyOutflowLimit cap what withdrawals and
+	// transfers take from an account per calendar day and month, in the
+	// account's timezone. Accounts may override every limit.
+	DailyOutflowLimit   float64 `yaml:"daily_outflow_limit"`
+	MonthlyOutflowLimit float64 `yaml:"monthly_outflow_limit"`
+}
+
+// FeesConfig controls the job that charges each account its product's
+// monthly fee once the month has ended. Months are taken in
+// statements.timezone.
+type FeesConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// HoldsConfig controls funds holds and the sweeper that expires them.
+type HoldsConfig struct {
+	// DefaultExpiry applies to holds placed without an expiry time, and
+	// MaxExpiry bounds the ones placed with one.
+	DefaultExpiry time.Duration `yaml:"default_expiry"`
+	MaxExpiry     time.Duration `yaml:"max_expiry"`
+	// SweepInterval is how often holds past their expiry are expired.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+// SchedulesConfig controls the scheduler that executes scheduled and
+// recurring transfers. Recurrences are evaluated in statements.timezone.
+type SchedulesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often due schedules are looked for.
+	Interval time.Duration `yaml:"interval"`
+	// A transfer refused for insufficient funds is retried every
+	// RetryInterval, up to MaxAttempts attempts in all, before the
+	// occurrence is given up.
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	MaxAttempts   int           `yaml:"max_attempts"`
+}
+
+// WebhooksConfig controls the dispatcher that sends events to webhook
+// subscribers.
+type WebhooksConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often due deliveries are looked for, and Timeout how
+	// long a subscriber has to respond.
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	// A failed delivery is retried after InitialBackoff, doubling up to
+	// MaxBackoff, until MaxAttempts attempts have failed.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+}
+
+// EventsConfig configures the sinks domain events are published to besides
+// the in-process subscribers.
+type EventsConfig struct {
+	// NDJSONPath is a file each event is appended to as a line of JSON;
+	// empty for none.
+	NDJSONPath string `yaml:"ndjson_path"`
+}
+
+// StreamConfig controls the Server-Sent Events streams of account changes.
+type StreamConfig struct {
+	// Heartbeat is how often an idle stream is sent a comment.
+	Heartbeat time.Duration `yaml:"heartbeat"`
+	// BufferSize is how many recent messages, across all accounts, are kept
+	// for clients resuming with Last-Event-ID.
+	BufferSize int `yaml:"buffer_size"`
 }
 
-func LoadConfig() AWSConfig {
-	err := godotenv.Load()
+// ReconciliationConfig controls the job that checks each account's stored
+// balance against the balance of its completed transactions.
+type ReconciliationConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`
+	// Correct sets balances that differ to the ledger's, recording each
+	// correction in the account's history. Otherwise differences are only
+	// reported.
+	Correct bool `yaml:"correct"`
+}
+
+// RiskConfig configures the rules transactions are screened with before
+// they are submitted.
+type RiskConfig struct {
+	// RulesFile is a YAML file of rules; empty for none, in which case every
+	// transaction is allowed.
+	RulesFile string `yaml:"rules_file"`
+}
+
+// KYCConfig caps the limits of customers whose identity is not verified.
+// Each cap applies where it is lower than the product's limit, or the
+// product has none; zero leaves the product's. Verified customers follow
+// their product alone, and rejected ones may not transact at all.
+type KYCConfig struct {
+	Unverified KYCTierConfig `yaml:"unverified"`
+	Pending    KYCTierConfig `yaml:"pending"`
+}
+
+// KYCTierConfig is the limits of one KYC status.
+type KYCTierConfig struct {
+	MaxTransactionAmount float64 `yaml:"max_transaction_amount"`
+	DailyOutflowLimit    float64 `yaml:"daily_outflow_limit"`
+	MonthlyOutflowLimit  float64 `yaml:"monthly_outflow_limit"`
+}
+
+type LogConfig struct {
+	Level     string `yaml:"level"`
+	RedactPII bool   `yaml:"redact_pii"`
+}
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+}
+
+// Default returns the configuration used when nothing else is provided.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			CacheTTL:     5 * time.Second,
+		},
+		AWS: AWSConfig{
+			Region: "us-east-1",
+		},
+		DynamoDB: DynamoDBConfig{
+			AccountsTable:           "BankAccounts",
+			OutboxTable:             "BankOutbox",
+			MigrationsTable:         "SchemaMigrations",
+			StatementsTable:         "BankStatements",
+			AccountHistoryTable:     "BankAccountHistory",
+			InterestAccrualsTable:   "BankInterestAccruals",
+			InterestPostingsTable:   "BankInterestPostings",
+			HoldsTable:              "BankHolds",
+			SchedulesTable:          "BankSchedules",
+			ScheduleExecutionsTable: "BankScheduleExecutions",
+			WebhooksTable:           "BankWebhooks",
+			WebhookDeliveriesTable:  "BankWebhookDeliveries",
+			ReconciliationsTable:    "BankReconciliations",
+			BalancePostingsTable:    "BankBalancePostings",
+			RiskDecisionsTable:      "BankRiskDecisions",
+			LimitCountersTable:      "BankLimitCounters",
+			KYCRecordsTable:         "BankKYCRecords",
+		},
+		TransactionService: TransactionServiceConfig{
+			URL:     "http://localhost:5000",
+			Timeout: 5 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: 24 * time.Hour,
+		},
+		Statements: StatementsConfig{
+			Enabled:  true,
+			Interval: time.Hour,
+			Timezone: "UTC",
+		},
+		Interest: InterestConfig{
+			Enabled:  true,
+			Interval: time.Hour,
+		},
+		Products: []ProductConfig{
+			{AccountType: DefaultAccountType, InitialDeposit: 1000},
+			{AccountType: "savings", InitialDeposit: 1000},
+		},
+		Fees: FeesConfig{
+			Enabled:  true,
+			Interval: time.Hour,
+		},
+		Holds: HoldsConfig{
+			DefaultExpiry: 7 * 24 * time.Hour,
+			MaxExpiry:     30 * 24 * time.Hour,
+			SweepInterval: time.Minute,
+		},
+		Schedules: SchedulesConfig{
+			Enabled:       true,
+			Interval:      time.Minute,
+			RetryInterval: time.Hour,
+			MaxAttempts:   3,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:        true,
+			Interval:       5 * time.Second,
+			Timeout:        10 * time.Second,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     time.Hour,
+			MaxAttempts:    10,
+		},
+		Reconciliation: ReconciliationConfig{
+			Interval: 24 * time.Hour,
+		},
+		KYC: KYCConfig{
+			Unverified: KYCTierConfig{MaxTransactionAmount: 1000, DailyOutflowLimit: 1000, MonthlyOutflowLimit: 3000},
+			Pending:    KYCTierConfig{MaxTransactionAmount: 5000, DailyOutflowLimit: 5000, MonthlyOutflowLimit: 20000},
+		},
+		Stream: StreamConfig{
+			Heartbeat:  15 * time.Second,
+			BufferSize: 1000,
+		},
+		Log: LogConfig{
+			Level:     "info",
+			RedactPII: true,
+		},
+		Tracing: TracingConfig{
+			Exporter: "none",
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the optional file named by
+// -config or CONFIG_FILE, the environment (including a .env file if present)
+// and the given command line arguments, then validates it.
+func Load(args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("error loading .env file", "error", err)
+	}
+
+	fset := flag.NewFlagSet("corebank-api", flag.ContinueOnError)
+	configFile := fset.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	port := fset.String("port", "", "HTTP listen port")
+	logLevel := fset.String("log-level", "", "log level: debug, info, warn or error")
+	txnURL := fset.String("transaction-service-url", "", "base URL of the transaction service")
+	dynamoEndpoint := fset.String("dynamodb-endpoint", "", "DynamoDB endpoint override")
+	tablePrefix := fset.String("table-prefix", "", "prefix added to every DynamoDB table name")
+	if err := fset.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	fset.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "transaction-service-url":
+			cfg.TransactionService.URL = *txnURL
+		case "dynamodb-endpoint":
+			cfg.DynamoDB.Endpoint = *dynamoEndpoint
+		case "table-prefix":
+			cfg.DynamoDB.TablePrefix = *tablePrefix
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Println("Error loading .env file")
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	// Reject unknown keys so typos don't silently fall back to defaults
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
 	}
+	return nil
+}
+
+// applyEnv overrides fields from environment variables that are set.
+func (c *Config) applyEnv() error {
+	var errs []error
 
-	return AWSConfig{
-		Region:          getEnv("AWS_REGION", "us-east-1"),
-		AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
-		SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+	setString(&c.Server.Port, "PORT")
+	errs = append(errs,
+		setDuration(&c.Server.ReadHeaderTimeout, "HTTP_READ_HEADER_TIMEOUT"),
+		setDuration(&c.Server.ReadTimeout, "HTTP_READ_TIMEOUT"),
+		setDuration(&c.Server.WriteTimeout, "HTTP_WRITE_TIMEOUT"),
+		setDuration(&c.Server.IdleTimeout, "HTTP_IDLE_TIMEOUT"),
+		setInt(&c.Server.MaxHeaderBytes, "HTTP_MAX_HEADER_BYTES"),
+		setDuration(&c.Server.DrainDelay, "SHUTDOWN_DRAIN_DELAY"),
+		setDuration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT"),
+		setDuration(&c.Health.CheckTimeout, "HEALTH_CHECK_TIMEOUT"),
+		setDuration(&c.Health.CacheTTL, "HEALTH_CACHE_TTL"),
+	)
+
+	setString(&c.AWS.Region, "AWS_REGION")
+	setString(&c.AWS.AccessKeyID, "AWS_ACCESS_KEY_ID")
+	setString(&c.AWS.SecretAccessKey, "AWS_SECRET_ACCESS_KEY")
+	setString(&c.DynamoDB.Endpoint, "DYNAMODB_ENDPOINT")
+	setString(&c.DynamoDB.TablePrefix, "DYNAMODB_TABLE_PREFIX")
+	setString(&c.DynamoDB.TableSuffix, "DYNAMODB_TABLE_SUFFIX")
+	setString(&c.DynamoDB.AccountsTable, "DYNAMODB_ACCOUNTS_TABLE")
+	setString(&c.DynamoDB.OutboxTable, "DYNAMODB_OUTBOX_TABLE")
+	setString(&c.DynamoDB.MigrationsTable, "DYNAMODB_MIGRATIONS_TABLE")
+	setString(&c.DynamoDB.StatementsTable, "DYNAMODB_STATEMENTS_TABLE")
+	setString(&c.DynamoDB.AccountHistoryTable, "DYNAMODB_ACCOUNT_HISTORY_TABLE")
+	setString(&c.DynamoDB.InterestAccrualsTable, "DYNAMODB_INTEREST_ACCRUALS_TABLE")
+	setString(&c.DynamoDB.InterestPostingsTable, "DYNAMODB_INTEREST_POSTINGS_TABLE")
+	setString(&c.DynamoDB.HoldsTable, "DYNAMODB_HOLDS_TABLE")
+	setString(&c.DynamoDB.SchedulesTable, "DYNAMODB_SCHEDULES_TABLE")
+	setString(&c.DynamoDB.ScheduleExecutionsTable, "DYNAMODB_SCHEDULE_EXECUTIONS_TABLE")
+	setString(&c.DynamoDB.WebhooksTable, "DYNAMODB_WEBHOOKS_TABLE")
+	setString(&c.DynamoDB.WebhookDeliveriesTable, "DYNAMODB_WEBHOOK_DELIVERIES_TABLE")
+	setString(&c.DynamoDB.ReconciliationsTable, "DYNAMODB_RECONCILIATIONS_TABLE")
+	setString(&c.DynamoDB.BalancePostingsTable, "DYNAMODB_BALANCE_POSTINGS_TABLE")
+	setString(&c.DynamoDB.RiskDecisionsTable, "DYNAMODB_RISK_DECISIONS_TABLE")
+	setString(&c.DynamoDB.LimitCountersTable, "DYNAMODB_LIMIT_COUNTERS_TABLE")
+	setString(&c.DynamoDB.KYCRecordsTable, "DYNAMODB_KYC_RECORDS_TABLE")
+
+	setString(&c.TransactionService.URL, "TRANSACTION_SERVICE_URL")
+	errs = append(errs, setDuration(&c.TransactionService.Timeout, "TRANSACTION_SERVICE_TIMEOUT"))
+
+	setList(&c.CORS.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+
+	errs = append(errs, setBool(&c.Auth.Enabled, "AUTH_ENABLED"))
+	if value, ok := os.LookupEnv("AUTH_TOKENS"); ok {
+		tokens, err := parseTokens(value)
+		errs = append(errs, err)
+		c.Auth.Tokens = tokens
 	}
+
+	errs = append(errs, setDuration(&c.Idempotency.TTL, "IDEMPOTENCY_KEY_TTL"))
+
+	errs = append(errs,
+		setBool(&c.Statements.Enabled, "STATEMENTS_ENABLED"),
+		setDuration(&c.Statements.Interval, "STATEMENTS_INTERVAL"),
+	)
+	setString(&c.Statements.Timezone, "STATEMENTS_TIMEZONE")
+
+	errs = append(errs,
+		setBool(&c.Interest.Enabled, "INTEREST_ENABLED"),
+		setDuration(&c.Interest.Interval, "INTEREST_INTERVAL"),
+		setBool(&c.Fees.Enabled, "FEES_ENABLED"),
+		setDuration(&c.Fees.Interval, "FEES_INTERVAL"),
+		setDuration(&c.Holds.DefaultExpiry, "HOLDS_DEFAULT_EXPIRY"),
+		setDuration(&c.Holds.MaxExpiry, "HOLDS_MAX_EXPIRY"),
+		setDuration(&c.Holds.SweepInterval, "HOLDS_SWEEP_INTERVAL"),
+		setBool(&c.Schedules.Enabled, "SCHEDULES_ENABLED"),
+		setDuration(&c.Schedules.Interval, "SCHEDULES_INTERVAL"),
+		setDuration(&c.Schedules.RetryInterval, "SCHEDULES_RETRY_INTERVAL"),
+		setInt(&c.Schedules.MaxAttempts, "SCHEDULES_MAX_ATTEMPTS"),
+		setBool(&c.Webhooks.Enabled, "WEBHOOKS_ENABLED"),
+		setDuration(&c.Webhooks.Interval, "WEBHOOKS_INTERVAL"),
+		setDuration(&c.Webhooks.Timeout, "WEBHOOKS_TIMEOUT"),
+		setDuration(&c.Webhooks.InitialBackoff, "WEBHOOKS_INITIAL_BACKOFF"),
+		setDuration(&c.Webhooks.MaxBackoff, "WEBHOOKS_MAX_BACKOFF"),
+		setInt(&c.Webhooks.MaxAttempts, "WEBHOOKS_MAX_ATTEMPTS"),
+	)
+	setString(&c.Events.NDJSONPath, "EVENTS_NDJSON_PATH")
+	errs = append(errs,
+		setDuration(&c.Stream.Heartbeat, "STREAM_HEARTBEAT"),
+		setInt(&c.Stream.BufferSize, "STREAM_BUFFER_SIZE"),
+		setBool(&c.Reconciliation.Enabled, "RECONCILIATION_ENABLED"),
+		setDuration(&c.Reconciliation.Interval, "RECONCILIATION_INTERVAL"),
+		setBool(&c.Reconciliation.Correct, "RECONCILIATION_CORRECT"),
+	)
+	setString(&c.Risk.RulesFile, "RISK_RULES_FILE")
+
+	setString(&c.Log.Level, "LOG_LEVEL")
+	errs = append(errs, setBool(&c.Log.RedactPII, "LOG_REDACT_PII"))
+	setString(&c.Tracing.Exporter, "OTEL_TRACES_EXPORTER")
+
+	return errors.Join(errs...)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// parseTokens reads AUTH_TOKENS, a comma separated list of
+// subject:role:token entries.
+func parseTokens(value string) ([]TokenConfig, error) {
+	var tokens []TokenConfig
+	for _, entry := range splitList(value) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, errors.New("AUTH_TOKENS entries must be subject:role:token")
+		}
+		tokens = append(tokens, TokenConfig{Subject: parts[0], Role: parts[1], Token: parts[2]})
 	}
-	return defaultValue
-}
\ No newline at end of file
+	return tokens, nil
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default configuration is invalid: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "bad port",
+			change: func(c *Config) { c.Server.Port = "80a" },
+			want:   []string{`server.port: "80a" is not a valid port`},
+		},
+		{
+			name:   "non-positive durations",
+			change: func(c *Config) { c.Server.ReadTimeout = 0; c.Webhooks.Timeout = -time.Second },
+			want:   []string{"server.read_timeout: must be positive", "webhooks.timeout: must be positive"},
+		},
+		{
+			name:   "hold expiry above the maximum",
+			change: func(c *Config) { c.Holds.DefaultExpiry = 2 * c.Holds.MaxExpiry },
+			want:   []string{"holds.default_expiry: must not exceed holds.max_expiry"},
+		},
+		{
+			name:   "half of the AWS credentials",
+			change: func(c *Config) { c.AWS.AccessKeyID = "AKIA" },
+			want:   []string{"access_key_id and secret_access_key must be set together"},
+		},
+		{
+			name:   "bad DynamoDB endpoint",
+			change: func(c *Config) { c.DynamoDB.Endpoint = "localhost:8000" },
+			want:   []string{"dynamodb.endpoint: must be an http or https URL"},
+		},
+		{
+			name:   "missing table",
+			change: func(c *Config) { c.DynamoDB.HoldsTable = "" },
+			want:   []string{"dynamodb.holds_table: is required"},
+		},
+		{
+			name:   "unknown timezone",
+			change: func(c *Config) { c.Statements.Timezone = "Mars/Olympus" },
+			want:   []string{"statements.timezone:"},
+		},
+		{
+			name: "bad products",
+			change: func(c *Config) {
+				c.Products = append(c.Products,
+					ProductConfig{AccountType: "savings"},
+					ProductConfig{AccountType: "basic", MonthlyFee: math.NaN(), InitialDeposit: 500, MaxTransactionAmount: 100})
+			},
+			want: []string{
+				`products[2].account_type: duplicate product for "savings"`,
+				"products[3].monthly_fee: must be zero or a positive amount",
+				"products[3].initial_deposit: must not exceed max_transaction_amount",
+			},
+		},
+		{
+			name:   "no default product",
+			change: func(c *Config) { c.Products = c.Products[1:] },
+			want:   []string{`a product for the default account type "checking" is required`},
+		},
+		{
+			name:   "negative KYC cap",
+			change: func(c *Config) { c.KYC.Pending.DailyOutflowLimit = -1 },
+			want:   []string{"kyc.pending.daily_outflow_limit: must be zero or a positive amount"},
+		},
+		{
+			name: "bad interest products",
+			change: func(c *Config) {
+				c.Interest.Products = []InterestProductConfig{
+					{AccountType: "brokerage", Rate: 0.01},
+					{AccountType: "savings", Rate: 1.5, Tiers: []InterestTierConfig{{From: 1000, Rate: 0.02}, {From: 500, Rate: 0.03}},
+						Compounding: "weekly", DayCount: "30/365"},
+				}
+			},
+			want: []string{
+				`interest.products[0].account_type: "brokerage" is not in the product catalog`,
+				"interest.products[1].rate: must be between 0 and 1",
+				"interest.products[1].tiers[1].from: must be positive and above the previous tier",
+				`interest.products[1].compounding: "weekly"`,
+				`interest.products[1].day_count: "30/365"`,
+			},
+		},
+		{
+			name: "bad tokens",
+			change: func(c *Config) {
+				c.Auth.Enabled = true
+				c.Auth.Tokens = []TokenConfig{
+					{Token: "t1", Subject: "ops", Role: RoleAdmin},
+					{Token: "t1", Subject: "dana", Role: "owner"},
+					{Token: "", Subject: "kim", Role: RoleUser},
+				}
+			},
+			want: []string{
+				`auth.tokens[1]: role must be "admin" or "user"`,
+				"auth.tokens[1]: duplicate token",
+				"auth.tokens[2]: token and subject are required",
+			},
+		},
+		{
+			name:   "auth without tokens",
+			change: func(c *Config) { c.Auth.Enabled = true },
+			want:   []string{"auth.tokens: at least one token is required"},
+		},
+		{
+			name:   "bad log level and exporter",
+			change: func(c *Config) { c.Log.Level = "trace"; c.Tracing.Exporter = "zipkin" },
+			want:   []string{`log.level: "trace"`, `tracing.exporter: "zipkin"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.change(&cfg)
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Validate succeeded, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			if got := len(strings.Split(err.Error(), "\n")); got != len(tt.want) {
+				t.Errorf("got %d problems, want %d: %v", got, len(tt.want), err)
+			}
+		})
+	}
+}
+
+func TestValidateTableNames(t *testing.T) {
+	tests := []struct {
+		name           string
+		prefix, suffix string
+		wantErr        string
+	}{
+		{name: "no prefix"},
+		{name: "environment prefix", prefix: "staging-", suffix: ".v2"},
+		{name: "prefix with a slash", prefix: "dev/", wantErr: `dynamodb.accounts_table: table name "dev/BankAccounts"`},
+		{name: "too long", suffix: strings.Repeat("x", 250), wantErr: "must be 3-255 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.DynamoDB.TablePrefix, cfg.DynamoDB.TableSuffix = tt.prefix, tt.suffix
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Each layer overrides the one before it: defaults, file, environment,
+// flags.
+func TestLoadLayering(t *testing.T) {
+	path := writeConfig(t, `server:
+  port: "9000"
+  read_timeout: 20s
+log:
+  level: debug
+dynamodb:
+  table_prefix: file-
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "9100")
+	t.Setenv("DYNAMODB_TABLE_PREFIX", "env-")
+	t.Setenv("AUTH_TOKENS", "ops:admin:secret")
+
+	cfg, err := Load([]string{"-table-prefix", "flag-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != "9100" {
+		t.Errorf("port = %q, want the environment's", cfg.Server.Port)
+	}
+	if cfg.Server.ReadTimeout != 20*time.Second || cfg.Log.Level != "debug" {
+		t.Errorf("read timeout %s and log level %q, want the file's", cfg.Server.ReadTimeout, cfg.Log.Level)
+	}
+	if cfg.Server.WriteTimeout != Default().Server.WriteTimeout {
+		t.Errorf("write timeout = %s, want the default", cfg.Server.WriteTimeout)
+	}
+	if got := cfg.DynamoDB.Table(cfg.DynamoDB.AccountsTable); got != "flag-BankAccounts" {
+		t.Errorf("accounts table = %q, want the flag's prefix", got)
+	}
+	if len(cfg.Auth.Tokens) != 1 || cfg.Auth.Tokens[0] != (TokenConfig{Subject: "ops", Role: RoleAdmin, Token: "secret"}) {
+		t.Errorf("tokens = %+v", cfg.Auth.Tokens)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{name: "unknown file key", file: "server:\n  prot: \"9000\"\n", want: "field prot not found"},
+		{name: "bad environment duration", env: map[string]string{"HTTP_READ_TIMEOUT": "soon"}, want: "HTTP_READ_TIMEOUT"},
+		{name: "bad token list", env: map[string]string{"AUTH_TOKENS": "ops:secret"}, want: "subject:role:token"},
+		{name: "invalid result", env: map[string]string{"PORT": "0"}, want: "server.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			if tt.file != "" {
+				t.Setenv("CONFIG_FILE", writeConfig(t, tt.file))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.AWS.AccessKeyID, cfg.AWS.SecretAccessKey = "AKIA-1", "aws-key-2"
+	cfg.Auth.Tokens = []TokenConfig{{Token: "token-3", Subject: "ops", Role: RoleAdmin}}
+
+	out := cfg.YAML()
+	for _, secret := range []string{"AKIA-1", "aws-key-2", "token-3"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("rendered configuration contains %q", secret)
+		}
+	}
+	if !strings.Contains(out, "subject: ops") {
+		t.Errorf("rendered configuration lost the token subject:\n%s", out)
+	}
+	if cfg.Auth.Tokens[0].Token != "token-3" {
+		t.Error("Redacted changed the original tokens")
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with secrets masked, safe to
+// print or log.
+func (c Config) Redacted() Config {
+	if c.AWS.AccessKeyID != "" {
+		c.AWS.AccessKeyID = redacted
+	}
+	if c.AWS.SecretAccessKey != "" {
+		c.AWS.SecretAccessKey = redacted
+	}
+
+	tokens := make([]TokenConfig, len(c.Auth.Tokens))
+	for i, t := range c.Auth.Tokens {
+		t.Token = redacted
+		tokens[i] = t
+	}
+	c.Auth.Tokens = tokens
+	return c
+}
+
+// YAML renders the redacted configuration in the config file format.
+func (c Config) YAML() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
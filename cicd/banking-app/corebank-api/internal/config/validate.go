@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Roles a bearer token can be granted.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Validate reports every problem with the configuration at once, so a bad
+// deploy fails at startup with a complete list.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		fail("server.port: %q is not a valid port", c.Server.Port)
+	}
+	for name, d := range map[string]time.Duration{
+		"server.read_header_timeout":  c.Server.ReadHeaderTimeout,
+		"server.read_timeout":         c.Server.ReadTimeout,
+		"server.idle_timeout":         c.Server.IdleTimeout,
+		"server.shutdown_timeout":     c.Server.ShutdownTimeout,
+		"health.check_timeout":        c.Health.CheckTimeout,
+		"transaction_service.timeout": c.TransactionService.Timeout,
+		"idempotency.ttl":             c.Idempotency.TTL,
+		"statements.interval":         c.Statements.Interval,
+		"interest.interval":           c.Interest.Interval,
+		"fees.interval":               c.Fees.Interval,
+		"holds.default_expiry":        c.Holds.DefaultExpiry,
+		"holds.max_expiry":            c.Holds.MaxExpiry,
+		"holds.sweep_interval":        c.Holds.SweepInterval,
+		"schedules.interval":          c.Schedules.Interval,
+		"schedules.retry_interval":    c.Schedules.RetryInterval,
+		"webhooks.interval":           c.Webhooks.Interval,
+		"webhooks.timeout":            c.Webhooks.Timeout,
+		"webhooks.initial_backoff":    c.Webhooks.InitialBackoff,
+		"webhooks.max_backoff":        c.Webhooks.MaxBackoff,
+		"stream.heartbeat":            c.Stream.Heartbeat,
+		"reconciliation.interval":     c.Reconciliation.Interval,
+	} {
+		if d <= 0 {
+			fail("%s: must be positive", name)
+		}
+	}
+	if c.Holds.DefaultExpiry > c.Holds.MaxExpiry {
+		fail("holds.default_expiry: must not exceed holds.max_expiry")
+	}
+	if c.Schedules.MaxAttempts < 1 {
+		fail("schedules.max_attempts: must be at least 1")
+	}
+	if c.Webhooks.InitialBackoff > c.Webhooks.MaxBackoff {
+		fail("webhooks.initial_backoff: must not exceed webhooks.max_backoff")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		fail("webhooks.max_attempts: must be at least 1")
+	}
+	if c.Stream.BufferSize < 1 {
+		fail("stream.buffer_size: must be at least 1")
+	}
+	if c.Server.WriteTimeout < 0 {
+		fail("server.write_timeout: must not be negative")
+	}
+	if c.Server.DrainDelay < 0 {
+		fail("server.drain_delay: must not be negative")
+	}
+	if c.Server.MaxHeaderBytes < 1024 {
+		fail("server.max_header_bytes: must be at least 1024")
+	}
+
+	if c.AWS.Region == "" {
+		fail("aws.region: is required")
+	}
+	if (c.AWS.AccessKeyID == "") != (c.AWS.SecretAccessKey == "") {
+		fail("aws: access_key_id and secret_access_key must be set together")
+	}
+	if c.DynamoDB.Endpoint != "" {
+		if err := validateURL(c.DynamoDB.Endpoint); err != nil {
+			fail("dynamodb.endpoint: %v", err)
+		}
+	}
+	for _, table := range []struct{ key, base string }{
+		{"accounts_table", c.DynamoDB.AccountsTable},
+		{"outbox_table", c.DynamoDB.OutboxTable},
+		{"migrations_table", c.DynamoDB.MigrationsTable},
+		{"statements_table", c.DynamoDB.StatementsTable},
+		{"account_history_table", c.DynamoDB.AccountHistoryTable},
+		{"interest_accruals_table", c.DynamoDB.InterestAccrualsTable},
+		{"interest_postings_table", c.DynamoDB.InterestPostingsTable},
+		{"holds_table", c.DynamoDB.HoldsTable},
+		{"schedules_table", c.DynamoDB.SchedulesTable},
+		{"schedule_executions_table", c.DynamoDB.ScheduleExecutionsTable},
+		{"webhooks_table", c.DynamoDB.WebhooksTable},
+		{"webhook_deliveries_table", c.DynamoDB.WebhookDeliveriesTable},
+		{"reconciliations_table", c.DynamoDB.ReconciliationsTable},
+		{"balance_postings_table", c.DynamoDB.BalancePostingsTable},
+		{"risk_decisions_table", c.DynamoDB.RiskDecisionsTable},
+		{"limit_counters_table", c.DynamoDB.LimitCountersTable},
+		{"kyc_records_table", c.DynamoDB.KYCRecordsTable},
+	} {
+		if table.base == "" {
+			fail("dynamodb.%s: is required", table.key)
+		} else if name := c.DynamoDB.Table(table.base); !validTableName(name) {
+			fail("dynamodb.%s: table name %q must be 3-255 characters of a-z, A-Z, 0-9, '_', '-' or '.'", table.key, name)
+		}
+	}
+
+	if _, err := time.LoadLocation(c.Statements.Timezone); err != nil {
+		fail("statements.timezone: %v", err)
+	}
+
+	accountTypes := make(map[string]bool)
+	for i, p := range c.Products {
+		key := fmt.Sprintf("products[%d]", i)
+		if p.AccountType == "" {
+			fail("%s.account_type: is required", key)
+		} else if accountTypes[p.AccountType] {
+			fail("%s.account_type: duplicate product for %q", key, p.AccountType)
+		}
+		accountTypes[p.AccountType] = true
+		for name, amount := range map[string]float64{
+			"overdraft_limit":        p.OverdraftLimit,
+			"minimum_balance":        p.MinimumBalance,
+			"initial_deposit":        p.InitialDeposit,
+			"monthly_fee":            p.MonthlyFee,
+			"max_transaction_amount": p.MaxTransactionAmount,
+			"daily_outflow_limit":    p.DailyOutflowLimit,
+			"monthly_outflow_limit":  p.MonthlyOutflowLimit,
+		} {
+			if !(amount >= 0) || math.IsInf(amount, 0) {
+				fail("%s.%s: must be zero or a positive amount", key, name)
+			}
+		}
+		if p.MaxTransactionAmount > 0 && p.InitialDeposit > p.MaxTransactionAmount {
+			fail("%s.initial_deposit: must not exceed max_transaction_amount", key)
+		}
+	}
+	if !accountTypes[DefaultAccountType] {
+		fail("products: a product for the default account type %q is required", DefaultAccountType)
+	}
+	for name, tier := range map[string]KYCTierConfig{"unverified": c.KYC.Unverified, "pending": c.KYC.Pending} {
+		for field, amount := range map[string]float64{
+			"max_transaction_amount": tier.MaxTransactionAmount,
+			"daily_outflow_limit":    tier.DailyOutflowLimit,
+			"monthly_outflow_limit":  tier.MonthlyOutflowLimit,
+		} {
+			if !(amount >= 0) || math.IsInf(amount, 0) {
+				fail("kyc.%s.%s: must be zero or a positive amount", name, field)
+			}
+		}
+	}
+
+	products := make(map[string]bool)
+	for i, p := range c.Interest.Products {
+		key := fmt.Sprintf("interest.products[%d]", i)
+		switch {
+		case p.AccountType == "":
+			fail("%s.account_type: is required", key)
+		case products[p.AccountType]:
+			fail("%s.account_type: duplicate product for %q", key, p.AccountType)
+		case !accountTypes[p.AccountType]:
+			fail("%s.account_type: %q is not in the product catalog", key, p.AccountType)
+		}
+		products[p.AccountType] = true
+		if !validRate(p.Rate) {
+			fail("%s.rate: must be between 0 and 1", key)
+		}
+		for j, tier := range p.Tiers {
+			if !validRate(tier.Rate) {
+				fail("%s.tiers[%d].rate: must be between 0 and 1", key, j)
+			}
+			if tier.From <= 0 || (j > 0 && tier.From <= p.Tiers[j-1].From) {
+				fail("%s.tiers[%d].from: must be positive and above the previous tier", key, j)
+			}
+		}
+		switch p.Compounding {
+		case "", "daily", "monthly", "quarterly", "annually":
+		default:
+			fail("%s.compounding: %q is not one of daily, monthly, quarterly, annually", key, p.Compounding)
+		}
+		switch p.DayCount {
+		case "", "actual/365", "actual/360", "actual/actual", "30/360":
+		default:
+			fail("%s.day_count: %q is not one of actual/365, actual/360, actual/actual, 30/360", key, p.DayCount)
+		}
+	}
+
+	if err := validateURL(c.TransactionService.URL); err != nil {
+		fail("transaction_service.url: %v", err)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin != "*" {
+			if err := validateURL(origin); err != nil {
+				fail("cors.allowed_origins: %q: %v", origin, err)
+			}
+		}
+	}
+
+	if c.Auth.Enabled && len(c.Auth.Tokens) == 0 {
+		fail("auth.tokens: at least one token is required when auth is enabled")
+	}
+	seen := make(map[string]bool)
+	for i, t := range c.Auth.Tokens {
+		if t.Token == "" || t.Subject == "" {
+			fail("auth.tokens[%d]: token and subject are required", i)
+		}
+		if t.Role != RoleAdmin && t.Role != RoleUser {
+			fail("auth.tokens[%d]: role must be %q or %q", i, RoleAdmin, RoleUser)
+		}
+		if seen[t.Token] {
+			fail("auth.tokens[%d]: duplicate token", i)
+		}
+		seen[t.Token] = true
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		fail("log.level: %q is not one of debug, info, warn, error", c.Log.Level)
+	}
+	switch strings.ToLower(c.Tracing.Exporter) {
+	case "otlp", "stdout", "console", "none":
+	default:
+		fail("tracing.exporter: %q is not one of otlp, stdout, none", c.Tracing.Exporter)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("must be an http or https URL")
+	}
+	if u.Host == "" {
+		return errors.New("must include a host")
+	}
+	return nil
+}
+
+// validRate reports whether rate is an annual rate between 0% and 100%.
+func validRate(rate float64) bool {
+	return rate >= 0 && rate <= 1
+}
+
+// validTableName applies DynamoDB's table naming rules.
+func validTableName(name string) bool {
+	if len(name) < 3 || len(name) > 255 {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+// Package events publishes domain events, such as an account being created,
+// to the subscribers that act on them: the initial deposit, webhooks,
+// metrics and the audit log each subscribe independently instead of being
+// called by the code that made the change.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+)
+
+// Event types.
+const (
+	AccountCreated       = "AccountCreated"
+	AccountUpdated       = "AccountUpdated"
+	AccountDeleted       = "AccountDeleted"
+	TransactionSubmitted = "TransactionSubmitted"
+	TransactionUpdated   = "TransactionUpdated"
+)
+
+// Event is a change that has been made. Which of the optional fields are set
+// depends on Type.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	AccountID  string    `json:"account_id"`
+	// Actor is the authenticated subject, or the operator for CLI changes.
+	Actor     string `json:"actor,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Account is the account after the change, for AccountCreated and
+	// AccountUpdated.
+	Account *models.Account `json:"account,omitempty"`
+	// Changes are the fields an AccountUpdated changed.
+	Changes []models.AccountChange `json:"changes,omitempty"`
+	// InitialDeposit is the amount an AccountCreated is to be credited, zero
+	// for none.
+	InitialDeposit float64 `json:"initial_deposit,omitempty"`
+	// Transaction is the transaction a TransactionSubmitted recorded with the
+	// transaction service, or as a TransactionUpdated left it.
+	Transaction *models.Transaction `json:"transaction,omitempty"`
+}
+
+func NewAccountCreated(account *models.Account, initialDeposit float64) Event {
+	return Event{Type: AccountCreated, AccountID: account.ID, Account: account, InitialDeposit: initialDeposit}
+}
+
+func NewAccountUpdated(account *models.Account, changes []models.AccountChange) Event {
+	return Event{Type: AccountUpdated, AccountID: account.ID, Account: account, Changes: changes}
+}
+
+func NewAccountDeleted(id string) Event {
+	return Event{Type: AccountDeleted, AccountID: id}
+}
+
+func NewTransactionSubmitted(txn *models.Transaction) Event {
+	return Event{Type: TransactionSubmitted, AccountID: txn.AccountID, Transaction: txn}
+}
+
+func NewTransactionUpdated(txn *models.Transaction) Event {
+	return Event{Type: TransactionUpdated, AccountID: txn.AccountID, Transaction: txn}
+}
+
+// Changed returns the change an AccountUpdated made to field, if any.
+func (e Event) Changed(field string) (models.AccountChange, bool) {
+	for _, c := range e.Changes {
+		if c.Field == field {
+			return c, true
+		}
+	}
+	return models.AccountChange{}, false
+}
+
+// Publisher publishes events; Bus implements it.
+type Publisher interface {
+	// Publish hands the event to every subscriber and returns their
+	// failures joined. The change has been made either way, so only
+	// publishers whose response depends on a subscriber, such as account
+	// creation on its initial deposit, need to check the error.
+	Publish(ctx context.Context, event Event) error
+}
+
+// Handler acts on an event.
+type Handler func(ctx context.Context, event Event) error
+
+type subscriber struct {
+	name    string
+	types   []string
+	handler Handler
+}
+
+// Bus delivers each event to its subscribers in-process, synchronously and
+// in the order they subscribed.
+type Bus struct {
+	subscribers []subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler under name for events of the given types, or
+// every event if none are given. Subscribe must be called before the bus is
+// used.
+func (b *Bus) Subscribe(name string, handler Handler, types ...string) {
+	b.subscribers = append(b.subscribers, subscriber{name: name, types: types, handler: handler})
+}
+
+// Publish fills in the event's ID, time, actor and request ID where unset
+// and hands it to every subscriber, even when an earlier one fails. Each
+// failure is logged.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if p, ok := auth.FromContext(ctx); ok && event.Actor == "" {
+		event.Actor = p.Subject
+	}
+	if event.RequestID == "" {
+		event.RequestID = logging.RequestID(ctx)
+	}
+
+	var errs []error
+	for _, s := range b.subscribers {
+		if len(s.types) > 0 && !slices.Contains(s.types, event.Type) {
+			continue
+		}
+		if err := s.handler(ctx, event); err != nil {
+			logging.FromContext(ctx).Error("event subscriber failed", "subscriber", s.name,
+				"event_type", event.Type, "event_id", event.ID, "account_id", event.AccountID, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
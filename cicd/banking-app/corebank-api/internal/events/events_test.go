@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+)
+
+// recorder subscribes under name and records the events it is handed to
+// calls.
+func recorder(calls *[]string, name string, err error) Handler {
+	return func(_ context.Context, event Event) error {
+		*calls = append(*calls, name+":"+event.Type)
+		return err
+	}
+}
+
+func TestBusDelivers(t *testing.T) {
+	var calls []string
+	bus := NewBus()
+	bus.Subscribe("all", recorder(&calls, "all", nil))
+	bus.Subscribe("created", recorder(&calls, "created", nil), AccountCreated)
+	bus.Subscribe("transactions", recorder(&calls, "transactions", nil), TransactionSubmitted, TransactionUpdated)
+
+	ctx := context.Background()
+	account := &models.Account{ID: "acc"}
+	for _, event := range []Event{
+		NewAccountCreated(account, 0),
+		NewAccountDeleted("acc"),
+		NewTransactionUpdated(&models.Transaction{ID: "txn-1", AccountID: "acc"}),
+	} {
+		if err := bus.Publish(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "all:AccountCreated created:AccountCreated all:AccountDeleted all:TransactionUpdated transactions:TransactionUpdated"
+	if got := strings.Join(calls, " "); got != want {
+		t.Fatalf("delivered %s, want %s", got, want)
+	}
+}
+
+// A failing subscriber does not keep the event from the rest, and its
+// failure is returned.
+func TestBusJoinsFailures(t *testing.T) {
+	var calls []string
+	bus := NewBus()
+	bus.Subscribe("webhooks", recorder(&calls, "webhooks", errors.New("queue full")))
+	bus.Subscribe("audit", recorder(&calls, "audit", nil))
+	bus.Subscribe("initial_deposit", recorder(&calls, "initial_deposit", errors.New("service down")))
+
+	err := bus.Publish(context.Background(), NewAccountDeleted("acc"))
+	if err == nil || err.Error() != "webhooks: queue full\ninitial_deposit: service down" {
+		t.Fatalf("got %v, want both failures", err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("delivered to %v, want every subscriber", calls)
+	}
+}
+
+func TestBusFillsIn(t *testing.T) {
+	var got []Event
+	bus := NewBus()
+	bus.Subscribe("record", func(_ context.Context, event Event) error {
+		got = append(got, event)
+		return nil
+	})
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "dana", Role: config.RoleUser})
+	ctx = logging.WithRequestID(ctx, "req-1")
+	before := time.Now().UTC()
+	bus.Publish(ctx, NewAccountDeleted("acc"))
+	occurred := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	bus.Publish(ctx, Event{ID: "evt-1", Type: AccountDeleted, OccurredAt: occurred, Actor: "ops", RequestID: "req-0"})
+	bus.Publish(context.Background(), NewAccountDeleted("acc"))
+
+	filled := got[0]
+	if filled.ID == "" || filled.OccurredAt.Before(before) || filled.Actor != "dana" || filled.RequestID != "req-1" {
+		t.Fatalf("event = %+v, want its ID, time, actor and request ID filled in", filled)
+	}
+	if set := got[1]; set.ID != "evt-1" || !set.OccurredAt.Equal(occurred) || set.Actor != "ops" || set.RequestID != "req-0" {
+		t.Fatalf("event = %+v, want the fields it set kept", set)
+	}
+	if anonymous := got[2]; anonymous.Actor != "" || anonymous.RequestID != "" || anonymous.ID == filled.ID {
+		t.Fatalf("event = %+v, want a new ID and no actor or request ID", anonymous)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	event := NewAccountUpdated(&models.Account{ID: "acc"}, []models.AccountChange{
+		{Field: "owner", Old: "dana", New: "erin"},
+		{Field: "email", New: "erin@example.com"},
+	})
+	if c, ok := event.Changed("email"); !ok || c.New != "erin@example.com" {
+		t.Fatalf("Changed(email) = %+v, %v", c, ok)
+	}
+	if c, ok := event.Changed("timezone"); ok {
+		t.Fatalf("Changed(timezone) = %+v, want no change", c)
+	}
+}
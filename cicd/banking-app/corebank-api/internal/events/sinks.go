@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// NDJSONSink writes each event as a line of JSON to w, e.g. an append-only
+// file that other tools tail or replay.
+func NDJSONSink(w io.Writer) Handler {
+	var mu sync.Mutex
+	return func(_ context.Context, event Event) error {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = w.Write(append(line, '\n'))
+		return err
+	}
+}
+
+// AuditLog logs who made each change to logger. Account details are left
+// out; they are in the account's history.
+func AuditLog(logger *slog.Logger) Handler {
+	return func(ctx context.Context, event Event) error {
+		attrs := []any{"event_id", event.ID, "event_type", event.Type, "account_id", event.AccountID,
+			"actor", event.Actor, "request_id", event.RequestID}
+		if len(event.Changes) > 0 {
+			fields := make([]string, len(event.Changes))
+			for i, c := range event.Changes {
+				fields[i] = c.Field
+			}
+			attrs = append(attrs, "fields", fields)
+		}
+		if event.Transaction != nil {
+			attrs = append(attrs, "transaction_id", event.Transaction.ID, "transaction_type", event.Transaction.Type)
+		}
+		logger.InfoContext(ctx, "audit", attrs...)
+		return nil
+	}
+}
+
+// Broker is the adapter to an external message broker such as Kafka, SNS or
+// NATS. An implementation wraps the broker's client; Send must not return
+// until the broker has accepted the message.
+type Broker interface {
+	Send(ctx context.Context, topic, key string, payload []byte) error
+	Close() error
+}
+
+// BrokerSink forwards each event as JSON to topic, keyed by account ID so
+// brokers that partition by key keep each account's events in order.
+func BrokerSink(broker Broker, topic string) Handler {
+	return func(ctx context.Context, event Event) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		return broker.Send(ctx, topic, event.AccountID, payload)
+	}
+}
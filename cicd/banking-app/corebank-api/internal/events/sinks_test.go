@@ -0,0 +1,130 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/corebank-api/internal/models"
+)
+
+func TestNDJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NDJSONSink(&buf)
+
+	// Concurrent events are written as whole lines
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := NewTransactionSubmitted(&models.Transaction{ID: fmt.Sprintf("txn-%d", i), AccountID: "acc"})
+			if err := sink(context.Background(), event); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if event.Type != TransactionSubmitted || event.Transaction == nil {
+			t.Fatalf("event = %+v", event)
+		}
+		seen[event.Transaction.ID] = true
+	}
+	if len(seen) != 20 {
+		t.Fatalf("wrote %d distinct events, want 20", len(seen))
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	audit := AuditLog(slog.New(slog.NewJSONHandler(&buf, nil)))
+	account := &models.Account{ID: "acc", Owner: "Dana Smith", Email: "dana@example.com", Balance: 1234.56}
+	event := NewAccountUpdated(account, []models.AccountChange{
+		{Field: "owner", Old: "Dana Jones", New: "Dana Smith"},
+		{Field: "email", Old: "dana@example.org", New: "dana@example.com"},
+	})
+	event.ID, event.Actor, event.RequestID = "evt-1", "ops", "req-1"
+	if err := audit(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	txn := NewTransactionSubmitted(&models.Transaction{ID: "txn-1", AccountID: "acc", Type: "deposit", Amount: 99.5})
+	if err := audit(context.Background(), txn); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %q, want a line per event", buf.String())
+	}
+	var updated, submitted map[string]any
+	json.Unmarshal([]byte(lines[0]), &updated)
+	json.Unmarshal([]byte(lines[1]), &submitted)
+	if updated["msg"] != "audit" || updated["event_id"] != "evt-1" || updated["event_type"] != AccountUpdated ||
+		updated["account_id"] != "acc" || updated["actor"] != "ops" || updated["request_id"] != "req-1" {
+		t.Fatalf("logged %v", updated)
+	}
+	if fields, _ := updated["fields"].([]any); len(fields) != 2 || fields[0] != "owner" || fields[1] != "email" {
+		t.Fatalf("fields = %v, want the changed fields' names", updated["fields"])
+	}
+	if submitted["transaction_id"] != "txn-1" || submitted["transaction_type"] != "deposit" {
+		t.Fatalf("logged %v", submitted)
+	}
+
+	// Account details and changed values stay in the account's history
+	for _, detail := range []string{"Dana", "dana@", "1234.56", "99.5"} {
+		if strings.Contains(buf.String(), detail) {
+			t.Errorf("audit log contains %q: %s", detail, buf.String())
+		}
+	}
+}
+
+type recordingBroker struct {
+	topic, key string
+	payload    []byte
+	err        error
+}
+
+func (b *recordingBroker) Send(_ context.Context, topic, key string, payload []byte) error {
+	b.topic, b.key, b.payload = topic, key, payload
+	return b.err
+}
+
+func (b *recordingBroker) Close() error { return nil }
+
+func TestBrokerSink(t *testing.T) {
+	broker := &recordingBroker{}
+	sink := BrokerSink(broker, "corebank.events")
+	event := NewTransactionSubmitted(&models.Transaction{ID: "txn-1", AccountID: "acc", Type: "deposit", Amount: 10})
+	event.ID = "evt-1"
+
+	if err := sink(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	var sent Event
+	if err := json.Unmarshal(broker.payload, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if broker.topic != "corebank.events" || broker.key != "acc" || sent.ID != "evt-1" || sent.Transaction.ID != "txn-1" {
+		t.Fatalf("sent %+v to %s keyed %s", sent, broker.topic, broker.key)
+	}
+
+	broker.err = errors.New("broker unavailable")
+	if err := sink(context.Background(), event); !errors.Is(err, broker.err) {
+		t.Fatalf("got %v, want the broker's error", err)
+	}
+}
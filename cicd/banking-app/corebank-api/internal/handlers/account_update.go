@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+)
+
+// MergePatchContentType is the media type of a JSON Merge Patch (RFC 7396).
+const MergePatchContentType = "application/merge-patch+json"
+
+// protectedFields are account fields only the server sets: balance moves
+// through transactions, status through the operations CLI, limits through
+// PUT /accounts/{id}/limits and kyc_status through the account's kyc
+// endpoints.
+var protectedFields = map[string]bool{
+	"id":         true,
+	"balance":    true,
+	"created_at": true,
+	"updated_at": true,
+	"status":     true,
+	"limits":     true,
+	"kyc_status": true,
+}
+
+// adminFields are the editable fields only an admin may change: the owner
+// decides who may access the account, the account type which product rules
+// it follows and the timezone when its outflow limits start a new day or
+// month.
+var adminFields = map[string]bool{
+	"owner":        true,
+	"account_type": true,
+	"timezone":     true,
+}
+
+// patchAccount applies a JSON Merge Patch to the editable fields of an
+// account: owner, email, account_type and timezone. A null email or
+// timezone clears it and a null account_type resets it to checking.
+// Patching a server-controlled field is rejected, as is anyone but an admin
+// changing one of adminFields.
+func (h *AccountHandler) patchAccount(w http.ResponseWriter, r *http.Request, id string) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != MergePatchContentType && mediaType != "application/json" {
+		WriteError(w, r, http.StatusUnsupportedMediaType, "PATCH requires Content-Type "+MergePatchContentType)
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		WriteError(w, r, http.StatusBadRequest, "body must be a JSON object")
+		return
+	}
+
+	existing, ok := h.account(w, r, id)
+	if !ok {
+		return
+	}
+
+	names := make([]string, 0, len(patch))
+	for name := range patch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updated := *existing
+	for _, name := range names {
+		if protectedFields[name] {
+			WriteError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf("%s is server-controlled and cannot be changed", name))
+			return
+		}
+
+		var value *string
+		if err := json.Unmarshal(patch[name], &value); err != nil {
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("%s must be a string or null", name))
+			return
+		}
+		var v string
+		if value != nil {
+			v = *value
+		}
+
+		switch name {
+		case "owner":
+			updated.Owner = v
+		case "email":
+			updated.Email = v
+		case "account_type":
+			if v == "" {
+				v = config.DefaultAccountType
+			}
+			updated.AccountType = v
+		case "timezone":
+			updated.Timezone = v
+		default:
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown field %q", name))
+			return
+		}
+	}
+
+	h.saveAccount(w, r, existing, &updated)
+}
+
+// saveAccount validates updated, records how it differs from existing and
+// writes the result. Only an admin may change adminFields, and an account
+// changes type only if its balance keeps to the new product's rules.
+func (h *AccountHandler) saveAccount(w http.ResponseWriter, r *http.Request, existing, updated *models.Account) {
+	problems := validateAccountFields(updated)
+	if updated.AccountType != existing.AccountType {
+		// Accounts keep a type withdrawn from the catalog until it changes
+		if err := h.products.Validate(updated.AccountType); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		WriteError(w, r, http.StatusBadRequest, strings.Join(problems, "; "))
+		return
+	}
+
+	changes := diffAccount(r, existing, updated)
+	if len(changes) == 0 {
+		json.NewEncoder(w).Encode(existing)
+		return
+	}
+	if !auth.IsAdmin(r.Context()) {
+		for _, c := range changes {
+			if adminFields[c.Field] {
+				WriteError(w, r, http.StatusForbidden, fmt.Sprintf("changing %s requires an admin token", c.Field))
+				return
+			}
+		}
+	}
+	if updated.AccountType != existing.AccountType {
+		if err := products.CheckBalance(h.products.For(updated), updated.Balance); err != nil {
+			WriteErrorCode(w, r, http.StatusUnprocessableEntity, CodeInsufficientFunds, fmt.Sprintf("Account %s: %v", updated.ID, err))
+			return
+		}
+	}
+
+	err := h.repo.Update(r.Context(), updated, changes)
+	if errors.Is(err, repository.ErrNotFound) {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+	}
+	logging.FromContext(r.Context()).Info("account updated", "account_id", updated.ID, "fields", fields)
+	h.events.Publish(r.Context(), events.NewAccountUpdated(updated, changes))
+	json.NewEncoder(w).Encode(updated)
+}
+
+// HandleHistory serves GET /accounts/{id}/history, the account's field
+// changes, oldest first.
+func (h *AccountHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	account, ok := h.account(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	history, err := h.repo.History(r.Context(), account.ID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if history == nil {
+		history = []models.AccountChange{}
+	}
+	json.NewEncoder(w).Encode(history)
+}
+
+// changedProtectedField returns the first server-controlled field a full
+// account body changes, or "" if it only echoes them. present holds the keys
+// the body set, so omitted fields are not mistaken for zero values.
+// updated_at is ignored since every write moves it.
+func changedProtectedField(existing, body *models.Account, present map[string]json.RawMessage) string {
+	set := func(name string) bool {
+		raw, ok := present[name]
+		return ok && string(raw) != "null"
+	}
+	status := existing.Status
+	if status == "" {
+		status = models.AccountStatusActive
+	}
+
+	switch {
+	case set("id") && body.ID != existing.ID:
+		return "id"
+	case set("balance") && body.Balance != existing.Balance:
+		return "balance"
+	case set("created_at") && !body.CreatedAt.Equal(existing.CreatedAt):
+		return "created_at"
+	case set("status") && body.Status != status:
+		return "status"
+	case set("limits") && formatLimits(body.Limits) != formatLimits(existing.Limits):
+		return "limits"
+	case set("kyc_status") && body.KYCLevel() != existing.KYCLevel():
+		return "kyc_status"
+	}
+	return ""
+}
+
+// validateAccountFields trims the editable fields of account, defaults its
+// type and reports what is wrong with them. Whether the type is in the
+// product catalog is left to the caller.
+func validateAccountFields(account *models.Account) []string {
+	account.Owner = strings.TrimSpace(account.Owner)
+	account.Email = strings.TrimSpace(account.Email)
+	account.AccountType = strings.TrimSpace(account.AccountType)
+	if account.AccountType == "" {
+		account.AccountType = config.DefaultAccountType
+	}
+	account.Timezone = strings.TrimSpace(account.Timezone)
+
+	var problems []string
+	switch {
+	case account.Owner == "":
+		problems = append(problems, "owner is required")
+	case len(account.Owner) > maxOwnerLength:
+		problems = append(problems, fmt.Sprintf("owner must be at most %d characters", maxOwnerLength))
+	}
+	if account.Email != "" {
+		if addr, err := mail.ParseAddress(account.Email); err != nil || addr.Address != account.Email {
+			problems = append(problems, fmt.Sprintf("email %q is not a valid address", account.Email))
+		}
+	}
+	if account.Timezone != "" {
+		if _, err := time.LoadLocation(account.Timezone); err != nil || account.Timezone == "Local" {
+			problems = append(problems, fmt.Sprintf("timezone %q is not an IANA timezone", account.Timezone))
+		}
+	}
+	return problems
+}
+
+// diffAccount lists the editable fields that differ between before and
+// after, attributed to the caller.
+func diffAccount(r *http.Request, before, after *models.Account) []models.AccountChange {
+	var actor string
+	if p, ok := auth.FromContext(r.Context()); ok {
+		actor = p.Subject
+	}
+	now := time.Now().UTC()
+
+	var changes []models.AccountChange
+	for _, f := range []struct{ name, old, new string }{
+		{"owner", before.Owner, after.Owner},
+		{"email", before.Email, after.Email},
+		{"account_type", before.AccountType, after.AccountType},
+		{"timezone", before.Timezone, after.Timezone},
+	} {
+		if f.old != f.new {
+			changes = append(changes, models.AccountChange{
+				Field:     f.name,
+				Old:       f.old,
+				New:       f.new,
+				Actor:     actor,
+				RequestID: logging.RequestID(r.Context()),
+				ChangedAt: now,
+			})
+		}
+	}
+	return changes
+}
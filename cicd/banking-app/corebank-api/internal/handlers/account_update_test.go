@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+)
+
+func TestPatchAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		as      auth.Principal
+		balance float64
+		patch   string
+		want    int
+		wantErr string
+	}{
+		{name: "customer changes email", as: customer, patch: `{"email":"dana@example.com"}`, want: http.StatusOK},
+		{name: "customer echoes owner", as: customer, patch: `{"owner":"dana","email":"dana@example.com"}`, want: http.StatusOK},
+		{name: "customer changes owner", as: customer, patch: `{"owner":"erin"}`, want: http.StatusForbidden,
+			wantErr: "changing owner requires an admin token"},
+		{name: "customer changes type", as: customer, patch: `{"account_type":"savings"}`, want: http.StatusForbidden,
+			wantErr: "changing account_type requires an admin token"},
+		{name: "customer changes timezone", as: customer, patch: `{"timezone":"Europe/Berlin"}`, want: http.StatusForbidden,
+			wantErr: "changing timezone requires an admin token"},
+		{name: "admin changes owner", as: admin, patch: `{"owner":"erin"}`, want: http.StatusOK},
+		{name: "admin changes timezone", as: admin, patch: `{"timezone":"Europe/Berlin"}`, want: http.StatusOK},
+		{name: "admin changes type", as: admin, balance: 100, patch: `{"account_type":"savings"}`, want: http.StatusOK},
+		{name: "admin changes type below its minimum balance", as: admin, balance: 99.99, patch: `{"account_type":"savings"}`,
+			want: http.StatusUnprocessableEntity, wantErr: "Account acc: insufficient funds: balance 99.99 is below the 100.00 savings accounts keep"},
+		{name: "admin changes type within its overdraft", as: admin, balance: -50, patch: `{"account_type":"overdraft"}`, want: http.StatusOK},
+		{name: "admin changes type past its overdraft", as: admin, balance: -50, patch: `{"account_type":"checking"}`,
+			want: http.StatusUnprocessableEntity, wantErr: "Account acc: insufficient funds: balance -50.00 is below the 0.00 checking accounts keep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountType := config.DefaultAccountType
+			if tt.balance < 0 {
+				accountType = "overdraft"
+			}
+			accounts := newMemAccounts(models.Account{ID: "acc", Owner: "dana", AccountType: accountType, Balance: tt.balance})
+			h, _ := newTestAccountHandler(accounts)
+
+			r := as(tt.as, http.MethodPatch, "/accounts/acc", tt.patch)
+			r.Header.Set("Content-Type", MergePatchContentType)
+			r.SetPathValue("id", "acc")
+			w := httptest.NewRecorder()
+			h.HandleAccountByID(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body, tt.want)
+			}
+			if tt.wantErr == "" {
+				return
+			}
+			var body errorResponse
+			if json.Unmarshal(w.Body.Bytes(), &body); body.Error != tt.wantErr {
+				t.Fatalf("error %q, want %q", body.Error, tt.wantErr)
+			}
+			if history, _ := accounts.History(r.Context(), "acc"); len(history) != 0 {
+				t.Fatalf("history = %+v, want the account unchanged", history)
+			}
+		})
+	}
+}
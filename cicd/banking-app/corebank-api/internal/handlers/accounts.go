@@ -164,27 +164,43 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	// "log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
 	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
 	"github.com/corebank-api/internal/repository"
 	"github.com/google/uuid"
 )
 
 type AccountHandler struct {
-	repo             *repository.AccountRepository
+	repo             AccountStore
 	pythonServiceURL string
+	httpClient       *http.Client
+	products         *products.Catalog
+	events           events.Publisher
 }
 
-func NewAccountHandler(repo *repository.AccountRepository, pythonServiceURL string) *AccountHandler {
+// NewAccountHandler publishes account events to publisher, whose
+// subscribers take care of side effects such as the initial deposit.
+func NewAccountHandler(repo AccountStore, pythonServiceURL string, httpClient *http.Client, catalog *products.Catalog, publisher events.Publisher) *AccountHandler {
 	return &AccountHandler{
 		repo:             repo,
 		pythonServiceURL: pythonServiceURL,
+		httpClient:       httpClient,
+		products:         catalog,
+		events:           publisher,
 	}
 }
 
@@ -197,44 +213,127 @@ func (h *AccountHandler) HandleAccounts(w http.ResponseWriter, r *http.Request)
 	case http.MethodPost:
 		h.createAccount(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		WriteError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
 func (h *AccountHandler) HandleAccountByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	id := r.URL.Path[len("/accounts/"):]
+	id := r.PathValue("id")
 
 	switch r.Method {
 	case http.MethodGet:
 		h.getAccount(w, r, id)
 	case http.MethodPut:
 		h.updateAccount(w, r, id)
+	case http.MethodPatch:
+		h.patchAccount(w, r, id)
 	case http.MethodDelete:
 		h.deleteAccount(w, r, id)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		WriteError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 	}
 }
 
+// NextCursorHeader carries the cursor for the next page of a paginated list.
+const NextCursorHeader = "X-Next-Cursor"
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// listAccounts lists every account to an admin and only their own to
+// anyone else, so a page may hold fewer than limit accounts even when more
+// follow.
 func (h *AccountHandler) listAccounts(w http.ResponseWriter, r *http.Request) {
-	accounts, err := h.repo.ListAll(r.Context())
+	query := r.URL.Query()
+
+	// Without paging parameters the whole table is returned, as before
+	if !query.Has("limit") && !query.Has("cursor") {
+		accounts, err := h.repo.ListAll(r.Context())
+		if err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(accessible(r.Context(), accounts))
+		return
+	}
+
+	limit := defaultPageSize
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageSize {
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+			return
+		}
+		limit = n
+	}
+
+	accounts, next, err := h.repo.ListPage(r.Context(), limit, query.Get("cursor"))
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
+	accounts = accessible(r.Context(), accounts)
+	if accounts == nil {
+		accounts = []models.Account{}
+	}
+	if next != "" {
+		w.Header().Set(NextCursorHeader, next)
+	}
 	json.NewEncoder(w).Encode(accounts)
 }
 
+// accessible returns the accounts the caller in ctx may access.
+func accessible(ctx context.Context, accounts []models.Account) []models.Account {
+	if auth.IsAdmin(ctx) {
+		return accounts
+	}
+	var own []models.Account
+	for _, account := range accounts {
+		if auth.CanAccess(ctx, account.Owner) {
+			own = append(own, account)
+		}
+	}
+	return own
+}
+
 func (h *AccountHandler) createAccount(w http.ResponseWriter, r *http.Request) {
 	var account models.Account
 	if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Customers open accounts for themselves; without an owner the account
+	// is theirs
+	if p, ok := auth.FromContext(r.Context()); ok && !p.IsAdmin() {
+		switch strings.TrimSpace(account.Owner) {
+		case "":
+			account.Owner = p.Subject
+		case p.Subject:
+		default:
+			WriteError(w, r, http.StatusForbidden, "opening an account for another owner requires an admin token")
+			return
+		}
+	}
+
+	problems := validateAccountFields(&account)
+	if err := h.products.Validate(account.AccountType); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		WriteError(w, r, http.StatusBadRequest, strings.Join(problems, "; "))
 		return
 	}
 
 	// Log before setting ID
-	fmt.Println("Creating account with Owner: ", account.Owner)
+	logging.FromContext(r.Context()).Info("creating account", "owner", account.Owner, "email", account.Email)
 
 	// Auto-generate account ID using UUID
 	account.ID = uuid.New().String()
@@ -244,16 +343,29 @@ func (h *AccountHandler) createAccount(w http.ResponseWriter, r *http.Request) {
 
 	// Set the creation timestamp
 	account.CreatedAt = time.Now()
+	account.Status = models.AccountStatusActive
+	// Only an admin sets an account's own limits, through its limits
+	account.Limits = nil
+	// Customers are verified through the account's kyc endpoints
+	account.KYCStatus = models.KYCUnverified
 
 	// Call repository to create the account
 	if err := h.repo.Create(r.Context(), &account); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, repository.ErrConflict) {
+			WriteError(w, r, http.StatusConflict, "Account "+account.ID+" already exists")
+			return
+		}
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Call Python transaction service to create an initial deposit transaction
-	if err := h.callTransactionService(account.ID); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to call transaction service: %v", err), http.StatusInternalServerError)
+	// The initial deposit is recorded by a subscriber through the outbox.
+	// If the transaction service is down the account is still created and
+	// the deposit is redriven later, rather than failing a request that
+	// already took effect; only failing to queue it fails the request.
+	created := events.NewAccountCreated(&account, h.products.For(&account).InitialDeposit)
+	if err := h.events.Publish(r.Context(), created); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -283,7 +395,7 @@ func (h *AccountHandler) createAccount(w http.ResponseWriter, r *http.Request) {
 // 		return
 // 	}
 
-// 	if err := h.callTransactionService(account.ID); err != nil {
+// 	if err := h.callTransactionService(r.Context(), account.ID); err != nil {
 // 		http.Error(w, fmt.Sprintf("Failed to call transaction service: %v", err), http.StatusInternalServerError)
 // 		return
 // 	}
@@ -293,80 +405,87 @@ func (h *AccountHandler) createAccount(w http.ResponseWriter, r *http.Request) {
 // }
 
 func (h *AccountHandler) getAccount(w http.ResponseWriter, r *http.Request, id string) {
+	account, ok := h.account(w, r, id)
+	if !ok {
+		return
+	}
+	json.NewEncoder(w).Encode(account)
+}
+
+// account loads the account with the given ID and checks the caller may
+// access it, writing the error response itself when either fails.
+func (h *AccountHandler) account(w http.ResponseWriter, r *http.Request, id string) (*models.Account, bool) {
 	account, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
 	}
 	if account == nil {
-		http.Error(w, "Account not found", http.StatusNotFound)
-		return
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return nil, false
 	}
-	json.NewEncoder(w).Encode(account)
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return nil, false
+	}
+	return account, true
 }
 
+// updateAccount replaces the editable fields of an account. Server-controlled
+// fields may be echoed back unchanged, as returned by GET, but not changed.
 func (h *AccountHandler) updateAccount(w http.ResponseWriter, r *http.Request, id string) {
-	var updatedAccount models.Account
-	if err := json.NewDecoder(r.Body).Decode(&updatedAccount); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	existingAccount, err := h.repo.GetByID(r.Context(), id)
+	raw, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
-	if existingAccount == nil {
-		http.Error(w, "Account not found", http.StatusNotFound)
+	var body models.Account
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
+	json.Unmarshal(raw, &present)
 
-	updatedAccount.ID = id
-	updatedAccount.CreatedAt = existingAccount.CreatedAt
+	existingAccount, ok := h.account(w, r, id)
+	if !ok {
+		return
+	}
 
-	// Using Update instead of Create for clarity
-	if err := h.repo.Update(r.Context(), &updatedAccount); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if field := changedProtectedField(existingAccount, &body, present); field != "" {
+		WriteError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf("%s is server-controlled and cannot be changed", field))
+		return
+	}
+	if strings.TrimSpace(body.AccountType) == "" {
+		WriteError(w, r, http.StatusBadRequest, "account_type is required; use PATCH to change single fields")
 		return
 	}
 
-	json.NewEncoder(w).Encode(updatedAccount)
+	updatedAccount := *existingAccount
+	updatedAccount.Owner = body.Owner
+	updatedAccount.Email = body.Email
+	updatedAccount.AccountType = body.AccountType
+	updatedAccount.Timezone = body.Timezone
+	h.saveAccount(w, r, existingAccount, &updatedAccount)
 }
 
+// deleteAccount deletes an account the caller may access. Deleting one that
+// does not exist succeeds, as it always has.
 func (h *AccountHandler) deleteAccount(w http.ResponseWriter, r *http.Request, id string) {
-	err := h.repo.Delete(r.Context(), id)
+	account, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete account: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func (h *AccountHandler) callTransactionService(accountID string) error {
-	transaction := models.Transaction{
-		AccountID: accountID,
-		Amount:    1000,
-		Type:      "deposit",
-		Status:    "pending",
-		CreatedAt: time.Now(),
-	}
-
-	txnJSON, err := json.Marshal(transaction)
-	if err != nil {
-		return fmt.Errorf("failed to marshal transaction: %w", err)
+	if account != nil && !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
 	}
-
-	resp, err := http.Post(h.pythonServiceURL+"/transactions", "application/json", bytes.NewBuffer(txnJSON))
+	err = h.repo.Delete(r.Context(), id)
 	if err != nil {
-		return fmt.Errorf("failed to call transaction service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Accept any 2xx success code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("transaction service returned status: %d", resp.StatusCode)
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to delete account: %v", err))
+		return
 	}
-
-	return nil
-}
\ No newline at end of file
+	h.events.Publish(r.Context(), events.NewAccountDeleted(id))
+	w.WriteHeader(http.StatusNoContent)
+}
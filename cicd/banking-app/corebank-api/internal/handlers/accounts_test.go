@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/products"
+)
+
+// newTestAccountHandler returns a handler for the accounts with checking and
+// savings accounts, the latter opened with a deposit of 50, and the events
+// it publishes.
+func newTestAccountHandler(accounts AccountStore) (*AccountHandler, *[]events.Event) {
+	catalog := products.NewCatalog([]config.ProductConfig{
+		{AccountType: config.DefaultAccountType},
+		{AccountType: "savings", MinimumBalance: 100, InitialDeposit: 50},
+		{AccountType: "overdraft", OverdraftLimit: 50},
+	})
+	published := &[]events.Event{}
+	bus := events.NewBus()
+	bus.Subscribe("record", func(_ context.Context, event events.Event) error {
+		*published = append(*published, event)
+		return nil
+	})
+	return NewAccountHandler(accounts, "", nil, catalog, bus), published
+}
+
+func TestCreateAccountOwner(t *testing.T) {
+	tests := []struct {
+		name      string
+		as        auth.Principal
+		body      string
+		want      int
+		wantOwner string
+	}{
+		{name: "customer for themselves", as: customer, body: `{"owner":"dana"}`, want: http.StatusCreated, wantOwner: "dana"},
+		{name: "customer without an owner", as: customer, body: `{}`, want: http.StatusCreated, wantOwner: "dana"},
+		{name: "customer for someone else", as: customer, body: `{"owner":"erin"}`, want: http.StatusForbidden},
+		{name: "admin for a customer", as: admin, body: `{"owner":"erin"}`, want: http.StatusCreated, wantOwner: "erin"},
+		{name: "admin without an owner", as: admin, body: `{}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newMemAccounts()
+			h, _ := newTestAccountHandler(accounts)
+
+			w := httptest.NewRecorder()
+			h.HandleAccounts(w, as(tt.as, http.MethodPost, "/accounts", tt.body))
+			if w.Code != tt.want {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body, tt.want)
+			}
+			stored, _ := accounts.ListAll(context.Background())
+			if tt.want != http.StatusCreated {
+				if len(stored) != 0 {
+					t.Fatalf("stored %+v, want nothing", stored)
+				}
+				return
+			}
+			var created struct{ Owner string }
+			json.Unmarshal(w.Body.Bytes(), &created)
+			if created.Owner != tt.wantOwner || len(stored) != 1 || stored[0].Owner != tt.wantOwner {
+				t.Fatalf("created %+v, stored %+v; want owner %s", created, stored, tt.wantOwner)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+)
+
+const (
+	// maxImportBody and maxImportRows bound one bulk import; larger loads
+	// are split into several requests.
+	maxImportBody = 16 << 20
+	maxImportRows = 10000
+
+	maxOwnerLength = 200
+)
+
+// Bulk formats, chosen with ?format= or from the Content-Type.
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// AccountCSVHeader lists the columns of an account CSV export.
+var AccountCSVHeader = []string{"id", "owner", "email", "account_type", "status", "balance", "created_at", "updated_at"}
+
+// AccountCSVRecord renders an account as a row under AccountCSVHeader.
+func AccountCSVRecord(account models.Account) []string {
+	status := account.Status
+	if status == "" {
+		status = models.AccountStatusActive
+	}
+	return []string{
+		account.ID,
+		account.Owner,
+		account.Email,
+		account.AccountType,
+		status,
+		strconv.FormatFloat(account.Balance, 'f', 2, 64),
+		formatCSVTime(account.CreatedAt),
+		formatCSVTime(account.UpdatedAt),
+	}
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// importRow is one account to import: owner, email and account_type, as in
+// POST /accounts.
+type importRow struct {
+	line    int
+	account models.Account
+	errors  []string
+}
+
+// HandleBulkImport serves POST /accounts:bulk. Rows are validated one by
+// one; valid rows are written in batches and invalid ones reported, so one
+// bad row doesn't block the rest. ?dry_run=true only validates, and
+// ?initial_deposit=false skips the initial deposit each new account gets.
+func (h *AccountHandler) HandleBulkImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "bulk import requires an admin token")
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun, err := boolParam(query.Get("dry_run"), false)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, "dry_run: "+err.Error())
+		return
+	}
+	deposit, err := boolParam(query.Get("initial_deposit"), true)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, "initial_deposit: "+err.Error())
+		return
+	}
+	format, err := importFormat(query.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		WriteError(w, r, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBody)
+	var rows []importRow
+	if format == formatCSV {
+		rows, err = parseCSVImport(body)
+	} else {
+		rows, err = parseNDJSONImport(body)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		WriteError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("import must not exceed %d bytes", maxImportBody))
+		return
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		WriteError(w, r, http.StatusBadRequest, "import contains no accounts")
+		return
+	}
+
+	result := models.BulkImportResult{DryRun: dryRun, Total: len(rows), Rows: make([]models.BulkImportRow, len(rows))}
+	now := time.Now()
+	var valid []models.Account
+	for i := range rows {
+		row := &rows[i]
+		h.validateImportRow(row)
+		result.Rows[i] = models.BulkImportRow{Line: row.line, Errors: row.errors}
+		if len(row.errors) > 0 {
+			result.Rows[i].Status = models.BulkRowInvalid
+			result.Invalid++
+			continue
+		}
+
+		result.Valid++
+		result.Rows[i].Status = models.BulkRowValid
+		if !dryRun {
+			row.account.ID = uuid.New().String()
+			row.account.CreatedAt = now
+			row.account.Status = models.AccountStatusActive
+			row.account.KYCStatus = models.KYCUnverified
+			result.Rows[i].AccountID = row.account.ID
+			valid = append(valid, row.account)
+		}
+	}
+
+	if !dryRun && len(valid) > 0 {
+		unwritten, err := h.repo.CreateBatch(r.Context(), valid)
+		failed := make(map[string]bool, len(unwritten))
+		for _, id := range unwritten {
+			failed[id] = true
+		}
+
+		logger := logging.FromContext(r.Context())
+		for i := range result.Rows {
+			row := &result.Rows[i]
+			switch {
+			case row.Status != models.BulkRowValid:
+			case failed[row.AccountID]:
+				row.Status = models.BulkRowFailed
+				row.AccountID = ""
+				if err != nil {
+					row.Errors = []string{err.Error()}
+				}
+				result.Failed++
+			default:
+				row.Status = models.BulkRowCreated
+				result.Created++
+			}
+		}
+		logger.Info("bulk import finished", "rows", result.Total, "created", result.Created,
+			"invalid", result.Invalid, "failed", result.Failed)
+
+		// As in createAccount, subscribers record each initial deposit;
+		// failures are logged and the accounts exist either way
+		for i := range valid {
+			account := &valid[i]
+			if failed[account.ID] {
+				continue
+			}
+			amount := 0.0
+			if deposit {
+				amount = h.products.For(account).InitialDeposit
+			}
+			h.events.Publish(r.Context(), events.NewAccountCreated(account, amount))
+		}
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleExport serves GET /accounts:export, streaming every account as
+// NDJSON (the default) or CSV for backups. Pages are written as they are
+// read, so a failure part way through truncates the export; it is logged
+// and the response ends early.
+func (h *AccountHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "export requires an admin token")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = formatNDJSON
+	case formatNDJSON, formatCSV:
+	default:
+		WriteError(w, r, http.StatusBadRequest, "format must be ndjson or csv")
+		return
+	}
+
+	// Read the first page before committing to a 200
+	accounts, cursor, err := h.repo.ListPage(r.Context(), maxPageSize, "")
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == formatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"accounts-%s.%s\"", time.Now().UTC().Format("20060102T150405Z"), format))
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	cw := csv.NewWriter(w)
+	if format == formatCSV {
+		cw.Write(AccountCSVHeader)
+	}
+
+	count := 0
+	for {
+		// A large table takes longer than the server's write timeout
+		rc.SetWriteDeadline(time.Now().Add(time.Minute))
+		for _, account := range accounts {
+			if format == formatCSV {
+				err = cw.Write(AccountCSVRecord(account))
+			} else {
+				err = enc.Encode(account)
+			}
+			if err != nil {
+				break
+			}
+		}
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Warn("account export aborted", "exported", count, "error", err)
+			return
+		}
+		count += len(accounts)
+		rc.Flush()
+
+		if cursor == "" {
+			break
+		}
+		accounts, cursor, err = h.repo.ListPage(r.Context(), maxPageSize, cursor)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("account export truncated", "exported", count, "error", err)
+			return
+		}
+	}
+	logging.FromContext(r.Context()).Info("accounts exported", "count", count, "format", format)
+}
+
+func boolParam(raw string, def bool) (bool, error) {
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%q is not a boolean", raw)
+	}
+	return v, nil
+}
+
+// importFormat picks the input format from ?format=, falling back to the
+// Content-Type.
+func importFormat(param, contentType string) (string, error) {
+	switch param {
+	case formatCSV, formatNDJSON:
+		return param, nil
+	case "":
+	default:
+		return "", fmt.Errorf("format must be csv or ndjson, got %q", param)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return formatCSV, nil
+	case "application/x-ndjson", "application/jsonl", "application/json":
+		return formatNDJSON, nil
+	}
+	return "", fmt.Errorf("send text/csv or application/x-ndjson, or set ?format=")
+}
+
+// parseCSVImport reads a CSV with a header row naming any of the columns
+// owner, email and account_type.
+func parseCSVImport(body io.Reader) ([]importRow, error) {
+	cr := csv.NewReader(body)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "owner", "email", "account_type":
+		default:
+			return nil, fmt.Errorf("unknown CSV column %q; expected owner, email and account_type", name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["owner"]; !ok {
+		return nil, errors.New("CSV header must include an owner column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	// Rows of the wrong width are reported per row rather than failing the
+	// whole file
+	cr.FieldsPerRecord = -1
+	var rows []importRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid CSV: %w", err)
+			}
+			return nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("import must not exceed %d rows", maxImportRows)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := importRow{line: line}
+		if len(record) != len(header) {
+			row.errors = append(row.errors, fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))
+		}
+		row.account = models.Account{
+			Owner:       field(record, "owner"),
+			Email:       field(record, "email"),
+			AccountType: field(record, "account_type"),
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseNDJSONImport reads one JSON object per line, each shaped like the
+// body of POST /accounts. Blank lines are skipped.
+func parseNDJSONImport(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("import must not exceed %d rows", maxImportRows)
+		}
+
+		var input struct {
+			Owner       string `json:"owner"`
+			Email       string `json:"email"`
+			AccountType string `json:"account_type"`
+		}
+		row := importRow{line: line}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&input); err != nil {
+			row.errors = append(row.errors, "invalid JSON: "+err.Error())
+		}
+		row.account = models.Account{Owner: input.Owner, Email: input.Email, AccountType: input.AccountType}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// validateImportRow normalises a row and records what is wrong with it.
+func (h *AccountHandler) validateImportRow(row *importRow) {
+	if len(row.errors) > 0 {
+		return
+	}
+	row.errors = validateAccountFields(&row.account)
+	if err := h.products.Validate(row.account.AccountType); err != nil {
+		row.errors = append(row.errors, err.Error())
+	}
+}
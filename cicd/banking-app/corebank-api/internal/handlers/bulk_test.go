@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+)
+
+// importAs posts body to the bulk import as p.
+func importAs(h *AccountHandler, p auth.Principal, query, contentType, body string) *httptest.ResponseRecorder {
+	r := as(p, http.MethodPost, "/accounts:bulk"+query, body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.HandleBulkImport(w, r)
+	return w
+}
+
+func decodeImport(t *testing.T, w *httptest.ResponseRecorder) models.BulkImportResult {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", w.Code, w.Body)
+	}
+	var result models.BulkImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// A dry run reports each row as an import would, and stores nothing.
+func TestBulkImportValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  []string
+		wantErrors  []string
+		wantLines   []int
+	}{
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body: `{"owner":"gina","email":"gina@example.com","account_type":"savings"}
+{"owner":"henry","email":"not an email"}
+
+{"email":"nobody@example.com"}
+{"owner":"ivan","account_type":"platinum"}
+{"owner":"june","nickname":"j"}
+{"owner":"  kim  "}
+`,
+			wantStatus: []string{models.BulkRowValid, models.BulkRowInvalid, models.BulkRowInvalid, models.BulkRowInvalid, models.BulkRowInvalid, models.BulkRowValid},
+			wantErrors: []string{"", `email "not an email" is not a valid address`, "owner is required",
+				`account_type "platinum" is not one of checking, savings, overdraft`, `invalid JSON: json: unknown field "nickname"`, ""},
+			wantLines: []int{1, 2, 4, 5, 6, 7},
+		},
+		{
+			name:        "csv",
+			contentType: "text/csv",
+			body:        "\ufeffOwner, email\ngina,gina@example.com\n,nobody@example.com\nhenry\n" + strings.Repeat("x", maxOwnerLength+1) + ",\n",
+			wantStatus:  []string{models.BulkRowValid, models.BulkRowInvalid, models.BulkRowInvalid, models.BulkRowInvalid},
+			wantErrors:  []string{"", "owner is required", "expected 2 fields, got 1", "owner must be at most 200 characters"},
+			wantLines:   []int{2, 3, 4, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newMemAccounts()
+			h, published := newTestAccountHandler(accounts)
+
+			result := decodeImport(t, importAs(h, admin, "?dry_run=true", tt.contentType, tt.body))
+			var valid int
+			for _, status := range tt.wantStatus {
+				if status == models.BulkRowValid {
+					valid++
+				}
+			}
+			if !result.DryRun || result.Total != len(tt.wantStatus) || result.Valid != valid || result.Invalid != len(tt.wantStatus)-valid ||
+				result.Created != 0 {
+				t.Fatalf("result = %+v", result)
+			}
+			for i, row := range result.Rows {
+				if row.Status != tt.wantStatus[i] || strings.Join(row.Errors, "; ") != tt.wantErrors[i] || row.Line != tt.wantLines[i] ||
+					row.AccountID != "" {
+					t.Errorf("row %d = %+v, want %s on line %d with %q", i, row, tt.wantStatus[i], tt.wantLines[i], tt.wantErrors[i])
+				}
+			}
+			if stored, _ := accounts.ListAll(context.Background()); len(stored) != 0 || len(*published) != 0 {
+				t.Fatalf("dry run stored %+v and published %+v", stored, *published)
+			}
+		})
+	}
+}
+
+func TestBulkImportRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		as          auth.Principal
+		query       string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "customer", as: customer, contentType: "text/csv", body: "owner\ndana\n", want: http.StatusForbidden},
+		{name: "bad dry_run", as: admin, query: "?dry_run=maybe", contentType: "text/csv", body: "owner\ndana\n", want: http.StatusBadRequest},
+		{name: "bad initial_deposit", as: admin, query: "?initial_deposit=2", contentType: "text/csv", body: "owner\ndana\n",
+			want: http.StatusBadRequest},
+		{name: "unknown format", as: admin, query: "?format=xml", body: "<owner/>", want: http.StatusUnsupportedMediaType},
+		{name: "unknown content type", as: admin, contentType: "text/plain", body: "owner\ndana\n", want: http.StatusUnsupportedMediaType},
+		{name: "empty", as: admin, contentType: "application/x-ndjson", body: "\n\n", want: http.StatusBadRequest},
+		{name: "unknown column", as: admin, contentType: "text/csv", body: "owner,phone\ndana,555\n", want: http.StatusBadRequest},
+		{name: "no owner column", as: admin, contentType: "text/csv", body: "email\ndana@example.com\n", want: http.StatusBadRequest},
+		{name: "too many rows", as: admin, contentType: "text/csv", body: "owner\n" + strings.Repeat("dana\n", maxImportRows+1),
+			want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newMemAccounts()
+			h, _ := newTestAccountHandler(accounts)
+			if w := importAs(h, tt.as, tt.query, tt.contentType, tt.body); w.Code != tt.want {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body, tt.want)
+			}
+			if stored, _ := accounts.ListAll(context.Background()); len(stored) != 0 {
+				t.Fatalf("stored %+v", stored)
+			}
+		})
+	}
+}
+
+// failingBatch leaves the accounts of the given owners unwritten.
+type failingBatch struct {
+	*memAccounts
+	owners []string
+}
+
+func (s failingBatch) CreateBatch(ctx context.Context, accounts []models.Account) ([]string, error) {
+	var written []models.Account
+	var unwritten []string
+	for _, a := range accounts {
+		if slices.Contains(s.owners, a.Owner) {
+			unwritten = append(unwritten, a.ID)
+		} else {
+			written = append(written, a)
+		}
+	}
+	s.memAccounts.CreateBatch(ctx, written)
+	return unwritten, errors.New("throttled")
+}
+
+func TestBulkImport(t *testing.T) {
+	body := "owner,email,account_type\ngina,gina@example.com,savings\nhenry,,\nivan,not an email,\njune,,savings\n"
+	tests := []struct {
+		name         string
+		query        string
+		wantDeposits []float64
+	}{
+		{name: "with initial deposits", wantDeposits: []float64{50, 0}},
+		{name: "without initial deposits", query: "?initial_deposit=false", wantDeposits: []float64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newMemAccounts()
+			h, published := newTestAccountHandler(failingBatch{memAccounts: accounts, owners: []string{"june"}})
+
+			result := decodeImport(t, importAs(h, admin, tt.query, "text/csv", body))
+			if result.DryRun || result.Total != 4 || result.Valid != 3 || result.Invalid != 1 || result.Created != 2 || result.Failed != 1 {
+				t.Fatalf("result = %+v", result)
+			}
+			statuses := make([]string, len(result.Rows))
+			for i, row := range result.Rows {
+				statuses[i] = row.Status
+			}
+			want := []string{models.BulkRowCreated, models.BulkRowCreated, models.BulkRowInvalid, models.BulkRowFailed}
+			if !slices.Equal(statuses, want) {
+				t.Fatalf("rows %v, want %v", statuses, want)
+			}
+			if failed := result.Rows[3]; failed.AccountID != "" || strings.Join(failed.Errors, "") != "throttled" {
+				t.Fatalf("failed row = %+v", failed)
+			}
+
+			for i, row := range result.Rows[:2] {
+				account, _ := accounts.GetByID(context.Background(), row.AccountID)
+				if account == nil || account.Status != models.AccountStatusActive || account.KYCStatus != models.KYCUnverified ||
+					account.Balance != 0 || account.CreatedAt.IsZero() {
+					t.Fatalf("row %d stored %+v", i, account)
+				}
+			}
+			if stored, _ := accounts.ListAll(context.Background()); len(stored) != 2 {
+				t.Fatalf("stored %d accounts, want 2", len(stored))
+			}
+
+			// Each created account is published, with its product's initial
+			// deposit unless turned off
+			var deposits []float64
+			for _, event := range *published {
+				if event.Type != events.AccountCreated {
+					t.Fatalf("published %s", event.Type)
+				}
+				deposits = append(deposits, event.InitialDeposit)
+			}
+			if !slices.Equal(deposits, tt.wantDeposits) {
+				t.Fatalf("initial deposits %v, want %v", deposits, tt.wantDeposits)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	var stored []models.Account
+	for i := range maxPageSize + 1 {
+		stored = append(stored, models.Account{ID: fmt.Sprintf("acc-%03d", i), Owner: "dana", Email: "dana@example.com", Balance: 12.5})
+	}
+	h, _ := newTestAccountHandler(newMemAccounts(stored...))
+	export := func(p auth.Principal, format string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleExport(w, as(p, http.MethodGet, "/accounts:export?format="+format, ""))
+		return w
+	}
+
+	w := export(admin, "csv")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("CSV export: %d %v", w.Code, err)
+	}
+	if len(records) != len(stored)+1 || !slices.Equal(records[0], AccountCSVHeader) ||
+		!slices.Equal(records[1][:6], []string{"acc-000", "dana", "dana@example.com", "", models.AccountStatusActive, "12.50"}) {
+		t.Fatalf("CSV export of %d rows starting %v", len(records), records[:2])
+	}
+
+	w = export(admin, "")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var last models.Account
+	json.Unmarshal([]byte(lines[len(lines)-1]), &last)
+	if w.Header().Get("Content-Type") != "application/x-ndjson" || len(lines) != len(stored) || last.ID != "acc-100" {
+		t.Fatalf("NDJSON export of %d lines ending %+v", len(lines), last)
+	}
+
+	if w := export(admin, "xml"); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format: got %d, want 400", w.Code)
+	}
+	if w := export(customer, "csv"); w.Code != http.StatusForbidden {
+		t.Fatalf("customer: got %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// Error codes returned in the "code" field of error responses. Clients should
+// branch on these rather than on the human-readable message.
+const (
+	CodeBadRequest          = "bad_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeConflict            = "conflict"
+	CodeAccountInactive     = "account_inactive"
+	CodeInsufficientFunds   = "insufficient_funds"
+	CodeLimitExceeded       = "limit_exceeded"
+	CodeTransactionDenied   = "transaction_denied"
+	CodeKYCRejected         = "kyc_rejected"
+	CodeIdempotencyMismatch = "idempotency_key_mismatch"
+	CodeUnprocessable       = "unprocessable"
+	CodeRateLimited         = "rate_limited"
+	CodeInternal            = "internal"
+	CodeUnavailable         = "unavailable"
+)
+
+type errorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WriteError logs the failure and writes a JSON error body that carries the
+// request ID, so callers can quote it when reporting problems. The error code
+// is derived from status; use WriteErrorCode for a more specific one.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	WriteErrorCode(w, r, status, codeForStatus(status), message)
+}
+
+// WriteErrorCode is WriteError with an explicit error code.
+func WriteErrorCode(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	logger := logging.FromContext(r.Context())
+	if status >= http.StatusInternalServerError {
+		logger.Error("request failed", "status", status, "code", code, "error", message)
+	} else {
+		logger.Warn("request rejected", "status", status, "code", code, "error", message)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{
+		Error:     message,
+		Code:      code,
+		RequestID: logging.RequestID(r.Context()),
+	})
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// upstreamErrorStatus maps a failed transaction service call to a response
+// status, so an open circuit is reported as temporarily unavailable.
+func upstreamErrorStatus(err error) int {
+	if errors.Is(err, upstream.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// requestError is a failure carrying the response it maps to, for logic
+// that handlers share with background jobs.
+type requestError struct {
+	status int
+	code   string
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+
+func (e *requestError) Unwrap() error { return e.err }
+
+// upstreamError wraps a failed transaction service call with the status
+// upstreamErrorStatus maps it to.
+func upstreamError(msg string, err error) error {
+	status := upstreamErrorStatus(err)
+	return &requestError{status: status, code: codeForStatus(status), err: fmt.Errorf("%s: %w", msg, err)}
+}
+
+// writeRequestError writes the response for err: a requestError's status and
+// code, or 500 for anything else.
+func writeRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	var rerr *requestError
+	if errors.As(err, &rerr) {
+		WriteErrorCode(w, r, rerr.status, rerr.code, rerr.Error())
+		return
+	}
+	WriteError(w, r, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/holds"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/outbox"
+	"github.com/corebank-api/internal/repository"
+)
+
+type HoldHandler struct {
+	accounts AccountStore
+	store    holds.Store
+	balances *holds.Balances
+	outbox   *outbox.Outbox
+	// transactions applies the checks of new transactions to holds and
+	// their captures.
+	transactions  *TransactionHandler
+	defaultExpiry time.Duration
+	maxExpiry     time.Duration
+}
+
+func NewHoldHandler(accounts AccountStore, store holds.Store, balances *holds.Balances, outbox *outbox.Outbox, transactions *TransactionHandler, defaultExpiry, maxExpiry time.Duration) *HoldHandler {
+	return &HoldHandler{
+		accounts:      accounts,
+		store:         store,
+		balances:      balances,
+		outbox:        outbox,
+		transactions:  transactions,
+		defaultExpiry: defaultExpiry,
+		maxExpiry:     maxExpiry,
+	}
+}
+
+type placeHoldRequest struct {
+	Amount      float64    `json:"amount"`
+	Reference   string     `json:"reference"`
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type captureHoldRequest struct {
+	// Amount defaults to what remains of the hold
+	Amount *float64 `json:"amount"`
+	// Final releases whatever remains after this capture
+	Final bool `json:"final"`
+}
+
+// HandlePlaceHold serves POST /accounts/{id}/holds. The hold is checked as
+// a withdrawal of its amount would be: against the account's transaction
+// amount limit and its available balance under its product's rules. Its
+// outflow limits and risk rules apply when it is captured.
+func (h *HoldHandler) HandlePlaceHold(w http.ResponseWriter, r *http.Request) {
+	var req placeHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Amount <= 0 || math.IsInf(req.Amount, 0) || math.IsNaN(req.Amount) {
+		WriteError(w, r, http.StatusBadRequest, "amount must be a positive number")
+		return
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(h.defaultExpiry)
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.UTC()
+		if !expiresAt.After(now) {
+			WriteError(w, r, http.StatusBadRequest, "expires_at must be in the future")
+			return
+		}
+		if expiresAt.After(now.Add(h.maxExpiry)) {
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("expires_at must be within %s", h.maxExpiry))
+			return
+		}
+	}
+
+	account, ok := h.account(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if !account.IsActive() {
+		WriteErrorCode(w, r, http.StatusConflict, CodeAccountInactive, fmt.Sprintf("Account is %s", account.Status))
+		return
+	}
+	if err := kycBlocked(account); err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	if !h.transactions.checkProductRules(w, r, account, "withdrawal", req.Amount) {
+		return
+	}
+
+	hold := &models.Hold{
+		ID:          uuid.New().String(),
+		AccountID:   account.ID,
+		Amount:      req.Amount,
+		Reference:   req.Reference,
+		Description: req.Description,
+		Status:      models.HoldActive,
+		Captures:    []models.HoldCapture{},
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.store.Create(r.Context(), hold); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("hold placed", "hold_id", hold.ID, "account_id", account.ID, "amount", hold.Amount)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+// HandleListHolds serves GET /accounts/{id}/holds, optionally filtered by
+// ?status=.
+func (h *HoldHandler) HandleListHolds(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.HoldActive, models.HoldCaptured, models.HoldReleased, models.HoldExpired:
+	default:
+		WriteError(w, r, http.StatusBadRequest, "status must be active, captured, released or expired")
+		return
+	}
+
+	account, ok := h.account(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	list, err := h.store.ListByAccount(r.Context(), account.ID, status)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.Hold{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleBalance serves GET /accounts/{id}/balance, the account's ledger
+// balance next to what it has available after pending transactions and
+// holds.
+func (h *HoldHandler) HandleBalance(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.account(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	balance, err := h.balances.Get(r.Context(), account)
+	if err != nil {
+		WriteError(w, r, upstreamErrorStatus(err), fmt.Sprintf("failed to read balance: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// HandleGetHold serves GET /holds/{id}.
+func (h *HoldHandler) HandleGetHold(w http.ResponseWriter, r *http.Request) {
+	hold, _, ok := h.hold(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hold)
+}
+
+// HandleCapture serves POST /holds/{id}/capture. It converts part or all of
+// an active hold into a withdrawal; the hold stays active for the rest
+// unless the capture is final or nothing remains. The withdrawal is checked
+// as one submitted to POST /transactions is, except against the balance the
+// hold already reserves: the account must be active and its customer not
+// rejected by KYC, it counts against the daily and monthly outflow limits,
+// and the risk rules screen it. The hold is updated before the withdrawal is
+// submitted, so concurrent captures cannot both spend the same funds.
+func (h *HoldHandler) HandleCapture(w http.ResponseWriter, r *http.Request) {
+	var req captureHoldRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	hold, account, ok := h.activeHold(w, r)
+	if !ok {
+		return
+	}
+	remaining := hold.Remaining()
+	amount := remaining
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if amount <= 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		WriteError(w, r, http.StatusBadRequest, "amount must be a positive number")
+		return
+	}
+	if amount > remaining {
+		WriteErrorCode(w, r, http.StatusUnprocessableEntity, CodeUnprocessable,
+			fmt.Sprintf("amount %.2f exceeds the %.2f remaining on hold %s", amount, remaining, hold.ID))
+		return
+	}
+
+	if !account.IsActive() {
+		WriteErrorCode(w, r, http.StatusConflict, CodeAccountInactive, fmt.Sprintf("Account is %s", account.Status))
+		return
+	}
+	if err := kycBlocked(account); err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	description := "Hold capture"
+	if hold.Reference != "" {
+		description += " " + hold.Reference
+	}
+	txn := models.Transaction{
+		AccountID:   hold.AccountID,
+		Amount:      amount,
+		Type:        "withdrawal",
+		Description: description,
+		Status:      "pending",
+	}
+	decision, err := h.transactions.screen(r.Context(), account, &txn)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	reservation, err := h.transactions.reserveOutflow(r.Context(), account, txn.Type, amount)
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	original := *hold
+	now := time.Now().UTC()
+	capture := models.HoldCapture{Amount: amount, OutboxID: uuid.New().String(), CapturedAt: now}
+	hold.Captured = math.Round((hold.Captured+amount)*100) / 100
+	hold.Captures = append(append([]models.HoldCapture{}, hold.Captures...), capture)
+	if req.Final || hold.Captured >= hold.Amount {
+		hold.Status = models.HoldCaptured
+	}
+	hold.UpdatedAt = now
+	if !h.update(w, r, hold, original.UpdatedAt) {
+		h.transactions.cancelOutflow(r.Context(), reservation)
+		return
+	}
+
+	var actor string
+	if p, ok := auth.FromContext(r.Context()); ok {
+		actor = p.Subject
+	}
+	entry := &models.OutboxEntry{
+		ID:          capture.OutboxID,
+		Source:      models.OutboxSourceHoldCapture,
+		Reason:      "hold " + hold.ID,
+		Actor:       actor,
+		Transaction: txn,
+	}
+	if err := h.outbox.Submit(r.Context(), entry); err != nil {
+		// Put the funds back on hold so the capture can be retried
+		h.transactions.cancelOutflow(r.Context(), reservation)
+		original.UpdatedAt = time.Now().UTC()
+		if rerr := h.store.Update(r.Context(), &original, hold.UpdatedAt); rerr != nil {
+			logging.FromContext(r.Context()).Error("hold capture could not be rolled back",
+				"hold_id", hold.ID, "outbox_id", capture.OutboxID, "error", rerr)
+		}
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to record capture: %v", err))
+		return
+	}
+	h.transactions.recordCaptureDecision(r.Context(), decision, entry)
+
+	// Link the capture to its transaction if it was delivered straight
+	// away. Failing to is harmless: the outbox entry records it too.
+	if entry.Transaction.ID != "" {
+		prev := hold.UpdatedAt
+		hold.Captures[len(hold.Captures)-1].TransactionID = entry.Transaction.ID
+		hold.UpdatedAt = time.Now().UTC()
+		if err := h.store.Update(r.Context(), hold, prev); err != nil {
+			logging.FromContext(r.Context()).Warn("hold capture not linked to its transaction",
+				"hold_id", hold.ID, "transaction_id", entry.Transaction.ID, "error", err)
+			hold.UpdatedAt = prev
+		}
+	}
+	logging.FromContext(r.Context()).Info("hold captured", "hold_id", hold.ID, "account_id", hold.AccountID, "amount", amount)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hold)
+}
+
+// HandleRelease serves POST /holds/{id}/release, returning what remains of
+// an active hold to the available balance.
+func (h *HoldHandler) HandleRelease(w http.ResponseWriter, r *http.Request) {
+	hold, _, ok := h.activeHold(w, r)
+	if !ok {
+		return
+	}
+	prev := hold.UpdatedAt
+	hold.Status = models.HoldReleased
+	hold.UpdatedAt = time.Now().UTC()
+	if !h.update(w, r, hold, prev) {
+		return
+	}
+	logging.FromContext(r.Context()).Info("hold released", "hold_id", hold.ID, "account_id", hold.AccountID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hold)
+}
+
+// account loads the account named in the path and checks the caller may
+// access it, writing the error response itself when either fails.
+func (h *HoldHandler) account(w http.ResponseWriter, r *http.Request, id string) (*models.Account, bool) {
+	account, err := h.accounts.GetByID(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return nil, false
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return nil, false
+	}
+	return account, true
+}
+
+// hold loads the hold named in the path and its account, and checks the
+// caller may access the account.
+func (h *HoldHandler) hold(w http.ResponseWriter, r *http.Request) (*models.Hold, *models.Account, bool) {
+	hold, err := h.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	if hold == nil {
+		WriteError(w, r, http.StatusNotFound, "Hold not found")
+		return nil, nil, false
+	}
+	account, ok := h.account(w, r, hold.AccountID)
+	if !ok {
+		return nil, nil, false
+	}
+	return hold, account, true
+}
+
+// activeHold is hold for operations that need the hold to still reserve
+// funds. A hold past its expiry is rejected even if the sweeper has not
+// expired it yet.
+func (h *HoldHandler) activeHold(w http.ResponseWriter, r *http.Request) (*models.Hold, *models.Account, bool) {
+	hold, account, ok := h.hold(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	if hold.Status != models.HoldActive {
+		WriteError(w, r, http.StatusConflict, fmt.Sprintf("Hold is %s", hold.Status))
+		return nil, nil, false
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		WriteError(w, r, http.StatusConflict, "Hold has expired")
+		return nil, nil, false
+	}
+	return hold, account, true
+}
+
+// update saves a changed hold, answering 409 if it was changed concurrently.
+func (h *HoldHandler) update(w http.ResponseWriter, r *http.Request, hold *models.Hold, prevUpdatedAt time.Time) bool {
+	err := h.store.Update(r.Context(), hold, prevUpdatedAt)
+	if errors.Is(err, repository.ErrConflict) {
+		WriteError(w, r, http.StatusConflict, "Hold was changed by another request; retry")
+		return false
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+const (
+	maxDocumentNumberLength = 64
+	maxAddressLength        = 500
+)
+
+var kycDocumentTypes = map[string]bool{
+	models.KYCDocumentPassport:       true,
+	models.KYCDocumentNationalID:     true,
+	models.KYCDocumentDrivingLicence: true,
+}
+
+// KYCHandler serves customers' identity submissions and the decisions on
+// them.
+type KYCHandler struct {
+	accounts KYCAccountStore
+	records  KYCStore
+	events   events.Publisher
+}
+
+func NewKYCHandler(accounts KYCAccountStore, records KYCStore, publisher events.Publisher) *KYCHandler {
+	return &KYCHandler{accounts: accounts, records: records, events: publisher}
+}
+
+// HandleGetKYC serves GET /accounts/{id}/kyc, the account's KYC status and
+// latest submission.
+func (h *KYCHandler) HandleGetKYC(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.account(w, r)
+	if !ok {
+		return
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
+	}
+	record, err := h.records.Get(r.Context(), account.ID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if record == nil {
+		record = &models.KYCRecord{AccountID: account.ID}
+	}
+	// The account holds the status transactions are checked against
+	record.Status = account.KYCLevel()
+	h.writeRecord(w, r, http.StatusOK, record)
+}
+
+// HandleSubmitKYC serves POST /accounts/{id}/kyc, which submits the
+// customer's identity for verification and puts the account's KYC status
+// to pending. Only unverified customers may submit.
+func (h *KYCHandler) HandleSubmitKYC(w http.ResponseWriter, r *http.Request) {
+	var submission models.KYCSubmission
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if problems := validateKYCSubmission(&submission); len(problems) > 0 {
+		WriteError(w, r, http.StatusBadRequest, strings.Join(problems, "; "))
+		return
+	}
+
+	account, ok := h.account(w, r)
+	if !ok {
+		return
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
+	}
+	switch account.KYCLevel() {
+	case models.KYCPending:
+		WriteError(w, r, http.StatusConflict, "identity already submitted and awaiting a decision")
+		return
+	case models.KYCVerified:
+		WriteError(w, r, http.StatusConflict, "identity already verified")
+		return
+	case models.KYCRejected:
+		WriteErrorCode(w, r, http.StatusConflict, CodeKYCRejected, "identity verification was rejected; it can only be reviewed by an admin")
+		return
+	}
+
+	now := time.Now().UTC()
+	record := &models.KYCRecord{
+		AccountID:      account.ID,
+		Status:         models.KYCPending,
+		LegalName:      submission.LegalName,
+		DateOfBirth:    submission.DateOfBirth,
+		Nationality:    submission.Nationality,
+		DocumentType:   submission.DocumentType,
+		DocumentNumber: submission.DocumentNumber,
+		Address:        submission.Address,
+		SubmittedAt:    &now,
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		record.SubmittedBy = p.Subject
+	}
+	// The record is saved first so a failed status change leaves the
+	// customer unverified and free to submit again
+	if err := h.records.Put(r.Context(), record); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !h.setStatus(w, r, account, models.KYCPending, "identity submitted", now) {
+		return
+	}
+	h.writeRecord(w, r, http.StatusAccepted, record)
+}
+
+// HandleDecideKYC serves POST /accounts/{id}/kyc/decision, which records an
+// admin's decision on the account's submission. A decision may be changed
+// later; rejecting requires a reason.
+func (h *KYCHandler) HandleDecideKYC(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "deciding on KYC submissions requires an admin token")
+		return
+	}
+	var decision models.KYCDecision
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	decision.Reason = strings.TrimSpace(decision.Reason)
+	switch {
+	case decision.Decision != models.KYCVerified && decision.Decision != models.KYCRejected:
+		WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("decision must be %s or %s", models.KYCVerified, models.KYCRejected))
+		return
+	case decision.Decision == models.KYCRejected && decision.Reason == "":
+		WriteError(w, r, http.StatusBadRequest, "reason is required to reject a submission")
+		return
+	}
+
+	account, ok := h.account(w, r)
+	if !ok {
+		return
+	}
+	record, err := h.records.Get(r.Context(), account.ID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if record == nil || account.KYCLevel() == models.KYCUnverified {
+		WriteError(w, r, http.StatusConflict, "the customer has not submitted their identity")
+		return
+	}
+
+	now := time.Now().UTC()
+	record.Status = decision.Decision
+	record.DecidedAt = &now
+	record.Reason = decision.Reason
+	record.DecidedBy = ""
+	if p, ok := auth.FromContext(r.Context()); ok {
+		record.DecidedBy = p.Subject
+	}
+	if err := h.records.Put(r.Context(), record); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if account.KYCLevel() != decision.Decision {
+		if !h.setStatus(w, r, account, decision.Decision, decision.Reason, now) {
+			return
+		}
+	}
+	logging.FromContext(r.Context()).Info("KYC decision recorded", "account_id", account.ID, "decision", decision.Decision)
+	h.writeRecord(w, r, http.StatusOK, record)
+}
+
+// HandleListKYC serves GET /kyc, the KYC records of every account, oldest
+// submission first, optionally those with ?status=. It requires an admin
+// token.
+func (h *KYCHandler) HandleListKYC(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "listing KYC records requires an admin token")
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.KYCPending, models.KYCVerified, models.KYCRejected:
+	default:
+		WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("status must be %s, %s or %s", models.KYCPending, models.KYCVerified, models.KYCRejected))
+		return
+	}
+
+	records, err := h.records.List(r.Context(), status)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if records == nil {
+		records = []models.KYCRecord{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+func (h *KYCHandler) account(w http.ResponseWriter, r *http.Request) (*models.Account, bool) {
+	account, err := h.accounts.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return nil, false
+	}
+	return account, true
+}
+
+// setStatus changes the account's KYC status, recording why in its history,
+// and publishes the change. It writes the response and returns false if
+// the change cannot be saved, including when the status changed since the
+// account was read.
+func (h *KYCHandler) setStatus(w http.ResponseWriter, r *http.Request, account *models.Account, status, reason string, now time.Time) bool {
+	change := models.AccountChange{
+		Field:     "kyc_status",
+		Old:       account.KYCLevel(),
+		New:       status,
+		RequestID: logging.RequestID(r.Context()),
+		ChangedAt: now,
+		Reason:    reason,
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		change.Actor = p.Subject
+	}
+	err := h.accounts.SetKYCStatus(r.Context(), account.ID, change.Old, status, change)
+	if errors.Is(err, repository.ErrConflict) {
+		WriteError(w, r, http.StatusConflict, "the account's KYC status changed while it was being updated; retry")
+		return false
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	account.KYCStatus = status
+	account.UpdatedAt = now
+	h.events.Publish(r.Context(), events.NewAccountUpdated(account, []models.AccountChange{change}))
+	return true
+}
+
+// writeRecord writes record with its document number masked for anyone
+// but an admin.
+func (h *KYCHandler) writeRecord(w http.ResponseWriter, r *http.Request, status int, record *models.KYCRecord) {
+	shown := *record
+	if !auth.IsAdmin(r.Context()) {
+		shown.DocumentNumber = maskDocumentNumber(shown.DocumentNumber)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(shown)
+}
+
+// maskDocumentNumber keeps only the last four characters of a document
+// number.
+func maskDocumentNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+// validateKYCSubmission trims and normalises a submission and reports what
+// is wrong with it.
+func validateKYCSubmission(s *models.KYCSubmission) []string {
+	s.LegalName = strings.TrimSpace(s.LegalName)
+	s.DateOfBirth = strings.TrimSpace(s.DateOfBirth)
+	s.Nationality = strings.ToUpper(strings.TrimSpace(s.Nationality))
+	s.DocumentType = strings.TrimSpace(s.DocumentType)
+	s.DocumentNumber = strings.TrimSpace(s.DocumentNumber)
+	s.Address = strings.TrimSpace(s.Address)
+
+	var problems []string
+	switch {
+	case s.LegalName == "":
+		problems = append(problems, "legal_name is required")
+	case len(s.LegalName) > maxOwnerLength:
+		problems = append(problems, fmt.Sprintf("legal_name must be at most %d characters", maxOwnerLength))
+	}
+	if born, err := time.Parse(time.DateOnly, s.DateOfBirth); err != nil || born.After(time.Now()) || born.Year() < 1900 {
+		problems = append(problems, "date_of_birth must be a past date, YYYY-MM-DD")
+	}
+	if len(s.Nationality) != 2 || strings.Trim(s.Nationality, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		problems = append(problems, "nationality must be an ISO 3166-1 alpha-2 country code")
+	}
+	if !kycDocumentTypes[s.DocumentType] {
+		problems = append(problems, fmt.Sprintf("document_type must be %s, %s or %s",
+			models.KYCDocumentPassport, models.KYCDocumentNationalID, models.KYCDocumentDrivingLicence))
+	}
+	switch {
+	case s.DocumentNumber == "":
+		problems = append(problems, "document_number is required")
+	case len(s.DocumentNumber) > maxDocumentNumberLength:
+		problems = append(problems, fmt.Sprintf("document_number must be at most %d characters", maxDocumentNumberLength))
+	}
+	switch {
+	case s.Address == "":
+		problems = append(problems, "address is required")
+	case len(s.Address) > maxAddressLength:
+		problems = append(problems, fmt.Sprintf("address must be at most %d characters", maxAddressLength))
+	}
+	return problems
+}
+
+// kycBlocked returns the error for a new transaction on the account of a
+// customer whose identity verification was rejected, nil for any other.
+func kycBlocked(account *models.Account) error {
+	if account.KYCLevel() != models.KYCRejected {
+		return nil
+	}
+	return &requestError{status: http.StatusForbidden, code: CodeKYCRejected,
+		err: fmt.Errorf("Account %s: the customer's identity verification was rejected", account.ID)}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/limits"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+// LimitHandler serves accounts' outflow limits and what remains of them.
+type LimitHandler struct {
+	accounts LimitStore
+	limiter  *limits.Limiter
+	events   events.Publisher
+}
+
+func NewLimitHandler(accounts LimitStore, limiter *limits.Limiter, publisher events.Publisher) *LimitHandler {
+	return &LimitHandler{accounts: accounts, limiter: limiter, events: publisher}
+}
+
+// HandleGetLimits serves GET /accounts/{id}/limits, the account's limits and
+// the allowance remaining today and this month.
+func (h *LimitHandler) HandleGetLimits(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.account(w, r)
+	if !ok {
+		return
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
+	}
+	h.writeStatus(w, r, account)
+}
+
+// HandleSetLimits serves PUT /accounts/{id}/limits, which replaces the
+// account's own limits. Limits left out follow the account's product, and
+// an empty body removes every override. It requires an admin token.
+func (h *LimitHandler) HandleSetLimits(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "setting account limits requires an admin token")
+		return
+	}
+	var overrides models.AccountLimits
+	if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	for name, limit := range map[string]*float64{
+		"max_transaction_amount": overrides.MaxTransactionAmount,
+		"daily_outflow":          overrides.DailyOutflow,
+		"monthly_outflow":        overrides.MonthlyOutflow,
+	} {
+		if limit != nil && (!(*limit >= 0) || math.IsInf(*limit, 0)) {
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("%s must be zero or a positive amount", name))
+			return
+		}
+	}
+	var limits *models.AccountLimits
+	if overrides != (models.AccountLimits{}) {
+		limits = &overrides
+	}
+
+	account, ok := h.account(w, r)
+	if !ok {
+		return
+	}
+	change := models.AccountChange{
+		Field:     "limits",
+		Old:       formatLimits(account.Limits),
+		New:       formatLimits(limits),
+		RequestID: logging.RequestID(r.Context()),
+		ChangedAt: time.Now().UTC(),
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		change.Actor = p.Subject
+	}
+	if change.Old != change.New {
+		err := h.accounts.SetLimits(r.Context(), account.ID, limits, change)
+		if errors.Is(err, repository.ErrNotFound) {
+			WriteError(w, r, http.StatusNotFound, "Account not found")
+			return
+		}
+		if err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		account.Limits = limits
+		account.UpdatedAt = change.ChangedAt
+		h.events.Publish(r.Context(), events.NewAccountUpdated(account, []models.AccountChange{change}))
+	}
+	h.writeStatus(w, r, account)
+}
+
+func (h *LimitHandler) account(w http.ResponseWriter, r *http.Request) (*models.Account, bool) {
+	account, err := h.accounts.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return nil, false
+	}
+	return account, true
+}
+
+func (h *LimitHandler) writeStatus(w http.ResponseWriter, r *http.Request, account *models.Account) {
+	status, err := h.limiter.Status(r.Context(), account, time.Now())
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// formatLimits records an account's limits in its history as JSON, empty
+// for none.
+func formatLimits(limits *models.AccountLimits) string {
+	if limits == nil {
+		return ""
+	}
+	b, _ := json.Marshal(limits)
+	return string(b)
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleProducts serves GET /products, the account types accounts can be
+// opened with and the rules each follows.
+func (h *AccountHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.products.List())
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/reconcile"
+)
+
+// ReconciliationHandler runs balance reconciliations and serves their
+// reports. They cover every account, so all of its routes require an admin
+// token.
+type ReconciliationHandler struct {
+	reconciler *reconcile.Reconciler
+	store      reconcile.Store
+}
+
+func NewReconciliationHandler(reconciler *reconcile.Reconciler, store reconcile.Store) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciler: reconciler, store: store}
+}
+
+type reconcileRequest struct {
+	AccountIDs []string `json:"account_ids"`
+	Correct    bool     `json:"correct"`
+}
+
+// HandleReconcile serves POST /reconciliations. It checks the given
+// accounts, or every account, and with correct set makes their stored
+// balances match the ledger before returning the report.
+func (h *ReconciliationHandler) HandleReconcile(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	var req reconcileRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	opts := reconcile.Options{AccountIDs: req.AccountIDs, Correct: req.Correct}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		opts.Actor = p.Subject
+	}
+	report, err := h.reconciler.Reconcile(r.Context(), opts)
+	switch {
+	case errors.Is(err, reconcile.ErrAccountNotFound):
+		WriteError(w, r, http.StatusNotFound, err.Error())
+		return
+	case err != nil && report != nil:
+		WriteError(w, r, http.StatusInternalServerError, "reconciliation "+report.ID+" ran but its report was not saved: "+err.Error())
+		return
+	case err != nil:
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleListReconciliations serves GET /reconciliations, every report newest
+// first.
+func (h *ReconciliationHandler) HandleListReconciliations(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	reports, err := h.store.List(r.Context())
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if reports == nil {
+		reports = []models.Reconciliation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// HandleGetReconciliation serves GET /reconciliations/{id}.
+func (h *ReconciliationHandler) HandleGetReconciliation(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	report, err := h.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if report == nil {
+		WriteError(w, r, http.StatusNotFound, "Reconciliation not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ReconciliationHandler) admin(w http.ResponseWriter, r *http.Request) bool {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "reconciliation requires an admin token")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/risk"
+)
+
+// RiskHandler serves the log of risk decisions. Decisions explain what the
+// rules look for, so all of its routes require an admin token.
+type RiskHandler struct {
+	store risk.Store
+}
+
+func NewRiskHandler(store risk.Store) *RiskHandler {
+	return &RiskHandler{store: store}
+}
+
+// HandleListDecisions serves GET /risk/decisions, newest first, optionally
+// filtered by ?account_id= and ?decision=.
+func (h *RiskHandler) HandleListDecisions(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	decision := query.Get("decision")
+	switch decision {
+	case "", models.RiskAllow, models.RiskReview, models.RiskDeny:
+	default:
+		WriteError(w, r, http.StatusBadRequest, "decision must be allow, review or deny")
+		return
+	}
+
+	decisions, err := h.store.List(r.Context(), query.Get("account_id"), decision)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if decisions == nil {
+		decisions = []models.RiskDecision{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decisions)
+}
+
+// HandleGetDecision serves GET /risk/decisions/{id}. The decision on a
+// recorded transaction has the transaction's ID.
+func (h *RiskHandler) HandleGetDecision(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	decision, err := h.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if decision == nil {
+		WriteError(w, r, http.StatusNotFound, "Risk decision not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
+}
+
+func (h *RiskHandler) admin(w http.ResponseWriter, r *http.Request) bool {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "risk decisions require an admin token")
+		return false
+	}
+	return true
+}
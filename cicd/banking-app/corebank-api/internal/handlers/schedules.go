@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/schedules"
+)
+
+type ScheduleHandler struct {
+	accounts AccountStore
+	store    schedules.Store
+	loc      *time.Location
+}
+
+// NewScheduleHandler serves schedules whose recurrences are evaluated in
+// loc.
+func NewScheduleHandler(accounts AccountStore, store schedules.Store, loc *time.Location) *ScheduleHandler {
+	return &ScheduleHandler{accounts: accounts, store: store, loc: loc}
+}
+
+type createScheduleRequest struct {
+	FromAccountID string     `json:"from_account_id"`
+	ToAccountID   string     `json:"to_account_id"`
+	Amount        float64    `json:"amount"`
+	Description   string     `json:"description"`
+	Recurrence    string     `json:"recurrence"`
+	StartAt       *time.Time `json:"start_at"`
+}
+
+// HandleCreateSchedule serves POST /schedules. Without a recurrence the
+// transfer is made once at start_at, which is then required; with one it is
+// made on every occurrence from start_at, by default now, on. Occurrences
+// before now are skipped.
+func (h *ScheduleHandler) HandleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req createScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch {
+	case req.Amount <= 0 || math.IsInf(req.Amount, 0) || math.IsNaN(req.Amount):
+		WriteError(w, r, http.StatusBadRequest, "amount must be a positive number")
+		return
+	case req.FromAccountID == req.ToAccountID:
+		WriteError(w, r, http.StatusBadRequest, "cannot transfer to the same account")
+		return
+	case req.Recurrence == "" && req.StartAt == nil:
+		WriteError(w, r, http.StatusBadRequest, "start_at is required without a recurrence")
+		return
+	}
+
+	now := time.Now().UTC()
+	schedule := &models.Schedule{
+		ID:            uuid.New().String(),
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Recurrence:    req.Recurrence,
+		StartAt:       now,
+		Status:        models.ScheduleActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.StartAt != nil {
+		schedule.StartAt = req.StartAt.UTC()
+	}
+	first, ok, err := schedules.NextOccurrence(schedule, latest(schedule.StartAt, now).Add(-time.Nanosecond), h.loc)
+	if err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ok {
+		WriteError(w, r, http.StatusBadRequest, "the schedule has no occurrences after now")
+		return
+	}
+	first = first.UTC()
+	schedule.NextOccurrence = &first
+	schedule.NextAttemptAt = &first
+
+	for _, id := range []string{req.FromAccountID, req.ToAccountID} {
+		account, err := h.accounts.GetByID(r.Context(), id)
+		if err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if account == nil {
+			WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("Account not found: %s", id))
+			return
+		}
+		if !account.IsActive() {
+			WriteErrorCode(w, r, http.StatusConflict, CodeAccountInactive, fmt.Sprintf("Account %s is %s", id, account.Status))
+			return
+		}
+		if id == req.FromAccountID && !auth.CanAccess(r.Context(), account.Owner) {
+			WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+			return
+		}
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		schedule.CreatedBy = p.Subject
+	}
+
+	if err := h.store.Create(r.Context(), schedule); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("transfer scheduled", "schedule_id", schedule.ID,
+		"from_account_id", schedule.FromAccountID, "to_account_id", schedule.ToAccountID, "next_occurrence", first)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// HandleListSchedules serves GET /schedules, the schedules transferring from
+// ?account_id=. Only admins may list every schedule.
+func (h *ScheduleHandler) HandleListSchedules(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("account_id")
+	if accountID == "" {
+		if !auth.IsAdmin(r.Context()) {
+			WriteError(w, r, http.StatusForbidden, "account_id is required to list schedules")
+			return
+		}
+	} else if _, ok := h.account(w, r, accountID); !ok {
+		return
+	}
+
+	list, err := h.store.List(r.Context(), accountID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.Schedule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleGetSchedule serves GET /schedules/{id}.
+func (h *ScheduleHandler) HandleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.schedule(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// HandleCancelSchedule serves POST /schedules/{id}/cancel. Transfers already
+// made are not affected.
+func (h *ScheduleHandler) HandleCancelSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.schedule(w, r)
+	if !ok {
+		return
+	}
+	if schedule.Status != models.ScheduleActive {
+		WriteError(w, r, http.StatusConflict, fmt.Sprintf("Schedule is %s", schedule.Status))
+		return
+	}
+
+	prev := schedule.UpdatedAt
+	schedule.Status = models.ScheduleCancelled
+	schedule.NextOccurrence = nil
+	schedule.NextAttemptAt = nil
+	schedule.UpdatedAt = time.Now().UTC()
+	err := h.store.Update(r.Context(), schedule, prev)
+	if errors.Is(err, repository.ErrConflict) {
+		WriteError(w, r, http.StatusConflict, "Schedule was changed by another request; retry")
+		return
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("schedule cancelled", "schedule_id", schedule.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// HandleExecutions serves GET /schedules/{id}/executions, every attempt at
+// the schedule's occurrences, oldest first.
+func (h *ScheduleHandler) HandleExecutions(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.schedule(w, r)
+	if !ok {
+		return
+	}
+	executions, err := h.store.Executions(r.Context(), schedule.ID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if executions == nil {
+		executions = []models.ScheduleExecution{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(executions)
+}
+
+// account loads an account and checks the caller may access it, writing
+// the error response itself when either fails.
+func (h *ScheduleHandler) account(w http.ResponseWriter, r *http.Request, id string) (*models.Account, bool) {
+	account, err := h.accounts.GetByID(r.Context(), id)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return nil, false
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return nil, false
+	}
+	return account, true
+}
+
+// schedule loads the schedule named in the path and checks the caller may
+// access its source account. A schedule whose source account has been
+// deleted is left to admins.
+func (h *ScheduleHandler) schedule(w http.ResponseWriter, r *http.Request) (*models.Schedule, bool) {
+	schedule, err := h.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if schedule == nil {
+		WriteError(w, r, http.StatusNotFound, "Schedule not found")
+		return nil, false
+	}
+
+	account, err := h.accounts.GetByID(r.Context(), schedule.FromAccountID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if (account == nil && !auth.IsAdmin(r.Context())) || (account != nil && !auth.CanAccess(r.Context(), account.Owner)) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this schedule")
+		return nil, false
+	}
+	return schedule, true
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/statements"
+)
+
+// maxStatementRange bounds ad hoc statements, which fetch the account's
+// whole history from the transaction service.
+const maxStatementRange = 366 * 24 * time.Hour
+
+type StatementHandler struct {
+	accountRepo AccountStore
+	store       statements.Store
+	generator   *statements.Generator
+}
+
+func NewStatementHandler(accountRepo AccountStore, store statements.Store, generator *statements.Generator) *StatementHandler {
+	return &StatementHandler{
+		accountRepo: accountRepo,
+		store:       store,
+		generator:   generator,
+	}
+}
+
+// HandleStatement serves GET /accounts/{id}/statements. The range is either
+// a month (?period=YYYY-MM), served from the stored statement when the
+// month-end job has produced one, or ?from= and ?to=, as dates (to is
+// inclusive) or RFC 3339 times (to is exclusive). It defaults to the current
+// month so far.
+func (h *StatementHandler) HandleStatement(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	switch format {
+	case "":
+		format = statements.FormatJSON
+	case statements.FormatJSON, statements.FormatCSV, statements.FormatPDF:
+	default:
+		WriteError(w, r, http.StatusBadRequest, "format must be json, csv or pdf")
+		return
+	}
+
+	account, err := h.accountRepo.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
+	}
+
+	statement, ok := h.statement(w, r, account)
+	if !ok {
+		return
+	}
+
+	var body bytes.Buffer
+	switch format {
+	case statements.FormatCSV:
+		err = statements.WriteCSV(&body, statement)
+	case statements.FormatPDF:
+		err = statements.WritePDF(&body, statement)
+	default:
+		err = json.NewEncoder(&body).Encode(statement)
+	}
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to render statement: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", statements.ContentType(format))
+	if format != statements.FormatJSON {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statements.Filename(statement, format)))
+	}
+	w.Write(body.Bytes())
+}
+
+// statement builds or loads the requested statement, writing the error
+// response itself when it fails.
+func (h *StatementHandler) statement(w http.ResponseWriter, r *http.Request, account *models.Account) (*models.Statement, bool) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	period := query.Get("period")
+	if period != "" {
+		if query.Has("from") || query.Has("to") {
+			WriteError(w, r, http.StatusBadRequest, "period cannot be combined with from or to")
+			return nil, false
+		}
+		var err error
+		if from, to, err = h.generator.Month(period); err != nil {
+			WriteError(w, r, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+
+		stored, err := h.store.Get(r.Context(), account.ID, period)
+		if err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return nil, false
+		}
+		if stored != nil {
+			return stored, true
+		}
+	} else {
+		var err error
+		if from, to, err = h.parseRange(query.Get("from"), query.Get("to")); err != nil {
+			WriteError(w, r, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+	}
+
+	statement, err := h.generator.Generate(r.Context(), account, from, to)
+	if err != nil {
+		WriteError(w, r, upstreamErrorStatus(err), fmt.Sprintf("failed to fetch transactions: %v", err))
+		return nil, false
+	}
+	statement.Period = period
+	return statement, true
+}
+
+// parseRange reads from and to as dates in the statement timezone or as
+// RFC 3339 times. A date for to includes that whole day.
+func (h *StatementHandler) parseRange(rawFrom, rawTo string) (from, to time.Time, err error) {
+	loc := h.generator.Location()
+	now := time.Now().In(loc)
+
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if rawFrom != "" {
+		if from, err = parseStatementTime(rawFrom, loc, false); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from: %w", err)
+		}
+	}
+	to = now
+	if rawTo != "" {
+		if to, err = parseStatementTime(rawTo, loc, true); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to: %w", err)
+		}
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > maxStatementRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("range must not exceed 366 days")
+	}
+	return from, to, nil
+}
+
+func parseStatementTime(raw string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", raw, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD) or RFC 3339 time", raw)
+	}
+	return t, nil
+}
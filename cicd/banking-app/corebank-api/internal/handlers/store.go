@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// AccountStore is the account persistence the handlers depend on.
+// repository.AccountRepository implements it against DynamoDB.
+type AccountStore interface {
+	// Create saves a new account, failing with repository.ErrConflict if
+	// one with its ID already exists.
+	Create(ctx context.Context, account *models.Account) error
+	// GetByID returns nil and no error when the account does not exist.
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+	// Update saves the owner, email and account_type of an existing account
+	// and appends changes to its history. It fails with an error matching
+	// repository.ErrNotFound if the account does not exist.
+	Update(ctx context.Context, account *models.Account, changes []models.AccountChange) error
+	Delete(ctx context.Context, id string) error
+	ListAll(ctx context.Context) ([]models.Account, error)
+	// History returns the changes made to an account, oldest first.
+	History(ctx context.Context, id string) ([]models.AccountChange, error)
+	// ListPage returns up to limit accounts after cursor and the cursor for
+	// the next page, which is empty on the last page.
+	ListPage(ctx context.Context, limit int, cursor string) ([]models.Account, string, error)
+	// CreateBatch writes new accounts in bulk and returns the IDs of any it
+	// could not write.
+	CreateBatch(ctx context.Context, accounts []models.Account) (unwritten []string, err error)
+}
+
+// BalanceSource reports the balance product rules are checked against.
+// holds.Balances implements it from the account's stored balance, its
+// pending transactions and its active holds.
+type BalanceSource interface {
+	// AvailableBalance is the account's stored balance once every pending
+	// transaction settles, less what its active holds reserve.
+	AvailableBalance(ctx context.Context, account *models.Account) (float64, error)
+}
+
+// LimitStore reads accounts and sets their own limits;
+// repository.AccountRepository implements it.
+type LimitStore interface {
+	// GetByID returns nil and no error when the account does not exist.
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+	// SetLimits replaces the account's limits, removing them if limits is
+	// nil, and records change in its history. It fails with an error
+	// matching repository.ErrNotFound if the account does not exist.
+	SetLimits(ctx context.Context, id string, limits *models.AccountLimits, change models.AccountChange) error
+}
+
+// KYCAccountStore reads accounts and sets their KYC status;
+// repository.AccountRepository implements it.
+type KYCAccountStore interface {
+	// GetByID returns nil and no error when the account does not exist.
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+	// SetKYCStatus changes the account's KYC status from old to status and
+	// records change in its history. It fails with an error matching
+	// repository.ErrConflict if the account no longer exists or its KYC
+	// status is no longer old.
+	SetKYCStatus(ctx context.Context, id, old, status string, change models.AccountChange) error
+}
+
+// KYCStore keeps each account's identity submission; repository.KYCRepository
+// implements it against DynamoDB.
+type KYCStore interface {
+	// Put saves record, replacing the account's previous one.
+	Put(ctx context.Context, record *models.KYCRecord) error
+	// Get returns nil and no error when the account has submitted nothing.
+	Get(ctx context.Context, accountID string) (*models.KYCRecord, error)
+	// List returns the records with the given status, or every record when
+	// status is empty, oldest submission first.
+	List(ctx context.Context, status string) ([]models.KYCRecord, error)
+}
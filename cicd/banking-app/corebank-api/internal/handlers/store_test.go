@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+// memAccounts keeps accounts in memory with the conditions
+// repository.AccountRepository puts on them. It implements AccountStore and
+// posting.Store.
+type memAccounts struct {
+	mu       sync.Mutex
+	accounts map[string]models.Account
+	history  map[string][]models.AccountChange
+	postings map[string]models.BalancePosting
+}
+
+func newMemAccounts(accounts ...models.Account) *memAccounts {
+	s := &memAccounts{
+		accounts: make(map[string]models.Account),
+		history:  make(map[string][]models.AccountChange),
+		postings: make(map[string]models.BalancePosting),
+	}
+	for _, a := range accounts {
+		s.accounts[a.ID] = a
+	}
+	return s
+}
+
+func (s *memAccounts) Create(_ context.Context, a *models.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[a.ID]; ok {
+		return repository.ErrConflict
+	}
+	s.accounts[a.ID] = *a
+	return nil
+}
+
+func (s *memAccounts) GetByID(_ context.Context, id string) (*models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *memAccounts) Update(_ context.Context, a *models.Account, changes []models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[a.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	s.accounts[a.ID] = *a
+	s.history[a.ID] = append(s.history[a.ID], changes...)
+	return nil
+}
+
+func (s *memAccounts) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.accounts, id)
+	return nil
+}
+
+func (s *memAccounts) ListAll(_ context.Context) ([]models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]models.Account, 0, len(s.accounts))
+	for _, a := range s.accounts {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (s *memAccounts) History(_ context.Context, id string) ([]models.AccountChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history[id], nil
+}
+
+func (s *memAccounts) ListPage(ctx context.Context, limit int, cursor string) ([]models.Account, string, error) {
+	all, _ := s.ListAll(ctx)
+	start := sort.Search(len(all), func(i int) bool { return all[i].ID > cursor })
+	end := min(start+limit, len(all))
+	if end == len(all) {
+		return all[start:], "", nil
+	}
+	return all[start:end], all[end-1].ID, nil
+}
+
+func (s *memAccounts) CreateBatch(ctx context.Context, accounts []models.Account) ([]string, error) {
+	var unwritten []string
+	for i := range accounts {
+		if err := s.Create(ctx, &accounts[i]); err != nil {
+			unwritten = append(unwritten, accounts[i].ID)
+		}
+	}
+	return unwritten, nil
+}
+
+func (s *memAccounts) PostTransaction(ctx context.Context, posting *models.BalancePosting, old float64, change models.AccountChange) error {
+	return s.PostAdjustment(ctx, posting, nil, old, change)
+}
+
+func (s *memAccounts) PostAdjustment(_ context.Context, posting *models.BalancePosting, covered []models.BalancePosting, old float64, change models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	postings := append([]models.BalancePosting{*posting}, covered...)
+	for _, p := range postings {
+		if _, ok := s.postings[p.ID]; ok {
+			return repository.ErrAlreadyPosted
+		}
+	}
+	account, ok := s.accounts[posting.AccountID]
+	if !ok || account.Balance != old {
+		return repository.ErrConflict
+	}
+	account.Balance = posting.Balance
+	s.accounts[account.ID] = account
+	for _, p := range postings {
+		s.postings[p.ID] = p
+	}
+	s.history[account.ID] = append(s.history[account.ID], change)
+	return nil
+}
+
+func (s *memAccounts) GetPosting(_ context.Context, id string) (*models.BalancePosting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.postings[id]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// balance returns the stored balance of an account.
+func (s *memAccounts) balance(id string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accounts[id].Balance
+}
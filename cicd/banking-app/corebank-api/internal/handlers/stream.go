@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/stream"
+)
+
+// StreamHandler serves Server-Sent Events streams of account changes.
+type StreamHandler struct {
+	repo      AccountStore
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+// NewStreamHandler sends a comment on idle streams every heartbeat, which
+// keeps proxies from closing them and lets clients notice a dead connection.
+func NewStreamHandler(repo AccountStore, hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{repo: repo, hub: hub, heartbeat: heartbeat}
+}
+
+// HandleAccountEvents serves GET /accounts/{id}/events, a stream of the
+// account's balance and transaction changes made through this instance. A
+// client reconnecting with Last-Event-ID is sent what it missed if this
+// instance still has it; otherwise, as on a first connection, the stream
+// starts with the account's current state.
+func (h *StreamHandler) HandleAccountEvents(w http.ResponseWriter, r *http.Request) {
+	account, err := h.repo.GetByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if account == nil {
+		WriteError(w, r, http.StatusNotFound, "Account not found")
+		return
+	}
+	if !auth.CanAccess(r.Context(), account.Owner) {
+		WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+		return
+	}
+
+	// Subscribe before sending the current state so no change falls between
+	// the two
+	sub, missed, resumed := h.hub.Subscribe(account.ID, r.Header.Get("Last-Event-ID"))
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	logger := logging.FromContext(r.Context())
+	send := func(format string, args ...any) bool {
+		// The stream outlives the server's write timeout, so each write
+		// gets its own deadline
+		rc.SetWriteDeadline(time.Now().Add(h.heartbeat + 10*time.Second))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Clients wait a heartbeat before reconnecting after the stream drops
+	if !send("retry: %d\n\n", h.heartbeat.Milliseconds()) {
+		return
+	}
+	if resumed {
+		for _, m := range missed {
+			if !send("id: %s\nevent: %s\ndata: %s\n\n", m.ID, m.Type, m.Data) {
+				return
+			}
+		}
+	} else {
+		data, _ := json.Marshal(account)
+		if !send("id: %s\nevent: %s\ndata: %s\n\n", sub.Cursor, stream.MessageAccount, data) {
+			return
+		}
+	}
+	logger.Info("account stream opened", "account_id", account.ID, "resumed", resumed, "replayed", len(missed))
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		case m, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind, or shutting down; the client
+				// reconnects and resumes
+				return
+			}
+			if !send("id: %s\nevent: %s\ndata: %s\n\n", m.ID, m.Type, m.Data) {
+				return
+			}
+			if m.Type == stream.MessageAccountDeleted {
+				return
+			}
+		}
+	}
+}
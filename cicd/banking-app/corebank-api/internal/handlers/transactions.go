@@ -2,32 +2,59 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/limits"
+	"github.com/corebank-api/internal/logging"
 	"github.com/corebank-api/internal/models"
-	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/risk"
+	"github.com/corebank-api/internal/upstream"
 )
 
 type TransactionHandler struct {
-	accountRepo      *repository.AccountRepository
+	accountRepo      AccountStore
 	pythonServiceURL string
 	httpClient       *http.Client
+	transactions     *upstream.TransactionService
+	balances         BalanceSource
+	poster           *posting.Poster
+	risk             *risk.Engine
+	limits           *limits.Limiter
+	events           events.Publisher
 }
 
 func NewTransactionHandler(
-	accountRepo *repository.AccountRepository,
+	accountRepo AccountStore,
 	pythonServiceURL string,
+	httpClient *http.Client,
+	balances BalanceSource,
+	poster *posting.Poster,
+	engine *risk.Engine,
+	limiter *limits.Limiter,
+	publisher events.Publisher,
 ) *TransactionHandler {
 	return &TransactionHandler{
 		accountRepo:      accountRepo,
 		pythonServiceURL: pythonServiceURL,
-		httpClient:       &http.Client{Timeout: 5 * time.Second},
+		httpClient:       httpClient,
+		transactions:     upstream.NewTransactionService(pythonServiceURL, httpClient),
+		balances:         balances,
+		poster:           poster,
+		risk:             engine,
+		limits:           limiter,
+		events:           publisher,
 	}
 }
 
@@ -35,28 +62,71 @@ func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.R
 	w.Header().Set("Content-Type", "application/json")
 
 	// For POST requests, verify account exists first
+	var txn models.Transaction
+	var decision *models.RiskDecision
+	var reservation *limits.Reservation
 	if r.Method == http.MethodPost {
-		var txn models.Transaction
 		if err := json.NewDecoder(r.Body).Decode(&txn); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
 		// Verify account exists in Go's database
 		account, err := h.accountRepo.GetByID(r.Context(), txn.AccountID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 		if account == nil {
-			http.Error(w, "Account not found", http.StatusBadRequest)
+			WriteError(w, r, http.StatusBadRequest, "Account not found")
 			return
 		}
+		if !auth.CanAccess(r.Context(), account.Owner) {
+			WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+			return
+		}
+		// Completing a deposit credits the balance, which only an admin may
+		// do; customers' deposits wait, pending, for settlement
+		if txn.Status == "completed" && txn.Type == "deposit" && !auth.IsAdmin(r.Context()) {
+			WriteError(w, r, http.StatusForbidden, "completing a deposit requires an admin token")
+			return
+		}
+		if !account.IsActive() {
+			WriteErrorCode(w, r, http.StatusConflict, CodeAccountInactive, fmt.Sprintf("Account is %s", account.Status))
+			return
+		}
+		if err := kycBlocked(account); err != nil {
+			writeRequestError(w, r, err)
+			return
+		}
+		if !h.checkProductRules(w, r, account, txn.Type, txn.Amount) {
+			return
+		}
+		decision, err = h.screen(r.Context(), account, &txn)
+		if err != nil {
+			writeRequestError(w, r, err)
+			return
+		}
+		// A transaction under review waits, pending, for an admin to
+		// complete it
+		if decision != nil && decision.Decision == models.RiskReview {
+			txn.Status = "pending"
+		}
+		if reservation, err = h.reserveOutflow(r.Context(), account, txn.Type, txn.Amount); err != nil {
+			writeRequestError(w, r, err)
+			return
+		}
+		// The outflow no longer counts if the transaction is not recorded
+		defer func() {
+			if reservation != nil {
+				h.cancelOutflow(r.Context(), reservation)
+			}
+		}()
 
 		// Marshal the transaction back to JSON for forwarding
 		txnBytes, err := json.Marshal(txn)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("failed to marshal transaction: %v", err), http.StatusInternalServerError)
+			WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to marshal transaction: %v", err))
 			return
 		}
 
@@ -67,95 +137,429 @@ func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.R
 	// Use path.Join to avoid slash issues in URL construction
 	targetURL, err := url.JoinPath(h.pythonServiceURL, r.URL.Path)
 	if err != nil {
-		http.Error(w, "invalid URL path", http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, "invalid URL path")
 		return
 	}
 
 	// Forward the request to the Python service
-	forwardReq, err := http.NewRequest(r.Method, targetURL, r.Body)
+	forwardReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to create forward request: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to create forward request: %v", err))
 		return
 	}
 
-	// Copy headers from the original request
-	forwardReq.Header = r.Header
+	// Copy the headers the service needs, but not the caller's credentials
+	forwardReq.Header = forwardHeader(r)
 
 	// Execute the forward request
 	resp, err := h.httpClient.Do(forwardReq)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to forward request to transaction service: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, upstreamErrorStatus(err), fmt.Sprintf("failed to forward request to transaction service: %v", err))
 		return
 	}
 	defer resp.Body.Close()
 
+	if r.Method == http.MethodPost && resp.StatusCode < 300 {
+		// The transaction is recorded, so its outflow stays counted
+		reservation = nil
+
+		// Read the created transaction to publish it, then pass it on
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			WriteError(w, r, http.StatusBadGateway, fmt.Sprintf("failed to read transaction service response: %v", err))
+			return
+		}
+		var created models.Transaction
+		if err := json.Unmarshal(body, &created); err == nil {
+			h.events.Publish(r.Context(), events.NewTransactionSubmitted(&created))
+		}
+		h.recordDecision(r.Context(), decision, created.ID)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The service records every transaction as pending, so one submitted
+		// as completed is completed here
+		if txn.Status == "completed" && created.ID != "" {
+			completed, err := h.complete(r.Context(), created.ID)
+			if err != nil {
+				writeRequestError(w, r, err)
+				return
+			}
+			w.WriteHeader(resp.StatusCode)
+			json.NewEncoder(w).Encode(completed)
+			return
+		}
+	}
+
 	// Copy the response from the Python service back to the client
 	w.WriteHeader(resp.StatusCode)
 	if _, err := io.Copy(w, resp.Body); err != nil {
-		log.Printf("failed to write response: %v", err)
+		logging.FromContext(r.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+// forwardedHeaders are the caller's headers passed on to the transaction
+// service. Credentials such as Authorization are for this API alone.
+var forwardedHeaders = []string{"Accept", "Content-Type", logging.RequestIDHeader}
+
+// forwardHeader returns the headers of r to send with a request forwarded to
+// the transaction service.
+func forwardHeader(r *http.Request) http.Header {
+	header := make(http.Header)
+	for _, name := range forwardedHeaders {
+		if value := r.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	return header
+}
+
+// screen runs the risk rules on a transaction about to be submitted. A
+// denied transaction is logged and fails with a 422 transaction_denied;
+// otherwise the decision, nil without rules, is to be recorded with
+// recordDecision once the transaction is.
+func (h *TransactionHandler) screen(ctx context.Context, account *models.Account, txn *models.Transaction) (*models.RiskDecision, error) {
+	decision := h.risk.Evaluate(ctx, account, txn)
+	if decision == nil || decision.Decision != models.RiskDeny {
+		return decision, nil
+	}
+	if err := h.risk.Record(ctx, decision); err != nil {
+		logging.FromContext(ctx).Error("failed to record risk decision", "decision_id", decision.ID, "error", err)
+	}
+	return nil, &requestError{status: http.StatusUnprocessableEntity, code: CodeTransactionDenied,
+		err: fmt.Errorf("Transaction denied by risk rules (decision %s)", decision.ID)}
+}
+
+// recordDecision logs the risk decision on a recorded transaction under
+// the transaction's ID. The transaction stands if it cannot be logged.
+func (h *TransactionHandler) recordDecision(ctx context.Context, decision *models.RiskDecision, txnID string) {
+	if decision == nil || txnID == "" {
+		return
+	}
+	decision.TransactionID = txnID
+	if err := h.risk.Record(ctx, decision); err != nil {
+		logging.FromContext(ctx).Error("failed to record risk decision", "transaction_id", txnID,
+			"decision", decision.Decision, "error", err)
+	}
+}
+
+// recordCaptureDecision logs the risk decision on a hold capture under its
+// withdrawal's ID. A withdrawal whose delivery was deferred has no ID yet,
+// so its decision is logged without one and cannot hold it for review.
+func (h *TransactionHandler) recordCaptureDecision(ctx context.Context, decision *models.RiskDecision, entry *models.OutboxEntry) {
+	if decision == nil || entry.Transaction.ID != "" {
+		h.recordDecision(ctx, decision, entry.Transaction.ID)
+		return
 	}
+	logging.FromContext(ctx).Warn("risk decision on deferred hold capture not linked to its transaction",
+		"outbox_id", entry.ID, "decision", decision.Decision)
+	if err := h.risk.Record(ctx, decision); err != nil {
+		logging.FromContext(ctx).Error("failed to record risk decision", "outbox_id", entry.ID,
+			"decision", decision.Decision, "error", err)
+	}
+}
+
+// reserveOutflow counts a withdrawal or transfer against the account's
+// daily and monthly limits, failing with a 422 limit_exceeded if it would
+// pass one. Deposits are not counted and return no reservation.
+func (h *TransactionHandler) reserveOutflow(ctx context.Context, account *models.Account, txnType string, amount float64) (*limits.Reservation, error) {
+	if txnType == "deposit" {
+		return nil, nil
+	}
+	reservation, err := h.limits.Reserve(ctx, account, amount, time.Now())
+	if errors.Is(err, products.ErrLimitExceeded) {
+		return nil, &requestError{status: http.StatusUnprocessableEntity, code: CodeLimitExceeded, err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check outflow limits: %w", err)
+	}
+	return reservation, nil
+}
+
+// cancelOutflow takes back the reservation of a transaction that was not
+// recorded.
+func (h *TransactionHandler) cancelOutflow(ctx context.Context, reservation *limits.Reservation) {
+	if err := h.limits.Cancel(ctx, reservation); err != nil {
+		logging.FromContext(ctx).Error("failed to cancel outflow reservation", "account_id", reservation.AccountID,
+			"amount", reservation.Amount, "error", err)
+	}
+}
+
+// releaseOutflow takes a failed withdrawal back off its account's daily and
+// monthly outflow.
+func (h *TransactionHandler) releaseOutflow(ctx context.Context, txn *models.Transaction) {
+	if txn.Type == "deposit" {
+		return
+	}
+	account, err := h.accountRepo.GetByID(ctx, txn.AccountID)
+	if err == nil && account != nil {
+		err = h.limits.Release(ctx, account, txn)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to release outflow of failed transaction", "transaction_id", txn.ID, "error", err)
+	}
+}
+
+// checkProductRules applies the rules of the account's product to a new
+// transaction. It writes the response and returns false if the transaction
+// breaks a rule.
+func (h *TransactionHandler) checkProductRules(w http.ResponseWriter, r *http.Request, account *models.Account, txnType string, amount float64) bool {
+	if err := h.productRules(r.Context(), account, txnType, amount); err != nil {
+		writeRequestError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// productRules checks a new transaction against its amount limit, as
+// limits.Limiter.For sets it for the account, and for anything but a
+// deposit the minimum balance and overdraft limit of the account's product.
+// A broken rule is reported with an error matching products.ErrLimitExceeded
+// or products.ErrInsufficientFunds.
+func (h *TransactionHandler) productRules(ctx context.Context, account *models.Account, txnType string, amount float64) error {
+	product := h.limits.For(account)
+	amount = math.Abs(amount)
+	err := products.CheckAmount(product, amount)
+	if err == nil && txnType != "deposit" {
+		balance, berr := h.balances.AvailableBalance(ctx, account)
+		if berr != nil {
+			status := upstreamErrorStatus(berr)
+			return &requestError{status: status, code: codeForStatus(status), err: fmt.Errorf("failed to read balance: %w", berr)}
+		}
+		err = products.CheckDebit(product, balance, amount)
+	}
+
+	switch {
+	case errors.Is(err, products.ErrLimitExceeded):
+		return &requestError{status: http.StatusUnprocessableEntity, code: CodeLimitExceeded, err: fmt.Errorf("Account %s: %w", account.ID, err)}
+	case errors.Is(err, products.ErrInsufficientFunds):
+		return &requestError{status: http.StatusUnprocessableEntity, code: CodeInsufficientFunds, err: fmt.Errorf("Account %s: %w", account.ID, err)}
+	}
+	return nil
 }
 
 func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract transaction ID from the URL path
-	txnID := r.URL.Path[len("/transactions/"):]
+	txnID := r.PathValue("id")
+
+	// Only the account's owner or an admin may change its transactions
+	if err := h.checkTransactionAccess(r.Context(), txnID); err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	// Completing a transaction applies it to the account's balance, which
+	// only an admin may do
+	status := r.URL.Query().Get("status")
+	if status == "completed" {
+		if !auth.IsAdmin(r.Context()) {
+			WriteError(w, r, http.StatusForbidden, "completing a transaction requires an admin token")
+			return
+		}
+		completed, err := h.complete(r.Context(), txnID)
+		if err != nil {
+			writeRequestError(w, r, err)
+			return
+		}
+		json.NewEncoder(w).Encode(completed)
+		return
+	}
+	// Once applied to the balance a transaction stays completed, as nothing
+	// would take it back off
+	posted, err := h.poster.Posted(r.Context(), txnID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to check transaction posting: %v", err))
+		return
+	}
+	if posted {
+		WriteErrorCode(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("Transaction %s is completed and applied to its account's balance; it cannot be moved to %s", txnID, status))
+		return
+	}
 
 	// Construct target URL safely
 	targetURL, err := url.JoinPath(h.pythonServiceURL, "/transactions", txnID)
 	if err != nil {
-		http.Error(w, "invalid URL path", http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, "invalid URL path")
 		return
 	}
+	// The new status travels in the query string
+	if r.URL.RawQuery != "" {
+		targetURL += "?" + r.URL.RawQuery
+	}
 
 	// Forward the request to the Python service
-	forwardReq, err := http.NewRequest(r.Method, targetURL, r.Body)
+	forwardReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to create forward request: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to create forward request: %v", err))
 		return
 	}
 
-	forwardReq.Header = r.Header
+	forwardReq.Header = forwardHeader(r)
 
 	// Execute the forward request
 	resp, err := h.httpClient.Do(forwardReq)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to forward request to transaction service: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, upstreamErrorStatus(err), fmt.Sprintf("failed to forward request to transaction service: %v", err))
 		return
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 300 {
+		// Read the updated transaction to publish it, then pass it on
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			WriteError(w, r, http.StatusBadGateway, fmt.Sprintf("failed to read transaction service response: %v", err))
+			return
+		}
+		var updated models.Transaction
+		if err := json.Unmarshal(body, &updated); err == nil {
+			h.events.Publish(r.Context(), events.NewTransactionUpdated(&updated))
+			if updated.Status == "failed" {
+				h.releaseOutflow(r.Context(), &updated)
+			}
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	// Copy the response from the Python service back to the client
 	w.WriteHeader(resp.StatusCode)
 	if _, err := io.Copy(w, resp.Body); err != nil {
-		log.Printf("failed to write response: %v", err)
+		logging.FromContext(r.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+// checkTransactionAccess fails with a 404 if the transaction does not exist
+// and a 403 if the caller may not access its account.
+func (h *TransactionHandler) checkTransactionAccess(ctx context.Context, id string) error {
+	txn, err := h.transactions.Get(ctx, id)
+	var serr *upstream.StatusError
+	if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+		return &requestError{status: http.StatusNotFound, code: CodeNotFound, err: errors.New("Transaction not found")}
+	}
+	if err != nil {
+		return upstreamError("failed to read transaction", err)
+	}
+	account, err := h.accountRepo.GetByID(ctx, txn.AccountID)
+	if err != nil {
+		return err
+	}
+	var owner string
+	if account != nil {
+		owner = account.Owner
+	}
+	if !auth.CanAccess(ctx, owner) {
+		return &requestError{status: http.StatusForbidden, code: CodeForbidden, err: errors.New("not allowed to access this account")}
+	}
+	return nil
+}
+
+// complete marks a transaction completed and applies it to its account's
+// balance, once however often it is completed. The caller must have checked
+// that the principal may complete it: an admin may complete any
+// transaction, a customer only a withdrawal they are submitting that the risk
+// rules did not put under review. If the balance cannot be applied the
+// transaction does not stay completed: a withdrawal the account cannot cover
+// is marked failed and fails with an error matching
+// products.ErrInsufficientFunds, and after any other error it is put back to
+// pending to be completed again.
+func (h *TransactionHandler) complete(ctx context.Context, id string) (*models.Transaction, error) {
+	completed, err := h.transactions.UpdateStatus(ctx, id, "completed")
+	var serr *upstream.StatusError
+	if errors.As(err, &serr) && serr.StatusCode == http.StatusNotFound {
+		return nil, &requestError{status: http.StatusNotFound, code: CodeNotFound, err: errors.New("Transaction not found")}
+	}
+	if err != nil {
+		return nil, upstreamError("failed to complete transaction", err)
 	}
+
+	var actor string
+	if p, ok := auth.FromContext(ctx); ok {
+		actor = p.Subject
+	}
+	result, err := h.poster.Post(ctx, completed, actor)
+	if err != nil {
+		status := "pending"
+		if errors.Is(err, products.ErrInsufficientFunds) {
+			status = "failed"
+		}
+		if undone, uerr := h.transactions.UpdateStatus(ctx, id, status); uerr != nil {
+			logging.FromContext(ctx).Error("failed to undo completion of unposted transaction", "transaction_id", id,
+				"status", status, "error", uerr)
+		} else {
+			h.events.Publish(ctx, events.NewTransactionUpdated(undone))
+			if status == "failed" {
+				h.releaseOutflow(ctx, undone)
+			}
+		}
+
+		switch {
+		case errors.Is(err, products.ErrInsufficientFunds):
+			return nil, &requestError{status: http.StatusUnprocessableEntity, code: CodeInsufficientFunds, err: err}
+		case errors.Is(err, posting.ErrAccountNotFound):
+			return nil, &requestError{status: http.StatusConflict, code: CodeConflict, err: err}
+		}
+		return nil, fmt.Errorf("failed to post transaction %s: %w", id, err)
+	}
+
+	h.events.Publish(ctx, events.NewTransactionUpdated(completed))
+	if result != nil {
+		h.events.Publish(ctx, events.NewAccountUpdated(result.Account, []models.AccountChange{result.Change}))
+	}
+	return completed, nil
 }
 
+// HandleGetTransactions lists transactions from the transaction service.
+// Only an admin may list them across accounts; anyone else must filter by
+// an account_id they have access to.
 func (h *TransactionHandler) HandleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Construct target URL safely
+	if !auth.IsAdmin(r.Context()) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			WriteError(w, r, http.StatusForbidden, "account_id is required to list transactions")
+			return
+		}
+		account, err := h.accountRepo.GetByID(r.Context(), accountID)
+		if err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if account == nil {
+			WriteError(w, r, http.StatusNotFound, "Account not found")
+			return
+		}
+		if !auth.CanAccess(r.Context(), account.Owner) {
+			WriteError(w, r, http.StatusForbidden, "not allowed to access this account")
+			return
+		}
+	}
+
+	// Construct target URL safely, keeping the account_id/limit/offset filters
 	targetURL, err := url.JoinPath(h.pythonServiceURL, "/transactions")
 	if err != nil {
-		http.Error(w, "invalid URL path", http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, "invalid URL path")
 		return
 	}
+	if r.URL.RawQuery != "" {
+		targetURL += "?" + r.URL.RawQuery
+	}
 
 	// Forward the request to the Python service
-	forwardReq, err := http.NewRequest(r.Method, targetURL, r.Body)
+	forwardReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to create forward request: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to create forward request: %v", err))
 		return
 	}
 
-	forwardReq.Header = r.Header
+	forwardReq.Header = forwardHeader(r)
 
 	// Execute the forward request
 	resp, err := h.httpClient.Do(forwardReq)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to forward request to transaction service: %v", err), http.StatusInternalServerError)
+		WriteError(w, r, upstreamErrorStatus(err), fmt.Sprintf("failed to forward request to transaction service: %v", err))
 		return
 	}
 	defer resp.Body.Close()
@@ -163,7 +567,7 @@ func (h *TransactionHandler) HandleGetTransactions(w http.ResponseWriter, r *htt
 	// Copy the response from the Python service back to the client
 	w.WriteHeader(resp.StatusCode)
 	if _, err := io.Copy(w, resp.Body); err != nil {
-		log.Printf("failed to write response: %v", err)
+		logging.FromContext(r.Context()).Error("failed to write response", "error", err)
 	}
 }
 
@@ -371,4 +775,121 @@ func (h *TransactionHandler) HandleGetTransactions(w http.ResponseWriter, r *htt
 //     // Copy the response from the Python service back to the client
 //     w.WriteHeader(resp.StatusCode)
 //     io.Copy(w, resp.Body)
-// }
\ No newline at end of file
+// }
+
+// HandleTransfers records a transfer between two existing accounts. If the
+// deposit cannot be recorded the withdrawal is marked failed, so a transfer
+// never leaves money debited without the matching credit.
+func (h *TransactionHandler) HandleTransfers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var transfer models.Transfer
+	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Transfer(r.Context(), &transfer); err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// Transfer records a transfer as a pending withdrawal from the source
+// account and a pending deposit to the destination, and sets them on
+// transfer. The caller must have access to the source account, and both
+// accounts must be active and keep to their products' rules;
+// a broken rule fails with an error matching products.ErrInsufficientFunds
+// or products.ErrLimitExceeded. Scheduled transfers are executed through it
+// too.
+func (h *TransactionHandler) Transfer(ctx context.Context, transfer *models.Transfer) error {
+	if transfer.Amount <= 0 {
+		return &requestError{status: http.StatusBadRequest, code: CodeBadRequest, err: errors.New("amount must be positive")}
+	}
+	if transfer.FromAccountID == transfer.ToAccountID {
+		return &requestError{status: http.StatusBadRequest, code: CodeBadRequest, err: errors.New("cannot transfer to the same account")}
+	}
+
+	accounts := make([]*models.Account, 2)
+	for i, id := range []string{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := h.accountRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return &requestError{status: http.StatusBadRequest, code: CodeBadRequest, err: fmt.Errorf("Account not found: %s", id)}
+		}
+		// Money may be sent to anyone's account but only taken from the
+		// caller's own
+		if i == 0 && !auth.CanAccess(ctx, account.Owner) {
+			return &requestError{status: http.StatusForbidden, code: CodeForbidden, err: errors.New("not allowed to access this account")}
+		}
+		if !account.IsActive() {
+			return &requestError{status: http.StatusConflict, code: CodeAccountInactive, err: fmt.Errorf("Account %s is %s", id, account.Status)}
+		}
+		if err := kycBlocked(account); err != nil {
+			return err
+		}
+		accounts[i] = account
+	}
+	if err := h.productRules(ctx, accounts[0], "withdrawal", transfer.Amount); err != nil {
+		return err
+	}
+	if err := h.productRules(ctx, accounts[1], "deposit", transfer.Amount); err != nil {
+		return err
+	}
+	decision, err := h.screen(ctx, accounts[0], &models.Transaction{
+		AccountID: transfer.FromAccountID,
+		Amount:    transfer.Amount,
+		Type:      "withdrawal",
+	})
+	if err != nil {
+		return err
+	}
+	reservation, err := h.reserveOutflow(ctx, accounts[0], "withdrawal", transfer.Amount)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.FromContext(ctx)
+
+	withdrawal, err := h.transactions.Create(ctx, models.Transaction{
+		AccountID:   transfer.FromAccountID,
+		Amount:      transfer.Amount,
+		Type:        "withdrawal",
+		Description: transfer.Description,
+		Status:      "pending",
+	})
+	if err != nil {
+		h.cancelOutflow(ctx, reservation)
+		return upstreamError("failed to record withdrawal", err)
+	}
+
+	deposit, err := h.transactions.Create(ctx, models.Transaction{
+		AccountID:   transfer.ToAccountID,
+		Amount:      transfer.Amount,
+		Type:        "deposit",
+		Description: transfer.Description,
+		Status:      "pending",
+	})
+	if err != nil {
+		if _, cerr := h.transactions.UpdateStatus(ctx, withdrawal.ID, "failed"); cerr != nil {
+			logger.Error("failed to cancel transfer withdrawal", "transaction_id", withdrawal.ID, "error", cerr)
+		} else {
+			h.cancelOutflow(ctx, reservation)
+		}
+		return upstreamError("failed to record deposit", err)
+	}
+
+	h.recordDecision(ctx, decision, withdrawal.ID)
+	logger.Info("transfer recorded", "from_account_id", transfer.FromAccountID, "to_account_id", transfer.ToAccountID,
+		"withdrawal_id", withdrawal.ID, "deposit_id", deposit.ID)
+
+	transfer.Withdrawal = withdrawal
+	transfer.Deposit = deposit
+	h.events.Publish(ctx, events.NewTransactionSubmitted(withdrawal))
+	h.events.Publish(ctx, events.NewTransactionSubmitted(deposit))
+	return nil
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/limits"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/risk"
+)
+
+// fakeTransactionService records transactions as the transaction service
+// does: every new one pending.
+type fakeTransactionService struct {
+	mu   sync.Mutex
+	txns map[string]models.Transaction
+	// header is that of the last request
+	header http.Header
+}
+
+func (f *fakeTransactionService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.header = r.Header.Clone()
+	w.Header().Set("Content-Type", "application/json")
+
+	id := strings.TrimPrefix(r.URL.Path, "/transactions/")
+	txn, ok := f.txns[id]
+	switch {
+	case r.URL.Path == "/transactions" && r.Method == http.MethodPost:
+		json.NewDecoder(r.Body).Decode(&txn)
+		txn.ID, txn.Status, txn.CreatedAt = uuid.NewString(), "pending", time.Now()
+		f.txns[txn.ID] = txn
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(txn)
+	case r.URL.Path == "/transactions":
+		list := []models.Transaction{}
+		for _, txn := range f.txns {
+			if txn.AccountID == r.URL.Query().Get("account_id") {
+				list = append(list, txn)
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "Transaction not found"})
+	case r.Method == http.MethodPut:
+		now := time.Now()
+		txn.Status, txn.ProcessedAt = r.URL.Query().Get("status"), &now
+		f.txns[id] = txn
+		json.NewEncoder(w).Encode(txn)
+	default:
+		json.NewEncoder(w).Encode(txn)
+	}
+}
+
+func (f *fakeTransactionService) add(txn models.Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txns[txn.ID] = txn
+}
+
+func (f *fakeTransactionService) get(id string) models.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.txns[id]
+}
+
+func (f *fakeTransactionService) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.txns)
+}
+
+// storedBalances reports an account's stored balance as its available
+// balance.
+type storedBalances struct{}
+
+func (storedBalances) AvailableBalance(_ context.Context, account *models.Account) (float64, error) {
+	return account.Balance, nil
+}
+
+func (storedBalances) Held(context.Context, string) (float64, error) {
+	return 0, nil
+}
+
+// unlimited counts no outflow, so no limit is reached.
+type unlimited struct{}
+
+func (unlimited) Reserve(context.Context, float64, []repository.CounterLimit) error { return nil }
+
+func (unlimited) Release(context.Context, string, float64, []string) error { return nil }
+
+func (unlimited) Used(context.Context, []string) (map[string]float64, error) { return nil, nil }
+
+var (
+	admin    = auth.Principal{Subject: "ops", Role: config.RoleAdmin}
+	customer = auth.Principal{Subject: "dana", Role: config.RoleUser}
+)
+
+// newTestTransactionHandler returns a handler for the accounts over a fake
+// transaction service, without risk rules or outflow limits.
+func newTestTransactionHandler(t *testing.T, accounts *memAccounts) (*TransactionHandler, *fakeTransactionService) {
+	t.Helper()
+	txns := &fakeTransactionService{txns: make(map[string]models.Transaction)}
+	server := httptest.NewServer(txns)
+	t.Cleanup(server.Close)
+
+	catalog := products.NewCatalog([]config.ProductConfig{{AccountType: config.DefaultAccountType}})
+	poster := posting.NewPoster(accounts, storedBalances{}, catalog)
+	limiter := limits.NewLimiter(unlimited{}, catalog, config.KYCConfig{}, time.UTC)
+	h := NewTransactionHandler(accounts, server.URL, server.Client(), storedBalances{}, poster, risk.NewEngine(nil, nil), limiter,
+		events.NewBus())
+	return h, txns
+}
+
+// as returns a request made by p.
+func as(p auth.Principal, method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	return r.WithContext(auth.WithPrincipal(r.Context(), p))
+}
+
+// Completing a transaction credits or debits the stored balance, so
+// customers may only complete withdrawals they submit.
+func TestCompletingRequiresAdmin(t *testing.T) {
+	accounts := newMemAccounts(models.Account{ID: "acc", Owner: "dana", Balance: 100, KYCStatus: models.KYCVerified})
+	h, txns := newTestTransactionHandler(t, accounts)
+	submit := func(p auth.Principal, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleTransactions(w, as(p, http.MethodPost, "/transactions", body))
+		return w
+	}
+	update := func(p auth.Principal, id string) *httptest.ResponseRecorder {
+		r := as(p, http.MethodPut, "/transactions/"+id+"?status=completed", "")
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.HandleTransactionByID(w, r)
+		return w
+	}
+
+	w := submit(customer, `{"account_id":"acc","type":"deposit","amount":500,"status":"completed"}`)
+	if w.Code != http.StatusForbidden || txns.count() != 0 {
+		t.Fatalf("customer submitting a completed deposit: %d %s, want 403 and nothing recorded", w.Code, w.Body)
+	}
+
+	txns.add(models.Transaction{ID: "dep-1", AccountID: "acc", Type: "deposit", Amount: 500, Status: "pending"})
+	if w := update(customer, "dep-1"); w.Code != http.StatusForbidden {
+		t.Fatalf("customer completing a deposit: %d %s, want 403", w.Code, w.Body)
+	}
+	if status := txns.get("dep-1").Status; status != "pending" || accounts.balance("acc") != 100 {
+		t.Fatalf("deposit %s with balance %v, want it pending and the balance untouched", status, accounts.balance("acc"))
+	}
+
+	// Customers' own withdrawals may be submitted completed
+	w = submit(customer, `{"account_id":"acc","type":"withdrawal","amount":30,"status":"completed"}`)
+	if w.Code != http.StatusCreated || accounts.balance("acc") != 70 {
+		t.Fatalf("customer submitting a completed withdrawal: %d %s with balance %v", w.Code, w.Body, accounts.balance("acc"))
+	}
+
+	if w := update(admin, "dep-1"); w.Code != http.StatusOK || accounts.balance("acc") != 570 {
+		t.Fatalf("admin completing a deposit: %d %s with balance %v", w.Code, w.Body, accounts.balance("acc"))
+	}
+	w = submit(admin, `{"account_id":"acc","type":"deposit","amount":30,"status":"completed"}`)
+	if w.Code != http.StatusCreated || accounts.balance("acc") != 600 {
+		t.Fatalf("admin submitting a completed deposit: %d %s with balance %v", w.Code, w.Body, accounts.balance("acc"))
+	}
+}
+
+// The caller's token is for this API only and is not passed on.
+func TestForwardedHeaders(t *testing.T) {
+	accounts := newMemAccounts(models.Account{ID: "acc", Owner: "dana", Balance: 100, KYCStatus: models.KYCVerified})
+	h, txns := newTestTransactionHandler(t, accounts)
+	txns.add(models.Transaction{ID: "txn-1", AccountID: "acc", Type: "withdrawal", Amount: 5, Status: "pending"})
+
+	requests := []struct {
+		r    *http.Request
+		call func(http.ResponseWriter, *http.Request)
+	}{
+		{as(customer, http.MethodPost, "/transactions", `{"account_id":"acc","type":"withdrawal","amount":5}`), h.HandleTransactions},
+		{as(customer, http.MethodPut, "/transactions/txn-1?status=failed", ""), h.HandleTransactionByID},
+		{as(customer, http.MethodGet, "/transactions?account_id=acc", ""), h.HandleGetTransactions},
+	}
+	for _, req := range requests {
+		req.r.SetPathValue("id", "txn-1")
+		req.r.Header.Set("Authorization", "Bearer secret-token")
+		req.r.Header.Set("Content-Type", "application/json")
+		req.r.Header.Set(logging.RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		req.call(w, req.r)
+		if w.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", req.r.Method, req.r.URL, w.Code, w.Body)
+		}
+		txns.mu.Lock()
+		header := txns.header
+		txns.mu.Unlock()
+		if header.Get("Authorization") != "" || header.Get(logging.RequestIDHeader) != "req-1" || header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s forwarded %v", req.r.Method, req.r.URL, header)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/webhooks"
+)
+
+// minSecretLength is the shortest signing secret a subscriber may choose.
+const minSecretLength = 16
+
+// WebhookHandler manages webhook subscriptions. Subscriptions receive events
+// for every account, so all of its routes require an admin token.
+type WebhookHandler struct {
+	store      webhooks.Store
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhookHandler(store webhooks.Store, dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{store: store, dispatcher: dispatcher}
+}
+
+type createWebhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+}
+
+// HandleCreateWebhook serves POST /webhooks. The response is the only one
+// that includes the signing secret, generated unless one is given.
+func (h *WebhookHandler) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if problems := validateWebhook(&req); len(problems) > 0 {
+		WriteError(w, r, http.StatusBadRequest, strings.Join(problems, "; "))
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhooks.NewSecret(); err != nil {
+			WriteError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	now := time.Now().UTC()
+	subscription := &models.WebhookSubscription{
+		ID:          uuid.New().String(),
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      secret,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		subscription.CreatedBy = p.Subject
+	}
+	if err := h.store.CreateSubscription(r.Context(), subscription); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("webhook subscribed", "subscription_id", subscription.ID, "event_types", subscription.EventTypes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+func validateWebhook(req *createWebhookRequest) []string {
+	var problems []string
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, "url must be an absolute http or https URL")
+	}
+	if len(req.EventTypes) == 0 {
+		problems = append(problems, "event_types is required")
+	}
+	for _, t := range req.EventTypes {
+		if t != "*" && !slices.Contains(models.EventTypes, t) {
+			problems = append(problems, fmt.Sprintf("event type %q is not one of %s or *", t, strings.Join(models.EventTypes, ", ")))
+		}
+	}
+	if req.Secret != "" && len(req.Secret) < minSecretLength {
+		problems = append(problems, fmt.Sprintf("secret must be at least %d characters", minSecretLength))
+	}
+	return problems
+}
+
+// HandleListWebhooks serves GET /webhooks, every subscription oldest first.
+func (h *WebhookHandler) HandleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if !h.admin(w, r) {
+		return
+	}
+	list, err := h.store.ListSubscriptions(r.Context())
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []models.WebhookSubscription{}
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleGetWebhook serves GET /webhooks/{id}.
+func (h *WebhookHandler) HandleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.subscription(w, r)
+	if !ok {
+		return
+	}
+	subscription.Secret = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// HandleDeleteWebhook serves DELETE /webhooks/{id}. Pending deliveries to
+// the subscription fail on their next attempt; its delivery log is kept.
+func (h *WebhookHandler) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.subscription(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteSubscription(r.Context(), subscription.ID); err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("webhook unsubscribed", "subscription_id", subscription.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDeliveries serves GET /webhooks/{id}/deliveries, the subscription's
+// delivery log, newest first.
+func (h *WebhookHandler) HandleDeliveries(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.subscription(w, r)
+	if !ok {
+		return
+	}
+	deliveries, err := h.store.Deliveries(r.Context(), subscription.ID)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// HandleRedeliver serves POST /webhooks/{id}/deliveries/{delivery_id}/redeliver.
+// The event is sent again as a new delivery, whatever the outcome of the
+// original, on the dispatcher's next run.
+func (h *WebhookHandler) HandleRedeliver(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.subscription(w, r)
+	if !ok {
+		return
+	}
+	original, err := h.store.GetDelivery(r.Context(), r.PathValue("delivery_id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if original == nil || original.SubscriptionID != subscription.ID {
+		WriteError(w, r, http.StatusNotFound, "Delivery not found")
+		return
+	}
+
+	delivery, err := h.dispatcher.Redeliver(r.Context(), subscription, original)
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logging.FromContext(r.Context()).Info("webhook redelivery queued", "subscription_id", subscription.ID,
+		"delivery_id", delivery.ID, "redelivery_of", original.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func (h *WebhookHandler) admin(w http.ResponseWriter, r *http.Request) bool {
+	if !auth.IsAdmin(r.Context()) {
+		WriteError(w, r, http.StatusForbidden, "webhooks require an admin token")
+		return false
+	}
+	return true
+}
+
+// subscription loads the subscription named in the path, writing the error
+// response itself when the caller is not an admin or it does not exist.
+func (h *WebhookHandler) subscription(w http.ResponseWriter, r *http.Request) (*models.WebhookSubscription, bool) {
+	if !h.admin(w, r) {
+		return nil, false
+	}
+	subscription, err := h.store.GetSubscription(r.Context(), r.PathValue("id"))
+	if err != nil {
+		WriteError(w, r, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if subscription == nil {
+		WriteError(w, r, http.StatusNotFound, "Webhook not found")
+		return nil, false
+	}
+	return subscription, true
+}
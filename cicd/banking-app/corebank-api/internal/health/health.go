@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check probes a single dependency and returns an error if it is unhealthy.
+type Check func(ctx context.Context) error
+
+// Status values reported in the readiness body.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Result is the outcome of the most recent run of a check.
+type Result struct {
+	Status    string    `json:"status"`
+	LatencyMS int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the readiness response body.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+var errShuttingDown = errors.New("server is shutting down")
+
+type entry struct {
+	name  string
+	check Check
+
+	mu     sync.Mutex
+	result Result
+	ran    bool
+}
+
+// Checker runs dependency checks for the readiness probe. Each check runs with
+// a timeout and its result is cached for a short TTL, so frequent probes
+// don't hammer DynamoDB or the transaction service.
+type Checker struct {
+	timeout  time.Duration
+	ttl      time.Duration
+	entries  []*entry
+	draining atomic.Bool
+}
+
+// NewChecker returns a Checker with no dependencies registered.
+func NewChecker(timeout, ttl time.Duration) *Checker {
+	return &Checker{timeout: timeout, ttl: ttl}
+}
+
+// Register adds a named dependency check. It must be called before the
+// handlers start serving.
+func (c *Checker) Register(name string, check Check) {
+	c.entries = append(c.entries, &entry{name: name, check: check})
+}
+
+// SetDraining makes readiness fail regardless of dependency health, so load
+// balancers stop routing new traffic before the server shuts down.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Ready runs every registered check, reusing cached results that are still
+// fresh, and reports overall readiness.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(c.entries))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range c.entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			result := c.run(ctx, e)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[e.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(e)
+	}
+	wg.Wait()
+
+	if c.draining.Load() {
+		report.Status = StatusDown
+		report.Checks["shutdown"] = Result{
+			Status:    StatusDown,
+			Error:     errShuttingDown.Error(),
+			CheckedAt: time.Now().UTC(),
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ran && time.Since(e.result.CheckedAt) < c.ttl {
+		return e.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := e.check(ctx)
+	result := Result{
+		Status:    StatusUp,
+		LatencyMS: time.Since(start).Milliseconds(),
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	e.result = result
+	e.ran = true
+	return result
+}
+
+// LivenessHandler reports that the process is up. It never checks
+// dependencies, so an outage downstream doesn't get the pod restarted.
+func (c *Checker) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": StatusUp})
+}
+
+// ReadinessHandler reports per-dependency status and latency, with 503 when
+// any dependency is down or the server is draining.
+func (c *Checker) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := c.Ready(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != StatusUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
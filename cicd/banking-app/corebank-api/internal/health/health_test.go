@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counted returns a check failing with err, or passing if err is nil, and
+// the number of times it ran.
+func counted(err error) (Check, *atomic.Int32) {
+	var runs atomic.Int32
+	return func(context.Context) error {
+		runs.Add(1)
+		return err
+	}, &runs
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks map[string]error
+		drain  bool
+		want   string
+		// results is how many checks the report lists
+		results int
+		down    []string
+	}{
+		{name: "no dependencies", want: StatusUp},
+		{name: "all up", checks: map[string]error{"dynamodb": nil, "transactions": nil}, want: StatusUp, results: 2},
+		{
+			name:   "one down",
+			checks: map[string]error{"dynamodb": nil, "transactions": errors.New("connection refused")},
+			want:   StatusDown, results: 2, down: []string{"transactions"},
+		},
+		{
+			name:   "draining",
+			checks: map[string]error{"dynamodb": nil},
+			drain:  true, want: StatusDown, results: 2, down: []string{"shutdown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(time.Second, time.Minute)
+			for name, err := range tt.checks {
+				check, _ := counted(err)
+				c.Register(name, check)
+			}
+			if tt.drain {
+				c.SetDraining()
+			}
+
+			rec := httptest.NewRecorder()
+			c.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			var report Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			wantCode := http.StatusOK
+			if tt.want != StatusUp {
+				wantCode = http.StatusServiceUnavailable
+			}
+			if report.Status != tt.want || rec.Code != wantCode {
+				t.Fatalf("got %s with %d, want %s with %d", report.Status, rec.Code, tt.want, wantCode)
+			}
+			if len(report.Checks) != tt.results {
+				t.Fatalf("checks = %+v", report.Checks)
+			}
+			for _, name := range tt.down {
+				if result := report.Checks[name]; result.Status != StatusDown || result.Error == "" {
+					t.Errorf("%s = %+v, want down with an error", name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyCachesResults(t *testing.T) {
+	check, runs := counted(nil)
+	c := NewChecker(time.Second, time.Hour)
+	c.Register("dynamodb", check)
+	for range 3 {
+		c.Ready(context.Background())
+	}
+	if runs.Load() != 1 {
+		t.Fatalf("check ran %d times within its TTL, want 1", runs.Load())
+	}
+
+	c = NewChecker(time.Second, 0)
+	check, runs = counted(nil)
+	c.Register("dynamodb", check)
+	for range 3 {
+		c.Ready(context.Background())
+	}
+	if runs.Load() != 3 {
+		t.Fatalf("check ran %d times without a TTL, want 3", runs.Load())
+	}
+}
+
+func TestReadyTimesOutChecks(t *testing.T) {
+	c := NewChecker(10*time.Millisecond, time.Minute)
+	c.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	report := c.Ready(context.Background())
+	if report.Status != StatusDown || report.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("got %+v, want the slow check timed out", report)
+	}
+}
+
+// Liveness never runs the checks.
+func TestLiveness(t *testing.T) {
+	check, runs := counted(errors.New("down"))
+	c := NewChecker(time.Second, time.Minute)
+	c.Register("dynamodb", check)
+	c.SetDraining()
+
+	rec := httptest.NewRecorder()
+	c.LivenessHandler(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK || runs.Load() != 0 {
+		t.Fatalf("got %d after %d checks, want 200 without checking", rec.Code, runs.Load())
+	}
+}
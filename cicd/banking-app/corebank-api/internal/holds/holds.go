@@ -0,0 +1,102 @@
+// Package holds reserves funds on accounts ahead of the transactions that
+// will spend them, and works out the balance left available around them.
+package holds
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// Store persists holds; repository.HoldRepository implements it against
+// DynamoDB.
+type Store interface {
+	Create(ctx context.Context, hold *models.Hold) error
+	// Get returns nil and no error when the hold does not exist.
+	Get(ctx context.Context, id string) (*models.Hold, error)
+	// Update replaces a hold read with the given updated_at, failing with
+	// repository.ErrConflict if it has changed since.
+	Update(ctx context.Context, hold *models.Hold, prevUpdatedAt time.Time) error
+	// ListByAccount returns an account's holds with the given status, or all
+	// of them when status is empty, newest first.
+	ListByAccount(ctx context.Context, accountID, status string) ([]models.Hold, error)
+	// ListExpired returns active holds whose expiry is not after now.
+	ListExpired(ctx context.Context, now time.Time) ([]models.Hold, error)
+}
+
+// Ledger reports an account's pending transactions; statements.Generator
+// implements it.
+type Ledger interface {
+	// Pending returns the total of the account's pending deposits and of
+	// its pending withdrawals and transfers, both positive.
+	Pending(ctx context.Context, accountID string) (credits, debits float64, err error)
+}
+
+// Balances combines the balance stored on an account, which completed
+// transactions are posted to, with its pending transactions and active
+// holds.
+type Balances struct {
+	ledger Ledger
+	store  Store
+}
+
+func NewBalances(ledger Ledger, store Store) *Balances {
+	return &Balances{ledger: ledger, store: store}
+}
+
+// Get returns the account's balance as of now. The ledger balance is the
+// one stored on account, the same posting.Poster checks withdrawals
+// against. Pending debits are deducted from the available balance but
+// pending credits are not added, as they may yet fail. Holds past their
+// expiry no longer reserve anything, even before the sweeper has expired
+// them.
+func (b *Balances) Get(ctx context.Context, account *models.Account) (*models.Balance, error) {
+	credits, debits, err := b.ledger.Pending(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := b.Held(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Balance{
+		AccountID:        account.ID,
+		LedgerBalance:    account.Balance,
+		Pending:          round(credits - debits),
+		PendingDebits:    debits,
+		Held:             held,
+		AvailableBalance: round(account.Balance - debits - held),
+	}, nil
+}
+
+// Held is what the account's active holds reserve as of now.
+func (b *Balances) Held(ctx context.Context, accountID string) (float64, error) {
+	active, err := b.store.ListByAccount(ctx, accountID, models.HoldActive)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	held := 0.0
+	for _, hold := range active {
+		if hold.ExpiresAt.After(now) {
+			held = round(held + hold.Remaining())
+		}
+	}
+	return held, nil
+}
+
+// AvailableBalance is what the account can spend: its balance once pending
+// withdrawals and transfers settle, less what active holds reserve.
+func (b *Balances) AvailableBalance(ctx context.Context, account *models.Account) (float64, error) {
+	balance, err := b.Get(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	return balance.AvailableBalance, nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,101 @@
+package holds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+type fakeLedger struct{ credits, debits float64 }
+
+func (l fakeLedger) Pending(context.Context, string) (float64, float64, error) {
+	return l.credits, l.debits, nil
+}
+
+// fakeStore serves ListByAccount from a fixed list; Balances uses nothing
+// else.
+type fakeStore struct {
+	Store
+	holds []models.Hold
+}
+
+func (s fakeStore) ListByAccount(_ context.Context, accountID, status string) ([]models.Hold, error) {
+	var list []models.Hold
+	for _, hold := range s.holds {
+		if hold.AccountID == accountID && (status == "" || hold.Status == status) {
+			list = append(list, hold)
+		}
+	}
+	return list, nil
+}
+
+func TestBalancesGet(t *testing.T) {
+	now := time.Now()
+	active := func(amount, captured float64, expiresAt time.Time) models.Hold {
+		return models.Hold{AccountID: "acc", Amount: amount, Captured: captured, Status: models.HoldActive, ExpiresAt: expiresAt}
+	}
+	tests := []struct {
+		name            string
+		balance         float64
+		credits, debits float64
+		holds           []models.Hold
+		want            models.Balance
+	}{
+		{
+			name:    "stored balance only",
+			balance: 100,
+			want:    models.Balance{LedgerBalance: 100, AvailableBalance: 100},
+		},
+		{
+			name:    "pending deposits are not spendable",
+			balance: 100, credits: 500,
+			want: models.Balance{LedgerBalance: 100, Pending: 500, AvailableBalance: 100},
+		},
+		{
+			name:    "pending debits are deducted",
+			balance: 100, credits: 500, debits: 70.25,
+			want: models.Balance{LedgerBalance: 100, Pending: 429.75, PendingDebits: 70.25, AvailableBalance: 29.75},
+		},
+		{
+			name:    "active holds reserve what remains of them",
+			balance: 100, debits: 10,
+			holds: []models.Hold{active(50, 20, now.Add(time.Hour)), active(5, 0, now.Add(time.Minute))},
+			want:  models.Balance{LedgerBalance: 100, Pending: -10, PendingDebits: 10, Held: 35, AvailableBalance: 55},
+		},
+		{
+			name:    "expired holds reserve nothing before the sweeper runs",
+			balance: 100,
+			holds:   []models.Hold{active(50, 0, now.Add(-time.Second))},
+			want:    models.Balance{LedgerBalance: 100, AvailableBalance: 100},
+		},
+		{
+			name:    "released and captured holds reserve nothing",
+			balance: 100,
+			holds: []models.Hold{
+				{AccountID: "acc", Amount: 50, Status: models.HoldReleased, ExpiresAt: now.Add(time.Hour)},
+				{AccountID: "acc", Amount: 50, Captured: 50, Status: models.HoldCaptured, ExpiresAt: now.Add(time.Hour)},
+			},
+			want: models.Balance{LedgerBalance: 100, AvailableBalance: 100},
+		},
+		{
+			name:    "the available balance may be negative",
+			balance: -20, debits: 30,
+			want: models.Balance{LedgerBalance: -20, Pending: -30, PendingDebits: 30, AvailableBalance: -50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBalances(fakeLedger{credits: tt.credits, debits: tt.debits}, fakeStore{holds: tt.holds})
+			got, err := b.Get(context.Background(), &models.Account{ID: "acc", Balance: tt.balance})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.AccountID = "acc"
+			if *got != tt.want {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package holds
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+// Sweeper expires active holds once they pass their expiry, releasing what
+// they reserve. A hold captured or released while the sweeper runs keeps
+// that outcome.
+type Sweeper struct {
+	store    Store
+	interval time.Duration
+}
+
+func NewSweeper(store Store, interval time.Duration) *Sweeper {
+	return &Sweeper{store: store, interval: interval}
+}
+
+// Run expires stale holds now and then every interval until ctx is
+// cancelled. It has the signature of a server.Worker.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("hold sweep incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce expires every active hold whose expiry is not after now and
+// returns how many it expired. Holds that fail are retried on the next run.
+func (s *Sweeper) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	stale, err := s.store.ListExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	expired := 0
+	for i := range stale {
+		hold := &stale[i]
+		prev := hold.UpdatedAt
+		hold.Status = models.HoldExpired
+		hold.UpdatedAt = now.UTC()
+		err := s.store.Update(ctx, hold, prev)
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			// Captured or released since it was listed
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			expired++
+			slog.Info("hold expired", "hold_id", hold.ID, "account_id", hold.AccountID, "amount", hold.Amount-hold.Captured)
+		}
+	}
+	return expired, errors.Join(errs...)
+}
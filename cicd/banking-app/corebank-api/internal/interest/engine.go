@@ -0,0 +1,434 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// DateLayout is the format of an accrual date.
+const DateLayout = "2006-01-02"
+
+// lookback is how many days back each run accrues days that are missing, so
+// an engine that was down for a few days catches up without a replay.
+const lookback = 7
+
+// Store persists accruals and postings. repository.InterestRepository
+// implements it against DynamoDB.
+type Store interface {
+	// PutAccrual stores an accrual and reports whether it did. Unless
+	// replace is set, an accrual already stored for the day is kept.
+	PutAccrual(ctx context.Context, accrual *models.InterestAccrual, replace bool) (bool, error)
+	// Accruals returns the account's accruals for dates, oldest first.
+	Accruals(ctx context.Context, accountID string, dates []string) ([]models.InterestAccrual, error)
+	// CreatePosting stores a posting unless the period already has one, and
+	// reports whether it did.
+	CreatePosting(ctx context.Context, posting *models.InterestPosting) (bool, error)
+	UpdatePosting(ctx context.Context, posting *models.InterestPosting) error
+	DeletePosting(ctx context.Context, accountID, period string) error
+	// GetPosting returns nil and no error when the period has not been paid.
+	GetPosting(ctx context.Context, accountID, period string) (*models.InterestPosting, error)
+}
+
+// AccountLister lists the accounts interest is accrued on.
+type AccountLister interface {
+	ListAll(ctx context.Context) ([]models.Account, error)
+}
+
+// Balances reports closing balances per day. statements.Generator
+// implements it, so interest is earned on the balances statements show.
+type Balances interface {
+	DailyBalances(ctx context.Context, account *models.Account, from time.Time, days int) ([]float64, error)
+	Location() *time.Location
+}
+
+// Submitter posts transactions; outbox.Outbox implements it, completing the
+// entries marked Complete.
+type Submitter interface {
+	Submit(ctx context.Context, entry *models.OutboxEntry) error
+}
+
+// Engine accrues and pays interest. It is safe to run on several instances:
+// a day is accrued and a period paid only once.
+type Engine struct {
+	products map[string]Product
+	accounts AccountLister
+	store    Store
+	balances Balances
+	outbox   Submitter
+	interval time.Duration
+
+	// done is the last day accrued for every account, so later ticks on the
+	// same day do nothing
+	done string
+}
+
+func NewEngine(products map[string]Product, accounts AccountLister, store Store, balances Balances, outbox Submitter, interval time.Duration) *Engine {
+	return &Engine{
+		products: products,
+		accounts: accounts,
+		store:    store,
+		balances: balances,
+		outbox:   outbox,
+		interval: interval,
+	}
+}
+
+// Location returns the zone days are taken in.
+func (e *Engine) Location() *time.Location {
+	return e.balances.Location()
+}
+
+// ReplayResult is what replaying a date range did to one account.
+type ReplayResult struct {
+	AccountID string `json:"account_id"`
+	Days      int    `json:"days"`
+	// Paid is the interest posted for periods in the range that had ended:
+	// their first payment, or a correction to an earlier one. It is
+	// negative when the correction reclaimed interest.
+	Paid float64 `json:"paid"`
+}
+
+// Run accrues interest now and then every interval until ctx is cancelled.
+// It has the signature of a server.Worker.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("interest accrual incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce accrues every day up to the one before now that is missing from
+// the last week, then pays each account the interest for its last period if
+// that has ended and is unpaid. Accounts that fail are logged and retried on
+// the next run.
+func (e *Engine) RunOnce(ctx context.Context, now time.Time) error {
+	today := e.day(now)
+	last := today.AddDate(0, 0, -1)
+	date := last.Format(DateLayout)
+	if date == e.done || len(e.products) == 0 {
+		return nil
+	}
+
+	accounts, err := e.accounts.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var accrued, paid, failed int
+	for i := range accounts {
+		account := &accounts[i]
+		product, ok := e.products[account.AccountType]
+		if !ok {
+			continue
+		}
+
+		from := latest(last.AddDate(0, 0, 1-lookback), e.day(account.CreatedAt))
+		days, err := e.accrue(ctx, account, product, from, last, false)
+		var amount float64
+		if err == nil {
+			amount, err = e.settle(ctx, account, product, product.PeriodStart(today).AddDate(0, 0, -1), false)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			slog.Warn("failed to accrue interest", "account_id", account.ID, "date", date, "error", err)
+			continue
+		}
+		accrued += days
+		if amount != 0 {
+			paid++
+		}
+	}
+
+	slog.Info("interest accrued", "date", date, "days", accrued, "paid", paid, "failed", failed)
+	if failed == 0 {
+		e.done = date
+	}
+	return nil
+}
+
+// Replay recomputes and replaces the accruals for every day in [from, to],
+// for one account or every account when accountID is empty. Periods in the
+// range that have ended are paid if they were not, or corrected by the
+// difference if they were. Unlike RunOnce it stops at the first failure.
+func (e *Engine) Replay(ctx context.Context, accountID string, from, to time.Time) ([]ReplayResult, error) {
+	today := e.day(time.Now())
+	from, to = e.day(from), e.day(to)
+	if to.Before(from) {
+		return nil, errors.New("replay range ends before it starts")
+	}
+	if !to.Before(today) {
+		return nil, errors.New("only days that have ended can be replayed")
+	}
+
+	accounts, err := e.accounts.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ReplayResult
+	for i := range accounts {
+		account := &accounts[i]
+		product, ok := e.products[account.AccountType]
+		if !ok || (accountID != "" && account.ID != accountID) {
+			continue
+		}
+
+		start := latest(from, e.day(account.CreatedAt))
+		if start.After(to) {
+			continue
+		}
+		result := ReplayResult{AccountID: account.ID}
+		if result.Days, err = e.accrue(ctx, account, product, start, to, true); err != nil {
+			return results, fmt.Errorf("account %s: %w", account.ID, err)
+		}
+		for day := start; !day.After(to); day = product.PeriodEnd(day) {
+			if product.PeriodEnd(day).After(today) {
+				break
+			}
+			amount, err := e.settle(ctx, account, product, day, true)
+			if err != nil {
+				return results, fmt.Errorf("account %s: %w", account.ID, err)
+			}
+			result.Paid = round(result.Paid + amount)
+		}
+		results = append(results, result)
+	}
+	if accountID != "" && len(results) == 0 {
+		return nil, fmt.Errorf("account %s has no interest product or did not exist in the range", accountID)
+	}
+	return results, nil
+}
+
+// accrue stores the account's accrual for each day in [from, to] and
+// returns how many it stored. Unless replace is set, days that already
+// have an accrual are kept as they are.
+func (e *Engine) accrue(ctx context.Context, account *models.Account, product Product, from, to time.Time, replace bool) (int, error) {
+	if from.After(to) {
+		return 0, nil
+	}
+	stored, err := e.store.Accruals(ctx, account.ID, dates(product.PeriodStart(from), to.AddDate(0, 0, 1)))
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]models.InterestAccrual, len(stored))
+	for _, accrual := range stored {
+		existing[accrual.Date] = accrual
+	}
+
+	// Interest accrued earlier in the period, which daily compounding
+	// earns interest on
+	var accrued float64
+	for _, accrual := range stored {
+		if accrual.Date < from.Format(DateLayout) {
+			accrued += accrual.Amount
+		}
+	}
+
+	days := dates(from, to.AddDate(0, 0, 1))
+	if !replace {
+		missing := 0
+		for _, date := range days {
+			if _, ok := existing[date]; !ok {
+				missing++
+			}
+		}
+		if missing == 0 {
+			return 0, nil
+		}
+	}
+	balances, err := e.balances.DailyBalances(ctx, account, from, len(days))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i, date := range days {
+		day := from.AddDate(0, 0, i)
+		if day.Equal(product.PeriodStart(day)) {
+			accrued = 0
+		}
+		if accrual, ok := existing[date]; ok && !replace {
+			accrued += accrual.Amount
+			continue
+		}
+
+		base := balances[i]
+		if product.Compounding == CompoundDaily {
+			base += accrued
+		}
+		accrual := &models.InterestAccrual{
+			AccountID:   account.ID,
+			Date:        date,
+			Period:      product.Period(day),
+			AccountType: account.AccountType,
+			Balance:     round(base),
+			Amount:      math.Round(product.DailyInterest(base, day)*1e8) / 1e8,
+			CreatedAt:   time.Now().UTC(),
+		}
+		created, err := e.store.PutAccrual(ctx, accrual, replace)
+		if err != nil {
+			return count, err
+		}
+		if !created {
+			// Another instance accrued the day first; compound on its amount
+			kept, err := e.store.Accruals(ctx, account.ID, []string{date})
+			if err != nil {
+				return count, err
+			}
+			if len(kept) == 1 {
+				accrual = &kept[0]
+			}
+		} else {
+			count++
+		}
+		accrued += accrual.Amount
+	}
+	return count, nil
+}
+
+// settle pays the account the interest accrued in the period containing day
+// and returns the amount posted. A period already paid is left alone unless
+// correct is set, in which case any difference from what was paid is posted.
+func (e *Engine) settle(ctx context.Context, account *models.Account, product Product, day time.Time, correct bool) (float64, error) {
+	period := product.Period(day)
+	posting, err := e.store.GetPosting(ctx, account.ID, period)
+	if err != nil || (posting != nil && !correct) {
+		return 0, err
+	}
+
+	accruals, err := e.store.Accruals(ctx, account.ID, dates(product.PeriodStart(day), product.PeriodEnd(day)))
+	if err != nil {
+		return 0, err
+	}
+	if posting == nil && len(accruals) == 0 {
+		return 0, nil
+	}
+	var total float64
+	for _, accrual := range accruals {
+		total += accrual.Amount
+	}
+	total = round(total)
+
+	if posting == nil {
+		now := time.Now().UTC()
+		posting = &models.InterestPosting{
+			AccountID: account.ID,
+			Period:    period,
+			Amount:    total,
+			OutboxIDs: []string{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		entry := interestEntry(account.ID, total, "interest for "+period)
+		if entry != nil {
+			posting.OutboxIDs = append(posting.OutboxIDs, entry.ID)
+		}
+		created, err := e.store.CreatePosting(ctx, posting)
+		if err != nil || !created || entry == nil {
+			return 0, err
+		}
+		if err := e.outbox.Submit(ctx, entry); err != nil {
+			// Let the next run pay the period
+			if derr := e.store.DeletePosting(ctx, account.ID, period); derr != nil {
+				slog.Error("interest posting left without its transaction",
+					"account_id", account.ID, "period", period, "error", derr)
+			}
+			return 0, err
+		}
+		return total, nil
+	}
+
+	diff := round(total - posting.Amount)
+	entry := interestEntry(account.ID, diff, "interest correction for "+period)
+	if entry == nil {
+		return 0, nil
+	}
+	previous := *posting
+	posting.Amount = total
+	posting.OutboxIDs = append(posting.OutboxIDs, entry.ID)
+	posting.UpdatedAt = time.Now().UTC()
+	if err := e.store.UpdatePosting(ctx, posting); err != nil {
+		return 0, err
+	}
+	if err := e.outbox.Submit(ctx, entry); err != nil {
+		if uerr := e.store.UpdatePosting(ctx, &previous); uerr != nil {
+			slog.Error("interest posting left without its correction",
+				"account_id", account.ID, "period", period, "error", uerr)
+		}
+		return 0, err
+	}
+	return diff, nil
+}
+
+// day returns the start of the day containing t in the engine's location.
+func (e *Engine) day(t time.Time) time.Time {
+	t = t.In(e.balances.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// interestEntry is the outbox entry paying amount, or reclaiming it when it
+// is negative, completed and applied to the account's balance once
+// delivered. It returns nil for a zero amount.
+func interestEntry(accountID string, amount float64, description string) *models.OutboxEntry {
+	if amount == 0 {
+		return nil
+	}
+	txnType := "deposit"
+	if amount < 0 {
+		txnType = "withdrawal"
+	}
+	return &models.OutboxEntry{
+		ID:     uuid.New().String(),
+		Source: models.OutboxSourceInterest,
+		Transaction: models.Transaction{
+			AccountID:   accountID,
+			Amount:      math.Abs(amount),
+			Type:        txnType,
+			Description: description,
+			Status:      "pending",
+		},
+		Actor:    "interest",
+		Complete: true,
+	}
+}
+
+// dates lists the days in [from, to).
+func dates(from, to time.Time) []string {
+	var days []string
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(DateLayout))
+	}
+	return days
+}
+
+// round keeps amounts to whole cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,209 @@
+package interest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// memStore keeps accruals and postings in memory.
+type memStore struct {
+	accruals map[string]models.InterestAccrual
+	postings map[string]models.InterestPosting
+}
+
+func newMemStore() *memStore {
+	return &memStore{accruals: make(map[string]models.InterestAccrual), postings: make(map[string]models.InterestPosting)}
+}
+
+func (s *memStore) PutAccrual(_ context.Context, accrual *models.InterestAccrual, replace bool) (bool, error) {
+	key := accrual.AccountID + "#" + accrual.Date
+	if _, ok := s.accruals[key]; ok && !replace {
+		return false, nil
+	}
+	s.accruals[key] = *accrual
+	return true, nil
+}
+
+func (s *memStore) Accruals(_ context.Context, accountID string, dates []string) ([]models.InterestAccrual, error) {
+	var list []models.InterestAccrual
+	for _, date := range dates {
+		if accrual, ok := s.accruals[accountID+"#"+date]; ok {
+			list = append(list, accrual)
+		}
+	}
+	return list, nil
+}
+
+func (s *memStore) CreatePosting(_ context.Context, posting *models.InterestPosting) (bool, error) {
+	key := posting.AccountID + "#" + posting.Period
+	if _, ok := s.postings[key]; ok {
+		return false, nil
+	}
+	s.postings[key] = *posting
+	return true, nil
+}
+
+func (s *memStore) UpdatePosting(_ context.Context, posting *models.InterestPosting) error {
+	s.postings[posting.AccountID+"#"+posting.Period] = *posting
+	return nil
+}
+
+func (s *memStore) DeletePosting(_ context.Context, accountID, period string) error {
+	delete(s.postings, accountID+"#"+period)
+	return nil
+}
+
+func (s *memStore) GetPosting(_ context.Context, accountID, period string) (*models.InterestPosting, error) {
+	posting, ok := s.postings[accountID+"#"+period]
+	if !ok {
+		return nil, nil
+	}
+	return &posting, nil
+}
+
+type fakeAccounts []models.Account
+
+func (a fakeAccounts) ListAll(context.Context) ([]models.Account, error) {
+	return a, nil
+}
+
+// flatBalances reports the same closing balance every day.
+type flatBalances struct{ balance float64 }
+
+func (b *flatBalances) DailyBalances(_ context.Context, _ *models.Account, _ time.Time, days int) ([]float64, error) {
+	balances := make([]float64, days)
+	for i := range balances {
+		balances[i] = b.balance
+	}
+	return balances, nil
+}
+
+func (b *flatBalances) Location() *time.Location {
+	return time.UTC
+}
+
+type fakeOutbox struct{ entries []models.OutboxEntry }
+
+func (o *fakeOutbox) Submit(_ context.Context, entry *models.OutboxEntry) error {
+	o.entries = append(o.entries, *entry)
+	return nil
+}
+
+func newTestEngine(compounding string, balances *flatBalances) (*Engine, *memStore, *fakeOutbox) {
+	products := map[string]Product{"savings": {
+		AccountType: "savings",
+		Rate:        0.0365,
+		Compounding: compounding,
+		DayCount:    DayCountActual365,
+	}}
+	accounts := fakeAccounts{{ID: "acc", AccountType: "savings", CreatedAt: date(2023, time.June, 1)}}
+	store, outbox := newMemStore(), &fakeOutbox{}
+	return NewEngine(products, accounts, store, balances, outbox, time.Hour), store, outbox
+}
+
+func TestReplayCompounding(t *testing.T) {
+	// 10000 at 3.65% on actual/365 earns exactly 1 a day
+	tests := []struct {
+		compounding string
+		// second is the interest earned on the second day of the period
+		second float64
+		paid   float64
+	}{
+		{compounding: CompoundMonthly, second: 1, paid: 31},
+		{compounding: CompoundDaily, second: 1.0001, paid: 31.05},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compounding, func(t *testing.T) {
+			engine, store, outbox := newTestEngine(tt.compounding, &flatBalances{balance: 10000})
+			results, err := engine.Replay(context.Background(), "acc", date(2024, time.January, 1), date(2024, time.January, 31))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Days != 31 || results[0].Paid != tt.paid {
+				t.Fatalf("results = %+v, want 31 days and %v paid", results, tt.paid)
+			}
+			if got := store.accruals["acc#2024-01-02"].Amount; !near(got, tt.second) {
+				t.Errorf("second day earned %v, want %v", got, tt.second)
+			}
+			if len(outbox.entries) != 1 {
+				t.Fatalf("submitted %d entries, want 1", len(outbox.entries))
+			}
+			entry := outbox.entries[0]
+			if entry.Transaction.Type != "deposit" || entry.Transaction.Amount != tt.paid || !entry.Complete {
+				t.Errorf("entry = %+v, want a completed deposit of %v", entry, tt.paid)
+			}
+		})
+	}
+}
+
+func TestReplayIdempotent(t *testing.T) {
+	balances := &flatBalances{balance: 10000}
+	engine, store, outbox := newTestEngine(CompoundMonthly, balances)
+	ctx := context.Background()
+	from, to := date(2024, time.January, 1), date(2024, time.February, 29)
+
+	first, err := engine.Replay(ctx, "acc", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first[0].Paid != 60 || len(outbox.entries) != 2 {
+		t.Fatalf("first replay paid %v in %d entries, want 60 in 2", first[0].Paid, len(outbox.entries))
+	}
+
+	// Nothing changed, so replaying again pays nothing more
+	again, err := engine.Replay(ctx, "acc", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].Paid != 0 || len(outbox.entries) != 2 {
+		t.Fatalf("second replay paid %v in %d entries, want nothing", again[0].Paid, len(outbox.entries))
+	}
+	if got := store.postings["acc#2024-01"].Amount; got != 31 {
+		t.Errorf("January posting is %v, want 31", got)
+	}
+
+	// Lower balances are corrected by reclaiming the difference
+	balances.balance = 5000
+	corrected, err := engine.Replay(ctx, "acc", date(2024, time.February, 1), to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrected[0].Paid != -14.5 {
+		t.Fatalf("correction paid %v, want -14.5", corrected[0].Paid)
+	}
+	last := outbox.entries[len(outbox.entries)-1]
+	if last.Transaction.Type != "withdrawal" || last.Transaction.Amount != 14.5 || !last.Complete {
+		t.Errorf("correction entry = %+v, want a completed withdrawal of 14.5", last)
+	}
+	posting := store.postings["acc#2024-02"]
+	if posting.Amount != 14.5 || !slices.Contains(posting.OutboxIDs, last.ID) || len(posting.OutboxIDs) != 2 {
+		t.Errorf("February posting = %+v, want 14.5 paid by both entries", posting)
+	}
+}
+
+func TestRunOnceSkipsAccruedDays(t *testing.T) {
+	engine, store, outbox := newTestEngine(CompoundMonthly, &flatBalances{balance: 10000})
+	ctx := context.Background()
+	now := date(2024, time.March, 3).Add(9 * time.Hour)
+
+	if err := engine.RunOnce(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	// The last week is accrued, and February, having ended, is paid for
+	// what accrued of it
+	if len(store.accruals) != 7 || len(outbox.entries) != 1 || outbox.entries[0].Transaction.Amount != 5 {
+		t.Fatalf("accrued %d days and submitted %+v, want 7 days and 5 paid", len(store.accruals), outbox.entries)
+	}
+
+	engine.done = ""
+	if err := engine.RunOnce(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.accruals) != 7 || len(outbox.entries) != 1 {
+		t.Errorf("second run accrued %d days and submitted %d entries, want no change", len(store.accruals), len(outbox.entries))
+	}
+}
@@ -0,0 +1,153 @@
+// Package interest accrues interest every day on accounts whose type has an
+// interest product, and pays what accrued as a completed deposit, applied to
+// the account's balance, at the end of each period. Accrual is idempotent
+// per account and day, and a date range can be replayed, correcting periods
+// that were already paid.
+package interest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/corebank-api/internal/config"
+)
+
+// Compounding frequencies.
+const (
+	CompoundDaily     = "daily"
+	CompoundMonthly   = "monthly"
+	CompoundQuarterly = "quarterly"
+	CompoundAnnually  = "annually"
+)
+
+// Day count conventions, which set the fraction of the annual rate earned
+// per day.
+const (
+	DayCountActual365    = "actual/365"
+	DayCountActual360    = "actual/360"
+	DayCountActualActual = "actual/actual"
+	DayCount30360        = "30/360"
+)
+
+// Tier pays Rate on the part of a balance above From.
+type Tier struct {
+	From float64
+	Rate float64
+}
+
+// Product is the interest paid on one account type. Rates are annual
+// fractions.
+type Product struct {
+	AccountType string
+	// Rate applies to the part of the balance below the first tier.
+	Rate        float64
+	Tiers       []Tier
+	Compounding string
+	DayCount    string
+}
+
+// ProductsFromConfig returns the configured products by account type, with
+// monthly compounding and actual/365 filled in where they are not set.
+func ProductsFromConfig(cfg []config.InterestProductConfig) map[string]Product {
+	products := make(map[string]Product, len(cfg))
+	for _, p := range cfg {
+		product := Product{
+			AccountType: p.AccountType,
+			Rate:        p.Rate,
+			Compounding: p.Compounding,
+			DayCount:    p.DayCount,
+		}
+		for _, t := range p.Tiers {
+			product.Tiers = append(product.Tiers, Tier{From: t.From, Rate: t.Rate})
+		}
+		if product.Compounding == "" {
+			product.Compounding = CompoundMonthly
+		}
+		if product.DayCount == "" {
+			product.DayCount = DayCountActual365
+		}
+		products[p.AccountType] = product
+	}
+	return products
+}
+
+// DailyInterest returns the interest earned on balance over day. Overdrawn
+// balances earn nothing.
+func (p Product) DailyInterest(balance float64, day time.Time) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	return p.annualInterest(balance) * p.dayFraction(day)
+}
+
+// annualInterest applies each tier's rate to its band of balance.
+func (p Product) annualInterest(balance float64) float64 {
+	interest, rate, from := 0.0, p.Rate, 0.0
+	for _, tier := range p.Tiers {
+		if balance <= tier.From {
+			break
+		}
+		interest += (tier.From - from) * rate
+		rate, from = tier.Rate, tier.From
+	}
+	return interest + (balance-from)*rate
+}
+
+// dayFraction is the part of a year day counts for. Under 30/360 every month
+// counts as 30 days, spread evenly over its actual days.
+func (p Product) dayFraction(day time.Time) float64 {
+	switch p.DayCount {
+	case DayCountActual360:
+		return 1.0 / 360
+	case DayCountActualActual:
+		return 1.0 / float64(daysIn(day.Year(), time.January, 12))
+	case DayCount30360:
+		return 30.0 / 360 / float64(daysIn(day.Year(), day.Month(), 1))
+	default:
+		return 1.0 / 365
+	}
+}
+
+// Period names the period interest earned on day is paid for: "2024-05" for
+// monthly (and daily) compounding, "2024-Q2" for quarterly and "2024" for
+// annual.
+func (p Product) Period(day time.Time) string {
+	switch p.Compounding {
+	case CompoundQuarterly:
+		return fmt.Sprintf("%d-Q%d", day.Year(), (int(day.Month())-1)/3+1)
+	case CompoundAnnually:
+		return fmt.Sprintf("%d", day.Year())
+	default:
+		return day.Format("2006-01")
+	}
+}
+
+// PeriodStart returns the first day of the period containing day.
+func (p Product) PeriodStart(day time.Time) time.Time {
+	month := day.Month()
+	switch p.Compounding {
+	case CompoundQuarterly:
+		month = (month-1)/3*3 + 1
+	case CompoundAnnually:
+		month = time.January
+	}
+	return time.Date(day.Year(), month, 1, 0, 0, 0, 0, day.Location())
+}
+
+// PeriodEnd returns the first day after the period containing day.
+func (p Product) PeriodEnd(day time.Time) time.Time {
+	months := 1
+	switch p.Compounding {
+	case CompoundQuarterly:
+		months = 3
+	case CompoundAnnually:
+		months = 12
+	}
+	return p.PeriodStart(day).AddDate(0, months, 0)
+}
+
+// daysIn counts the days in the given number of months starting at month.
+func daysIn(year int, month time.Month, months int) int {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return int(start.AddDate(0, months, 0).Sub(start).Hours() / 24)
+}
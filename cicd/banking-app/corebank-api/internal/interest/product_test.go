@@ -0,0 +1,110 @@
+package interest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDailyInterestDayCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		dayCount string
+		day      time.Time
+		want     float64
+	}{
+		{name: "actual/365", dayCount: DayCountActual365, day: date(2024, time.March, 1), want: 500.0 / 365},
+		{name: "actual/365 in a leap year", dayCount: DayCountActual365, day: date(2024, time.February, 29), want: 500.0 / 365},
+		{name: "actual/360", dayCount: DayCountActual360, day: date(2024, time.March, 1), want: 500.0 / 360},
+		{name: "actual/actual in a leap year", dayCount: DayCountActualActual, day: date(2024, time.March, 1), want: 500.0 / 366},
+		{name: "actual/actual", dayCount: DayCountActualActual, day: date(2023, time.March, 1), want: 500.0 / 365},
+		{name: "30/360 in a 31-day month", dayCount: DayCount30360, day: date(2024, time.January, 15), want: 500.0 / 12 / 31},
+		{name: "30/360 in February", dayCount: DayCount30360, day: date(2023, time.February, 15), want: 500.0 / 12 / 28},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Product{Rate: 0.05, DayCount: tt.dayCount}
+			if got := p.DailyInterest(10000, tt.day); !near(got, tt.want) {
+				t.Errorf("DailyInterest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Under 30/360 every month earns a twelfth of the annual interest, however
+// many days it has.
+func TestDailyInterest30360Month(t *testing.T) {
+	p := Product{Rate: 0.05, DayCount: DayCount30360}
+	for _, month := range []time.Month{time.January, time.February, time.April} {
+		var total float64
+		for day := date(2024, month, 1); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			total += p.DailyInterest(12000, day)
+		}
+		if !near(total, 50) {
+			t.Errorf("%s earned %v, want 50", month, total)
+		}
+	}
+}
+
+func TestDailyInterestTiers(t *testing.T) {
+	p := Product{
+		Rate:     0.01,
+		Tiers:    []Tier{{From: 1000, Rate: 0.02}, {From: 5000, Rate: 0.03}},
+		DayCount: DayCountActual360,
+	}
+	tests := []struct {
+		name    string
+		balance float64
+		annual  float64
+	}{
+		{name: "overdrawn earns nothing", balance: -500, annual: 0},
+		{name: "empty earns nothing", balance: 0, annual: 0},
+		{name: "below the first tier", balance: 500, annual: 5},
+		{name: "at a tier boundary", balance: 1000, annual: 10},
+		{name: "in the second band", balance: 3000, annual: 10 + 40},
+		{name: "in the top band", balance: 10000, annual: 10 + 80 + 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.DailyInterest(tt.balance, date(2024, time.May, 1)); !near(got, tt.annual/360) {
+				t.Errorf("DailyInterest(%v) = %v, want %v", tt.balance, got, tt.annual/360)
+			}
+		})
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	day := date(2024, time.May, 17)
+	tests := []struct {
+		compounding string
+		period      string
+		start, end  time.Time
+	}{
+		{compounding: CompoundDaily, period: "2024-05", start: date(2024, time.May, 1), end: date(2024, time.June, 1)},
+		{compounding: CompoundMonthly, period: "2024-05", start: date(2024, time.May, 1), end: date(2024, time.June, 1)},
+		{compounding: CompoundQuarterly, period: "2024-Q2", start: date(2024, time.April, 1), end: date(2024, time.July, 1)},
+		{compounding: CompoundAnnually, period: "2024", start: date(2024, time.January, 1), end: date(2025, time.January, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compounding, func(t *testing.T) {
+			p := Product{Compounding: tt.compounding}
+			if got := p.Period(day); got != tt.period {
+				t.Errorf("Period = %q, want %q", got, tt.period)
+			}
+			if got := p.PeriodStart(day); !got.Equal(tt.start) {
+				t.Errorf("PeriodStart = %v, want %v", got, tt.start)
+			}
+			if got := p.PeriodEnd(day); !got.Equal(tt.end) {
+				t.Errorf("PeriodEnd = %v, want %v", got, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package limits enforces the outflow limits of accounts: the largest single
+// transaction, and what withdrawals and transfers may take per calendar day
+// and month. Each account's product sets its limits, which the account may
+// override, capped for customers whose identity is not yet verified.
+// Outflow is counted in storage per period of the account's
+// timezone, so a new day or month starts from nothing.
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+)
+
+// Store keeps outflow counters; repository.LimitRepository implements it
+// against DynamoDB.
+type Store interface {
+	// Reserve adds amount to every counter, or to none if one would pass its
+	// limit, in which case it fails with repository.ErrLimitReached.
+	Reserve(ctx context.Context, amount float64, counters []repository.CounterLimit) error
+	// Release takes amount back off the counters, once per non-empty
+	// releaseID.
+	Release(ctx context.Context, releaseID string, amount float64, counterIDs []string) error
+	// Used returns what each counter has counted.
+	Used(ctx context.Context, counterIDs []string) (map[string]float64, error)
+}
+
+// Limiter checks and counts the outflow of accounts.
+type Limiter struct {
+	store    Store
+	products *products.Catalog
+	// tiers caps the limits of accounts by KYC status.
+	tiers map[string]config.KYCTierConfig
+	// loc is the timezone of accounts without one of their own.
+	loc *time.Location
+}
+
+func NewLimiter(store Store, catalog *products.Catalog, kyc config.KYCConfig, loc *time.Location) *Limiter {
+	tiers := map[string]config.KYCTierConfig{
+		models.KYCUnverified: kyc.Unverified,
+		models.KYCPending:    kyc.Pending,
+		// Rejected customers may not transact at all; their limits are
+		// reported as the unverified ones
+		models.KYCRejected: kyc.Unverified,
+	}
+	return &Limiter{store: store, products: catalog, tiers: tiers, loc: loc}
+}
+
+// Reservation is outflow counted against an account's limits.
+type Reservation struct {
+	AccountID  string
+	Amount     float64
+	counterIDs []string
+}
+
+// For returns the product account follows, its limits replaced by the
+// account's own and then capped by the account's KYC status, so no override
+// lifts a customer past what their verification allows.
+func (l *Limiter) For(account *models.Account) models.Product {
+	product := l.products.For(account)
+	if o := account.Limits; o != nil {
+		if o.MaxTransactionAmount != nil {
+			product.MaxTransactionAmount = *o.MaxTransactionAmount
+		}
+		if o.DailyOutflow != nil {
+			product.DailyOutflowLimit = *o.DailyOutflow
+		}
+		if o.MonthlyOutflow != nil {
+			product.MonthlyOutflowLimit = *o.MonthlyOutflow
+		}
+	}
+	if tier, ok := l.tiers[account.KYCLevel()]; ok {
+		product.MaxTransactionAmount = lower(product.MaxTransactionAmount, tier.MaxTransactionAmount)
+		product.DailyOutflowLimit = lower(product.DailyOutflowLimit, tier.DailyOutflowLimit)
+		product.MonthlyOutflowLimit = lower(product.MonthlyOutflowLimit, tier.MonthlyOutflowLimit)
+	}
+	return product
+}
+
+// Location returns the timezone account's days and months are taken in.
+func (l *Limiter) Location(account *models.Account) *time.Location {
+	if account.Timezone != "" {
+		if loc, err := time.LoadLocation(account.Timezone); err == nil {
+			return loc
+		}
+	}
+	return l.loc
+}
+
+// Reserve counts an outflow of amount made at now against the account's
+// daily and monthly limits. If it would pass either, nothing is counted and
+// it fails with an error matching products.ErrLimitExceeded. A reservation
+// whose transaction is not recorded must be cancelled.
+func (l *Limiter) Reserve(ctx context.Context, account *models.Account, amount float64, now time.Time) (*Reservation, error) {
+	amount = math.Abs(amount)
+	product := l.For(account)
+	day, month := l.counters(account, now)
+	err := l.store.Reserve(ctx, amount, []repository.CounterLimit{
+		{Counter: day, Max: product.DailyOutflowLimit},
+		{Counter: month, Max: product.MonthlyOutflowLimit},
+	})
+	if errors.Is(err, repository.ErrLimitReached) {
+		return nil, l.exceeded(ctx, account, product, amount, day.ID, month.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Reservation{AccountID: account.ID, Amount: amount, counterIDs: []string{day.ID, month.ID}}, nil
+}
+
+// exceeded explains which limit an outflow would pass.
+func (l *Limiter) exceeded(ctx context.Context, account *models.Account, product models.Product, amount float64, dayID, monthID string) error {
+	used, err := l.store.Used(ctx, []string{dayID, monthID})
+	if err != nil {
+		return fmt.Errorf("%w: account %s", products.ErrLimitExceeded, account.ID)
+	}
+	name, limit, remaining := "monthly", product.MonthlyOutflowLimit, product.MonthlyOutflowLimit-used[monthID]
+	if product.DailyOutflowLimit > 0 && used[dayID]+amount > product.DailyOutflowLimit {
+		name, limit, remaining = "daily", product.DailyOutflowLimit, product.DailyOutflowLimit-used[dayID]
+	}
+	return fmt.Errorf("%w: account %s has %.2f of its %s outflow limit of %.2f remaining",
+		products.ErrLimitExceeded, account.ID, math.Max(remaining, 0), name, limit)
+}
+
+// Cancel takes back a reservation whose transaction was not recorded.
+func (l *Limiter) Cancel(ctx context.Context, r *Reservation) error {
+	return l.store.Release(ctx, "", r.Amount, r.counterIDs)
+}
+
+// Release takes back the outflow of a recorded transaction that failed,
+// once however often it fails. It is counted against the day and month it
+// was created in.
+func (l *Limiter) Release(ctx context.Context, account *models.Account, txn *models.Transaction) error {
+	day, month := l.counters(account, txn.CreatedAt)
+	return l.store.Release(ctx, txn.ID, math.Abs(txn.Amount), []string{day.ID, month.ID})
+}
+
+// Status reports the account's limits and what remains of them at now.
+func (l *Limiter) Status(ctx context.Context, account *models.Account, now time.Time) (*models.LimitStatus, error) {
+	product := l.For(account)
+	loc := l.Location(account)
+	day, month := l.counters(account, now)
+	used, err := l.store.Used(ctx, []string{day.ID, month.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	local := now.In(loc)
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	startOfMonth := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	return &models.LimitStatus{
+		AccountID:            account.ID,
+		Timezone:             loc.String(),
+		KYCStatus:            account.KYCLevel(),
+		MaxTransactionAmount: product.MaxTransactionAmount,
+		Daily:                window(product.DailyOutflowLimit, used[day.ID], startOfDay.AddDate(0, 0, 1)),
+		Monthly:              window(product.MonthlyOutflowLimit, used[month.ID], startOfMonth.AddDate(0, 1, 0)),
+		Overrides:            account.Limits,
+	}, nil
+}
+
+func window(limit, used float64, resetsAt time.Time) models.LimitWindow {
+	w := models.LimitWindow{Limit: limit, Used: round(used), ResetsAt: resetsAt.UTC()}
+	if limit > 0 {
+		remaining := round(math.Max(limit-used, 0))
+		w.Remaining = &remaining
+	}
+	return w
+}
+
+// counters returns the account's day and month counters for the periods at
+// covers.
+func (l *Limiter) counters(account *models.Account, at time.Time) (day, month models.LimitCounter) {
+	local := at.In(l.Location(account))
+	dayStart, monthStart := local.Format("2006-01-02"), local.Format("2006-01")
+	day = models.LimitCounter{
+		ID:        account.ID + "#" + models.LimitPeriodDay + "#" + dayStart,
+		AccountID: account.ID,
+		Period:    models.LimitPeriodDay,
+		Start:     dayStart,
+	}
+	month = models.LimitCounter{
+		ID:        account.ID + "#" + models.LimitPeriodMonth + "#" + monthStart,
+		AccountID: account.ID,
+		Period:    models.LimitPeriodMonth,
+		Start:     monthStart,
+	}
+	return day, month
+}
+
+// lower returns the tighter of two limits, where zero is no limit.
+func lower(limit, ceiling float64) float64 {
+	if ceiling > 0 && (limit == 0 || ceiling < limit) {
+		return ceiling
+	}
+	return limit
+}
+
+// round keeps amounts to whole cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,208 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+)
+
+// memStore keeps counters in memory with the conditions
+// repository.LimitRepository puts on them.
+type memStore struct {
+	used     map[string]float64
+	released map[string]bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{used: make(map[string]float64), released: make(map[string]bool)}
+}
+
+func (s *memStore) Reserve(_ context.Context, amount float64, counters []repository.CounterLimit) error {
+	for _, c := range counters {
+		if c.Max > 0 && s.used[c.Counter.ID]+amount > c.Max {
+			return repository.ErrLimitReached
+		}
+	}
+	for _, c := range counters {
+		s.used[c.Counter.ID] += amount
+	}
+	return nil
+}
+
+func (s *memStore) Release(_ context.Context, releaseID string, amount float64, counterIDs []string) error {
+	if releaseID != "" {
+		if s.released[releaseID] {
+			return nil
+		}
+		s.released[releaseID] = true
+	}
+	for _, id := range counterIDs {
+		s.used[id] -= amount
+	}
+	return nil
+}
+
+func (s *memStore) Used(_ context.Context, counterIDs []string) (map[string]float64, error) {
+	used := make(map[string]float64)
+	for _, id := range counterIDs {
+		used[id] = s.used[id]
+	}
+	return used, nil
+}
+
+func newTestLimiter(store Store) *Limiter {
+	catalog := products.NewCatalog([]config.ProductConfig{{
+		AccountType:          config.DefaultAccountType,
+		MaxTransactionAmount: 5000,
+		DailyOutflowLimit:    10000,
+		MonthlyOutflowLimit:  50000,
+	}})
+	kyc := config.KYCConfig{
+		Unverified: config.KYCTierConfig{MaxTransactionAmount: 1000, DailyOutflowLimit: 1000, MonthlyOutflowLimit: 3000},
+		Pending:    config.KYCTierConfig{MaxTransactionAmount: 5000, DailyOutflowLimit: 5000, MonthlyOutflowLimit: 20000},
+	}
+	return NewLimiter(store, catalog, kyc, time.UTC)
+}
+
+func amount(v float64) *float64 { return &v }
+
+func TestFor(t *testing.T) {
+	l := newTestLimiter(nil)
+	tests := []struct {
+		name                string
+		kyc                 string
+		overrides           *models.AccountLimits
+		max, daily, monthly float64
+	}{
+		{name: "verified follows the product", kyc: models.KYCVerified, max: 5000, daily: 10000, monthly: 50000},
+		{name: "unverified is capped", kyc: models.KYCUnverified, max: 1000, daily: 1000, monthly: 3000},
+		{name: "legacy accounts are unverified", kyc: "", max: 1000, daily: 1000, monthly: 3000},
+		{name: "rejected reports the unverified caps", kyc: models.KYCRejected, max: 1000, daily: 1000, monthly: 3000},
+		{name: "pending is capped less", kyc: models.KYCPending, max: 5000, daily: 5000, monthly: 20000},
+		{
+			name: "verified overrides replace the product", kyc: models.KYCVerified,
+			overrides: &models.AccountLimits{MaxTransactionAmount: amount(20000), DailyOutflow: amount(0), MonthlyOutflow: amount(100)},
+			max:       20000, daily: 0, monthly: 100,
+		},
+		{
+			name: "overrides cannot lift the tier caps", kyc: models.KYCUnverified,
+			overrides: &models.AccountLimits{MaxTransactionAmount: amount(20000), DailyOutflow: amount(0), MonthlyOutflow: amount(100000)},
+			max:       1000, daily: 1000, monthly: 3000,
+		},
+		{
+			name: "overrides may tighten the tier caps", kyc: models.KYCPending,
+			overrides: &models.AccountLimits{DailyOutflow: amount(250)},
+			max:       5000, daily: 250, monthly: 20000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := l.For(&models.Account{ID: "acc", AccountType: config.DefaultAccountType, KYCStatus: tt.kyc, Limits: tt.overrides})
+			if p.MaxTransactionAmount != tt.max || p.DailyOutflowLimit != tt.daily || p.MonthlyOutflowLimit != tt.monthly {
+				t.Fatalf("got max %.2f, daily %.2f, monthly %.2f; want %.2f, %.2f, %.2f",
+					p.MaxTransactionAmount, p.DailyOutflowLimit, p.MonthlyOutflowLimit, tt.max, tt.daily, tt.monthly)
+			}
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	l := newTestLimiter(store)
+	account := &models.Account{ID: "acc", AccountType: config.DefaultAccountType, KYCStatus: models.KYCVerified}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	day, month := "acc#"+models.LimitPeriodDay+"#2026-10-19", "acc#"+models.LimitPeriodMonth+"#2026-10"
+
+	// Withdrawals are negative amounts; both count as outflow
+	for _, a := range []float64{4000, -5000} {
+		if _, err := l.Reserve(ctx, account, a, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.used[day] != 9000 || store.used[month] != 9000 {
+		t.Fatalf("used %v, want 9000 today and this month", store.used)
+	}
+
+	_, err := l.Reserve(ctx, account, 1000.01, now)
+	if !errors.Is(err, products.ErrLimitExceeded) || !strings.Contains(err.Error(), "1000.00 of its daily outflow limit of 10000.00 remaining") {
+		t.Fatalf("got %v, want the daily limit exceeded", err)
+	}
+	if store.used[day] != 9000 || store.used[month] != 9000 {
+		t.Fatalf("used %v after a refused reservation, want it unchanged", store.used)
+	}
+
+	// A cancelled reservation makes room again
+	r, err := l.Reserve(ctx, account, 1000, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Cancel(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if store.used[day] != 9000 {
+		t.Fatalf("used %v after a cancellation, want 9000 today", store.used)
+	}
+
+	// Tomorrow starts a new day, not a new month
+	store.used[month] = 49500
+	_, err = l.Reserve(ctx, account, 600, now.AddDate(0, 0, 1))
+	if !errors.Is(err, products.ErrLimitExceeded) || !strings.Contains(err.Error(), "500.00 of its monthly outflow limit of 50000.00 remaining") {
+		t.Fatalf("got %v, want the monthly limit exceeded", err)
+	}
+}
+
+// Days and months are taken in the account's timezone.
+func TestReserveInAccountTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("America/Los_Angeles"); err != nil {
+		t.Skip("no timezone data:", err)
+	}
+	store := newMemStore()
+	l := newTestLimiter(store)
+	account := &models.Account{ID: "acc", KYCStatus: models.KYCVerified, Timezone: "America/Los_Angeles"}
+	if _, err := l.Reserve(context.Background(), account, 100, time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if store.used["acc#"+models.LimitPeriodDay+"#2026-09-30"] != 100 || store.used["acc#"+models.LimitPeriodMonth+"#2026-09"] != 100 {
+		t.Fatalf("used %v, want the outflow counted on 30 September", store.used)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	l := newTestLimiter(store)
+	account := &models.Account{ID: "acc", KYCStatus: models.KYCVerified}
+	created := time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC)
+	for _, at := range []time.Time{created, created.Add(time.Hour)} {
+		if _, err := l.Reserve(ctx, account, 300, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A transaction failing after midnight is released from the day it was
+	// created in, however often its failure is reported
+	txn := &models.Transaction{ID: "txn-1", AccountID: "acc", Type: "withdrawal", Amount: -300, CreatedAt: created}
+	for range 2 {
+		if err := l.Release(ctx, account, txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := map[string]float64{
+		"acc#" + models.LimitPeriodDay + "#2026-10-19": 0,
+		"acc#" + models.LimitPeriodDay + "#2026-10-20": 300,
+		"acc#" + models.LimitPeriodMonth + "#2026-10":  300,
+	}
+	for id, used := range want {
+		if store.used[id] != used {
+			t.Errorf("counter %s used %.2f, want %.2f", id, store.used[id], used)
+		}
+	}
+}
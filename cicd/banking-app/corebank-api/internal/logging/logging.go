@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header used to accept and propagate request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+// Options controls how the JSON logger is built.
+type Options struct {
+	Level     string
+	RedactPII bool
+}
+
+// piiKeys lists attribute keys whose values are masked when RedactPII is set.
+var piiKeys = map[string]bool{
+	"email": true,
+	"owner": true,
+}
+
+// New returns a JSON logger writing to w.
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: ParseLevel(opts.Level)}
+	if opts.RedactPII {
+		handlerOpts.ReplaceAttr = redactPII
+	}
+	return slog.New(slog.NewJSONHandler(w, handlerOpts))
+}
+
+// ParseLevel maps a level name to a slog.Level, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithRequestID stores the request ID in ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger annotated with the request ID and,
+// when a span is active, the trace ID from ctx.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if id := RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		logger = logger.With("trace_id", sc.TraceID().String())
+	}
+	return logger
+}
+
+func redactPII(groups []string, a slog.Attr) slog.Attr {
+	if !piiKeys[a.Key] || a.Value.Kind() != slog.KindString {
+		return a
+	}
+	return slog.String(a.Key, Mask(a.Value.String()))
+}
+
+// Mask hides all but the first character of value. For email addresses the
+// domain is kept so that logs remain useful for troubleshooting.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	local, domain, isEmail := strings.Cut(value, "@")
+	masked := "***"
+	if local != "" {
+		masked = local[:1] + masked
+	}
+	if isEmail {
+		return masked + "@" + domain
+	}
+	return masked
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{value: "", want: ""},
+		{value: "dana", want: "d***"},
+		{value: "dana@example.com", want: "d***@example.com"},
+		{value: "@example.com", want: "***@example.com"},
+	}
+	for _, tt := range tests {
+		if got := Mask(tt.value); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRedactPII(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact bool
+		want   map[string]any
+	}{
+		{
+			name:   "redacted",
+			redact: true,
+			want:   map[string]any{"email": "d***@example.com", "owner": "D***", "account_id": "acc-1"},
+		},
+		{
+			name: "left alone",
+			want: map[string]any{"email": "dana@example.com", "owner": "Dana", "account_id": "acc-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(&buf, Options{RedactPII: tt.redact}).Info("account created",
+				"email", "dana@example.com", "owner", "Dana", "account_id", "acc-1")
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if entry[key] != want {
+					t.Errorf("%s = %v, want %v", key, entry[key], want)
+				}
+			}
+		})
+	}
+}
+
+// Keys are redacted inside groups too.
+func TestRedactPIIInGroup(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, Options{RedactPII: true}).Info("import", slog.Group("row", "owner", "Dana", "line", 3))
+	var entry struct {
+		Row map[string]any `json:"row"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Row["owner"] != "D***" || entry.Row["line"] != 3.0 {
+		t.Errorf("row = %v, want the owner masked", entry.Row)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		" WARN ":  slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+	for level, want := range tests {
+		if got := ParseLevel(level); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	ctx := context.Background()
+	if id := RequestID(ctx); id != "" {
+		t.Fatalf("RequestID without one = %q", id)
+	}
+	if id := RequestID(WithRequestID(ctx, "req-1")); id != "req-1" {
+		t.Fatalf("RequestID = %q, want req-1", id)
+	}
+}
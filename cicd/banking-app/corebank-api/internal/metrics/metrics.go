@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/corebank-api/internal/events"
+)
+
+const namespace = "corebank"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	dynamoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dynamodb_operation_duration_seconds",
+		Help:      "DynamoDB call latency, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	dynamoErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dynamodb_operation_errors_total",
+		Help:      "DynamoDB calls that returned an error, by operation.",
+	}, []string{"operation"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transaction_service_request_duration_seconds",
+		Help:      "Latency of calls to the transaction service, by method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status"})
+
+	circuitState = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "transaction_service_circuit_state",
+		Help:      "Transaction service circuit breaker state (0 closed, 1 half-open, 2 open).",
+	})
+
+	accountsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_created_total",
+		Help:      "Accounts successfully created.",
+	})
+
+	depositsPosted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deposits_posted_total",
+		Help:      "Completed deposits posted to account balances.",
+	})
+
+	depositsAmount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deposits_posted_amount_total",
+		Help:      "Sum of completed deposit amounts posted to account balances.",
+	})
+
+	riskDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "risk_decisions_total",
+		Help:      "Transactions screened by the risk rules, by decision.",
+	}, []string{"decision"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dynamoDuration,
+		dynamoErrors,
+		upstreamDuration,
+		circuitState,
+		accountsCreated,
+		depositsPosted,
+		depositsAmount,
+		riskDecisions,
+	)
+}
+
+// Handler serves the registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records a completed HTTP request.
+func ObserveHTTPRequest(route, method string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpDuration.WithLabelValues(route, method, code).Observe(elapsed.Seconds())
+}
+
+// ObserveDynamoDB records a DynamoDB call and whether it failed.
+func ObserveDynamoDB(operation string, elapsed time.Duration, err error) {
+	dynamoDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+	if err != nil {
+		dynamoErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveTransactionService records a call to the transaction service. status
+// is the HTTP status code, or a short reason when no response was received.
+func ObserveTransactionService(method, status string, elapsed time.Duration) {
+	upstreamDuration.WithLabelValues(method, status).Observe(elapsed.Seconds())
+}
+
+// SetCircuitState publishes the transaction service circuit breaker state.
+func SetCircuitState(state int) {
+	circuitState.Set(float64(state))
+}
+
+// AccountCreated counts a newly created account.
+func AccountCreated() {
+	accountsCreated.Inc()
+}
+
+// DepositPosted counts a completed deposit posted to its account's balance.
+func DepositPosted(amount float64) {
+	depositsPosted.Inc()
+	depositsAmount.Add(amount)
+}
+
+// RiskDecision counts a transaction screened by the risk rules.
+func RiskDecision(decision string) {
+	riskDecisions.WithLabelValues(decision).Inc()
+}
+
+// RecordEvent subscribes to domain events and counts the ones metrics
+// report on.
+func RecordEvent(_ context.Context, event events.Event) error {
+	if event.Type == events.AccountCreated {
+		AccountCreated()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+)
+
+// scrape reads the exposition served by Handler into series values, keyed
+// by name and labels as they appear in it.
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	series := make(map[string]float64)
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("unparseable sample %q", line)
+		}
+		series[line[:i]] = value
+	}
+	return series
+}
+
+func TestObserve(t *testing.T) {
+	const (
+		requests = `corebank_http_requests_total{method="GET",route="/accounts/{id}",status="404"}`
+		duration = `corebank_http_request_duration_seconds_count{method="GET",route="/accounts/{id}",status="404"}`
+		calls    = `corebank_dynamodb_operation_duration_seconds_count{operation="GetItem"}`
+		failures = `corebank_dynamodb_operation_errors_total{operation="GetItem"}`
+		upstream = `corebank_transaction_service_request_duration_seconds_count{method="POST",status="timeout"}`
+		circuit  = `corebank_transaction_service_circuit_state`
+		risk     = `corebank_risk_decisions_total{decision="review"}`
+		deposits = "corebank_deposits_posted_total"
+		amount   = "corebank_deposits_posted_amount_total"
+	)
+	before := scrape(t)
+
+	ObserveHTTPRequest("/accounts/{id}", "GET", 404, 5*time.Millisecond)
+	ObserveHTTPRequest("/accounts/{id}", "GET", 404, 5*time.Millisecond)
+	ObserveDynamoDB("GetItem", time.Millisecond, nil)
+	ObserveDynamoDB("GetItem", time.Millisecond, errors.New("throttled"))
+	ObserveTransactionService("POST", "timeout", time.Second)
+	SetCircuitState(2)
+	RiskDecision(models.RiskReview)
+	DepositPosted(250)
+
+	after := scrape(t)
+	for series, want := range map[string]float64{
+		requests: 2, duration: 2, calls: 2, failures: 1, upstream: 1, risk: 1, deposits: 1, amount: 250,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s went up by %v, want %v", series, got, want)
+		}
+	}
+	if after[circuit] != 2 {
+		t.Errorf("circuit state = %v, want 2", after[circuit])
+	}
+}
+
+func TestRecordEvent(t *testing.T) {
+	const (
+		created  = "corebank_accounts_created_total"
+		deposits = "corebank_deposits_posted_total"
+		amount   = "corebank_deposits_posted_amount_total"
+	)
+	before := scrape(t)
+
+	ctx := context.Background()
+	account := &models.Account{ID: "acc"}
+	for _, event := range []events.Event{
+		events.NewAccountCreated(account, 1000),
+		// Deposits are counted once posted, not when submitted
+		events.NewTransactionSubmitted(&models.Transaction{AccountID: "acc", Type: "deposit", Amount: 250}),
+		events.NewAccountDeleted("acc"),
+	} {
+		if err := RecordEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	after := scrape(t)
+	for series, want := range map[string]float64{created: 1, deposits: 0, amount: 0} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s went up by %v, want %v", series, got, want)
+		}
+	}
+}
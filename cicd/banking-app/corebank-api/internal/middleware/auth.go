@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/handlers"
+)
+
+// Auth requires a configured bearer token on every request except those for
+// the given public paths. It is a no-op when auth is disabled.
+func Auth(cfg config.AuthConfig, publicPaths []string, next http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return next
+	}
+
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="corebank-api"`)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		principal, ok := lookupToken(cfg.Tokens, token)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="corebank-api", error="invalid_token"`)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// lookupToken compares against every configured token in constant time.
+func lookupToken(tokens []config.TokenConfig, token string) (auth.Principal, bool) {
+	var found auth.Principal
+	ok := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			found = auth.Principal{Subject: t.Subject, Role: t.Role}
+			ok = true
+		}
+	}
+	return found, ok
+}
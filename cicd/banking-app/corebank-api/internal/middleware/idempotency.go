@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/handlers"
+	"github.com/corebank-api/internal/logging"
+)
+
+const (
+	// IdempotencyKeyHeader lets a client retry a POST without repeating its
+	// effect.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader is set on responses replayed for a repeated key.
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBody fits the largest bulk account import
+	maxIdempotentBody = 16 << 20
+)
+
+// IdempotencyStore remembers responses to POST requests by idempotency key.
+// It is held in memory, so keys are only honoured by the instance that first
+// saw them.
+type IdempotencyStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*idempotentResponse
+	lastSweep time.Time
+}
+
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// NewIdempotencyStore keeps responses for ttl after they complete.
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotentResponse),
+	}
+}
+
+// begin claims key for a request with the given body fingerprint. It returns
+// the existing entry, if any, and whether the caller now owns the key.
+func (s *IdempotencyStore) begin(key string, fingerprint [sha256.Size]byte) (idempotentResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, e := range s.entries {
+			if e.done && now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e, ok := s.entries[key]; ok && !(e.done && now.After(e.expires)) {
+		return *e, false
+	}
+	s.entries[key] = &idempotentResponse{fingerprint: fingerprint}
+	return idempotentResponse{}, true
+}
+
+func (s *IdempotencyStore) complete(key string, status int, header http.Header, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.done = true
+		e.status = status
+		e.header = header
+		e.body = body
+		e.expires = time.Now().Add(s.ttl)
+	}
+}
+
+func (s *IdempotencyStore) abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// Idempotency replays the stored response when a POST is repeated with the
+// same Idempotency-Key, so clients can retry creates after a timeout. Keys
+// are scoped to the caller and path. Server errors are not stored, leaving
+// the key free for another attempt.
+func Idempotency(store *IdempotencyStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			handlers.WriteError(w, r, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			handlers.WriteError(w, r, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var subject string
+		if p, ok := auth.FromContext(r.Context()); ok {
+			subject = p.Subject
+		}
+		scoped := subject + " " + r.URL.Path + " " + key
+		fingerprint := sha256.Sum256(body)
+
+		prev, owner := store.begin(scoped, fingerprint)
+		if !owner {
+			switch {
+			case prev.fingerprint != fingerprint:
+				handlers.WriteErrorCode(w, r, http.StatusUnprocessableEntity, handlers.CodeIdempotencyMismatch,
+					"Idempotency-Key was already used with a different request body")
+			case !prev.done:
+				w.Header().Set("Retry-After", "1")
+				handlers.WriteError(w, r, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+			default:
+				logging.FromContext(r.Context()).Info("replaying idempotent response", "status", prev.status)
+				for k, v := range prev.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(IdempotentReplayHeader, "true")
+				w.WriteHeader(prev.status)
+				w.Write(prev.body)
+			}
+			return
+		}
+
+		rec := &responseCapture{ResponseWriter: w}
+		defer func() {
+			// A panicking handler leaves the key free for a retry
+			if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+				store.abandon(scoped)
+				return
+			}
+			header := w.Header().Clone()
+			header.Del(logging.RequestIDHeader)
+			store.complete(scoped, rec.status, header, rec.body.Bytes())
+		}()
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+	})
+}
+
+// responseCapture passes a response through while keeping a copy of it.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/corebank-api/internal/metrics"
+)
+
+// Metrics records request count and latency for next under the given route
+// label. The label is the registered pattern rather than the raw path, so IDs
+// don't explode the metric cardinality.
+func Metrics(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		metrics.ObserveHTTPRequest(route, r.Method, rec.status, time.Since(start))
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/corebank-api/internal/logging"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength bounds client supplied request IDs so they can't be used
+// to bloat log lines.
+const maxRequestIDLength = 128
+
+// RequestID accepts the X-Request-ID header from the caller, or generates a
+// new one, and makes it available to handlers, logs and forwarded requests.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		// Keep the header on the request so proxied calls carry it along
+		r.Header.Set(logging.RequestIDHeader, id)
+		w.Header().Set(logging.RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
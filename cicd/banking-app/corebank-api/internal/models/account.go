@@ -2,6 +2,14 @@ package models
 
 import "time"
 
+// Account statuses. Accounts stored before statuses existed have none and
+// are treated as active.
+const (
+    AccountStatusActive = "active"
+    AccountStatusFrozen = "frozen"
+    AccountStatusClosed = "closed"
+)
+
 type Account struct {
     ID          string    `json:"id" dynamodbav:"id"`
     Owner       string    `json:"owner" dynamodbav:"owner"`
@@ -10,4 +18,27 @@ type Account struct {
     CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
     UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"` // Add this field
     AccountType string    `json:"account_type" dynamodbav:"account_type"`
+    Status      string    `json:"status" dynamodbav:"status"`
+    // Timezone is the IANA zone the account's daily and monthly limits
+    // reset in; the statements timezone if empty.
+    Timezone string `json:"timezone,omitempty" dynamodbav:"timezone,omitempty"`
+    // Limits overrides the limits of the account's product.
+    Limits *AccountLimits `json:"limits,omitempty" dynamodbav:"limits,omitempty"`
+    // KYCStatus is how far the customer's identity has been verified.
+    // Accounts stored before verification existed have none and are
+    // treated as unverified.
+    KYCStatus string `json:"kyc_status,omitempty" dynamodbav:"kyc_status,omitempty"`
+}
+
+// IsActive reports whether the account accepts new transactions.
+func (a *Account) IsActive() bool {
+    return a.Status == "" || a.Status == AccountStatusActive
+}
+
+// KYCLevel returns the account's KYC status, unverified if it has none.
+func (a *Account) KYCLevel() string {
+    if a.KYCStatus == "" {
+        return KYCUnverified
+    }
+    return a.KYCStatus
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AccountChange records one field of an account changing value.
+type AccountChange struct {
+	Field string `json:"field" dynamodbav:"field"`
+	Old   string `json:"old" dynamodbav:"old"`
+	New   string `json:"new" dynamodbav:"new"`
+	// Actor is the authenticated subject, or the operator for CLI changes.
+	Actor     string    `json:"actor,omitempty" dynamodbav:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty" dynamodbav:"request_id,omitempty"`
+	ChangedAt time.Time `json:"changed_at" dynamodbav:"changed_at"`
+	// Reason explains a change made other than by editing the account, such
+	// as a balance corrected by reconciliation.
+	Reason string `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+}
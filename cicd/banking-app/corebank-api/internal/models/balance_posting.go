@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// BalancePosting records a completed transaction applied to its account's
+// stored balance. It is keyed by the transaction's ID, so a transaction is
+// applied once however often it is completed.
+//
+// An adjustment, such as a reconciliation correction, is posted the same way
+// under its own ID. The completed transactions whose amounts it includes are
+// posted with it, each marked as covered by it, so none is applied again.
+type BalancePosting struct {
+	// ID is the transaction's ID, or the adjustment's.
+	ID        string `json:"id" dynamodbav:"id"`
+	AccountID string `json:"account_id" dynamodbav:"account_id"`
+	// Amount is what the transaction added to the balance, negative for a
+	// withdrawal.
+	Amount float64 `json:"amount" dynamodbav:"amount"`
+	// Balance is the account's balance once the transaction was applied.
+	Balance  float64   `json:"balance" dynamodbav:"balance"`
+	PostedAt time.Time `json:"posted_at" dynamodbav:"posted_at"`
+	// Covers lists, on an adjustment, the transactions it applied.
+	Covers []string `json:"covers,omitempty" dynamodbav:"covers,omitempty"`
+	// CoveredBy is, on a transaction applied by an adjustment, the
+	// adjustment's ID; its Amount was not added again.
+	CoveredBy string `json:"covered_by,omitempty" dynamodbav:"covered_by,omitempty"`
+}
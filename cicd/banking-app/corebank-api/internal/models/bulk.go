@@ -0,0 +1,30 @@
+package models
+
+// Outcomes of one row of a bulk account import.
+const (
+	BulkRowValid   = "valid"
+	BulkRowInvalid = "invalid"
+	BulkRowCreated = "created"
+	BulkRowFailed  = "failed"
+)
+
+// BulkImportResult reports what a bulk account import did, or in a dry run
+// would do, with each row.
+type BulkImportResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Valid   int             `json:"valid"`
+	Invalid int             `json:"invalid"`
+	Created int             `json:"created"`
+	Failed  int             `json:"failed"`
+	Rows    []BulkImportRow `json:"rows"`
+}
+
+// BulkImportRow is the outcome of one input row. Line is its line number in
+// the uploaded file.
+type BulkImportRow struct {
+	Line      int      `json:"line"`
+	Status    string   `json:"status"`
+	AccountID string   `json:"account_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Hold statuses. Only active holds reserve funds.
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// Hold reserves part of an account's balance, e.g. for a card
+// authorization, until it is captured into a transaction, released or
+// expires.
+type Hold struct {
+	ID          string        `json:"id" dynamodbav:"id"`
+	AccountID   string        `json:"account_id" dynamodbav:"account_id"`
+	Amount      float64       `json:"amount" dynamodbav:"amount"`
+	Captured    float64       `json:"captured" dynamodbav:"captured"`
+	Reference   string        `json:"reference,omitempty" dynamodbav:"reference,omitempty"`
+	Description string        `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Status      string        `json:"status" dynamodbav:"status"`
+	Captures    []HoldCapture `json:"captures" dynamodbav:"captures"`
+	ExpiresAt   time.Time     `json:"expires_at" dynamodbav:"expires_at"`
+	CreatedAt   time.Time     `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// Remaining is the part of the hold that is still reserved.
+func (h *Hold) Remaining() float64 {
+	if h.Status != HoldActive {
+		return 0
+	}
+	return math.Round((h.Amount-h.Captured)*100) / 100
+}
+
+// HoldCapture is part of a hold converted into a withdrawal. TransactionID
+// is set once the transaction service has recorded it.
+type HoldCapture struct {
+	Amount        float64   `json:"amount" dynamodbav:"amount"`
+	OutboxID      string    `json:"outbox_id" dynamodbav:"outbox_id"`
+	TransactionID string    `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	CapturedAt    time.Time `json:"captured_at" dynamodbav:"captured_at"`
+}
+
+// Balance is an account's balance as of now. The ledger balance is the
+// balance stored on the account, which completed transactions are posted
+// to; the available balance deducts pending debits and what active holds
+// reserve. Pending credits count only once they complete.
+type Balance struct {
+	AccountID        string  `json:"account_id"`
+	LedgerBalance    float64 `json:"ledger_balance"`
+	Pending          float64 `json:"pending"`
+	PendingDebits    float64 `json:"pending_debits"`
+	Held             float64 `json:"held"`
+	AvailableBalance float64 `json:"available_balance"`
+}
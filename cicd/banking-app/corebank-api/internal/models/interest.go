@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// InterestAccrual is the interest an account earned on one day. Amount is
+// kept unrounded; a period's accruals are summed and rounded to cents when
+// the interest is paid.
+type InterestAccrual struct {
+	ID          string `json:"-" dynamodbav:"id"`
+	AccountID   string `json:"account_id" dynamodbav:"account_id"`
+	Date        string `json:"date" dynamodbav:"date"`
+	Period      string `json:"period" dynamodbav:"period"`
+	AccountType string `json:"account_type" dynamodbav:"account_type"`
+	// Balance is the balance interest was earned on: the closing balance of
+	// the day, plus interest accrued earlier in the period when it
+	// compounds daily.
+	Balance   float64   `json:"balance" dynamodbav:"balance"`
+	Amount    float64   `json:"amount" dynamodbav:"amount"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+// InterestPosting records the interest paid to an account for a period.
+// Replaying accruals for a paid period posts the difference as a correction
+// and adds its outbox entry here.
+type InterestPosting struct {
+	ID        string    `json:"-" dynamodbav:"id"`
+	AccountID string    `json:"account_id" dynamodbav:"account_id"`
+	Period    string    `json:"period" dynamodbav:"period"`
+	Amount    float64   `json:"amount" dynamodbav:"amount"`
+	OutboxIDs []string  `json:"outbox_ids" dynamodbav:"outbox_ids"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
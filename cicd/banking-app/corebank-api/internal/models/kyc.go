@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// KYC statuses. A customer is unverified until they submit their identity,
+// pending until an admin decides on it, and then verified or rejected.
+const (
+	KYCUnverified = "unverified"
+	KYCPending    = "pending"
+	KYCVerified   = "verified"
+	KYCRejected   = "rejected"
+)
+
+// Identity document types a KYC submission may carry.
+const (
+	KYCDocumentPassport       = "passport"
+	KYCDocumentNationalID     = "national_id"
+	KYCDocumentDrivingLicence = "driving_licence"
+)
+
+// KYCSubmission is the identity a customer submits for verification.
+type KYCSubmission struct {
+	LegalName string `json:"legal_name"`
+	// DateOfBirth is YYYY-MM-DD.
+	DateOfBirth string `json:"date_of_birth"`
+	// Nationality is an ISO 3166-1 alpha-2 country code.
+	Nationality    string `json:"nationality"`
+	DocumentType   string `json:"document_type"`
+	DocumentNumber string `json:"document_number"`
+	Address        string `json:"address"`
+}
+
+// KYCDecision is an admin's verdict on a KYC submission.
+type KYCDecision struct {
+	// Decision is KYCVerified or KYCRejected.
+	Decision string `json:"decision"`
+	// Reason is required to reject a submission.
+	Reason string `json:"reason,omitempty"`
+}
+
+// KYCRecord is an account's latest KYC submission and the decision on it.
+// It is stored under the account's ID.
+type KYCRecord struct {
+	AccountID      string `json:"account_id" dynamodbav:"id"`
+	Status         string `json:"status" dynamodbav:"status"`
+	LegalName      string `json:"legal_name" dynamodbav:"legal_name"`
+	DateOfBirth    string `json:"date_of_birth" dynamodbav:"date_of_birth"`
+	Nationality    string `json:"nationality" dynamodbav:"nationality"`
+	DocumentType   string `json:"document_type" dynamodbav:"document_type"`
+	DocumentNumber string `json:"document_number" dynamodbav:"document_number"`
+	Address        string `json:"address" dynamodbav:"address"`
+	SubmittedBy    string `json:"submitted_by,omitempty" dynamodbav:"submitted_by,omitempty"`
+	// SubmittedAt is nil for an account that has submitted nothing.
+	SubmittedAt *time.Time `json:"submitted_at,omitempty" dynamodbav:"submitted_at,omitempty"`
+	DecidedBy   string     `json:"decided_by,omitempty" dynamodbav:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty" dynamodbav:"decided_at,omitempty"`
+	Reason      string     `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+}
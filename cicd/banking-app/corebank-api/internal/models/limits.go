@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// AccountLimits overrides the limits of an account's product. A nil limit
+// follows the product and a zero one removes it.
+type AccountLimits struct {
+	MaxTransactionAmount *float64 `json:"max_transaction_amount,omitempty" dynamodbav:"max_transaction_amount,omitempty"`
+	DailyOutflow         *float64 `json:"daily_outflow,omitempty" dynamodbav:"daily_outflow,omitempty"`
+	MonthlyOutflow       *float64 `json:"monthly_outflow,omitempty" dynamodbav:"monthly_outflow,omitempty"`
+}
+
+// Limit periods, the calendar days and months outflow is counted over in
+// the account's timezone.
+const (
+	LimitPeriodDay   = "day"
+	LimitPeriodMonth = "month"
+)
+
+// LimitCounter is an account's outflow in one calendar period. Its ID
+// combines the account, period and start, so each period starts a new
+// counter.
+type LimitCounter struct {
+	ID        string `json:"id" dynamodbav:"id"`
+	AccountID string `json:"account_id" dynamodbav:"account_id"`
+	Period    string `json:"period" dynamodbav:"period"`
+	// Start is the period's first day, YYYY-MM-DD, or month, YYYY-MM.
+	Start string  `json:"start" dynamodbav:"start"`
+	Used  float64 `json:"used" dynamodbav:"used"`
+}
+
+// LimitStatus is an account's limits and what remains of them. Limits of
+// zero are not enforced.
+type LimitStatus struct {
+	AccountID string `json:"account_id"`
+	// Timezone is the zone days and months are taken in.
+	Timezone string `json:"timezone"`
+	// KYCStatus is the verification level whose limits, if any, cap the
+	// product's.
+	KYCStatus            string      `json:"kyc_status"`
+	MaxTransactionAmount float64     `json:"max_transaction_amount"`
+	Daily                LimitWindow `json:"daily"`
+	Monthly              LimitWindow `json:"monthly"`
+	// Overrides are the account's own limits, if any.
+	Overrides *AccountLimits `json:"overrides,omitempty"`
+}
+
+// LimitWindow is the outflow of the current day or month against its limit.
+type LimitWindow struct {
+	Limit float64 `json:"limit"`
+	Used  float64 `json:"used"`
+	// Remaining is absent when there is no limit.
+	Remaining *float64  `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
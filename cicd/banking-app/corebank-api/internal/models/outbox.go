@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Outbox entry statuses.
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+)
+
+// Outbox entry sources.
+const (
+	OutboxSourceInitialDeposit = "initial_deposit"
+	OutboxSourceAdjustment     = "adjustment"
+	OutboxSourceInterest       = "interest"
+	OutboxSourceFee            = "fee"
+	OutboxSourceHoldCapture    = "hold_capture"
+)
+
+// OutboxEntry is a transaction that must reach the transaction service. It
+// is stored before delivery is attempted, so a failed call can be redriven,
+// and kept afterwards as a record of who posted it and why.
+type OutboxEntry struct {
+	ID          string      `json:"id" dynamodbav:"id"`
+	Source      string      `json:"source" dynamodbav:"source"`
+	Transaction Transaction `json:"transaction" dynamodbav:"transaction"`
+	Reason      string      `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+	Actor       string      `json:"actor,omitempty" dynamodbav:"actor,omitempty"`
+	Status      string      `json:"status" dynamodbav:"status"`
+	Attempts    int         `json:"attempts" dynamodbav:"attempts"`
+	LastError   string      `json:"last_error,omitempty" dynamodbav:"last_error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" dynamodbav:"updated_at"`
+	// Complete is whether the transaction is completed and applied to its
+	// account's balance once the transaction service has recorded it.
+	Complete bool `json:"complete,omitempty" dynamodbav:"complete,omitempty"`
+}
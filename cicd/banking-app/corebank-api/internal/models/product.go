@@ -0,0 +1,18 @@
+package models
+
+// Product is an account type and the rules accounts of that type follow.
+// Amounts of zero disable the rule.
+type Product struct {
+	AccountType string `json:"account_type"`
+	// OverdraftLimit is how far below MinimumBalance a withdrawal or
+	// transfer may take the balance.
+	OverdraftLimit       float64 `json:"overdraft_limit"`
+	MinimumBalance       float64 `json:"minimum_balance"`
+	InitialDeposit       float64 `json:"initial_deposit"`
+	MonthlyFee           float64 `json:"monthly_fee"`
+	MaxTransactionAmount float64 `json:"max_transaction_amount"`
+	// DailyOutflowLimit and MonthlyOutflowLimit cap what withdrawals and
+	// transfers take from an account per calendar day and month.
+	DailyOutflowLimit   float64 `json:"daily_outflow_limit"`
+	MonthlyOutflowLimit float64 `json:"monthly_outflow_limit"`
+}
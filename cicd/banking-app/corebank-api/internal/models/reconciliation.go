@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Reconciliation is the report of one run checking each account's stored
+// balance against the balance of its completed transactions in the
+// transaction service's ledger.
+type Reconciliation struct {
+	ID string `json:"id" dynamodbav:"id"`
+	// Correct is whether differing balances were adjusted to the ledger's.
+	Correct bool `json:"correct" dynamodbav:"correct"`
+	// RequestedBy is the admin or operator who ran it; empty for the job.
+	RequestedBy string `json:"requested_by,omitempty" dynamodbav:"requested_by,omitempty"`
+	// Checked counts the accounts compared and Failed those whose ledger
+	// could not be read.
+	Checked int `json:"checked" dynamodbav:"checked"`
+	Failed  int `json:"failed" dynamodbav:"failed"`
+	// Discrepancies are the accounts whose balances differ.
+	Discrepancies []Discrepancy `json:"discrepancies" dynamodbav:"discrepancies"`
+	StartedAt     time.Time     `json:"started_at" dynamodbav:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at" dynamodbav:"finished_at"`
+}
+
+// Discrepancy is an account whose stored balance differs from its ledger
+// balance.
+type Discrepancy struct {
+	AccountID string  `json:"account_id" dynamodbav:"account_id"`
+	Recorded  float64 `json:"recorded" dynamodbav:"recorded"`
+	Expected  float64 `json:"expected" dynamodbav:"expected"`
+	// Difference is Expected less Recorded.
+	Difference float64 `json:"difference" dynamodbav:"difference"`
+	// Pending is the net amount of the account's pending transactions,
+	// which count towards neither balance.
+	Pending   float64 `json:"pending" dynamodbav:"pending"`
+	Corrected bool    `json:"corrected" dynamodbav:"corrected"`
+	// Covered are the completed transactions the correction applied, which
+	// had never been applied to the balance.
+	Covered []string `json:"covered,omitempty" dynamodbav:"covered,omitempty"`
+	// Error is why a correction was not made.
+	Error string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
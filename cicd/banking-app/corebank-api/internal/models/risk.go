@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Risk decisions, from least to most severe. A transaction's decision is
+// the most severe of its rules'.
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskDeny   = "deny"
+)
+
+// RiskDecision records the risk rules' verdict on a submitted transaction.
+// Its ID is the transaction's once the transaction is recorded, so the
+// decision on a transaction can be looked up by the transaction's ID; a
+// denied transaction, never recorded, has a generated ID.
+type RiskDecision struct {
+	ID            string  `json:"id" dynamodbav:"id"`
+	TransactionID string  `json:"transaction_id,omitempty" dynamodbav:"transaction_id,omitempty"`
+	AccountID     string  `json:"account_id" dynamodbav:"account_id"`
+	Type          string  `json:"type" dynamodbav:"type"`
+	Amount        float64 `json:"amount" dynamodbav:"amount"`
+	// Decision is allow, review or deny.
+	Decision string           `json:"decision" dynamodbav:"decision"`
+	Rules    []RiskRuleResult `json:"rules" dynamodbav:"rules"`
+	// Actor is the authenticated subject who submitted the transaction.
+	Actor     string    `json:"actor,omitempty" dynamodbav:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty" dynamodbav:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+// RiskRuleResult is one rule's verdict and, unless it allowed the
+// transaction, why.
+type RiskRuleResult struct {
+	Rule     string `json:"rule" dynamodbav:"rule"`
+	Decision string `json:"decision" dynamodbav:"decision"`
+	Reason   string `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Schedule statuses. Only active schedules are executed.
+const (
+	ScheduleActive    = "active"
+	ScheduleCancelled = "cancelled"
+	ScheduleCompleted = "completed"
+)
+
+// Schedule execution statuses. A running execution has been started but its
+// outcome is not yet known.
+const (
+	ExecutionRunning   = "running"
+	ExecutionSucceeded = "succeeded"
+	ExecutionRetrying  = "retrying"
+	ExecutionFailed    = "failed"
+)
+
+// Schedule is a transfer to execute once at StartAt or, with a recurrence,
+// on every occurrence from StartAt on, such as rent on the 1st of every
+// month.
+type Schedule struct {
+	ID            string  `json:"id" dynamodbav:"id"`
+	FromAccountID string  `json:"from_account_id" dynamodbav:"from_account_id"`
+	ToAccountID   string  `json:"to_account_id" dynamodbav:"to_account_id"`
+	Amount        float64 `json:"amount" dynamodbav:"amount"`
+	Description   string  `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	// Recurrence is an RRULE such as FREQ=MONTHLY;BYMONTHDAY=1; empty for
+	// a one-off transfer.
+	Recurrence string    `json:"recurrence,omitempty" dynamodbav:"recurrence,omitempty"`
+	StartAt    time.Time `json:"start_at" dynamodbav:"start_at"`
+	Status     string    `json:"status" dynamodbav:"status"`
+	// NextOccurrence is the occurrence due next and NextAttemptAt when it is
+	// next tried, later than the occurrence while retrying. Both are unset
+	// once the schedule is no longer active.
+	NextOccurrence *time.Time `json:"next_occurrence,omitempty" dynamodbav:"next_occurrence,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" dynamodbav:"next_attempt_at,omitempty"`
+	// Attempts counts the failed attempts at NextOccurrence so far.
+	Attempts int `json:"attempts" dynamodbav:"attempts"`
+	// Occurrences counts the occurrences executed or given up.
+	Occurrences int       `json:"occurrences" dynamodbav:"occurrences"`
+	CreatedBy   string    `json:"created_by,omitempty" dynamodbav:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// ScheduleExecution is one attempt at an occurrence of a schedule.
+type ScheduleExecution struct {
+	ID           string     `json:"id" dynamodbav:"id"`
+	ScheduleID   string     `json:"schedule_id" dynamodbav:"schedule_id"`
+	Occurrence   time.Time  `json:"occurrence" dynamodbav:"occurrence"`
+	Attempt      int        `json:"attempt" dynamodbav:"attempt"`
+	Status       string     `json:"status" dynamodbav:"status"`
+	Error        string     `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	WithdrawalID string     `json:"withdrawal_id,omitempty" dynamodbav:"withdrawal_id,omitempty"`
+	DepositID    string     `json:"deposit_id,omitempty" dynamodbav:"deposit_id,omitempty"`
+	StartedAt    time.Time  `json:"started_at" dynamodbav:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty" dynamodbav:"finished_at,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Statement summarises an account's settled transactions over a period.
+// Balances are derived from completed transactions only: deposits credit the
+// account, withdrawals and transfers debit it.
+type Statement struct {
+	// ID keys statements stored by the month-end job: the account ID and
+	// period, e.g. "<account>#2024-05".
+	ID        string `json:"-" dynamodbav:"id"`
+	AccountID string `json:"account_id" dynamodbav:"account_id"`
+	Owner     string `json:"owner" dynamodbav:"owner"`
+	// Period is the calendar month, e.g. "2024-05", of a monthly statement.
+	Period         string          `json:"period,omitempty" dynamodbav:"period,omitempty"`
+	From           time.Time       `json:"from" dynamodbav:"from"`
+	To             time.Time       `json:"to" dynamodbav:"to"`
+	OpeningBalance float64         `json:"opening_balance" dynamodbav:"opening_balance"`
+	TotalCredits   float64         `json:"total_credits" dynamodbav:"total_credits"`
+	TotalDebits    float64         `json:"total_debits" dynamodbav:"total_debits"`
+	ClosingBalance float64         `json:"closing_balance" dynamodbav:"closing_balance"`
+	Lines          []StatementLine `json:"lines" dynamodbav:"lines"`
+	GeneratedAt    time.Time       `json:"generated_at" dynamodbav:"generated_at"`
+}
+
+// StatementLine is one transaction on a statement. Amount is signed:
+// negative for debits.
+type StatementLine struct {
+	Date          time.Time `json:"date" dynamodbav:"date"`
+	TransactionID string    `json:"transaction_id" dynamodbav:"transaction_id"`
+	Type          string    `json:"type" dynamodbav:"type"`
+	Description   string    `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Amount        float64   `json:"amount" dynamodbav:"amount"`
+	Balance       float64   `json:"balance" dynamodbav:"balance"`
+}
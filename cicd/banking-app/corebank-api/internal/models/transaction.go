@@ -10,4 +10,6 @@ type Transaction struct {
 	Description string    `json:"description" dynamodbav:"description"`
 	Status      string    `json:"status" dynamodbav:"status"` // "pending", "completed", "failed"
 	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+	// ProcessedAt is set by the transaction service when the status changes
+	ProcessedAt *time.Time `json:"processed_at,omitempty" dynamodbav:"processed_at,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package models
+
+// Transfer moves money between two accounts. The transaction service has no
+// transfer of its own, so it is recorded as a withdrawal from the source
+// account and a deposit to the destination, both pending.
+type Transfer struct {
+	FromAccountID string       `json:"from_account_id"`
+	ToAccountID   string       `json:"to_account_id"`
+	Amount        float64      `json:"amount"`
+	Description   string       `json:"description,omitempty"`
+	Withdrawal    *Transaction `json:"withdrawal,omitempty"`
+	Deposit       *Transaction `json:"deposit,omitempty"`
+}
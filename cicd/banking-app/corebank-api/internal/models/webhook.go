@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types webhook subscribers can receive.
+const (
+	EventAccountCreated    = "account.created"
+	EventAccountUpdated    = "account.updated"
+	EventAccountClosed     = "account.closed"
+	EventAccountDeleted    = "account.deleted"
+	EventTransactionPosted = "transaction.posted"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []string{
+	EventAccountCreated,
+	EventAccountUpdated,
+	EventAccountClosed,
+	EventAccountDeleted,
+	EventTransactionPosted,
+}
+
+// Webhook delivery statuses. Pending deliveries are attempted, and retried,
+// until they succeed or run out of attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends the events of the given types to URL.
+type WebhookSubscription struct {
+	ID  string `json:"id" dynamodbav:"id"`
+	URL string `json:"url" dynamodbav:"url"`
+	// EventTypes holds the types sent, or "*" for every type.
+	EventTypes []string `json:"event_types" dynamodbav:"event_types"`
+	// Secret signs every delivery. It is only returned when the
+	// subscription is created.
+	Secret      string    `json:"secret,omitempty" dynamodbav:"secret"`
+	Description string    `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	CreatedBy   string    `json:"created_by,omitempty" dynamodbav:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// Subscribes reports whether the subscription receives events of the given
+// type.
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the body of every webhook delivery. Redeliveries of an event
+// keep its ID, so receivers can ignore ones they have already handled.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription.
+type WebhookDelivery struct {
+	ID             string `json:"id" dynamodbav:"id"`
+	SubscriptionID string `json:"subscription_id" dynamodbav:"subscription_id"`
+	EventID        string `json:"event_id" dynamodbav:"event_id"`
+	EventType      string `json:"event_type" dynamodbav:"event_type"`
+	URL            string `json:"url" dynamodbav:"url"`
+	// Payload is the Event exactly as it is signed and sent.
+	Payload json.RawMessage `json:"payload" dynamodbav:"payload"`
+	Status  string          `json:"status" dynamodbav:"status"`
+	// Attempts counts the attempts made so far; NextAttemptAt is when a
+	// pending delivery is next attempted.
+	Attempts       int        `json:"attempts" dynamodbav:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" dynamodbav:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty" dynamodbav:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty" dynamodbav:"last_error,omitempty"`
+	// RedeliveryOf is the delivery this one was manually redelivered from.
+	RedeliveryOf string     `json:"redelivery_of,omitempty" dynamodbav:"redelivery_of,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" dynamodbav:"updated_at"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty" dynamodbav:"delivered_at,omitempty"`
+}
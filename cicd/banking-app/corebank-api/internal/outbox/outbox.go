@@ -0,0 +1,236 @@
+// Package outbox delivers transactions to the transaction service at least
+// once. Each transaction is stored before the call is made, so one that
+// fails can be redriven later instead of being lost.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// Store persists outbox entries. repository.OutboxRepository implements it
+// against DynamoDB.
+type Store interface {
+	Put(ctx context.Context, entry *models.OutboxEntry) error
+	// Create stores a new entry and reports false if one with its ID
+	// already exists.
+	Create(ctx context.Context, entry *models.OutboxEntry) (bool, error)
+	// Get returns nil and no error when the entry does not exist.
+	Get(ctx context.Context, id string) (*models.OutboxEntry, error)
+	// List returns entries with the given status, or all when it is empty,
+	// oldest first.
+	List(ctx context.Context, status string) ([]models.OutboxEntry, error)
+}
+
+// Poster applies completed transactions to account balances;
+// posting.Poster implements it.
+type Poster interface {
+	Post(ctx context.Context, txn *models.Transaction, actor string) (*posting.Result, error)
+}
+
+type Outbox struct {
+	store        Store
+	transactions *upstream.TransactionService
+	poster       Poster
+	delivered    []func(ctx context.Context, entry *models.OutboxEntry)
+}
+
+func New(store Store, transactions *upstream.TransactionService) *Outbox {
+	return &Outbox{store: store, transactions: transactions}
+}
+
+// OnDelivered registers fn to be called with each entry once its
+// transaction has been posted, e.g. to publish it as an event. It must be
+// called before the outbox is used.
+func (o *Outbox) OnDelivered(fn func(ctx context.Context, entry *models.OutboxEntry)) {
+	o.delivered = append(o.delivered, fn)
+}
+
+// SetPoster has entries marked Complete completed and applied to their
+// account's balance by poster once delivered. It must be called before the
+// outbox is used.
+func (o *Outbox) SetPoster(poster Poster) {
+	o.poster = poster
+}
+
+// Submit stores entry as pending, then tries to deliver it. It only fails if
+// the entry cannot be stored; a failed delivery leaves the entry pending
+// with LastError set, for Redrive to retry.
+func (o *Outbox) Submit(ctx context.Context, entry *models.OutboxEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	prepare(entry)
+	if err := o.store.Put(ctx, entry); err != nil {
+		return err
+	}
+	o.deliverNow(ctx, entry)
+	return nil
+}
+
+// SubmitOnce is Submit for an entry with a deterministic ID, such as a
+// charge that must be posted once per period. It reports false, and posts
+// nothing, if an entry with that ID was already submitted.
+func (o *Outbox) SubmitOnce(ctx context.Context, entry *models.OutboxEntry) (bool, error) {
+	prepare(entry)
+	created, err := o.store.Create(ctx, entry)
+	if err != nil || !created {
+		return false, err
+	}
+	o.deliverNow(ctx, entry)
+	return true, nil
+}
+
+func prepare(entry *models.OutboxEntry) {
+	now := time.Now().UTC()
+	entry.Status = models.OutboxPending
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+}
+
+// SubmitInitialDeposit subscribes to AccountCreated events and submits the
+// new account's initial deposit, if it has one.
+func (o *Outbox) SubmitInitialDeposit(ctx context.Context, event events.Event) error {
+	if event.InitialDeposit <= 0 {
+		return nil
+	}
+	err := o.Submit(ctx, &models.OutboxEntry{
+		Source: models.OutboxSourceInitialDeposit,
+		Actor:  event.Actor,
+		Transaction: models.Transaction{
+			AccountID: event.AccountID,
+			Amount:    event.InitialDeposit,
+			Type:      "deposit",
+			Status:    "pending",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record initial deposit: %w", err)
+	}
+	return nil
+}
+
+// deliverNow attempts delivery of a newly stored entry, leaving it for
+// Redrive if that fails.
+func (o *Outbox) deliverNow(ctx context.Context, entry *models.OutboxEntry) {
+	if err := o.Deliver(ctx, entry); err != nil {
+		logging.FromContext(ctx).Warn("transaction delivery deferred",
+			"outbox_id", entry.ID, "account_id", entry.Transaction.AccountID, "error", err)
+	}
+}
+
+// Deliver posts a pending entry's transaction, completing it if the entry
+// is marked Complete, and records the outcome on the entry. It returns the
+// delivery error, if any.
+func (o *Outbox) Deliver(ctx context.Context, entry *models.OutboxEntry) error {
+	if entry.Status == models.OutboxDelivered {
+		return nil
+	}
+
+	entry.Attempts++
+	err := o.deliver(ctx, entry)
+	if err != nil {
+		entry.LastError = err.Error()
+	} else {
+		entry.Status = models.OutboxDelivered
+		entry.LastError = ""
+		for _, fn := range o.delivered {
+			fn(ctx, entry)
+		}
+	}
+	entry.UpdatedAt = time.Now().UTC()
+
+	if perr := o.store.Put(ctx, entry); perr != nil {
+		if entry.Transaction.ID != "" {
+			// The transaction was posted; redriving this entry would post it
+			// again, so make the failure loud
+			logging.FromContext(ctx).Error("delivered outbox entry could not be marked",
+				"outbox_id", entry.ID, "transaction_id", entry.Transaction.ID, "error", perr)
+		}
+		return fmt.Errorf("failed to update outbox entry: %w", perr)
+	}
+	return err
+}
+
+// deliver posts the entry's transaction, unless an earlier attempt already
+// did and only failed to complete it, and completes it if the entry is
+// marked Complete. A transaction that cannot be applied to its balance is
+// put back to pending for the next attempt to complete.
+func (o *Outbox) deliver(ctx context.Context, entry *models.OutboxEntry) error {
+	if entry.Transaction.ID == "" {
+		created, err := o.transactions.Create(ctx, entry.Transaction)
+		if err != nil {
+			return err
+		}
+		entry.Transaction = *created
+	}
+	if !entry.Complete {
+		return nil
+	}
+	if o.poster == nil {
+		return errors.New("outbox has no poster to complete transactions with")
+	}
+
+	id := entry.Transaction.ID
+	completed, err := o.transactions.UpdateStatus(ctx, id, "completed")
+	if err != nil {
+		return fmt.Errorf("failed to complete transaction %s: %w", id, err)
+	}
+	entry.Transaction = *completed
+	if _, err := o.poster.Post(ctx, completed, entry.Actor); err != nil {
+		if undone, uerr := o.transactions.UpdateStatus(ctx, id, "pending"); uerr != nil {
+			logging.FromContext(ctx).Error("failed to undo completion of unposted transaction", "outbox_id", entry.ID,
+				"transaction_id", id, "error", uerr)
+		} else {
+			entry.Transaction = *undone
+		}
+		return fmt.Errorf("failed to post transaction %s: %w", id, err)
+	}
+	return nil
+}
+
+// Redrive retries the given pending entries, or every pending entry when no
+// IDs are given, and returns them with their new state.
+func (o *Outbox) Redrive(ctx context.Context, ids ...string) ([]models.OutboxEntry, error) {
+	var entries []models.OutboxEntry
+	if len(ids) == 0 {
+		pending, err := o.store.List(ctx, models.OutboxPending)
+		if err != nil {
+			return nil, err
+		}
+		entries = pending
+	} else {
+		for _, id := range ids {
+			entry, err := o.store.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if entry == nil {
+				return nil, fmt.Errorf("outbox entry %s not found", id)
+			}
+			entries = append(entries, *entry)
+		}
+	}
+
+	for i := range entries {
+		if err := o.Deliver(ctx, &entries[i]); err != nil {
+			logging.FromContext(ctx).Warn("redrive failed", "outbox_id", entries[i].ID, "error", err)
+		}
+	}
+	return entries, nil
+}
+
+// List returns entries with the given status, or all when it is empty.
+func (o *Outbox) List(ctx context.Context, status string) ([]models.OutboxEntry, error) {
+	return o.store.List(ctx, status)
+}
@@ -0,0 +1,220 @@
+// Package posting applies completed transactions to the balances stored on
+// accounts. Each transaction is applied once, keyed by its ID, however many
+// times it is completed.
+package posting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/metrics"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+)
+
+// ErrAccountNotFound is returned when a transaction's account does not
+// exist.
+var ErrAccountNotFound = errors.New("account not found")
+
+// maxAttempts bounds the retries when an account's balance changes between
+// reading and writing it.
+const maxAttempts = 5
+
+// Store reads accounts and applies transactions to their balances;
+// repository.AccountRepository implements it.
+type Store interface {
+	// GetByID returns nil and no error when the account does not exist.
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+	// PostTransaction moves the balance from old to posting.Balance,
+	// failing with repository.ErrAlreadyPosted if the transaction was
+	// applied before and repository.ErrConflict if the balance is no longer
+	// old.
+	PostTransaction(ctx context.Context, posting *models.BalancePosting, old float64, change models.AccountChange) error
+	// PostAdjustment is PostTransaction for an adjustment, storing the
+	// postings of the transactions it covers with it.
+	PostAdjustment(ctx context.Context, posting *models.BalancePosting, covered []models.BalancePosting, old float64, change models.AccountChange) error
+	// GetPosting returns nil and no error when the transaction has not been
+	// applied.
+	GetPosting(ctx context.Context, id string) (*models.BalancePosting, error)
+}
+
+// Holds reports what an account's active holds reserve; holds.Balances
+// implements it.
+type Holds interface {
+	Held(ctx context.Context, accountID string) (float64, error)
+}
+
+// Result is a transaction applied to its account.
+type Result struct {
+	// Account is the account with its new balance.
+	Account *models.Account
+	Posting *models.BalancePosting
+	// Change is the balance change recorded in the account's history.
+	Change models.AccountChange
+}
+
+// Poster applies completed transactions to account balances.
+type Poster struct {
+	store    Store
+	holds    Holds
+	products *products.Catalog
+}
+
+func NewPoster(store Store, holds Holds, catalog *products.Catalog) *Poster {
+	return &Poster{store: store, holds: holds, products: catalog}
+}
+
+// Post applies a completed transaction to its account's balance and records
+// the change, made by actor, in the account's history. It returns nil and no
+// error if the transaction was applied before. A withdrawal that would take
+// the balance, less what active holds reserve, below what the account's
+// product allows fails with an error matching products.ErrInsufficientFunds
+// and leaves the balance alone.
+func (p *Poster) Post(ctx context.Context, txn *models.Transaction, actor string) (*Result, error) {
+	amount := signedAmount(txn)
+	for range maxAttempts {
+		account, err := p.store.GetByID(ctx, txn.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, txn.AccountID)
+		}
+		if amount < 0 {
+			held, err := p.holds.Held(ctx, account.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read holds: %w", err)
+			}
+			if err := products.CheckDebit(p.products.For(account), round(account.Balance-held), -amount); err != nil {
+				return nil, fmt.Errorf("Account %s: %w", account.ID, err)
+			}
+		}
+
+		now := time.Now().UTC()
+		posting := &models.BalancePosting{
+			ID:        txn.ID,
+			AccountID: account.ID,
+			Amount:    amount,
+			Balance:   round(account.Balance + amount),
+			PostedAt:  now,
+		}
+		change := models.AccountChange{
+			Field:     "balance",
+			Old:       strconv.FormatFloat(account.Balance, 'f', 2, 64),
+			New:       strconv.FormatFloat(posting.Balance, 'f', 2, 64),
+			Actor:     actor,
+			RequestID: logging.RequestID(ctx),
+			ChangedAt: now,
+			Reason:    "transaction " + txn.ID + " completed",
+		}
+		err = p.store.PostTransaction(ctx, posting, account.Balance, change)
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		if errors.Is(err, repository.ErrAlreadyPosted) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		logging.FromContext(ctx).Info("transaction posted", "transaction_id", txn.ID, "account_id", account.ID,
+			"amount", amount, "balance", posting.Balance)
+		if txn.Type == "deposit" {
+			metrics.DepositPosted(txn.Amount)
+		}
+		account.Balance = posting.Balance
+		account.UpdatedAt = now
+		return &Result{Account: account, Posting: posting, Change: change}, nil
+	}
+	return nil, fmt.Errorf("balance of account %s changed %d times while posting transaction %s", txn.AccountID, maxAttempts, txn.ID)
+}
+
+// Adjustment changes an account's stored balance other than by one of its
+// transactions, such as to correct it.
+type Adjustment struct {
+	// ID identifies the adjustment, which is applied once.
+	ID     string
+	Amount float64
+	// Covers are completed transactions whose amounts Amount includes. They
+	// are marked posted with the adjustment, so none is applied again.
+	Covers []models.Transaction
+	// Reason is recorded with the change in the account's history.
+	Reason string
+}
+
+// Adjust applies an adjustment made by actor to account's balance, on
+// condition that it is still the balance account was read with. It fails
+// with repository.ErrConflict if the balance has changed, and with
+// repository.ErrAlreadyPosted if the adjustment or a transaction it covers
+// has been applied. Unlike Post it applies no product rules.
+func (p *Poster) Adjust(ctx context.Context, account *models.Account, adj Adjustment, actor string) (*Result, error) {
+	now := time.Now().UTC()
+	posting := &models.BalancePosting{
+		ID:        adj.ID,
+		AccountID: account.ID,
+		Amount:    round(adj.Amount),
+		Balance:   round(account.Balance + adj.Amount),
+		PostedAt:  now,
+	}
+	covered := make([]models.BalancePosting, 0, len(adj.Covers))
+	for _, txn := range adj.Covers {
+		posting.Covers = append(posting.Covers, txn.ID)
+		covered = append(covered, models.BalancePosting{
+			ID:        txn.ID,
+			AccountID: account.ID,
+			Amount:    signedAmount(&txn),
+			Balance:   posting.Balance,
+			PostedAt:  now,
+			CoveredBy: adj.ID,
+		})
+	}
+	change := models.AccountChange{
+		Field:     "balance",
+		Old:       strconv.FormatFloat(account.Balance, 'f', 2, 64),
+		New:       strconv.FormatFloat(posting.Balance, 'f', 2, 64),
+		Actor:     actor,
+		RequestID: logging.RequestID(ctx),
+		ChangedAt: now,
+		Reason:    adj.Reason,
+	}
+	if err := p.store.PostAdjustment(ctx, posting, covered, account.Balance, change); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("adjustment posted", "adjustment_id", adj.ID, "account_id", account.ID,
+		"amount", posting.Amount, "balance", posting.Balance, "covers", posting.Covers)
+	adjusted := *account
+	adjusted.Balance = posting.Balance
+	adjusted.UpdatedAt = now
+	return &Result{Account: &adjusted, Posting: posting, Change: change}, nil
+}
+
+// Posted reports whether the transaction with the given ID has been applied
+// to its account's balance.
+func (p *Poster) Posted(ctx context.Context, txnID string) (bool, error) {
+	posting, err := p.store.GetPosting(ctx, txnID)
+	if err != nil {
+		return false, err
+	}
+	return posting != nil, nil
+}
+
+// signedAmount is what a transaction adds to its account's balance.
+func signedAmount(txn *models.Transaction) float64 {
+	if txn.Type == "deposit" {
+		return math.Abs(txn.Amount)
+	}
+	return -math.Abs(txn.Amount)
+}
+
+// round keeps balances to whole cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
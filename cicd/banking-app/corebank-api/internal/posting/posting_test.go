@@ -0,0 +1,230 @@
+package posting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+)
+
+// memStore applies postings to accounts in memory with the conditions
+// repository.AccountRepository puts on them.
+type memStore struct {
+	accounts map[string]*models.Account
+	postings map[string]models.BalancePosting
+	changes  []models.AccountChange
+	// conflicts is how many writes fail because another deposit of 10
+	// lands first
+	conflicts int
+}
+
+func newMemStore(accounts ...models.Account) *memStore {
+	s := &memStore{accounts: make(map[string]*models.Account), postings: make(map[string]models.BalancePosting)}
+	for i := range accounts {
+		s.accounts[accounts[i].ID] = &accounts[i]
+	}
+	return s
+}
+
+func (s *memStore) GetByID(_ context.Context, id string) (*models.Account, error) {
+	account, ok := s.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (s *memStore) PostTransaction(ctx context.Context, posting *models.BalancePosting, old float64, change models.AccountChange) error {
+	return s.PostAdjustment(ctx, posting, nil, old, change)
+}
+
+func (s *memStore) PostAdjustment(_ context.Context, posting *models.BalancePosting, covered []models.BalancePosting, old float64, change models.AccountChange) error {
+	account := s.accounts[posting.AccountID]
+	if s.conflicts > 0 {
+		s.conflicts--
+		account.Balance += 10
+		return repository.ErrConflict
+	}
+	for _, p := range append([]models.BalancePosting{*posting}, covered...) {
+		if _, ok := s.postings[p.ID]; ok {
+			return repository.ErrAlreadyPosted
+		}
+	}
+	if account.Balance != old {
+		return repository.ErrConflict
+	}
+	account.Balance = posting.Balance
+	for _, p := range append([]models.BalancePosting{*posting}, covered...) {
+		s.postings[p.ID] = p
+	}
+	s.changes = append(s.changes, change)
+	return nil
+}
+
+func (s *memStore) GetPosting(_ context.Context, id string) (*models.BalancePosting, error) {
+	posting, ok := s.postings[id]
+	if !ok {
+		return nil, nil
+	}
+	return &posting, nil
+}
+
+type fixedHolds float64
+
+func (h fixedHolds) Held(context.Context, string) (float64, error) {
+	return float64(h), nil
+}
+
+func newTestPoster(store Store, held float64) *Poster {
+	catalog := products.NewCatalog([]config.ProductConfig{
+		{AccountType: config.DefaultAccountType},
+		{AccountType: "basic", MinimumBalance: 20, OverdraftLimit: 50},
+	})
+	return NewPoster(store, fixedHolds(held), catalog)
+}
+
+func TestPost(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountType string
+		held        float64
+		txn         models.Transaction
+		conflicts   int
+		want        float64
+		wantErr     error
+	}{
+		{name: "deposit", txn: models.Transaction{Type: "deposit", Amount: 50}, want: 150},
+		{name: "withdrawal", txn: models.Transaction{Type: "withdrawal", Amount: 70}, held: 30, want: 30},
+		{name: "withdrawal into held funds", txn: models.Transaction{Type: "withdrawal", Amount: 80}, held: 30, want: 100,
+			wantErr: products.ErrInsufficientFunds},
+		{name: "withdrawal into the overdraft", accountType: "basic", txn: models.Transaction{Type: "withdrawal", Amount: 130}, want: -30},
+		{name: "withdrawal past the overdraft", accountType: "basic", txn: models.Transaction{Type: "withdrawal", Amount: 130.01}, want: 100,
+			wantErr: products.ErrInsufficientFunds},
+		{name: "retried on a concurrent change", txn: models.Transaction{Type: "deposit", Amount: 50}, conflicts: 2, want: 170},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore(models.Account{ID: "acc", AccountType: tt.accountType, Balance: 100})
+			store.conflicts = tt.conflicts
+			p := newTestPoster(store, tt.held)
+			txn := tt.txn
+			txn.ID, txn.AccountID = "txn-1", "acc"
+
+			result, err := p.Post(context.Background(), &txn, "tester")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if balance := store.accounts["acc"].Balance; balance != tt.want {
+				t.Fatalf("balance = %v, want %v", balance, tt.want)
+			}
+			if tt.wantErr != nil {
+				if len(store.postings) != 0 {
+					t.Fatalf("postings = %v, want none", store.postings)
+				}
+				return
+			}
+			if result.Account.Balance != tt.want || result.Posting.ID != "txn-1" || result.Posting.Balance != tt.want {
+				t.Fatalf("result = %+v %+v, want the transaction posted", result.Account, result.Posting)
+			}
+			if result.Change.Actor != "tester" || !strings.Contains(result.Change.Reason, "txn-1") {
+				t.Fatalf("change = %+v", result.Change)
+			}
+		})
+	}
+}
+
+// A transaction is applied once however often it is posted.
+func TestPostOnce(t *testing.T) {
+	store := newMemStore(models.Account{ID: "acc", Balance: 100})
+	p := newTestPoster(store, 0)
+	txn := &models.Transaction{ID: "txn-1", AccountID: "acc", Type: "deposit", Amount: 50}
+	ctx := context.Background()
+
+	if _, err := p.Post(ctx, txn, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.Post(ctx, txn, "tester")
+	if err != nil || result != nil {
+		t.Fatalf("second post = %+v, %v; want nothing", result, err)
+	}
+	if balance := store.accounts["acc"].Balance; balance != 150 || len(store.changes) != 1 {
+		t.Fatalf("balance %v after %d changes, want 150 after one", balance, len(store.changes))
+	}
+	if posted, err := p.Posted(ctx, "txn-1"); err != nil || !posted {
+		t.Fatalf("Posted = %v, %v", posted, err)
+	}
+	if posted, err := p.Posted(ctx, "txn-2"); err != nil || posted {
+		t.Fatalf("Posted for an unposted transaction = %v, %v", posted, err)
+	}
+}
+
+func TestPostFailures(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPoster(newMemStore(), 0)
+	_, err := p.Post(ctx, &models.Transaction{ID: "txn-1", AccountID: "missing", Type: "deposit", Amount: 1}, "tester")
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("unknown account: got %v, want ErrAccountNotFound", err)
+	}
+
+	store := newMemStore(models.Account{ID: "acc", Balance: 100})
+	store.conflicts = maxAttempts
+	p = newTestPoster(store, 0)
+	_, err = p.Post(ctx, &models.Transaction{ID: "txn-1", AccountID: "acc", Type: "deposit", Amount: 1}, "tester")
+	if err == nil || !strings.Contains(err.Error(), "changed 5 times") {
+		t.Fatalf("constant conflicts: got %v, want the attempts given up", err)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	store := newMemStore(models.Account{ID: "acc", Balance: 100})
+	p := newTestPoster(store, 0)
+	ctx := context.Background()
+	account, _ := store.GetByID(ctx, "acc")
+	covers := []models.Transaction{
+		{ID: "dep-1", AccountID: "acc", Type: "deposit", Amount: 80},
+		{ID: "wd-1", AccountID: "acc", Type: "withdrawal", Amount: 30},
+	}
+
+	// Adjustments apply no product rules, so may take the balance anywhere
+	result, err := p.Adjust(ctx, account, Adjustment{ID: "adj-1", Amount: -150, Covers: covers, Reason: "correction"}, "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Account.Balance != -50 || account.Balance != 100 || store.accounts["acc"].Balance != -50 {
+		t.Fatalf("adjusted %v from %v, stored %v; want -50 from an untouched 100", result.Account.Balance, account.Balance,
+			store.accounts["acc"].Balance)
+	}
+	if got := strings.Join(result.Posting.Covers, ","); got != "dep-1,wd-1" || result.Change.Reason != "correction" {
+		t.Fatalf("posting %+v with change %+v", result.Posting, result.Change)
+	}
+	if covered := store.postings["wd-1"]; covered.CoveredBy != "adj-1" || covered.Amount != -30 {
+		t.Fatalf("covered posting = %+v, want the withdrawal marked covered", covered)
+	}
+
+	// Covered transactions are not applied again when they are posted
+	if result, err := p.Post(ctx, &covers[0], "tester"); err != nil || result != nil {
+		t.Fatalf("posting a covered transaction = %+v, %v; want nothing", result, err)
+	}
+
+	// An adjustment read with a stale balance, or applied twice, is refused
+	if _, err := p.Adjust(ctx, account, Adjustment{ID: "adj-2", Amount: 10}, "ops"); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("stale balance: got %v, want ErrConflict", err)
+	}
+	account, _ = store.GetByID(ctx, "acc")
+	if _, err := p.Adjust(ctx, account, Adjustment{ID: "adj-1", Amount: 10}, "ops"); !errors.Is(err, repository.ErrAlreadyPosted) {
+		t.Fatalf("repeated adjustment: got %v, want ErrAlreadyPosted", err)
+	}
+	cover := Adjustment{ID: "adj-3", Amount: 80, Covers: covers[:1]}
+	if _, err := p.Adjust(ctx, account, cover, "ops"); !errors.Is(err, repository.ErrAlreadyPosted) {
+		t.Fatalf("covering a posted transaction: got %v, want ErrAlreadyPosted", err)
+	}
+	if store.accounts["acc"].Balance != -50 || len(store.changes) != 1 {
+		t.Fatalf("balance %v after %d changes, want only the first adjustment", store.accounts["acc"].Balance, len(store.changes))
+	}
+}
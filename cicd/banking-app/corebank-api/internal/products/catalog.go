@@ -0,0 +1,109 @@
+// Package products is the catalog of account types. It enforces each type's
+// rules on transactions and charges its monthly fee.
+package products
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+)
+
+// Rule violations returned by CheckAmount, CheckBalance and CheckDebit.
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrLimitExceeded     = errors.New("transaction limit exceeded")
+)
+
+// Catalog holds the products accounts may be opened with.
+type Catalog struct {
+	products map[string]models.Product
+	list     []models.Product
+}
+
+// NewCatalog builds the catalog from validated configuration, which always
+// includes the default account type.
+func NewCatalog(cfg []config.ProductConfig) *Catalog {
+	c := &Catalog{products: make(map[string]models.Product, len(cfg))}
+	for _, p := range cfg {
+		product := models.Product{
+			AccountType:          p.AccountType,
+			OverdraftLimit:       p.OverdraftLimit,
+			MinimumBalance:       p.MinimumBalance,
+			InitialDeposit:       p.InitialDeposit,
+			MonthlyFee:           p.MonthlyFee,
+			MaxTransactionAmount: p.MaxTransactionAmount,
+			DailyOutflowLimit:    p.DailyOutflowLimit,
+			MonthlyOutflowLimit:  p.MonthlyOutflowLimit,
+		}
+		c.products[p.AccountType] = product
+		c.list = append(c.list, product)
+	}
+	return c
+}
+
+// Get returns the product for accountType and whether there is one.
+func (c *Catalog) Get(accountType string) (models.Product, bool) {
+	p, ok := c.products[accountType]
+	return p, ok
+}
+
+// For returns the product account follows. Accounts whose type is not in the
+// catalog, because they predate it or their type was withdrawn, follow the
+// default account type's rules.
+func (c *Catalog) For(account *models.Account) models.Product {
+	if p, ok := c.products[account.AccountType]; ok {
+		return p
+	}
+	return c.products[config.DefaultAccountType]
+}
+
+// List returns the products in configuration order.
+func (c *Catalog) List() []models.Product {
+	return append([]models.Product(nil), c.list...)
+}
+
+// Validate reports an account type that is not in the catalog.
+func (c *Catalog) Validate(accountType string) error {
+	if _, ok := c.products[accountType]; ok {
+		return nil
+	}
+	types := make([]string, len(c.list))
+	for i, p := range c.list {
+		types[i] = p.AccountType
+	}
+	return fmt.Errorf("account_type %q is not one of %s", accountType, strings.Join(types, ", "))
+}
+
+// CheckAmount rejects a single transaction above the product's limit.
+func CheckAmount(p models.Product, amount float64) error {
+	if p.MaxTransactionAmount > 0 && amount > p.MaxTransactionAmount {
+		return fmt.Errorf("%w: %s accounts allow at most %.2f per transaction",
+			ErrLimitExceeded, p.AccountType, p.MaxTransactionAmount)
+	}
+	return nil
+}
+
+// CheckBalance rejects a balance below the product's minimum balance less
+// its overdraft limit, e.g. that of an account changing to the product.
+func CheckBalance(p models.Product, balance float64) error {
+	floor := p.MinimumBalance - p.OverdraftLimit
+	if balance < floor {
+		return fmt.Errorf("%w: balance %.2f is below the %.2f %s accounts keep",
+			ErrInsufficientFunds, balance, floor, p.AccountType)
+	}
+	return nil
+}
+
+// CheckDebit rejects a withdrawal of amount that would take balance below
+// the product's minimum balance less its overdraft limit.
+func CheckDebit(p models.Product, balance, amount float64) error {
+	floor := p.MinimumBalance - p.OverdraftLimit
+	if balance-amount < floor {
+		return fmt.Errorf("%w: balance %.2f less %.2f would fall below %.2f",
+			ErrInsufficientFunds, balance, amount, floor)
+	}
+	return nil
+}
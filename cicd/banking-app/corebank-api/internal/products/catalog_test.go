@@ -0,0 +1,91 @@
+package products
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/models"
+)
+
+func newTestCatalog() *Catalog {
+	return NewCatalog([]config.ProductConfig{
+		{AccountType: config.DefaultAccountType},
+		{AccountType: "savings", MinimumBalance: 100, InitialDeposit: 50, MaxTransactionAmount: 1000},
+		{AccountType: "basic", MinimumBalance: 20, OverdraftLimit: 50, MaxTransactionAmount: 500, MonthlyFee: 5},
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	c := newTestCatalog()
+
+	var types []string
+	for _, p := range c.List() {
+		types = append(types, p.AccountType)
+	}
+	if strings.Join(types, ",") != "checking,savings,basic" {
+		t.Fatalf("List = %v, want configuration order", types)
+	}
+	if p, ok := c.Get("basic"); !ok || p.OverdraftLimit != 50 || p.MonthlyFee != 5 {
+		t.Fatalf("Get(basic) = %+v, %v", p, ok)
+	}
+	if _, ok := c.Get("platinum"); ok {
+		t.Fatal("Get(platinum) found a product")
+	}
+
+	// Accounts of a type not in the catalog follow the default's rules
+	for _, accountType := range []string{"savings", "platinum", ""} {
+		want := accountType
+		if accountType != "savings" {
+			want = config.DefaultAccountType
+		}
+		if p := c.For(&models.Account{AccountType: accountType}); p.AccountType != want {
+			t.Errorf("For(%q) = %s, want %s", accountType, p.AccountType, want)
+		}
+	}
+
+	if err := c.Validate("savings"); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Validate("platinum")
+	if err == nil || err.Error() != `account_type "platinum" is not one of checking, savings, basic` {
+		t.Fatalf("Validate(platinum) = %v", err)
+	}
+}
+
+func TestRules(t *testing.T) {
+	c := newTestCatalog()
+	tests := []struct {
+		name    string
+		product string
+		check   func(models.Product) error
+		wantErr error
+	}{
+		{name: "amount without a limit", product: "checking", check: func(p models.Product) error { return CheckAmount(p, 1e9) }},
+		{name: "amount at the limit", product: "basic", check: func(p models.Product) error { return CheckAmount(p, 500) }},
+		{name: "amount over the limit", product: "basic", check: func(p models.Product) error { return CheckAmount(p, 500.01) },
+			wantErr: ErrLimitExceeded},
+		{name: "debit to zero", product: "checking", check: func(p models.Product) error { return CheckDebit(p, 80, 80) }},
+		{name: "debit below zero", product: "checking", check: func(p models.Product) error { return CheckDebit(p, 80, 80.01) },
+			wantErr: ErrInsufficientFunds},
+		{name: "debit to the minimum balance", product: "savings", check: func(p models.Product) error { return CheckDebit(p, 150, 50) }},
+		{name: "debit below the minimum balance", product: "savings", check: func(p models.Product) error { return CheckDebit(p, 150, 51) },
+			wantErr: ErrInsufficientFunds},
+		// The floor is the minimum balance of 20 less the overdraft of 50
+		{name: "debit into the overdraft", product: "basic", check: func(p models.Product) error { return CheckDebit(p, 100, 130) }},
+		{name: "debit past the overdraft", product: "basic", check: func(p models.Product) error { return CheckDebit(p, 100, 130.01) },
+			wantErr: ErrInsufficientFunds},
+		{name: "balance at the floor", product: "basic", check: func(p models.Product) error { return CheckBalance(p, -30) }},
+		{name: "balance below the floor", product: "savings", check: func(p models.Product) error { return CheckBalance(p, 99.99) },
+			wantErr: ErrInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := c.Get(tt.product)
+			if err := tt.check(p); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
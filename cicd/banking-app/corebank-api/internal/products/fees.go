@@ -0,0 +1,120 @@
+package products
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// periodLayout is the format of a fee period, a calendar month.
+const periodLayout = "2006-01"
+
+// AccountLister lists the accounts the fee job charges.
+type AccountLister interface {
+	ListAll(ctx context.Context) ([]models.Account, error)
+}
+
+// Submitter posts a transaction at most once per entry ID; outbox.Outbox
+// implements it.
+type Submitter interface {
+	SubmitOnce(ctx context.Context, entry *models.OutboxEntry) (bool, error)
+}
+
+// FeeJob charges every account its product's monthly fee once the month has
+// ended. Each charge has an outbox ID derived from the account and month, so
+// it is posted once however many instances run the job.
+type FeeJob struct {
+	catalog  *Catalog
+	accounts AccountLister
+	outbox   Submitter
+	loc      *time.Location
+	interval time.Duration
+
+	// done is the last month charged for every account
+	done string
+}
+
+func NewFeeJob(catalog *Catalog, accounts AccountLister, outbox Submitter, loc *time.Location, interval time.Duration) *FeeJob {
+	return &FeeJob{catalog: catalog, accounts: accounts, outbox: outbox, loc: loc, interval: interval}
+}
+
+// FeeEntryID is the outbox ID of the fee charged to an account for period.
+func FeeEntryID(accountID, period string) string {
+	return "fee#" + accountID + "#" + period
+}
+
+// Run charges fees now and then every interval until ctx is cancelled. It
+// has the signature of a server.Worker.
+func (j *FeeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if err := j.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("monthly fees incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce charges the fee for the month before now to every open account
+// that existed in that month. Accounts that fail are logged and retried on
+// the next run.
+func (j *FeeJob) RunOnce(ctx context.Context, now time.Time) error {
+	now = now.In(j.loc)
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, j.loc)
+	period := end.AddDate(0, -1, 0).Format(periodLayout)
+	if period == j.done {
+		return nil
+	}
+
+	accounts, err := j.accounts.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var charged, failed int
+	for i := range accounts {
+		account := &accounts[i]
+		product := j.catalog.For(account)
+		if product.MonthlyFee <= 0 || account.Status == models.AccountStatusClosed ||
+			(!account.CreatedAt.IsZero() && !account.CreatedAt.Before(end)) {
+			continue
+		}
+
+		created, err := j.outbox.SubmitOnce(ctx, &models.OutboxEntry{
+			ID:     FeeEntryID(account.ID, period),
+			Source: models.OutboxSourceFee,
+			Transaction: models.Transaction{
+				AccountID:   account.ID,
+				Amount:      product.MonthlyFee,
+				Type:        "withdrawal",
+				Description: "monthly fee for " + period,
+				Status:      "pending",
+			},
+			Actor: "fees",
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			slog.Warn("failed to charge monthly fee", "account_id", account.ID, "period", period, "error", err)
+			continue
+		}
+		if created {
+			charged++
+		}
+	}
+
+	slog.Info("monthly fees charged", "period", period, "charged", charged, "failed", failed)
+	if failed == 0 {
+		j.done = period
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package products
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+type accountList []models.Account
+
+func (l accountList) ListAll(context.Context) ([]models.Account, error) {
+	return l, nil
+}
+
+// memOutbox keeps submitted entries by ID, failing those in fail.
+type memOutbox struct {
+	entries map[string]models.OutboxEntry
+	fail    map[string]bool
+	calls   int
+}
+
+func (o *memOutbox) SubmitOnce(_ context.Context, entry *models.OutboxEntry) (bool, error) {
+	o.calls++
+	if o.fail[entry.Transaction.AccountID] {
+		return false, errors.New("outbox unavailable")
+	}
+	if _, ok := o.entries[entry.ID]; ok {
+		return false, nil
+	}
+	o.entries[entry.ID] = *entry
+	return true, nil
+}
+
+func TestFeeJob(t *testing.T) {
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	accounts := accountList{
+		{ID: "basic", AccountType: "basic", CreatedAt: october.AddDate(0, -3, 0)},
+		{ID: "since", AccountType: "basic"},
+		{ID: "free", AccountType: "savings", CreatedAt: october.AddDate(0, -3, 0)},
+		{ID: "closed", AccountType: "basic", Status: models.AccountStatusClosed, CreatedAt: october.AddDate(0, -3, 0)},
+		{ID: "new", AccountType: "basic", CreatedAt: october},
+	}
+	outbox := &memOutbox{entries: make(map[string]models.OutboxEntry), fail: map[string]bool{"since": true}}
+	job := NewFeeJob(newTestCatalog(), accounts, outbox, time.UTC, time.Hour)
+	ctx := context.Background()
+	now := october.Add(36 * time.Hour)
+
+	if err := job.RunOnce(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := outbox.entries[FeeEntryID("basic", "2026-09")]
+	if len(outbox.entries) != 1 || !ok {
+		t.Fatalf("charged %+v, want only the basic account for September", outbox.entries)
+	}
+	txn := entry.Transaction
+	if entry.Source != models.OutboxSourceFee || txn.Type != "withdrawal" || txn.Amount != 5 || txn.Description != "monthly fee for 2026-09" {
+		t.Fatalf("fee = %+v", entry)
+	}
+
+	// A failed charge is retried on the next run; charged accounts are
+	// submitted again, but only once recorded
+	delete(outbox.fail, "since")
+	if err := job.RunOnce(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := outbox.entries[FeeEntryID("since", "2026-09")]; !ok || len(outbox.entries) != 2 {
+		t.Fatalf("charged %+v, want the failed account charged", outbox.entries)
+	}
+
+	// Once every account is charged the month is not gone through again
+	calls := outbox.calls
+	if err := job.RunOnce(ctx, now.Add(time.Hour)); err != nil || outbox.calls != calls {
+		t.Fatalf("rerun submitted %d more fees (%v), want none", outbox.calls-calls, err)
+	}
+}
+
+// The month is taken in the job's timezone.
+func TestFeeJobTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	outbox := &memOutbox{entries: make(map[string]models.OutboxEntry)}
+	job := NewFeeJob(newTestCatalog(), accountList{{ID: "basic", AccountType: "basic"}}, outbox, berlin, time.Hour)
+
+	// 23:30 UTC on 31 October is already November in Berlin
+	if err := job.RunOnce(context.Background(), time.Date(2026, 10, 31, 23, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := outbox.entries[FeeEntryID("basic", "2026-10")]; !ok {
+		t.Fatalf("charged %+v, want October's fee", outbox.entries)
+	}
+}
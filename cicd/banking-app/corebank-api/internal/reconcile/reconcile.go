@@ -0,0 +1,262 @@
+// Package reconcile checks each account's stored balance against the
+// balance of its completed transactions in the transaction service's ledger,
+// reports the accounts that differ and optionally corrects them. A
+// correction is posted as an adjustment covering the completed transactions
+// that were never applied to the balance, so they are not applied again.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/repository"
+)
+
+// ErrAccountNotFound is returned when an account asked for by ID does not
+// exist.
+var ErrAccountNotFound = errors.New("account not found")
+
+// jobActor is recorded as the actor of corrections made by the job.
+const jobActor = "reconciliation"
+
+// completionGrace is how long after a transaction is completed it may still
+// be on its way to the balance. An account with such a transaction is not
+// corrected, since the correction would count it twice.
+const completionGrace = 5 * time.Minute
+
+// AccountStore reads accounts; repository.AccountRepository implements it.
+type AccountStore interface {
+	ListAll(ctx context.Context) ([]models.Account, error)
+	// GetByID returns nil and no error when the account does not exist.
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+}
+
+// Ledger reports the balance of an account's transactions;
+// statements.Generator implements it from the transaction service.
+type Ledger interface {
+	Balances(ctx context.Context, accountID string) (ledger, pending float64, err error)
+	Completed(ctx context.Context, accountID string) ([]models.Transaction, error)
+}
+
+// Poster applies corrections to balances; posting.Poster implements it.
+type Poster interface {
+	Adjust(ctx context.Context, account *models.Account, adj posting.Adjustment, actor string) (*posting.Result, error)
+	Posted(ctx context.Context, txnID string) (bool, error)
+}
+
+// Store persists reports; repository.ReconciliationRepository implements
+// it against DynamoDB.
+type Store interface {
+	Create(ctx context.Context, report *models.Reconciliation) error
+	// Get returns nil and no error when the report does not exist.
+	Get(ctx context.Context, id string) (*models.Reconciliation, error)
+	// List returns every report, newest first.
+	List(ctx context.Context) ([]models.Reconciliation, error)
+}
+
+// Options select what a run checks and whether it corrects.
+type Options struct {
+	// AccountIDs limits the run to these accounts; every account if empty.
+	AccountIDs []string
+	Correct    bool
+	// Actor is who requested the run, recorded with the report and any
+	// correction.
+	Actor string
+}
+
+// Reconciler compares balances on request and, as a worker, every
+// interval.
+type Reconciler struct {
+	accounts AccountStore
+	ledger   Ledger
+	poster   Poster
+	store    Store
+	events   events.Publisher
+	interval time.Duration
+	// autoCorrect is whether the worker's runs correct balances
+	autoCorrect bool
+}
+
+// NewReconciler returns a reconciler whose worker runs correct balances if
+// correct is set, and otherwise only report them.
+func NewReconciler(accounts AccountStore, ledger Ledger, poster Poster, store Store, publisher events.Publisher, interval time.Duration, correct bool) *Reconciler {
+	return &Reconciler{accounts: accounts, ledger: ledger, poster: poster, store: store, events: publisher, interval: interval,
+		autoCorrect: correct}
+}
+
+// Run reconciles every account every interval, starting one interval from
+// now, until ctx is cancelled. It has the signature of a server.Worker.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := r.Reconcile(ctx, Options{Correct: r.autoCorrect}); err != nil && ctx.Err() == nil {
+			slog.Warn("balance reconciliation incomplete", "error", err)
+		}
+	}
+}
+
+// Reconcile compares the selected accounts' balances with their ledger
+// balances and stores and returns the report. Accounts whose ledger cannot
+// be read are counted as failed and left for the next run. A balance that
+// changed while it was being checked, or with a transaction completed too
+// recently to tell whether it is still being applied, is not corrected; the
+// discrepancy is reported with the error.
+func (r *Reconciler) Reconcile(ctx context.Context, opts Options) (*models.Reconciliation, error) {
+	accounts, err := r.load(ctx, opts.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.Reconciliation{
+		ID:            uuid.New().String(),
+		Correct:       opts.Correct,
+		RequestedBy:   opts.Actor,
+		Discrepancies: []models.Discrepancy{},
+		StartedAt:     time.Now().UTC(),
+	}
+	logger := logging.FromContext(ctx)
+	for i := range accounts {
+		account := &accounts[i]
+		expected, pending, err := r.ledger.Balances(ctx, account.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Failed++
+			logger.Warn("failed to read ledger balance", "account_id", account.ID, "error", err)
+			continue
+		}
+		report.Checked++
+		if round(expected-account.Balance) == 0 {
+			continue
+		}
+
+		d := models.Discrepancy{
+			AccountID:  account.ID,
+			Recorded:   account.Balance,
+			Expected:   expected,
+			Difference: round(expected - account.Balance),
+			Pending:    pending,
+		}
+		if opts.Correct {
+			if covered, err := r.correct(ctx, report, account, expected, opts.Actor); err != nil {
+				d.Error = err.Error()
+			} else {
+				d.Corrected, d.Covered = true, covered
+			}
+		}
+		logger.Warn("balance discrepancy", "reconciliation_id", report.ID, "account_id", d.AccountID,
+			"recorded", d.Recorded, "expected", d.Expected, "corrected", d.Corrected, "error", d.Error)
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	logger.Info("balances reconciled", "reconciliation_id", report.ID, "checked", report.Checked,
+		"failed", report.Failed, "discrepancies", len(report.Discrepancies), "correct", report.Correct)
+	if err := r.store.Create(ctx, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// load returns the accounts named, or every account if none are.
+func (r *Reconciler) load(ctx context.Context, ids []string) ([]models.Account, error) {
+	if len(ids) == 0 {
+		return r.accounts.ListAll(ctx)
+	}
+	accounts := make([]models.Account, 0, len(ids))
+	for _, id := range ids {
+		account, err := r.accounts.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, id)
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, nil
+}
+
+// correct posts an adjustment bringing an account's balance to its ledger
+// balance, recording the change and the report it came from in the
+// account's history, and publishes the update. The adjustment covers the
+// completed transactions that were never applied to the balance, whose IDs
+// it returns.
+func (r *Reconciler) correct(ctx context.Context, report *models.Reconciliation, account *models.Account, expected float64, actor string) ([]string, error) {
+	if actor == "" {
+		actor = jobActor
+	}
+	covers, err := r.unposted(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.poster.Adjust(ctx, account, posting.Adjustment{
+		ID:     "reconciliation#" + report.ID + "#" + account.ID,
+		Amount: expected - account.Balance,
+		Covers: covers,
+		Reason: "reconciliation " + report.ID + ": set to the balance of completed transactions",
+	}, actor)
+	if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrAlreadyPosted) {
+		return nil, errors.New("balance changed while it was being reconciled; the next run checks it again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := events.NewAccountUpdated(result.Account, []models.AccountChange{result.Change})
+	event.Actor = actor
+	r.events.Publish(ctx, event)
+	return result.Posting.Covers, nil
+}
+
+// unposted returns the account's completed transactions that have not been
+// applied to its balance. It fails if one was completed within
+// completionGrace, as it may be being applied.
+func (r *Reconciler) unposted(ctx context.Context, accountID string) ([]models.Transaction, error) {
+	completed, err := r.ledger.Completed(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read completed transactions: %w", err)
+	}
+	var unposted []models.Transaction
+	for _, txn := range completed {
+		posted, err := r.poster.Posted(ctx, txn.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check transaction %s was posted: %w", txn.ID, err)
+		}
+		if posted {
+			continue
+		}
+		completedAt := txn.CreatedAt
+		if txn.ProcessedAt != nil {
+			completedAt = *txn.ProcessedAt
+		}
+		if time.Since(completedAt) < completionGrace {
+			return nil, fmt.Errorf("transaction %s was completed at %s and may still be being applied; the next run checks it again",
+				txn.ID, completedAt.UTC().Format(time.RFC3339))
+		}
+		unposted = append(unposted, txn)
+	}
+	return unposted, nil
+}
+
+// round keeps differences to whole cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,251 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/repository"
+)
+
+type fakeAccounts []models.Account
+
+func (f fakeAccounts) ListAll(context.Context) ([]models.Account, error) {
+	return f, nil
+}
+
+func (f fakeAccounts) GetByID(_ context.Context, id string) (*models.Account, error) {
+	for i := range f {
+		if f[i].ID == id {
+			return &f[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeLedger holds each account's completed transactions; the ledger
+// balance is their sum.
+type fakeLedger struct {
+	completed map[string][]models.Transaction
+	failing   map[string]bool
+}
+
+func (f *fakeLedger) Balances(_ context.Context, accountID string) (float64, float64, error) {
+	if f.failing[accountID] {
+		return 0, 0, errors.New("transaction service unavailable")
+	}
+	var balance float64
+	for _, txn := range f.completed[accountID] {
+		if txn.Type == "withdrawal" {
+			balance -= txn.Amount
+		} else {
+			balance += txn.Amount
+		}
+	}
+	return balance, 0, nil
+}
+
+func (f *fakeLedger) Completed(_ context.Context, accountID string) ([]models.Transaction, error) {
+	return f.completed[accountID], nil
+}
+
+type fakePoster struct {
+	posted  map[string]bool
+	err     error
+	adjusts []posting.Adjustment
+	actors  []string
+}
+
+func (f *fakePoster) Adjust(_ context.Context, account *models.Account, adj posting.Adjustment, actor string) (*posting.Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.adjusts = append(f.adjusts, adj)
+	f.actors = append(f.actors, actor)
+	adjusted := *account
+	adjusted.Balance += adj.Amount
+	p := &models.BalancePosting{ID: adj.ID, AccountID: account.ID, Amount: adj.Amount, Balance: adjusted.Balance}
+	for _, txn := range adj.Covers {
+		p.Covers = append(p.Covers, txn.ID)
+	}
+	change := models.AccountChange{Field: "balance", Actor: actor, Reason: adj.Reason}
+	return &posting.Result{Account: &adjusted, Posting: p, Change: change}, nil
+}
+
+func (f *fakePoster) Posted(_ context.Context, txnID string) (bool, error) {
+	return f.posted[txnID], nil
+}
+
+type memStore struct {
+	reports []models.Reconciliation
+}
+
+func (s *memStore) Create(_ context.Context, report *models.Reconciliation) error {
+	s.reports = append(s.reports, *report)
+	return nil
+}
+
+func (s *memStore) Get(context.Context, string) (*models.Reconciliation, error) {
+	return nil, nil
+}
+
+func (s *memStore) List(context.Context) ([]models.Reconciliation, error) {
+	return s.reports, nil
+}
+
+type recordingPublisher struct {
+	published []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+// fixture is an account "acc" whose balance of 100 is missing a completed
+// deposit of 25.50, and an account "ok" whose balance is right.
+type fixture struct {
+	ledger    *fakeLedger
+	poster    *fakePoster
+	store     *memStore
+	publisher *recordingPublisher
+	r         *Reconciler
+}
+
+func newFixture() *fixture {
+	completedAt := time.Now().Add(-time.Hour)
+	f := &fixture{
+		ledger: &fakeLedger{
+			completed: map[string][]models.Transaction{
+				"acc": {
+					{ID: "dep-1", Type: "deposit", Amount: 120, ProcessedAt: &completedAt},
+					{ID: "wd-1", Type: "withdrawal", Amount: 20, ProcessedAt: &completedAt},
+					{ID: "dep-2", Type: "deposit", Amount: 25.50, CreatedAt: completedAt},
+				},
+				"ok": {{ID: "dep-3", Type: "deposit", Amount: 40, ProcessedAt: &completedAt}},
+			},
+			failing: map[string]bool{},
+		},
+		poster:    &fakePoster{posted: map[string]bool{"dep-1": true, "wd-1": true, "dep-3": true}},
+		store:     &memStore{},
+		publisher: &recordingPublisher{},
+	}
+	accounts := fakeAccounts{{ID: "acc", Balance: 100}, {ID: "ok", Balance: 40}}
+	f.r = NewReconciler(accounts, f.ledger, f.poster, f.store, f.publisher, time.Hour, false)
+	return f
+}
+
+func TestReconcileReports(t *testing.T) {
+	f := newFixture()
+	report, err := f.r.Reconcile(context.Background(), Options{Actor: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 2 || report.Failed != 0 || report.RequestedBy != "ops" || report.Correct {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.Discrepancies) != 1 {
+		t.Fatalf("discrepancies = %+v, want one for acc", report.Discrepancies)
+	}
+	d := report.Discrepancies[0]
+	if d.AccountID != "acc" || d.Recorded != 100 || d.Expected != 125.5 || d.Difference != 25.5 || d.Corrected {
+		t.Fatalf("discrepancy = %+v", d)
+	}
+	if len(f.poster.adjusts) != 0 || len(f.publisher.published) != 0 {
+		t.Fatal("a run without Correct changed a balance")
+	}
+	if len(f.store.reports) != 1 || f.store.reports[0].ID != report.ID {
+		t.Fatalf("stored reports = %+v, want the report", f.store.reports)
+	}
+}
+
+func TestReconcileCorrects(t *testing.T) {
+	f := newFixture()
+	report, err := f.r.Reconcile(context.Background(), Options{Correct: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := report.Discrepancies[0]
+	if !d.Corrected || d.Error != "" || strings.Join(d.Covered, ",") != "dep-2" {
+		t.Fatalf("discrepancy = %+v, want corrected covering dep-2", d)
+	}
+	if len(f.poster.adjusts) != 1 {
+		t.Fatalf("adjustments = %+v, want one", f.poster.adjusts)
+	}
+	adj := f.poster.adjusts[0]
+	if adj.ID != "reconciliation#"+report.ID+"#acc" || adj.Amount != 25.5 || f.poster.actors[0] != jobActor {
+		t.Fatalf("adjustment %+v by %q", adj, f.poster.actors[0])
+	}
+
+	if len(f.publisher.published) != 1 {
+		t.Fatalf("published %d events, want one", len(f.publisher.published))
+	}
+	event := f.publisher.published[0]
+	if event.Type != events.AccountUpdated || event.Actor != jobActor || event.Account.Balance != 125.5 {
+		t.Fatalf("event = %+v", event)
+	}
+}
+
+func TestReconcileDoesNotCorrect(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(f *fixture)
+		wantErr string
+	}{
+		{
+			name: "transaction completed within the grace period",
+			change: func(f *fixture) {
+				f.ledger.completed["acc"][2].CreatedAt = time.Now().Add(-time.Minute)
+			},
+			wantErr: "transaction dep-2 was completed at",
+		},
+		{
+			name:    "balance changed",
+			change:  func(f *fixture) { f.poster.err = repository.ErrConflict },
+			wantErr: "balance changed while it was being reconciled",
+		},
+		{
+			name:    "already adjusted",
+			change:  func(f *fixture) { f.poster.err = repository.ErrAlreadyPosted },
+			wantErr: "balance changed while it was being reconciled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			tt.change(f)
+			report, err := f.r.Reconcile(context.Background(), Options{Correct: true, Actor: "ops"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := report.Discrepancies[0]
+			if d.Corrected || !strings.Contains(d.Error, tt.wantErr) {
+				t.Fatalf("discrepancy = %+v, want uncorrected with %q", d, tt.wantErr)
+			}
+			if len(f.poster.adjusts) != 0 || len(f.publisher.published) != 0 {
+				t.Fatal("balance was adjusted")
+			}
+		})
+	}
+}
+
+func TestReconcileSelection(t *testing.T) {
+	f := newFixture()
+	f.ledger.failing["ok"] = true
+	report, err := f.r.Reconcile(context.Background(), Options{AccountIDs: []string{"ok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 0 || report.Failed != 1 || len(report.Discrepancies) != 0 {
+		t.Fatalf("report = %+v, want only ok, failed", report)
+	}
+
+	if _, err := f.r.Reconcile(context.Background(), Options{AccountIDs: []string{"ok", "missing"}}); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("got %v, want ErrAccountNotFound", err)
+	}
+}
@@ -135,33 +135,37 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"log"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/corebank-api/internal/logging"
 	"github.com/corebank-api/internal/models"
 	"github.com/google/uuid"
 )
 
-const AccountsTable = "BankAccounts"
-
 type AccountRepository struct {
-	client *dynamodb.Client
+	client        *dynamodb.Client
+	table         string
+	historyTable  string
+	postingsTable string
 }
 
-func NewAccountRepository() *AccountRepository {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-
+// NewAccountRepository stores accounts in the given table, their change
+// history in historyTable and the transactions applied to their balances in
+// postingsTable, using a shared client, see NewDynamoDBClient.
+func NewAccountRepository(client *dynamodb.Client, table, historyTable, postingsTable string) *AccountRepository {
 	return &AccountRepository{
-		client: dynamodb.NewFromConfig(cfg),
+		client:        client,
+		table:         table,
+		historyTable:  historyTable,
+		postingsTable: postingsTable,
 	}
 }
 
@@ -174,11 +178,14 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 	if account.AccountType == "" {
 		account.AccountType = "checking"
 	}
+	if account.Status == "" {
+		account.Status = models.AccountStatusActive
+	}
 	// Set the creation timestamp
 	account.CreatedAt = time.Now()
 
 	// Log the generated UUID for debugging purposes
-	fmt.Printf("Creating account with ID: %s\n", account.ID)
+	logging.FromContext(ctx).Debug("creating account", "account_id", account.ID)
 
 	// Marshal the account struct to a map for DynamoDB
 	item, err := attributevalue.MarshalMap(account)
@@ -186,24 +193,28 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 		return fmt.Errorf("failed to marshal account: %w", err)
 	}
 
-	// Insert the account into DynamoDB
+	// Insert the account into DynamoDB, never over an existing one
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(AccountsTable),
-		Item:      item,
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
 	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrConflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert item into DynamoDB: %w", err)
 	}
 
-	// Log the successful insertion (optional)
-	fmt.Println("Account created successfully")
+	logging.FromContext(ctx).Info("account created", "account_id", account.ID)
 	return nil
 }
 
 
 func (r *AccountRepository) GetByID(ctx context.Context, id string) (*models.Account, error) {
 	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(AccountsTable),
+		TableName: aws.String(r.table),
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberS{Value: id},
 		},
@@ -225,29 +236,39 @@ func (r *AccountRepository) GetByID(ctx context.Context, id string) (*models.Acc
 	return &account, nil
 }
 
-func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
-    // Set the updated timestamp
-    account.UpdatedAt = time.Now()
-
-    // Update the account's attributes in DynamoDB
-    _, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-        TableName: aws.String(AccountsTable),
-        Key: map[string]types.AttributeValue{
-            "id": &types.AttributeValueMemberS{Value: account.ID},
-        },
-        UpdateExpression: aws.String("SET balance = :balance, account_type = :account_type, updated_at = :updated_at"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":balance":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", account.Balance)},
-            ":account_type": &types.AttributeValueMemberS{Value: account.AccountType},
-            ":updated_at":   &types.AttributeValueMemberS{Value: account.UpdatedAt.Format(time.RFC3339)},
-        },
-        ReturnValues: types.ReturnValueAllNew,
-    })
-    if err != nil {
-        return fmt.Errorf("failed to update account: %w", err)
-    }
+// Update saves the editable fields of account, owner, email, account_type
+// and timezone, and appends changes to its history in one transaction.
+// Balance, status, limits and created_at are never written here. It returns
+// ErrNotFound if the account does not exist.
+func (r *AccountRepository) Update(ctx context.Context, account *models.Account, changes []models.AccountChange) error {
+	account.UpdatedAt = time.Now()
 
-    return nil
+	update := &types.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: account.ID},
+		},
+		UpdateExpression:    aws.String("SET #owner = :owner, email = :email, account_type = :account_type, #timezone = :timezone, updated_at = :updated_at"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner":    "owner",
+			"#timezone": "timezone",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner":        &types.AttributeValueMemberS{Value: account.Owner},
+			":email":        &types.AttributeValueMemberS{Value: account.Email},
+			":account_type": &types.AttributeValueMemberS{Value: account.AccountType},
+			":timezone":     &types.AttributeValueMemberS{Value: account.Timezone},
+			":updated_at":   &types.AttributeValueMemberS{Value: account.UpdatedAt.Format(time.RFC3339)},
+		},
+	}
+	if err := r.updateWithHistory(ctx, update, account.ID, changes); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	return nil
 }
 
 // func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
@@ -262,7 +283,7 @@ func (r *AccountRepository) Update(ctx context.Context, account *models.Account)
 
 //     // Update the account in DynamoDB
 //     _, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-//         TableName: aws.String(AccountsTable),
+//         TableName: aws.String(r.table),
 //         Item:      item,
 //     })
 //     if err != nil {
@@ -276,7 +297,7 @@ func (r *AccountRepository) Update(ctx context.Context, account *models.Account)
 func (r *AccountRepository) Delete(ctx context.Context, id string) error {
     // Delete the account from DynamoDB
     _, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-        TableName: aws.String(AccountsTable),
+        TableName: aws.String(r.table),
         Key: map[string]types.AttributeValue{
             "id": &types.AttributeValueMemberS{Value: id},
         },
@@ -290,7 +311,7 @@ func (r *AccountRepository) Delete(ctx context.Context, id string) error {
 
 // func (r *AccountRepository) UpdateBalance(ctx context.Context, id string, amount float64) error {
 // 	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-// 		TableName: aws.String(AccountsTable),
+// 		TableName: aws.String(r.table),
 // 		Key: map[string]types.AttributeValue{
 // 			"id": &types.AttributeValueMemberS{Value: id},
 // 		},
@@ -303,19 +324,435 @@ func (r *AccountRepository) Delete(ctx context.Context, id string) error {
 // 	return err
 // }
 
+// Ping verifies that the accounts table exists and is active.
+func (r *AccountRepository) Ping(ctx context.Context) error {
+	result, err := r.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(r.table),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe accounts table: %w", err)
+	}
+	if status := result.Table.TableStatus; status != types.TableStatusActive {
+		return fmt.Errorf("accounts table is %s", status)
+	}
+	return nil
+}
+
+// ListAll scans the whole table, following DynamoDB's 1 MB page boundaries.
 func (r *AccountRepository) ListAll(ctx context.Context) ([]models.Account, error) {
-	result, err := r.client.Scan(ctx, &dynamodb.ScanInput{
-		TableName: aws.String(AccountsTable),
+	var accounts []models.Account
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.table),
 	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan accounts: %w", err)
+		}
+
+		var items []models.Account
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal accounts: %w", err)
+		}
+		accounts = append(accounts, items...)
+	}
+
+	return accounts, nil
+}
+
+// ErrInvalidCursor is returned by ListPage for a cursor it did not issue.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListPage returns up to limit accounts after cursor and the cursor for the
+// next page, which is empty on the last page. Scan order is stable but not
+// sorted.
+func (r *AccountRepository) ListPage(ctx context.Context, limit int, cursor string) ([]models.Account, string, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.table),
+		Limit:     aws.Int32(int32(limit)),
+	}
+	if cursor != "" {
+		id, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(id) == 0 {
+			return nil, "", ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: string(id)},
+		}
+	}
+
+	result, err := r.client.Scan(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan accounts: %w", err)
+		return nil, "", fmt.Errorf("failed to scan accounts: %w", err)
 	}
 
 	var accounts []models.Account
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &accounts)
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &accounts); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal accounts: %w", err)
+	}
+
+	var next string
+	if last, ok := result.LastEvaluatedKey["id"].(*types.AttributeValueMemberS); ok {
+		next = base64.RawURLEncoding.EncodeToString([]byte(last.Value))
+	}
+	return accounts, next, nil
+}
+
+// ErrNotFound is returned when updating an account that does not exist.
+var ErrNotFound = errors.New("account not found")
+
+// SetStatus changes an account's status, e.g. to freeze or close it, and
+// records the change in its history as made by actor.
+func (r *AccountRepository) SetStatus(ctx context.Context, account *models.Account, status, actor string) error {
+	now := time.Now()
+	update := &types.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: account.ID},
+		},
+		UpdateExpression:    aws.String("SET #status = :status, updated_at = :updated_at"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":     &types.AttributeValueMemberS{Value: status},
+			":updated_at": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		},
+	}
+	change := models.AccountChange{Field: "status", Old: account.Status, New: status, Actor: actor, ChangedAt: now.UTC()}
+	if err := r.updateWithHistory(ctx, update, account.ID, []models.AccountChange{change}); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+	return nil
+}
+
+// SetKYCStatus changes an account's KYC status from old to status and
+// records change in its history. It fails with ErrConflict if the account
+// no longer exists or its KYC status is no longer old, e.g. because an admin
+// decided on it since it was read. Accounts stored without a KYC status are
+// unverified.
+func (r *AccountRepository) SetKYCStatus(ctx context.Context, id, old, status string, change models.AccountChange) error {
+	condition := "attribute_exists(id) AND kyc_status = :old"
+	if old == models.KYCUnverified {
+		condition = "attribute_exists(id) AND (attribute_not_exists(kyc_status) OR kyc_status = :old)"
+	}
+	update := &types.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET kyc_status = :kyc_status, updated_at = :updated_at"),
+		ConditionExpression: aws.String(condition),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":kyc_status": &types.AttributeValueMemberS{Value: status},
+			":old":        &types.AttributeValueMemberS{Value: old},
+			":updated_at": &types.AttributeValueMemberS{Value: change.ChangedAt.Format(time.RFC3339)},
+		},
+	}
+	if err := r.updateWithHistory(ctx, update, id, []models.AccountChange{change}); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to set account KYC status: %w", err)
+	}
+	return nil
+}
+
+// SetLimits replaces the account's own limits, removing them if limits is
+// nil, and records change in its history.
+func (r *AccountRepository) SetLimits(ctx context.Context, id string, limits *models.AccountLimits, change models.AccountChange) error {
+	update := &types.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("REMOVE #limits SET updated_at = :updated_at"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeNames: map[string]string{
+			"#limits": "limits",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":updated_at": &types.AttributeValueMemberS{Value: change.ChangedAt.Format(time.RFC3339)},
+		},
+	}
+	if limits != nil {
+		item, err := attributevalue.Marshal(limits)
+		if err != nil {
+			return fmt.Errorf("failed to marshal account limits: %w", err)
+		}
+		update.UpdateExpression = aws.String("SET #limits = :limits, updated_at = :updated_at")
+		update.ExpressionAttributeValues[":limits"] = item
+	}
+	if err := r.updateWithHistory(ctx, update, id, []models.AccountChange{change}); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to set account limits: %w", err)
+	}
+	return nil
+}
+
+// maxCovered is the most transactions one adjustment can cover: a
+// DynamoDB transaction writes at most 100 items, three of which are the
+// balance, the history and the adjustment itself.
+const maxCovered = 97
+
+// PostAdjustment applies an adjustment to its account's balance, moving it
+// from old to posting.Balance, like PostTransaction, and stores covered, the
+// postings of the transactions the adjustment applied, with it. It fails
+// with ErrAlreadyPosted if the adjustment or any covered transaction was
+// applied before, and with ErrConflict if the account no longer exists or
+// its balance is no longer old.
+func (r *AccountRepository) PostAdjustment(ctx context.Context, posting *models.BalancePosting, covered []models.BalancePosting, old float64, change models.AccountChange) error {
+	if len(covered) > maxCovered {
+		return fmt.Errorf("an adjustment can cover at most %d transactions, not %d", maxCovered, len(covered))
+	}
+	puts := make([]types.TransactWriteItem, 0, len(covered)+1)
+	for _, p := range append([]models.BalancePosting{*posting}, covered...) {
+		item, err := attributevalue.MarshalMap(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal balance posting: %w", err)
+		}
+		puts = append(puts, types.TransactWriteItem{Put: &types.Put{
+			TableName:           aws.String(r.postingsTable),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(id)"),
+		}})
+	}
+	err := r.updateWithHistory(ctx, r.balanceUpdate(posting.AccountID, old, posting.Balance, change.ChangedAt), posting.AccountID,
+		[]models.AccountChange{change}, puts...)
+	switch {
+	case errors.Is(err, errExtraCondition):
+		return ErrAlreadyPosted
+	case errors.Is(err, ErrNotFound):
+		return ErrConflict
+	case err != nil:
+		return fmt.Errorf("failed to post adjustment: %w", err)
+	}
+	return nil
+}
+
+// ErrAlreadyPosted is returned when applying a transaction that has already
+// been applied to its account's balance.
+var ErrAlreadyPosted = errors.New("transaction already posted")
+
+// errExtraCondition is returned by updateWithHistory when the condition of
+// one of its extra items failed.
+var errExtraCondition = errors.New("condition failed")
+
+// PostTransaction applies a completed transaction to its account's balance,
+// moving it from old to posting.Balance, records change in the account's
+// history and stores posting, all in one transaction. It fails with
+// ErrAlreadyPosted if the transaction was applied before, and with
+// ErrConflict if the account no longer exists or its balance is no longer
+// old.
+func (r *AccountRepository) PostTransaction(ctx context.Context, posting *models.BalancePosting, old float64, change models.AccountChange) error {
+	item, err := attributevalue.MarshalMap(posting)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal accounts: %w", err)
+		return fmt.Errorf("failed to marshal balance posting: %w", err)
+	}
+	put := types.TransactWriteItem{Put: &types.Put{
+		TableName:           aws.String(r.postingsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}}
+	err = r.updateWithHistory(ctx, r.balanceUpdate(posting.AccountID, old, posting.Balance, change.ChangedAt), posting.AccountID,
+		[]models.AccountChange{change}, put)
+	switch {
+	case errors.Is(err, errExtraCondition):
+		return ErrAlreadyPosted
+	case errors.Is(err, ErrNotFound):
+		return ErrConflict
+	case err != nil:
+		return fmt.Errorf("failed to post transaction: %w", err)
 	}
+	return nil
+}
 
-	return accounts, nil
-}
\ No newline at end of file
+// GetPosting returns the posting of the transaction with the given ID, or
+// nil and no error if it has not been applied to its account's balance.
+func (r *AccountRepository) GetPosting(ctx context.Context, id string) (*models.BalancePosting, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.postingsTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance posting: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var posting models.BalancePosting
+	if err := attributevalue.UnmarshalMap(result.Item, &posting); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal balance posting: %w", err)
+	}
+	return &posting, nil
+}
+
+// balanceUpdate sets an account's balance, on condition that it is still
+// old.
+func (r *AccountRepository) balanceUpdate(id string, old, balance float64, at time.Time) *types.Update {
+	return &types.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET balance = :balance, updated_at = :updated_at"),
+		ConditionExpression: aws.String("attribute_exists(id) AND balance = :old"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":balance":    &types.AttributeValueMemberN{Value: strconv.FormatFloat(balance, 'f', -1, 64)},
+			":old":        &types.AttributeValueMemberN{Value: strconv.FormatFloat(old, 'f', -1, 64)},
+			":updated_at": &types.AttributeValueMemberS{Value: at.Format(time.RFC3339)},
+		},
+	}
+}
+
+// updateWithHistory applies update, which must be conditional on the account
+// existing, together with appending changes to the account's history and
+// any extra items, so none is saved without the others. A failed condition
+// on update is reported as ErrNotFound and one on an extra item as
+// errExtraCondition.
+func (r *AccountRepository) updateWithHistory(ctx context.Context, update *types.Update, id string, changes []models.AccountChange, extra ...types.TransactWriteItem) error {
+	items := []types.TransactWriteItem{{Update: update}}
+	if len(changes) > 0 {
+		list, err := attributevalue.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal account changes: %w", err)
+		}
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName: aws.String(r.historyTable),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: id},
+			},
+			UpdateExpression: aws.String("SET changes = list_append(if_not_exists(changes, :empty), :changes)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":empty":   &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+				":changes": list,
+			},
+		}})
+	}
+
+	first := len(items)
+	items = append(items, extra...)
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for i, reason := range canceled.CancellationReasons {
+			if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			if i == 0 {
+				return ErrNotFound
+			}
+			if i >= first {
+				return errExtraCondition
+			}
+		}
+	}
+	return err
+}
+
+// History returns the changes made to an account, oldest first. It outlives
+// the account, so a deleted account's history can still be read.
+func (r *AccountRepository) History(ctx context.Context, id string) ([]models.AccountChange, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.historyTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account history: %w", err)
+	}
+
+	var history struct {
+		Changes []models.AccountChange `dynamodbav:"changes"`
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal account history: %w", err)
+	}
+	return history.Changes, nil
+}
+
+// batchSize is the most items one BatchWriteItem call accepts.
+const batchSize = 25
+
+// maxBatchAttempts bounds the retries of items DynamoDB leaves unprocessed
+// when a batch is throttled.
+const maxBatchAttempts = 5
+
+// CreateBatch writes new accounts as given, 25 per BatchWriteItem call. It
+// returns the IDs of accounts that were not written, either because their
+// batch failed or because DynamoDB left them unprocessed after retries; err
+// is the last failure. BatchWriteItem takes no conditions, so unlike Create
+// it cannot guard against overwriting and IDs must be freshly generated.
+func (r *AccountRepository) CreateBatch(ctx context.Context, accounts []models.Account) (unwritten []string, err error) {
+	for start := 0; start < len(accounts); start += batchSize {
+		chunk := accounts[start:min(start+batchSize, len(accounts))]
+
+		requests := make([]types.WriteRequest, 0, len(chunk))
+		for i := range chunk {
+			item, merr := attributevalue.MarshalMap(&chunk[i])
+			if merr != nil {
+				unwritten = append(unwritten, chunk[i].ID)
+				err = fmt.Errorf("failed to marshal account: %w", merr)
+				continue
+			}
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+		}
+
+		left, berr := r.writeBatch(ctx, requests)
+		if berr != nil {
+			err = berr
+		}
+		for _, req := range left {
+			if id, ok := req.PutRequest.Item["id"].(*types.AttributeValueMemberS); ok {
+				unwritten = append(unwritten, id.Value)
+			}
+		}
+		if ctx.Err() != nil {
+			for _, account := range accounts[start+len(chunk):] {
+				unwritten = append(unwritten, account.ID)
+			}
+			return unwritten, ctx.Err()
+		}
+	}
+	return unwritten, err
+}
+
+// writeBatch sends one batch, retrying unprocessed items with backoff, and
+// returns the requests that were never written.
+func (r *AccountRepository) writeBatch(ctx context.Context, requests []types.WriteRequest) ([]types.WriteRequest, error) {
+	backoff := 50 * time.Millisecond
+	for attempt := 1; len(requests) > 0; attempt++ {
+		result, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{r.table: requests},
+		})
+		if err != nil {
+			return requests, fmt.Errorf("failed to batch write accounts: %w", err)
+		}
+		requests = result.UnprocessedItems[r.table]
+		if len(requests) == 0 {
+			return nil, nil
+		}
+		if attempt == maxBatchAttempts {
+			return requests, fmt.Errorf("%d accounts left unprocessed after %d attempts", len(requests), attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return requests, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return nil, nil
+}
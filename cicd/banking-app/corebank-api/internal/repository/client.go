@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/corebank-api/internal/config"
+)
+
+// NewDynamoDBClient builds the DynamoDB client shared by every repository,
+// honouring the configured region, credentials and endpoint override.
+func NewDynamoDBClient(ctx context.Context, awsCfg config.AWSConfig, dynamoCfg config.DynamoDBConfig) (*dynamodb.Client, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(awsCfg.Region)}
+	if awsCfg.AccessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(awsCfg.AccessKeyID, awsCfg.SecretAccessKey, ""),
+		))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return dynamodb.NewFromConfig(cfg, WithMetrics, WithTracing, func(o *dynamodb.Options) {
+		if dynamoCfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(dynamoCfg.Endpoint)
+		}
+	}), nil
+}
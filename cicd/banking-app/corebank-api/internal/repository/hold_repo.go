@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// ErrConflict is returned when a hold, schedule or webhook delivery was
+// changed by someone else since it was read.
+var ErrConflict = errors.New("modified concurrently")
+
+type HoldRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewHoldRepository(client *dynamodb.Client, table string) *HoldRepository {
+	return &HoldRepository{client: client, table: table}
+}
+
+func (r *HoldRepository) Create(ctx context.Context, hold *models.Hold) error {
+	av, err := attributevalue.MarshalMap(hold)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hold: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create hold: %w", err)
+	}
+	return nil
+}
+
+// Get returns nil and no error when the hold does not exist.
+func (r *HoldRepository) Get(ctx context.Context, id string) (*models.Hold, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var hold models.Hold
+	if err := attributevalue.UnmarshalMap(result.Item, &hold); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hold: %w", err)
+	}
+	return &hold, nil
+}
+
+// Update replaces a hold read with the given updated_at. If it has changed
+// since, the hold is left alone and ErrConflict is returned.
+func (r *HoldRepository) Update(ctx context.Context, hold *models.Hold, prevUpdatedAt time.Time) error {
+	av, err := attributevalue.MarshalMap(hold)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hold: %w", err)
+	}
+	prev, err := attributevalue.Marshal(prevUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hold: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.table),
+		Item:                      av,
+		ConditionExpression:       aws.String("updated_at = :prev"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":prev": prev},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update hold: %w", err)
+	}
+	return nil
+}
+
+// ListByAccount returns an account's holds with the given status, or all of
+// them when status is empty, newest first.
+func (r *HoldRepository) ListByAccount(ctx context.Context, accountID, status string) ([]models.Hold, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String(holdsByAccountIndex),
+		KeyConditionExpression: aws.String("account_id = :account_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":account_id": &types.AttributeValueMemberS{Value: accountID},
+		},
+	}
+	if status != "" {
+		input.KeyConditionExpression = aws.String("account_id = :account_id AND #status = :status")
+		input.ExpressionAttributeNames = map[string]string{"#status": "status"}
+		input.ExpressionAttributeValues[":status"] = &types.AttributeValueMemberS{Value: status}
+	}
+	var holds []models.Hold
+	if err := query(ctx, r.client, input, &holds); err != nil {
+		return nil, fmt.Errorf("failed to query holds: %w", err)
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].CreatedAt.After(holds[j].CreatedAt)
+	})
+	return holds, nil
+}
+
+// ListExpired returns active holds whose expiry is not after now.
+func (r *HoldRepository) ListExpired(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	var active []models.Hold
+	err := query(ctx, r.client, &dynamodb.QueryInput{
+		TableName:                aws.String(r.table),
+		IndexName:                aws.String(holdsByExpiryIndex),
+		KeyConditionExpression:   aws.String("#status = :status AND expires_at <= :due"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: models.HoldActive},
+			":due":    dueBy(now),
+		},
+	}, &active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holds: %w", err)
+	}
+
+	expired := active[:0]
+	for _, hold := range active {
+		if !hold.ExpiresAt.After(now) {
+			expired = append(expired, hold)
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Expired holds are queried by status and expiry; holds expiring within the
+// second after now are left out.
+func TestListExpired(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 300, time.UTC)
+	hold := func(id string, expiresAt time.Time) map[string]any {
+		return map[string]any{
+			"id":         map[string]string{"S": id},
+			"status":     map[string]string{"S": "active"},
+			"expires_at": map[string]string{"S": expiresAt.Format(time.RFC3339Nano)},
+		}
+	}
+	f := &fakeDynamoDB{items: []map[string]any{
+		hold("expired", now.Add(-time.Hour)),
+		hold("expiring-now", now),
+		hold("expiring-soon", now.Add(500*time.Millisecond)),
+	}}
+	repo := NewHoldRepository(newFakeDynamoDB(t, f), "holds")
+
+	expired, err := repo.ListExpired(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 2 || expired[0].ID != "expired" || expired[1].ID != "expiring-now" {
+		t.Fatalf("expired = %+v", expired)
+	}
+
+	query := f.queries[0]
+	values, _ := query["ExpressionAttributeValues"].(map[string]any)
+	due, _ := values[":due"].(map[string]any)
+	if query["IndexName"] != holdsByExpiryIndex || query["KeyConditionExpression"] != "#status = :status AND expires_at <= :due" ||
+		due["S"] != "2026-10-19T12:00:01Z" {
+		t.Fatalf("query = %v", query)
+	}
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+
+	"github.com/corebank-api/internal/metrics"
+)
+
+// WithMetrics adds latency and error metrics for every DynamoDB operation made
+// through the client.
+func WithMetrics(o *dynamodb.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CorebankMetrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				start := time.Now()
+				out, md, err := next.HandleInitialize(ctx, in)
+				metrics.ObserveDynamoDB(awsmiddleware.GetOperationName(ctx), time.Since(start), err)
+				return out, md, err
+			}), middleware.Before)
+	})
+}
+
+// WithTracing records a span for every DynamoDB operation made through the
+// client.
+func WithTracing(o *dynamodb.Options) {
+	otelaws.AppendMiddlewares(&o.APIOptions)
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// batchGetSize is the most keys BatchGetItem accepts in one call.
+const batchGetSize = 100
+
+// InterestRepository stores daily interest accruals, keyed by account ID and
+// date, and interest postings, keyed by account ID and period.
+type InterestRepository struct {
+	client        *dynamodb.Client
+	accrualsTable string
+	postingsTable string
+}
+
+func NewInterestRepository(client *dynamodb.Client, accrualsTable, postingsTable string) *InterestRepository {
+	return &InterestRepository{
+		client:        client,
+		accrualsTable: accrualsTable,
+		postingsTable: postingsTable,
+	}
+}
+
+// InterestAccrualID is the key of an account's accrual for date.
+func InterestAccrualID(accountID, date string) string {
+	return accountID + "#" + date
+}
+
+// InterestPostingID is the key of an account's posting for period.
+func InterestPostingID(accountID, period string) string {
+	return accountID + "#" + period
+}
+
+// PutAccrual stores an accrual and reports whether it did. Unless replace is
+// set an accrual already stored for the day is kept, so accruing a day twice
+// has no effect.
+func (r *InterestRepository) PutAccrual(ctx context.Context, accrual *models.InterestAccrual, replace bool) (bool, error) {
+	accrual.ID = InterestAccrualID(accrual.AccountID, accrual.Date)
+	return r.put(ctx, r.accrualsTable, accrual, !replace)
+}
+
+// Accruals returns the account's accruals for the given dates, oldest first.
+// Dates without an accrual are left out.
+func (r *InterestRepository) Accruals(ctx context.Context, accountID string, dates []string) ([]models.InterestAccrual, error) {
+	var accruals []models.InterestAccrual
+	for start := 0; start < len(dates); start += batchGetSize {
+		end := min(start+batchGetSize, len(dates))
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, date := range dates[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: InterestAccrualID(accountID, date)},
+			})
+		}
+
+		items, err := r.batchGet(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			var accrual models.InterestAccrual
+			if err := attributevalue.UnmarshalMap(item, &accrual); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal interest accrual: %w", err)
+			}
+			accruals = append(accruals, accrual)
+		}
+	}
+
+	sort.Slice(accruals, func(i, j int) bool { return accruals[i].Date < accruals[j].Date })
+	return accruals, nil
+}
+
+// batchGet reads the accruals with keys, retrying unprocessed keys with
+// backoff.
+func (r *InterestRepository) batchGet(ctx context.Context, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	backoff := 50 * time.Millisecond
+	for attempt := 1; len(keys) > 0; attempt++ {
+		result, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{r.accrualsTable: {Keys: keys}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get interest accruals: %w", err)
+		}
+		items = append(items, result.Responses[r.accrualsTable]...)
+		keys = result.UnprocessedKeys[r.accrualsTable].Keys
+		if len(keys) == 0 {
+			break
+		}
+		if attempt == maxBatchAttempts {
+			return nil, fmt.Errorf("%d interest accruals left unread after %d attempts", len(keys), attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return items, nil
+}
+
+// CreatePosting stores a posting and reports whether it did. A period is
+// only paid once, so concurrent jobs cannot both pay it.
+func (r *InterestRepository) CreatePosting(ctx context.Context, posting *models.InterestPosting) (bool, error) {
+	posting.ID = InterestPostingID(posting.AccountID, posting.Period)
+	return r.put(ctx, r.postingsTable, posting, true)
+}
+
+// UpdatePosting replaces a stored posting.
+func (r *InterestRepository) UpdatePosting(ctx context.Context, posting *models.InterestPosting) error {
+	posting.ID = InterestPostingID(posting.AccountID, posting.Period)
+	_, err := r.put(ctx, r.postingsTable, posting, false)
+	return err
+}
+
+// DeletePosting removes a posting whose interest could not be submitted, so
+// the period is paid on the next run.
+func (r *InterestRepository) DeletePosting(ctx context.Context, accountID, period string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.postingsTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: InterestPostingID(accountID, period)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete interest posting: %w", err)
+	}
+	return nil
+}
+
+// GetPosting returns nil and no error when the period has not been paid.
+func (r *InterestRepository) GetPosting(ctx context.Context, accountID, period string) (*models.InterestPosting, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.postingsTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: InterestPostingID(accountID, period)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interest posting: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var posting models.InterestPosting
+	if err := attributevalue.UnmarshalMap(result.Item, &posting); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal interest posting: %w", err)
+	}
+	return &posting, nil
+}
+
+// put stores item in table. When onlyNew is set an existing item is kept and
+// put reports false.
+func (r *InterestRepository) put(ctx context.Context, table string, item any, onlyNew bool) (bool, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal interest record: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      av,
+	}
+	if onlyNew {
+		input.ConditionExpression = aws.String("attribute_not_exists(id)")
+	}
+	_, err = r.client.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to store interest record: %w", err)
+	}
+	return true, nil
+}
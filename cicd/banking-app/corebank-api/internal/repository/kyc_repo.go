@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// KYCRepository keeps each account's latest identity submission and the
+// decision on it, under the account's ID.
+type KYCRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewKYCRepository(client *dynamodb.Client, table string) *KYCRepository {
+	return &KYCRepository{client: client, table: table}
+}
+
+// Put saves record, replacing the account's previous one.
+func (r *KYCRepository) Put(ctx context.Context, record *models.KYCRecord) error {
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal KYC record: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save KYC record: %w", err)
+	}
+	return nil
+}
+
+// Get returns nil and no error when the account has submitted nothing.
+func (r *KYCRepository) Get(ctx context.Context, accountID string) (*models.KYCRecord, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: accountID},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC record: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var record models.KYCRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KYC record: %w", err)
+	}
+	return &record, nil
+}
+
+// List returns the records with the given status, or every record when
+// status is empty, oldest submission first.
+func (r *KYCRepository) List(ctx context.Context, status string) ([]models.KYCRecord, error) {
+	input := &dynamodb.ScanInput{TableName: aws.String(r.table)}
+	if status != "" {
+		input.FilterExpression = aws.String("#status = :status")
+		input.ExpressionAttributeNames = map[string]string{"#status": "status"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+		}
+	}
+
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan KYC records: %w", err)
+		}
+		items = append(items, page.Items...)
+	}
+	var records []models.KYCRecord
+	if err := attributevalue.UnmarshalListOfMaps(items, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KYC records: %w", err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return aws.ToTime(records[i].SubmittedAt).Before(aws.ToTime(records[j].SubmittedAt))
+	})
+	return records, nil
+}
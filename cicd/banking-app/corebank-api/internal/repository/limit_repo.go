@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// ErrLimitReached is returned when adding to a counter would take it past
+// its limit.
+var ErrLimitReached = errors.New("limit reached")
+
+// CounterLimit is a counter to add to and the most it may reach, zero for
+// no limit.
+type CounterLimit struct {
+	Counter models.LimitCounter
+	Max     float64
+}
+
+// LimitRepository keeps each account's outflow per calendar period. Releases
+// of failed transactions are recorded in the same table, keyed
+// "release#<transaction ID>", so each is made once.
+type LimitRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewLimitRepository(client *dynamodb.Client, table string) *LimitRepository {
+	return &LimitRepository{client: client, table: table}
+}
+
+// Reserve adds amount to every counter in one transaction, creating those
+// that do not exist yet. If any counter would pass its limit none is
+// changed and it fails with ErrLimitReached.
+func (r *LimitRepository) Reserve(ctx context.Context, amount float64, counters []CounterLimit) error {
+	items := make([]types.TransactWriteItem, len(counters))
+	for i, c := range counters {
+		update, err := reserveUpdate(r.table, amount, c)
+		if err != nil {
+			return err
+		}
+		items[i] = types.TransactWriteItem{Update: update}
+	}
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if conditionFailed(err) {
+		return ErrLimitReached
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve limits: %w", err)
+	}
+	return nil
+}
+
+// reserveUpdate adds amount to counter c, on condition that it stays within
+// c.Max. An amount over the limit on its own fails with ErrLimitReached
+// before anything is sent, as a counter that does not exist yet passes the
+// condition whatever is added to it.
+func reserveUpdate(table string, amount float64, c CounterLimit) (*types.Update, error) {
+	update := &types.Update{
+		TableName: aws.String(table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: c.Counter.ID},
+		},
+		UpdateExpression: aws.String("ADD #used :amount SET account_id = :account_id, #period = :period, #start = :start"),
+		ExpressionAttributeNames: map[string]string{
+			"#used":   "used",
+			"#period": "period",
+			"#start":  "start",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":amount":     number(amount),
+			":account_id": &types.AttributeValueMemberS{Value: c.Counter.AccountID},
+			":period":     &types.AttributeValueMemberS{Value: c.Counter.Period},
+			":start":      &types.AttributeValueMemberS{Value: c.Counter.Start},
+		},
+	}
+	if c.Max > 0 {
+		// used + amount <= max, without arithmetic in conditions; the
+		// room is rounded to cents so float error cannot refuse an
+		// outflow that exactly reaches the limit
+		room := math.Round((c.Max-amount)*100) / 100
+		if room < 0 {
+			return nil, ErrLimitReached
+		}
+		update.ConditionExpression = aws.String("attribute_not_exists(#used) OR #used <= :room")
+		update.ExpressionAttributeValues[":room"] = number(room)
+	}
+	return update, nil
+}
+
+// Release takes amount back off the counters that exist. With a
+// releaseID the release is recorded and made at most once; without one, as
+// when a reservation is cancelled straight away, it is made every time.
+func (r *LimitRepository) Release(ctx context.Context, releaseID string, amount float64, counterIDs []string) error {
+	var items []types.TransactWriteItem
+	for _, id := range counterIDs {
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName: aws.String(r.table),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: id},
+			},
+			UpdateExpression:          aws.String("ADD #used :amount"),
+			ConditionExpression:       aws.String("attribute_exists(#used)"),
+			ExpressionAttributeNames:  map[string]string{"#used": "used"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":amount": number(-amount)},
+		}})
+	}
+	if releaseID != "" {
+		items = append(items, types.TransactWriteItem{Put: &types.Put{
+			TableName: aws.String(r.table),
+			Item: map[string]types.AttributeValue{
+				"id":          &types.AttributeValueMemberS{Value: "release#" + releaseID},
+				"released_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(id)"),
+		}})
+	}
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	// Released before, or never counted
+	if conditionFailed(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release limits: %w", err)
+	}
+	return nil
+}
+
+// Used returns what each counter has counted, zero for those that do not
+// exist yet.
+func (r *LimitRepository) Used(ctx context.Context, counterIDs []string) (map[string]float64, error) {
+	used := make(map[string]float64, len(counterIDs))
+	for _, id := range counterIDs {
+		result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(r.table),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: id},
+			},
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get limit counter: %w", err)
+		}
+		var counter models.LimitCounter
+		if err := attributevalue.UnmarshalMap(result.Item, &counter); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal limit counter: %w", err)
+		}
+		used[id] = counter.Used
+	}
+	return used, nil
+}
+
+func number(v float64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatFloat(v, 'f', -1, 64)}
+}
+
+// conditionFailed reports whether a transaction was cancelled because an
+// item's condition failed.
+func conditionFailed(err error) bool {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+func TestReserveUpdate(t *testing.T) {
+	counter := models.LimitCounter{ID: "acc#day#2026-10-19", AccountID: "acc", Period: "day", Start: "2026-10-19"}
+	tests := []struct {
+		name      string
+		amount    float64
+		max       float64
+		wantErr   error
+		condition string
+		room      string
+	}{
+		{name: "no limit", amount: 1e6, max: 0},
+		{name: "within the limit", amount: 150, max: 400, condition: "attribute_not_exists(#used) OR #used <= :room", room: "250"},
+		{name: "exactly the limit", amount: 400, max: 400, condition: "attribute_not_exists(#used) OR #used <= :room", room: "0"},
+		{name: "rounded to the cent", amount: 0.1 + 0.2, max: 0.3, condition: "attribute_not_exists(#used) OR #used <= :room", room: "0"},
+		{name: "over the limit on its own", amount: 450, max: 400, wantErr: ErrLimitReached},
+		{name: "a cent over the limit", amount: 400.01, max: 400, wantErr: ErrLimitReached},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := reserveUpdate("limits", tt.amount, CounterLimit{Counter: counter, Max: tt.max})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := aws.ToString(update.ConditionExpression); got != tt.condition {
+				t.Fatalf("condition %q, want %q", got, tt.condition)
+			}
+			room, ok := update.ExpressionAttributeValues[":room"].(*types.AttributeValueMemberN)
+			if tt.room == "" {
+				if ok {
+					t.Fatalf("unexpected room %s", room.Value)
+				}
+				return
+			}
+			if !ok || room.Value != tt.room {
+				t.Fatalf("room %v, want %s", update.ExpressionAttributeValues[":room"], tt.room)
+			}
+		})
+	}
+}
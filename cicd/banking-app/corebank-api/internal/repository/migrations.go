@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// Migration is a one-off change to stored data. Each is applied once per
+// environment and recorded in the migrations table. Up must be safe to run
+// again if it fails part way.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(ctx context.Context, client *dynamodb.Client, tables Tables) error
+}
+
+// migrations are applied in order. Append new ones; never edit or reorder
+// ones that have shipped.
+var migrations = []Migration{
+	{
+		ID:          "0001_account_status",
+		Description: "mark accounts created before statuses existed as active",
+		Up:          backfillAccountStatus,
+	},
+}
+
+// MigrationState reports whether a migration has been applied.
+type MigrationState struct {
+	ID          string     `json:"id" dynamodbav:"id"`
+	Description string     `json:"description" dynamodbav:"description"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty" dynamodbav:"applied_at,omitempty"`
+}
+
+// MigrationStatus lists every known migration and when it was applied.
+func MigrationStatus(ctx context.Context, client *dynamodb.Client, tables Tables) ([]MigrationState, error) {
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		result, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(tables.Migrations),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: m.ID},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", m.ID, err)
+		}
+
+		state := MigrationState{ID: m.ID, Description: m.Description}
+		if result.Item != nil {
+			if err := attributevalue.UnmarshalMap(result.Item, &state); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal migration %s: %w", m.ID, err)
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Migrate creates missing tables, then applies pending migrations in order,
+// stopping at the first failure. It returns the migrations it applied.
+func Migrate(ctx context.Context, client *dynamodb.Client, tables Tables) ([]MigrationState, error) {
+	if err := CreateTables(ctx, client, tables); err != nil {
+		return nil, err
+	}
+
+	states, err := MigrationStatus(ctx, client, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []MigrationState
+	for i, m := range migrations {
+		if states[i].AppliedAt != nil {
+			continue
+		}
+		if err := m.Up(ctx, client, tables); err != nil {
+			return applied, fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+
+		now := time.Now().UTC()
+		state := MigrationState{ID: m.ID, Description: m.Description, AppliedAt: &now}
+		item, err := attributevalue.MarshalMap(state)
+		if err != nil {
+			return applied, fmt.Errorf("failed to marshal migration %s: %w", m.ID, err)
+		}
+		_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(tables.Migrations),
+			Item:      item,
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to record migration %s: %w", m.ID, err)
+		}
+		applied = append(applied, state)
+	}
+	return applied, nil
+}
+
+func backfillAccountStatus(ctx context.Context, client *dynamodb.Client, tables Tables) error {
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                aws.String(tables.Accounts),
+		ProjectionExpression:     aws.String("id"),
+		FilterExpression:         aws.String("attribute_not_exists(#status) OR #status = :empty"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberS{Value: ""},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to scan accounts: %w", err)
+		}
+		for _, item := range page.Items {
+			// Skip accounts whose status was set since the scan
+			_, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                aws.String(tables.Accounts),
+				Key:                      map[string]types.AttributeValue{"id": item["id"]},
+				UpdateExpression:         aws.String("SET #status = :active"),
+				ConditionExpression:      aws.String("attribute_exists(id) AND (attribute_not_exists(#status) OR #status = :empty)"),
+				ExpressionAttributeNames: map[string]string{"#status": "status"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":active": &types.AttributeValueMemberS{Value: models.AccountStatusActive},
+					":empty":  &types.AttributeValueMemberS{Value: ""},
+				},
+			})
+			var condErr *types.ConditionalCheckFailedException
+			if err != nil && !errors.As(err, &condErr) {
+				return fmt.Errorf("failed to set account status: %w", err)
+			}
+		}
+	}
+	return nil
+}
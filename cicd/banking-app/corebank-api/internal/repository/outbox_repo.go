@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// OutboxRepository stores outbox entries, see the outbox package.
+type OutboxRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewOutboxRepository(client *dynamodb.Client, table string) *OutboxRepository {
+	return &OutboxRepository{
+		client: client,
+		table:  table,
+	}
+}
+
+// Put creates or replaces an entry.
+func (r *OutboxRepository) Put(ctx context.Context, entry *models.OutboxEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Create stores a new entry and reports false, leaving the stored one as it
+// is, if an entry with the same ID exists.
+func (r *OutboxRepository) Create(ctx context.Context, entry *models.OutboxEntry) (bool, error) {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to store outbox entry: %w", err)
+	}
+	return true, nil
+}
+
+// Get returns nil and no error when the entry does not exist.
+func (r *OutboxRepository) Get(ctx context.Context, id string) (*models.OutboxEntry, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox entry: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var entry models.OutboxEntry
+	if err := attributevalue.UnmarshalMap(result.Item, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// List returns entries with the given status, or all entries when status is
+// empty, oldest first.
+func (r *OutboxRepository) List(ctx context.Context, status string) ([]models.OutboxEntry, error) {
+	var entries []models.OutboxEntry
+	if status != "" {
+		err := query(ctx, r.client, &dynamodb.QueryInput{
+			TableName:                aws.String(r.table),
+			IndexName:                aws.String(outboxByStatusIndex),
+			KeyConditionExpression:   aws.String("#status = :status"),
+			ExpressionAttributeNames: map[string]string{"#status": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":status": &types.AttributeValueMemberS{Value: status},
+			},
+		}, &entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query outbox: %w", err)
+		}
+	} else {
+		paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+			TableName: aws.String(r.table),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan outbox: %w", err)
+			}
+
+			var items []models.OutboxEntry
+			if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal outbox entries: %w", err)
+			}
+			entries = append(entries, items...)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
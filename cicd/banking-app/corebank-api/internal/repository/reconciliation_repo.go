@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// ReconciliationRepository stores the report of every reconciliation run.
+type ReconciliationRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewReconciliationRepository(client *dynamodb.Client, table string) *ReconciliationRepository {
+	return &ReconciliationRepository{client: client, table: table}
+}
+
+func (r *ReconciliationRepository) Create(ctx context.Context, report *models.Reconciliation) error {
+	item, err := attributevalue.MarshalMap(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reconciliation: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+	return nil
+}
+
+// Get returns nil and no error when the reconciliation does not exist.
+func (r *ReconciliationRepository) Get(ctx context.Context, id string) (*models.Reconciliation, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var report models.Reconciliation
+	if err := attributevalue.UnmarshalMap(result.Item, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliation: %w", err)
+	}
+	return &report, nil
+}
+
+// List returns every reconciliation, newest first.
+func (r *ReconciliationRepository) List(ctx context.Context) ([]models.Reconciliation, error) {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{TableName: aws.String(r.table)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliations: %w", err)
+		}
+		items = append(items, page.Items...)
+	}
+	var reports []models.Reconciliation
+	if err := attributevalue.UnmarshalListOfMaps(items, &reports); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reconciliations: %w", err)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].StartedAt.After(reports[j].StartedAt)
+	})
+	return reports, nil
+}
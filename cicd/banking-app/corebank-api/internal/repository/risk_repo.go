@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// RiskDecisionRepository logs the risk rules' decision on each transaction.
+type RiskDecisionRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewRiskDecisionRepository(client *dynamodb.Client, table string) *RiskDecisionRepository {
+	return &RiskDecisionRepository{client: client, table: table}
+}
+
+func (r *RiskDecisionRepository) Create(ctx context.Context, decision *models.RiskDecision) error {
+	item, err := attributevalue.MarshalMap(decision)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk decision: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create risk decision: %w", err)
+	}
+	return nil
+}
+
+// Get returns nil and no error when the decision does not exist.
+func (r *RiskDecisionRepository) Get(ctx context.Context, id string) (*models.RiskDecision, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk decision: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var decision models.RiskDecision
+	if err := attributevalue.UnmarshalMap(result.Item, &decision); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk decision: %w", err)
+	}
+	return &decision, nil
+}
+
+// List returns the decisions on an account's transactions, or on every
+// account's when accountID is empty, newest first. A non-empty decision
+// limits them to those decided that way.
+func (r *RiskDecisionRepository) List(ctx context.Context, accountID, decision string) ([]models.RiskDecision, error) {
+	input := &dynamodb.ScanInput{TableName: aws.String(r.table)}
+	var conditions []string
+	values := map[string]types.AttributeValue{}
+	if accountID != "" {
+		conditions = append(conditions, "account_id = :account_id")
+		values[":account_id"] = &types.AttributeValueMemberS{Value: accountID}
+	}
+	if decision != "" {
+		conditions = append(conditions, "#decision = :decision")
+		values[":decision"] = &types.AttributeValueMemberS{Value: decision}
+		input.ExpressionAttributeNames = map[string]string{"#decision": "decision"}
+	}
+	if len(conditions) > 0 {
+		input.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+		input.ExpressionAttributeValues = values
+	}
+
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk decisions: %w", err)
+		}
+		items = append(items, page.Items...)
+	}
+	var decisions []models.RiskDecision
+	if err := attributevalue.UnmarshalListOfMaps(items, &decisions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk decisions: %w", err)
+	}
+
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].CreatedAt.After(decisions[j].CreatedAt)
+	})
+	return decisions, nil
+}
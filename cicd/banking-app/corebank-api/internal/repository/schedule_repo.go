@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// ScheduleRepository stores scheduled transfers and the history of their
+// executions.
+type ScheduleRepository struct {
+	client          *dynamodb.Client
+	table           string
+	executionsTable string
+}
+
+func NewScheduleRepository(client *dynamodb.Client, table, executionsTable string) *ScheduleRepository {
+	return &ScheduleRepository{client: client, table: table, executionsTable: executionsTable}
+}
+
+// ScheduleExecutionID is the key of an attempt at a schedule's occurrence.
+func ScheduleExecutionID(scheduleID string, occurrence time.Time, attempt int) string {
+	return scheduleID + "#" + occurrence.UTC().Format(time.RFC3339) + "#" + strconv.Itoa(attempt)
+}
+
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *models.Schedule) error {
+	if _, err := r.put(ctx, r.table, schedule, "attribute_not_exists(id)", nil); err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+	return nil
+}
+
+// Get returns nil and no error when the schedule does not exist.
+func (r *ScheduleRepository) Get(ctx context.Context, id string) (*models.Schedule, error) {
+	var schedule models.Schedule
+	found, err := r.get(ctx, r.table, id, &schedule)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Update replaces a schedule read with the given updated_at. If it has
+// changed since, the schedule is left alone and ErrConflict is returned.
+func (r *ScheduleRepository) Update(ctx context.Context, schedule *models.Schedule, prevUpdatedAt time.Time) error {
+	prev, err := attributevalue.Marshal(prevUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule: %w", err)
+	}
+	stored, err := r.put(ctx, r.table, schedule, "updated_at = :prev", map[string]types.AttributeValue{":prev": prev})
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	if !stored {
+		return ErrConflict
+	}
+	return nil
+}
+
+// List returns the schedules transferring from an account, or every
+// schedule when accountID is empty, oldest first.
+func (r *ScheduleRepository) List(ctx context.Context, accountID string) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if accountID == "" {
+		if err := r.scan(ctx, &dynamodb.ScanInput{TableName: aws.String(r.table)}, &schedules); err != nil {
+			return nil, fmt.Errorf("failed to scan schedules: %w", err)
+		}
+	} else {
+		err := query(ctx, r.client, &dynamodb.QueryInput{
+			TableName:              aws.String(r.table),
+			IndexName:              aws.String(schedulesByAccountIndex),
+			KeyConditionExpression: aws.String("from_account_id = :account_id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":account_id": &types.AttributeValueMemberS{Value: accountID},
+			},
+		}, &schedules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query schedules: %w", err)
+		}
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+// ListDue returns the active schedules whose next attempt is not after now.
+func (r *ScheduleRepository) ListDue(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	var active []models.Schedule
+	err := query(ctx, r.client, &dynamodb.QueryInput{
+		TableName:                aws.String(r.table),
+		IndexName:                aws.String(schedulesDueIndex),
+		KeyConditionExpression:   aws.String("#status = :status AND next_attempt_at <= :due"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: models.ScheduleActive},
+			":due":    dueBy(now),
+		},
+	}, &active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+
+	due := active[:0]
+	for _, schedule := range active {
+		if schedule.NextAttemptAt != nil && !schedule.NextAttemptAt.After(now) {
+			due = append(due, schedule)
+		}
+	}
+	return due, nil
+}
+
+// CreateExecution stores a new execution and reports whether it did. An
+// attempt is only recorded once, so concurrent schedulers cannot both make
+// it.
+func (r *ScheduleRepository) CreateExecution(ctx context.Context, execution *models.ScheduleExecution) (bool, error) {
+	execution.ID = ScheduleExecutionID(execution.ScheduleID, execution.Occurrence, execution.Attempt)
+	created, err := r.put(ctx, r.executionsTable, execution, "attribute_not_exists(id)", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create schedule execution: %w", err)
+	}
+	return created, nil
+}
+
+// PutExecution replaces a stored execution, e.g. with its outcome.
+func (r *ScheduleRepository) PutExecution(ctx context.Context, execution *models.ScheduleExecution) error {
+	if _, err := r.put(ctx, r.executionsTable, execution, "", nil); err != nil {
+		return fmt.Errorf("failed to store schedule execution: %w", err)
+	}
+	return nil
+}
+
+// GetExecution returns nil and no error when the execution does not exist.
+func (r *ScheduleRepository) GetExecution(ctx context.Context, id string) (*models.ScheduleExecution, error) {
+	var execution models.ScheduleExecution
+	found, err := r.get(ctx, r.executionsTable, id, &execution)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// Executions returns a schedule's executions, oldest first.
+func (r *ScheduleRepository) Executions(ctx context.Context, scheduleID string) ([]models.ScheduleExecution, error) {
+	var executions []models.ScheduleExecution
+	err := query(ctx, r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.executionsTable),
+		IndexName:              aws.String(executionsByScheduleIndex),
+		KeyConditionExpression: aws.String("schedule_id = :schedule_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":schedule_id": &types.AttributeValueMemberS{Value: scheduleID},
+		},
+	}, &executions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule executions: %w", err)
+	}
+
+	sort.Slice(executions, func(i, j int) bool {
+		return executions[i].StartedAt.Before(executions[j].StartedAt)
+	})
+	return executions, nil
+}
+
+// put stores item in table. With a condition that fails the item is kept
+// and put reports false.
+func (r *ScheduleRepository) put(ctx context.Context, table string, item any, condition string, values map[string]types.AttributeValue) (bool, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return false, err
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String(table),
+		Item:                      av,
+		ExpressionAttributeValues: values,
+	}
+	if condition != "" {
+		input.ConditionExpression = aws.String(condition)
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *ScheduleRepository) get(ctx context.Context, table, id string, out any) (bool, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s item: %w", table, err)
+	}
+	if result.Item == nil {
+		return false, nil
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, out); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s item: %w", table, err)
+	}
+	return true, nil
+}
+
+func (r *ScheduleRepository) scan(ctx context.Context, input *dynamodb.ScanInput, out any) error {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+	}
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
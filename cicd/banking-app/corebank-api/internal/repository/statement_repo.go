@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// StatementRepository stores monthly statements, keyed by account ID and
+// period.
+type StatementRepository struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewStatementRepository(client *dynamodb.Client, table string) *StatementRepository {
+	return &StatementRepository{
+		client: client,
+		table:  table,
+	}
+}
+
+// StatementID is the key of an account's statement for period.
+func StatementID(accountID, period string) string {
+	return accountID + "#" + period
+}
+
+// Create stores a statement and reports whether it did. Statements are never
+// replaced, so concurrent generators cannot overwrite each other.
+func (r *StatementRepository) Create(ctx context.Context, statement *models.Statement) (bool, error) {
+	statement.ID = StatementID(statement.AccountID, statement.Period)
+	item, err := attributevalue.MarshalMap(statement)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to store statement: %w", err)
+	}
+	return true, nil
+}
+
+// Get returns nil and no error when no statement is stored for the period.
+func (r *StatementRepository) Get(ctx context.Context, accountID, period string) (*models.Statement, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: StatementID(accountID, period)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var statement models.Statement
+	if err := attributevalue.UnmarshalMap(result.Item, &statement); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal statement: %w", err)
+	}
+	return &statement, nil
+}
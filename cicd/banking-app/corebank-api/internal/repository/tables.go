@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/config"
+)
+
+// Tables holds the full names of the DynamoDB tables, prefix and suffix
+// included.
+type Tables struct {
+	Accounts   string
+	Outbox     string
+	Migrations string
+	Statements string
+	// AccountHistory holds each account's log of field changes.
+	AccountHistory     string
+	InterestAccruals   string
+	InterestPostings   string
+	Holds              string
+	Schedules          string
+	ScheduleExecutions string
+	Webhooks           string
+	WebhookDeliveries  string
+	Reconciliations    string
+	BalancePostings    string
+	RiskDecisions      string
+	LimitCounters      string
+	KYCRecords         string
+}
+
+// TablesFromConfig resolves the configured table names.
+func TablesFromConfig(cfg config.DynamoDBConfig) Tables {
+	return Tables{
+		Accounts:           cfg.Table(cfg.AccountsTable),
+		Outbox:             cfg.Table(cfg.OutboxTable),
+		Migrations:         cfg.Table(cfg.MigrationsTable),
+		Statements:         cfg.Table(cfg.StatementsTable),
+		AccountHistory:     cfg.Table(cfg.AccountHistoryTable),
+		InterestAccruals:   cfg.Table(cfg.InterestAccrualsTable),
+		InterestPostings:   cfg.Table(cfg.InterestPostingsTable),
+		Holds:              cfg.Table(cfg.HoldsTable),
+		Schedules:          cfg.Table(cfg.SchedulesTable),
+		ScheduleExecutions: cfg.Table(cfg.ScheduleExecutionsTable),
+		Webhooks:           cfg.Table(cfg.WebhooksTable),
+		WebhookDeliveries:  cfg.Table(cfg.WebhookDeliveriesTable),
+		Reconciliations:    cfg.Table(cfg.ReconciliationsTable),
+		BalancePostings:    cfg.Table(cfg.BalancePostingsTable),
+		RiskDecisions:      cfg.Table(cfg.RiskDecisionsTable),
+		LimitCounters:      cfg.Table(cfg.LimitCountersTable),
+		KYCRecords:         cfg.Table(cfg.KYCRecordsTable),
+	}
+}
+
+func (t Tables) all() []string {
+	return []string{t.Accounts, t.Outbox, t.Migrations, t.Statements, t.AccountHistory, t.InterestAccruals, t.InterestPostings,
+		t.Holds, t.Schedules, t.ScheduleExecutions, t.Webhooks, t.WebhookDeliveries, t.Reconciliations,
+		t.BalancePostings, t.RiskDecisions, t.LimitCounters, t.KYCRecords}
+}
+
+// Global secondary indexes, each projecting every attribute. Tables listed
+// by an attribute, or polled for items due by a time, are queried through
+// one instead of scanned.
+const (
+	holdsByAccountIndex           = "account_id-status"
+	holdsByExpiryIndex            = "status-expires_at"
+	schedulesByAccountIndex       = "from_account_id-created_at"
+	schedulesDueIndex             = "status-next_attempt_at"
+	executionsByScheduleIndex     = "schedule_id-started_at"
+	deliveriesBySubscriptionIndex = "subscription_id-created_at"
+	deliveriesDueIndex            = "status-next_attempt_at"
+	outboxByStatusIndex           = "status-created_at"
+)
+
+// index is a global secondary index keyed by string attributes.
+type index struct {
+	name    string
+	hash    string
+	sortKey string
+}
+
+func (t Tables) indexes() map[string][]index {
+	return map[string][]index{
+		t.Holds: {
+			{name: holdsByAccountIndex, hash: "account_id", sortKey: "status"},
+			{name: holdsByExpiryIndex, hash: "status", sortKey: "expires_at"},
+		},
+		t.Schedules: {
+			{name: schedulesByAccountIndex, hash: "from_account_id", sortKey: "created_at"},
+			{name: schedulesDueIndex, hash: "status", sortKey: "next_attempt_at"},
+		},
+		t.ScheduleExecutions: {
+			{name: executionsByScheduleIndex, hash: "schedule_id", sortKey: "started_at"},
+		},
+		t.WebhookDeliveries: {
+			{name: deliveriesBySubscriptionIndex, hash: "subscription_id", sortKey: "created_at"},
+			{name: deliveriesDueIndex, hash: "status", sortKey: "next_attempt_at"},
+		},
+		t.Outbox: {
+			{name: outboxByStatusIndex, hash: "status", sortKey: "created_at"},
+		},
+	}
+}
+
+// indexBackfillTimeout bounds the wait for an index added to an existing
+// table, which DynamoDB fills from every item already stored.
+const indexBackfillTimeout = 30 * time.Minute
+
+// CreateTables creates any missing table and index and waits for them to
+// become active. Every table is keyed by a string "id".
+func CreateTables(ctx context.Context, client *dynamodb.Client, tables Tables) error {
+	indexes := tables.indexes()
+	for _, table := range tables.all() {
+		if err := createTable(ctx, client, table, indexes[table]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createTable(ctx context.Context, client *dynamodb.Client, table string, indexes []index) error {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	var notFound *types.ResourceNotFoundException
+	if err == nil {
+		return createIndexes(ctx, client, out.Table, indexes)
+	}
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to describe table %s: %w", table, err)
+	}
+
+	slog.Info("creating table", "table", table)
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(table),
+		AttributeDefinitions: attributeDefinitions(indexes...),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	}
+	for _, idx := range indexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, idx.definition())
+	}
+	if _, err := client.CreateTable(ctx, input); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", table, err)
+	}
+
+	// Wait until the table is usable so readiness doesn't flap on startup
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	err = waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)}, 2*time.Minute)
+	if err != nil {
+		return fmt.Errorf("table %s did not become active: %w", table, err)
+	}
+	return nil
+}
+
+// createIndexes adds the indexes an existing table lacks, one at a time as
+// DynamoDB requires, and waits for each to become active.
+func createIndexes(ctx context.Context, client *dynamodb.Client, table *types.TableDescription, indexes []index) error {
+	name := aws.ToString(table.TableName)
+	existing := make(map[string]types.IndexStatus)
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		existing[aws.ToString(gsi.IndexName)] = gsi.IndexStatus
+	}
+
+	for _, idx := range indexes {
+		status, ok := existing[idx.name]
+		if status == types.IndexStatusActive {
+			continue
+		}
+		if !ok {
+			slog.Info("creating index", "table", name, "index", idx.name)
+			create := idx.definition()
+			_, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+				TableName:            aws.String(name),
+				AttributeDefinitions: attributeDefinitions(idx),
+				GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+					Create: &types.CreateGlobalSecondaryIndexAction{
+						IndexName:  create.IndexName,
+						KeySchema:  create.KeySchema,
+						Projection: create.Projection,
+					},
+				}},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create index %s on table %s: %w", idx.name, name, err)
+			}
+		}
+		if err := waitForIndex(ctx, client, name, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexPollInterval is how often waitForIndex checks on an index.
+const indexPollInterval = 5 * time.Second
+
+func waitForIndex(ctx context.Context, client *dynamodb.Client, table, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, indexBackfillTimeout)
+	defer cancel()
+	for {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return fmt.Errorf("index %s on table %s did not become active: %w", name, table, err)
+		}
+		for _, gsi := range out.Table.GlobalSecondaryIndexes {
+			if aws.ToString(gsi.IndexName) == name && gsi.IndexStatus == types.IndexStatusActive {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("index %s on table %s did not become active: %w", name, table, ctx.Err())
+		case <-time.After(indexPollInterval):
+		}
+	}
+}
+
+func (idx index) definition() types.GlobalSecondaryIndex {
+	keys := []types.KeySchemaElement{{AttributeName: aws.String(idx.hash), KeyType: types.KeyTypeHash}}
+	if idx.sortKey != "" {
+		keys = append(keys, types.KeySchemaElement{AttributeName: aws.String(idx.sortKey), KeyType: types.KeyTypeRange})
+	}
+	return types.GlobalSecondaryIndex{
+		IndexName:  aws.String(idx.name),
+		KeySchema:  keys,
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+}
+
+// attributeDefinitions defines "id" and the key attributes of the indexes,
+// all strings.
+func attributeDefinitions(indexes ...index) []types.AttributeDefinition {
+	defined := map[string]bool{"id": true}
+	defs := []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS}}
+	for _, idx := range indexes {
+		for _, attr := range []string{idx.hash, idx.sortKey} {
+			if attr == "" || defined[attr] {
+				continue
+			}
+			defined[attr] = true
+			defs = append(defs, types.AttributeDefinition{AttributeName: aws.String(attr), AttributeType: types.ScalarAttributeTypeS})
+		}
+	}
+	return defs
+}
+
+// query reads every page of an index query into out, a pointer to a slice.
+func query(ctx context.Context, client *dynamodb.Client, input *dynamodb.QueryInput, out any) error {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewQueryPaginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+	}
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// dueBy returns a sort key bound for the times stored up to t. Times are
+// stored in UTC as RFC 3339 strings, which drop trailing zero fractions and
+// so only sort by the second; the bound is the next whole second, and
+// callers compare the times they read with t.
+func dueBy(t time.Time) types.AttributeValue {
+	bound := t.UTC().Truncate(time.Second).Add(time.Second)
+	return &types.AttributeValueMemberS{Value: bound.Format(time.RFC3339Nano)}
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/config"
+)
+
+func TestTablesFromConfig(t *testing.T) {
+	cfg := config.Default().DynamoDB
+	cfg.TablePrefix, cfg.TableSuffix = "dev-", "-eu"
+	tables := TablesFromConfig(cfg)
+
+	if tables.Accounts != "dev-BankAccounts-eu" || tables.KYCRecords != "dev-BankKYCRecords-eu" {
+		t.Fatalf("tables = %+v, want the prefix and suffix on every name", tables)
+	}
+	// Every table is created, and no two share a name
+	all := tables.all()
+	if fields := reflect.TypeOf(tables).NumField(); len(all) != fields {
+		t.Fatalf("all lists %d tables, Tables has %d", len(all), fields)
+	}
+	seen := make(map[string]bool)
+	for _, name := range all {
+		if !strings.HasPrefix(name, "dev-") || !strings.HasSuffix(name, "-eu") || seen[name] {
+			t.Errorf("unexpected or duplicate table %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+// The client sends requests to the configured endpoint.
+func TestNewDynamoDBClientEndpoint(t *testing.T) {
+	var target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		target = r.Header.Get("X-Amz-Target")
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		io.WriteString(w, `{"TableNames":["dev-BankAccounts"]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewDynamoDBClient(context.Background(),
+		config.AWSConfig{Region: "eu-west-1", AccessKeyID: "local", SecretAccessKey: "local"},
+		config.DynamoDBConfig{Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := client.ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "DynamoDB_20120810.ListTables" || len(out.TableNames) != 1 || out.TableNames[0] != "dev-BankAccounts" {
+		t.Fatalf("got %v from %q, want the endpoint's tables", out.TableNames, target)
+	}
+}
+
+// fakeDynamoDB serves the table operations CreateTables makes, with every
+// table and index active as soon as it is created, and answers queries with
+// items.
+type fakeDynamoDB struct {
+	mu sync.Mutex
+	// indexes are those of each existing table
+	indexes map[string][]string
+	// updates are the indexes added by UpdateTable, as table/index, and
+	// the attributes each defined
+	updates    []string
+	attributes [][]string
+	queries    []map[string]any
+	items      []map[string]any
+}
+
+type fakeRequest struct {
+	TableName              string
+	AttributeDefinitions   []struct{ AttributeName string }
+	GlobalSecondaryIndexes []struct {
+		IndexName string
+	}
+	GlobalSecondaryIndexUpdates []struct {
+		Create struct{ IndexName string }
+	}
+}
+
+func (f *fakeDynamoDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	var req fakeRequest
+	json.Unmarshal(body, &req)
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "CreateTable":
+		f.indexes[req.TableName] = []string{}
+		for _, idx := range req.GlobalSecondaryIndexes {
+			f.indexes[req.TableName] = append(f.indexes[req.TableName], idx.IndexName)
+		}
+		f.describe(w, "TableDescription", req.TableName)
+	case "UpdateTable":
+		var attributes []string
+		for _, def := range req.AttributeDefinitions {
+			attributes = append(attributes, def.AttributeName)
+		}
+		for _, update := range req.GlobalSecondaryIndexUpdates {
+			f.indexes[req.TableName] = append(f.indexes[req.TableName], update.Create.IndexName)
+			f.updates = append(f.updates, req.TableName+"/"+update.Create.IndexName)
+			f.attributes = append(f.attributes, attributes)
+		}
+		f.describe(w, "TableDescription", req.TableName)
+	case "DescribeTable":
+		if _, ok := f.indexes[req.TableName]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"no such table"}`)
+			return
+		}
+		f.describe(w, "Table", req.TableName)
+	case "Query":
+		var query map[string]any
+		json.Unmarshal(body, &query)
+		f.queries = append(f.queries, query)
+		json.NewEncoder(w).Encode(map[string]any{"Items": f.items, "Count": len(f.items)})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"__type":"com.amazon.coral.validate#ValidationException","message":"unsupported"}`)
+	}
+}
+
+// describe writes the table's description under key.
+func (f *fakeDynamoDB) describe(w io.Writer, key, table string) {
+	indexes := []map[string]string{}
+	for _, name := range f.indexes[table] {
+		indexes = append(indexes, map[string]string{"IndexName": name, "IndexStatus": "ACTIVE"})
+	}
+	json.NewEncoder(w).Encode(map[string]any{key: map[string]any{
+		"TableName": table, "TableStatus": "ACTIVE", "GlobalSecondaryIndexes": indexes,
+	}})
+}
+
+func newFakeDynamoDB(t *testing.T, f *fakeDynamoDB) *dynamodb.Client {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	client, err := NewDynamoDBClient(context.Background(),
+		config.AWSConfig{Region: "eu-west-1", AccessKeyID: "local", SecretAccessKey: "local"},
+		config.DynamoDBConfig{Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// Missing tables are created with their indexes, and existing tables get
+// the indexes they lack.
+func TestCreateTablesIndexes(t *testing.T) {
+	tables := TablesFromConfig(config.Default().DynamoDB)
+	f := &fakeDynamoDB{indexes: map[string][]string{
+		tables.Holds:  {},
+		tables.Outbox: {outboxByStatusIndex},
+	}}
+	client := newFakeDynamoDB(t, f)
+
+	if err := CreateTables(context.Background(), client, tables); err != nil {
+		t.Fatal(err)
+	}
+	for table, indexes := range tables.indexes() {
+		var want []string
+		for _, idx := range indexes {
+			want = append(want, idx.name)
+		}
+		if got := f.indexes[table]; !slices.Equal(got, want) {
+			t.Errorf("table %s has indexes %v, want %v", table, got, want)
+		}
+	}
+	if len(f.indexes) != len(tables.all()) {
+		t.Errorf("created %d tables, want %d", len(f.indexes), len(tables.all()))
+	}
+
+	// An index is added by itself, with only its own attributes defined
+	want := []string{tables.Holds + "/" + holdsByAccountIndex, tables.Holds + "/" + holdsByExpiryIndex}
+	if !slices.Equal(f.updates, want) {
+		t.Fatalf("updates = %v, want %v", f.updates, want)
+	}
+	if got := f.attributes[1]; !slices.Equal(got, []string{"id", "status", "expires_at"}) {
+		t.Fatalf("expiry index defined %v", got)
+	}
+}
+
+func TestDueBy(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{name: "whole second", t: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), want: "2026-10-19T12:00:01Z"},
+		{name: "within a second", t: time.Date(2026, 10, 19, 12, 0, 0, 500, time.UTC), want: "2026-10-19T12:00:01Z"},
+		{name: "other zone", t: time.Date(2026, 10, 19, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), want: "2026-10-19T12:00:01Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound := dueBy(tt.t).(*types.AttributeValueMemberS).Value
+			if bound != tt.want {
+				t.Fatalf("bound = %s, want %s", bound, tt.want)
+			}
+			// A stored time up to t sorts no later than the bound
+			stored := tt.t.UTC().Format(time.RFC3339Nano)
+			if stored > bound {
+				t.Fatalf("%s sorts after %s", stored, bound)
+			}
+		})
+	}
+}
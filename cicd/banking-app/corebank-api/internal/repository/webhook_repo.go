@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// WebhookRepository stores webhook subscriptions and the log of deliveries
+// made to them.
+type WebhookRepository struct {
+	client          *dynamodb.Client
+	table           string
+	deliveriesTable string
+}
+
+func NewWebhookRepository(client *dynamodb.Client, table, deliveriesTable string) *WebhookRepository {
+	return &WebhookRepository{client: client, table: table, deliveriesTable: deliveriesTable}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	if _, err := r.put(ctx, r.table, subscription, "attribute_not_exists(id)", nil); err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription returns nil and no error when the subscription does not
+// exist.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	found, err := r.get(ctx, r.table, id, &subscription)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions returns every subscription, oldest first.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.scan(ctx, &dynamodb.ScanInput{TableName: aws.String(r.table)}, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to scan webhook subscriptions: %w", err)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes a subscription. Its delivery log is kept.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if _, err := r.put(ctx, r.deliveriesTable, delivery, "attribute_not_exists(id)", nil); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDelivery returns nil and no error when the delivery does not exist.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	found, err := r.get(ctx, r.deliveriesTable, id, &delivery)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// UpdateDelivery replaces a delivery read with the given updated_at. If it
+// has changed since, the delivery is left alone and ErrConflict is
+// returned.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery, prevUpdatedAt time.Time) error {
+	prev, err := attributevalue.Marshal(prevUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+	stored, err := r.put(ctx, r.deliveriesTable, delivery, "updated_at = :prev", map[string]types.AttributeValue{":prev": prev})
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if !stored {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Deliveries returns a subscription's deliveries, newest first.
+func (r *WebhookRepository) Deliveries(ctx context.Context, subscriptionID string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := query(ctx, r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.deliveriesTable),
+		IndexName:              aws.String(deliveriesBySubscriptionIndex),
+		KeyConditionExpression: aws.String("subscription_id = :subscription_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":subscription_id": &types.AttributeValueMemberS{Value: subscriptionID},
+		},
+	}, &deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// ListDue returns the pending deliveries whose next attempt is not after
+// now, oldest first.
+func (r *WebhookRepository) ListDue(ctx context.Context, now time.Time) ([]models.WebhookDelivery, error) {
+	var pending []models.WebhookDelivery
+	err := query(ctx, r.client, &dynamodb.QueryInput{
+		TableName:                aws.String(r.deliveriesTable),
+		IndexName:                aws.String(deliveriesDueIndex),
+		KeyConditionExpression:   aws.String("#status = :status AND next_attempt_at <= :due"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: models.DeliveryPending},
+			":due":    dueBy(now),
+		},
+	}, &pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+
+	due := pending[:0]
+	for _, delivery := range pending {
+		if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	return due, nil
+}
+
+// put stores item in table. With a condition that fails the item is kept
+// and put reports false.
+func (r *WebhookRepository) put(ctx context.Context, table string, item any, condition string, values map[string]types.AttributeValue) (bool, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return false, err
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String(table),
+		Item:                      av,
+		ExpressionAttributeValues: values,
+	}
+	if condition != "" {
+		input.ConditionExpression = aws.String(condition)
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *WebhookRepository) get(ctx context.Context, table, id string, out any) (bool, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s item: %w", table, err)
+	}
+	if result.Item == nil {
+		return false, nil
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, out); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s item: %w", table, err)
+	}
+	return true, nil
+}
+
+func (r *WebhookRepository) scan(ctx context.Context, input *dynamodb.ScanInput, out any) error {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+	}
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
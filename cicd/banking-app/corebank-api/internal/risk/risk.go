@@ -0,0 +1,116 @@
+// Package risk screens transactions before they are submitted. Each rule of
+// a configurable set decides to allow, review or deny a transaction; the
+// most severe decision stands and is logged with every rule's reasons.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/auth"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/metrics"
+	"github.com/corebank-api/internal/models"
+)
+
+// Input is a transaction being screened.
+type Input struct {
+	Account     *models.Account
+	Transaction *models.Transaction
+	// Now is when the transaction was submitted.
+	Now time.Time
+}
+
+// Rule decides on a transaction. Rules are given every transaction and
+// return allow, with no reason, for the ones they do not apply to.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, in *Input) (decision, reason string, err error)
+}
+
+// Store persists decisions; repository.RiskDecisionRepository implements it
+// against DynamoDB.
+type Store interface {
+	Create(ctx context.Context, decision *models.RiskDecision) error
+	// Get returns nil and no error when the decision does not exist.
+	Get(ctx context.Context, id string) (*models.RiskDecision, error)
+	// List returns the decisions on an account's transactions, or every
+	// account's if accountID is empty, newest first, limited to those
+	// decided as decision unless it is empty.
+	List(ctx context.Context, accountID, decision string) ([]models.RiskDecision, error)
+}
+
+// Engine evaluates rules and logs their decisions.
+type Engine struct {
+	rules []Rule
+	store Store
+}
+
+// NewEngine screens transactions with rules. With no rules every
+// transaction is allowed and nothing is logged.
+func NewEngine(rules []Rule, store Store) *Engine {
+	return &Engine{rules: rules, store: store}
+}
+
+// Evaluate runs every rule on a transaction about to be submitted and
+// returns the decision, or nil if there are no rules. A rule that cannot be
+// evaluated, e.g. because the transaction service is down, asks for review
+// rather than holding up or waving through the transaction.
+func (e *Engine) Evaluate(ctx context.Context, account *models.Account, txn *models.Transaction) *models.RiskDecision {
+	if len(e.rules) == 0 {
+		return nil
+	}
+	in := &Input{Account: account, Transaction: txn, Now: time.Now()}
+	decision := &models.RiskDecision{
+		ID:        uuid.New().String(),
+		AccountID: account.ID,
+		Type:      txn.Type,
+		Amount:    txn.Amount,
+		Decision:  models.RiskAllow,
+		Rules:     make([]models.RiskRuleResult, 0, len(e.rules)),
+		RequestID: logging.RequestID(ctx),
+		CreatedAt: in.Now.UTC(),
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		decision.Actor = p.Subject
+	}
+
+	for _, rule := range e.rules {
+		verdict, reason, err := rule.Evaluate(ctx, in)
+		if err != nil {
+			verdict, reason = models.RiskReview, fmt.Sprintf("could not be evaluated: %v", err)
+		}
+		decision.Rules = append(decision.Rules, models.RiskRuleResult{Rule: rule.Name(), Decision: verdict, Reason: reason})
+		if severity(verdict) > severity(decision.Decision) {
+			decision.Decision = verdict
+		}
+	}
+	metrics.RiskDecision(decision.Decision)
+	if decision.Decision != models.RiskAllow {
+		logging.FromContext(ctx).Warn("transaction flagged by risk rules", "decision_id", decision.ID,
+			"account_id", account.ID, "decision", decision.Decision)
+	}
+	return decision
+}
+
+// Record logs a decision. The decision on a recorded transaction takes the
+// transaction's ID.
+func (e *Engine) Record(ctx context.Context, decision *models.RiskDecision) error {
+	if decision.TransactionID != "" {
+		decision.ID = decision.TransactionID
+	}
+	return e.store.Create(ctx, decision)
+}
+
+func severity(decision string) int {
+	switch decision {
+	case models.RiskReview:
+		return 1
+	case models.RiskDeny:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,345 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// TransactionLister pages through an account's transactions, newest first;
+// upstream.TransactionService implements it.
+type TransactionLister interface {
+	List(ctx context.Context, accountID string, limit, offset int) ([]models.Transaction, error)
+}
+
+// pageSize is how many transactions the velocity rule reads per request,
+// and maxPages the most pages it reads before giving up.
+const (
+	pageSize = 100
+	maxPages = 10
+)
+
+// RuleConfig is one rule of a rules file. Which fields apply depends on
+// Type; amounts of zero disable the check they configure.
+type RuleConfig struct {
+	// Name identifies the rule in decisions and must be unique.
+	Name string `yaml:"name"`
+	// Type is amount, velocity, new_account or unusual_hours.
+	Type string `yaml:"type"`
+	// Decision is what the rule decides when it matches, review or deny;
+	// review if empty. The amount rule decides with its thresholds instead.
+	Decision string `yaml:"decision"`
+	// TransactionTypes limits the rule to these transaction types; every
+	// type if empty.
+	TransactionTypes []string `yaml:"transaction_types"`
+
+	// ReviewAbove and DenyAbove are the amount rule's thresholds.
+	ReviewAbove float64 `yaml:"review_above"`
+	DenyAbove   float64 `yaml:"deny_above"`
+
+	// Window is the velocity rule's period. It matches when the account's
+	// transactions in the window, counting the new one and leaving out
+	// failed ones, number more than MaxCount or add up to more than
+	// MaxAmount.
+	Window    time.Duration `yaml:"window"`
+	MaxCount  int           `yaml:"max_count"`
+	MaxAmount float64       `yaml:"max_amount"`
+
+	// MaxAge is how long the new_account rule considers an account new.
+	// It matches transactions on new accounts of more than MaxAmount, or
+	// any transaction if MaxAmount is zero.
+	MaxAge time.Duration `yaml:"max_age"`
+
+	// From and To, as HH:MM in the statements timezone, bound the
+	// unusual_hours rule's period, which wraps past midnight if To is
+	// earlier than From. It matches transactions in the period of more
+	// than MinAmount.
+	From      string  `yaml:"from"`
+	To        string  `yaml:"to"`
+	MinAmount float64 `yaml:"min_amount"`
+}
+
+// rulesFile is the layout of a rules file.
+type rulesFile struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// LoadRules reads the rules in a YAML file. The velocity rule reads recent
+// transactions from transactions, and the unusual_hours rule takes times of
+// day in loc.
+func LoadRules(path string, transactions TransactionLister, loc *time.Location) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk rules: %w", err)
+	}
+	defer file.Close()
+
+	// Reject unknown keys so a misspelt threshold doesn't silently disable
+	// a rule
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	var parsed rulesFile
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse risk rules %s: %w", path, err)
+	}
+	rules, err := NewRules(parsed.Rules, transactions, loc)
+	if err != nil {
+		return nil, fmt.Errorf("risk rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// NewRules builds rules from their configuration, reporting every invalid
+// rule at once.
+func NewRules(configs []RuleConfig, transactions TransactionLister, loc *time.Location) ([]Rule, error) {
+	var errs []error
+	var rules []Rule
+	seen := make(map[string]bool)
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			errs = append(errs, fmt.Errorf("rules[%d]: name is required", i))
+			continue
+		}
+		if seen[cfg.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate rule name", cfg.Name))
+			continue
+		}
+		seen[cfg.Name] = true
+		rule, err := newRule(cfg, transactions, loc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cfg.Name, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func newRule(cfg RuleConfig, transactions TransactionLister, loc *time.Location) (Rule, error) {
+	if cfg.Decision == "" {
+		cfg.Decision = models.RiskReview
+	}
+	if cfg.Decision != models.RiskReview && cfg.Decision != models.RiskDeny {
+		return nil, fmt.Errorf("decision must be review or deny, not %q", cfg.Decision)
+	}
+	for _, t := range cfg.TransactionTypes {
+		if t != "deposit" && t != "withdrawal" && t != "transfer" {
+			return nil, fmt.Errorf("unknown transaction type %q", t)
+		}
+	}
+	base := base{name: cfg.Name, decision: cfg.Decision, types: cfg.TransactionTypes}
+
+	switch cfg.Type {
+	case "amount":
+		if cfg.ReviewAbove < 0 || cfg.DenyAbove < 0 {
+			return nil, errors.New("review_above and deny_above must not be negative")
+		}
+		if cfg.ReviewAbove == 0 && cfg.DenyAbove == 0 {
+			return nil, errors.New("review_above or deny_above is required")
+		}
+		return &AmountRule{base: base, ReviewAbove: cfg.ReviewAbove, DenyAbove: cfg.DenyAbove}, nil
+	case "velocity":
+		if cfg.Window <= 0 {
+			return nil, errors.New("window must be positive")
+		}
+		if cfg.MaxCount < 0 || cfg.MaxAmount < 0 {
+			return nil, errors.New("max_count and max_amount must not be negative")
+		}
+		if cfg.MaxCount == 0 && cfg.MaxAmount == 0 {
+			return nil, errors.New("max_count or max_amount is required")
+		}
+		return &VelocityRule{base: base, Window: cfg.Window, MaxCount: cfg.MaxCount, MaxAmount: cfg.MaxAmount,
+			transactions: transactions}, nil
+	case "new_account":
+		if cfg.MaxAge <= 0 {
+			return nil, errors.New("max_age must be positive")
+		}
+		if cfg.MaxAmount < 0 {
+			return nil, errors.New("max_amount must not be negative")
+		}
+		return &NewAccountRule{base: base, MaxAge: cfg.MaxAge, MaxAmount: cfg.MaxAmount}, nil
+	case "unusual_hours":
+		from, err := parseClock(cfg.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		to, err := parseClock(cfg.To)
+		if err != nil {
+			return nil, fmt.Errorf("to: %w", err)
+		}
+		if from == to {
+			return nil, errors.New("from and to must differ")
+		}
+		if cfg.MinAmount < 0 {
+			return nil, errors.New("min_amount must not be negative")
+		}
+		return &HoursRule{base: base, From: from, To: to, MinAmount: cfg.MinAmount, Location: loc}, nil
+	}
+	return nil, fmt.Errorf("unknown rule type %q", cfg.Type)
+}
+
+// parseClock reads an HH:MM time of day as the time since midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// base holds what every rule has in common.
+type base struct {
+	name     string
+	decision string
+	// types limits the rule to these transaction types; every type if
+	// empty.
+	types []string
+}
+
+func (b base) Name() string {
+	return b.name
+}
+
+func (b base) appliesTo(txn *models.Transaction) bool {
+	return len(b.types) == 0 || slices.Contains(b.types, txn.Type)
+}
+
+// AmountRule reviews or denies transactions above a threshold.
+type AmountRule struct {
+	base
+	ReviewAbove float64
+	DenyAbove   float64
+}
+
+func (r *AmountRule) Evaluate(_ context.Context, in *Input) (string, string, error) {
+	amount := in.Transaction.Amount
+	switch {
+	case !r.appliesTo(in.Transaction):
+	case r.DenyAbove > 0 && amount > r.DenyAbove:
+		return models.RiskDeny, "amount " + formatAmount(amount) + " is above " + formatAmount(r.DenyAbove), nil
+	case r.ReviewAbove > 0 && amount > r.ReviewAbove:
+		return models.RiskReview, "amount " + formatAmount(amount) + " is above " + formatAmount(r.ReviewAbove), nil
+	}
+	return models.RiskAllow, "", nil
+}
+
+// VelocityRule flags an account making too many transactions, or moving
+// too much, within a window.
+type VelocityRule struct {
+	base
+	Window    time.Duration
+	MaxCount  int
+	MaxAmount float64
+
+	transactions TransactionLister
+}
+
+func (r *VelocityRule) Evaluate(ctx context.Context, in *Input) (string, string, error) {
+	if !r.appliesTo(in.Transaction) {
+		return models.RiskAllow, "", nil
+	}
+	since := in.Now.Add(-r.Window)
+	count, total := 1, in.Transaction.Amount
+	// Reading stops as soon as either limit is passed, so only an account
+	// with many transactions under both reads far back
+pages:
+	for offset := 0; !r.exceeded(count, total); offset += pageSize {
+		if offset == maxPages*pageSize {
+			return "", "", fmt.Errorf("more than %d transactions in %s", offset, r.Window)
+		}
+		page, err := r.transactions.List(ctx, in.Account.ID, pageSize, offset)
+		if err != nil {
+			return "", "", err
+		}
+		for _, txn := range page {
+			if txn.CreatedAt.Before(since) {
+				break pages
+			}
+			if txn.Status != "failed" && r.appliesTo(&txn) {
+				count++
+				total += txn.Amount
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	switch {
+	case r.MaxCount > 0 && count > r.MaxCount:
+		return r.decision, fmt.Sprintf("%d transactions in %s, more than %d", count, r.Window, r.MaxCount), nil
+	case r.MaxAmount > 0 && total > r.MaxAmount:
+		return r.decision, fmt.Sprintf("%s moved in %s, more than %s", formatAmount(total), r.Window,
+			formatAmount(r.MaxAmount)), nil
+	}
+	return models.RiskAllow, "", nil
+}
+
+func (r *VelocityRule) exceeded(count int, total float64) bool {
+	return r.MaxCount > 0 && count > r.MaxCount || r.MaxAmount > 0 && total > r.MaxAmount
+}
+
+// NewAccountRule restricts transactions on recently opened accounts.
+type NewAccountRule struct {
+	base
+	MaxAge    time.Duration
+	MaxAmount float64
+}
+
+func (r *NewAccountRule) Evaluate(_ context.Context, in *Input) (string, string, error) {
+	age := in.Now.Sub(in.Account.CreatedAt)
+	if !r.appliesTo(in.Transaction) || age >= r.MaxAge || in.Transaction.Amount <= r.MaxAmount {
+		return models.RiskAllow, "", nil
+	}
+	reason := fmt.Sprintf("account opened %s ago, less than %s", age.Round(time.Minute), r.MaxAge)
+	if r.MaxAmount > 0 {
+		reason += ", and amount is above " + formatAmount(r.MaxAmount)
+	}
+	return r.decision, reason, nil
+}
+
+// HoursRule flags transactions made at unusual times of day.
+type HoursRule struct {
+	base
+	// From and To are times since midnight; the period wraps past midnight
+	// if To is earlier than From.
+	From, To  time.Duration
+	MinAmount float64
+	Location  *time.Location
+}
+
+func (r *HoursRule) Evaluate(_ context.Context, in *Input) (string, string, error) {
+	if !r.appliesTo(in.Transaction) || in.Transaction.Amount <= r.MinAmount {
+		return models.RiskAllow, "", nil
+	}
+	local := in.Now.In(r.Location)
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	inside := clock >= r.From && clock < r.To
+	if r.To < r.From {
+		inside = clock >= r.From || clock < r.To
+	}
+	if !inside {
+		return models.RiskAllow, "", nil
+	}
+	return r.decision, fmt.Sprintf("submitted at %s, between %s and %s %s", local.Format("15:04"),
+		formatClock(r.From), formatClock(r.To), r.Location), nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,330 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// fakeLister serves an account's transactions, newest first, and counts
+// the pages read.
+type fakeLister struct {
+	txns  []models.Transaction
+	pages int
+}
+
+func (l *fakeLister) List(_ context.Context, _ string, limit, offset int) ([]models.Transaction, error) {
+	l.pages++
+	page := l.txns[min(offset, len(l.txns)):]
+	return page[:min(limit, len(page))], nil
+}
+
+// history returns n transactions of amount made every interval before now,
+// newest first.
+func history(n int, amount float64, interval time.Duration, now time.Time) []models.Transaction {
+	txns := make([]models.Transaction, n)
+	for i := range txns {
+		txns[i] = models.Transaction{Type: "withdrawal", Amount: amount, Status: "completed", CreatedAt: now.Add(-time.Duration(i+1) * interval)}
+	}
+	return txns
+}
+
+func TestVelocityRule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		maxCount  int
+		maxAmount float64
+		txns      []models.Transaction
+		want      string
+		wantPages int
+		wantErr   string
+	}{
+		{name: "under both limits", maxCount: 5, maxAmount: 1000, txns: history(3, 10, time.Minute, now), want: models.RiskAllow, wantPages: 1},
+		{name: "too many", maxCount: 3, txns: history(3, 10, time.Minute, now), want: models.RiskReview, wantPages: 1},
+		{name: "too much", maxAmount: 25, txns: history(2, 10, time.Minute, now), want: models.RiskReview, wantPages: 1},
+		{
+			name: "older transactions are not counted", maxCount: 3,
+			txns: history(10, 10, 30*time.Minute, now), want: models.RiskAllow, wantPages: 1,
+		},
+		{
+			name: "failed transactions are not counted", maxCount: 1,
+			txns: []models.Transaction{{Type: "withdrawal", Amount: 10, Status: "failed", CreatedAt: now.Add(-time.Minute)}},
+			want: models.RiskAllow, wantPages: 1,
+		},
+		{
+			name: "stops reading once a limit is passed", maxCount: 150,
+			txns: history(1000, 1, time.Second, now), want: models.RiskReview, wantPages: 2,
+		},
+		{
+			name: "reads a bounded number of pages", maxAmount: 1e9,
+			txns: history(maxPages*pageSize+1, 1, time.Second, now), wantPages: maxPages, wantErr: "more than 1000 transactions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := &fakeLister{txns: tt.txns}
+			rules, err := NewRules([]RuleConfig{{Name: "velocity", Type: "velocity", Window: time.Hour, MaxCount: tt.maxCount, MaxAmount: tt.maxAmount}},
+				lister, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := &Input{
+				Account:     &models.Account{ID: "acc"},
+				Transaction: &models.Transaction{AccountID: "acc", Type: "withdrawal", Amount: 10},
+				Now:         now,
+			}
+			got, _, err := rules[0].Evaluate(context.Background(), in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || got != tt.want {
+				t.Fatalf("got %q, %v; want %q", got, err, tt.want)
+			}
+			if lister.pages != tt.wantPages {
+				t.Fatalf("read %d pages, want %d", lister.pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestHoursRule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no timezone data:", err)
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		from, to string
+		loc      *time.Location
+		amount   float64
+		now      time.Time
+		want     string
+	}{
+		{name: "inside a daytime period", from: "09:00", to: "17:00", amount: 100, now: at(12, 0), want: models.RiskReview},
+		{name: "end of a daytime period", from: "09:00", to: "17:00", amount: 100, now: at(17, 0), want: models.RiskAllow},
+		{name: "before midnight in a wrapping period", from: "23:00", to: "05:00", amount: 100, now: at(23, 30), want: models.RiskReview},
+		{name: "after midnight in a wrapping period", from: "23:00", to: "05:00", amount: 100, now: at(2, 0), want: models.RiskReview},
+		{name: "start of a wrapping period", from: "23:00", to: "05:00", amount: 100, now: at(23, 0), want: models.RiskReview},
+		{name: "end of a wrapping period", from: "23:00", to: "05:00", amount: 100, now: at(5, 0), want: models.RiskAllow},
+		{name: "outside a wrapping period", from: "23:00", to: "05:00", amount: 100, now: at(12, 0), want: models.RiskAllow},
+		{name: "at or below the minimum amount", from: "23:00", to: "05:00", amount: 50, now: at(2, 0), want: models.RiskAllow},
+		// 22:30 UTC is 00:30 in Berlin in October
+		{
+			name: "taken in the rule's location", from: "00:00", to: "01:00", amount: 100, loc: berlin,
+			now: at(22, 30).AddDate(0, 0, -1), want: models.RiskReview,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+			rules, err := NewRules([]RuleConfig{{Name: "hours", Type: "unusual_hours", From: tt.from, To: tt.to, MinAmount: 50}}, nil, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := &Input{
+				Account:     &models.Account{ID: "acc"},
+				Transaction: &models.Transaction{AccountID: "acc", Type: "withdrawal", Amount: tt.amount},
+				Now:         tt.now,
+			}
+			got, reason, err := rules[0].Evaluate(context.Background(), in)
+			if err != nil || got != tt.want {
+				t.Fatalf("got %q, %v; want %q", got, err, tt.want)
+			}
+			if (got == models.RiskAllow) != (reason == "") {
+				t.Fatalf("decision %q has reason %q", got, reason)
+			}
+		})
+	}
+}
+
+// fixedRule decides the same way on every transaction.
+type fixedRule struct {
+	name     string
+	decision string
+	err      error
+}
+
+func (r fixedRule) Name() string {
+	return r.name
+}
+
+func (r fixedRule) Evaluate(context.Context, *Input) (string, string, error) {
+	if r.err != nil || r.decision == models.RiskAllow {
+		return r.decision, "", r.err
+	}
+	return r.decision, r.name + " matched", nil
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	down := errors.New("transaction service unavailable")
+	tests := []struct {
+		name  string
+		rules []Rule
+		want  string
+		// results are the recorded verdicts, one per rule
+		results []string
+	}{
+		{name: "no rules decide nothing"},
+		{
+			name:    "every rule allows",
+			rules:   []Rule{fixedRule{name: "a", decision: models.RiskAllow}, fixedRule{name: "b", decision: models.RiskAllow}},
+			want:    models.RiskAllow,
+			results: []string{models.RiskAllow, models.RiskAllow},
+		},
+		{
+			name:    "review outranks allow",
+			rules:   []Rule{fixedRule{name: "a", decision: models.RiskAllow}, fixedRule{name: "b", decision: models.RiskReview}},
+			want:    models.RiskReview,
+			results: []string{models.RiskAllow, models.RiskReview},
+		},
+		{
+			name: "deny outranks review whatever the order",
+			rules: []Rule{
+				fixedRule{name: "a", decision: models.RiskDeny}, fixedRule{name: "b", decision: models.RiskReview},
+				fixedRule{name: "c", decision: models.RiskAllow},
+			},
+			want:    models.RiskDeny,
+			results: []string{models.RiskDeny, models.RiskReview, models.RiskAllow},
+		},
+		{
+			name:    "a rule that cannot be evaluated asks for review",
+			rules:   []Rule{fixedRule{name: "a", decision: models.RiskAllow}, fixedRule{name: "b", err: down}},
+			want:    models.RiskReview,
+			results: []string{models.RiskAllow, models.RiskReview},
+		},
+		{
+			name:    "an evaluation error does not lift a denial",
+			rules:   []Rule{fixedRule{name: "a", err: down}, fixedRule{name: "b", decision: models.RiskDeny}},
+			want:    models.RiskDeny,
+			results: []string{models.RiskReview, models.RiskDeny},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &models.Account{ID: "acc"}
+			txn := &models.Transaction{AccountID: "acc", Type: "withdrawal", Amount: 10}
+			decision := NewEngine(tt.rules, nil).Evaluate(context.Background(), account, txn)
+			if tt.rules == nil {
+				if decision != nil {
+					t.Fatalf("got %+v, want no decision", decision)
+				}
+				return
+			}
+			if decision.Decision != tt.want || len(decision.Rules) != len(tt.results) {
+				t.Fatalf("got %+v, want %q from %d rules", decision, tt.want, len(tt.results))
+			}
+			for i, result := range decision.Rules {
+				rule := tt.rules[i].(fixedRule)
+				if result.Rule != rule.name || result.Decision != tt.results[i] {
+					t.Errorf("rule %d: got %+v, want %s to decide %q", i, result, rule.name, tt.results[i])
+				}
+				if rule.err != nil && !strings.Contains(result.Reason, rule.err.Error()) {
+					t.Errorf("reason %q does not give the evaluation error", result.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		rules   int
+		wantErr []string
+	}{
+		{
+			name: "valid rules",
+			yaml: `rules:
+  - name: large
+    type: amount
+    review_above: 2000
+    deny_above: 10000
+  - name: busy
+    type: velocity
+    decision: deny
+    window: 1h
+    max_count: 10
+  - name: night
+    type: unusual_hours
+    from: "23:00"
+    to: "05:00"
+`,
+			rules: 3,
+		},
+		{
+			name: "misspelt key",
+			yaml: `rules:
+  - name: large
+    type: amount
+    review_abve: 2000
+`,
+			wantErr: []string{"field review_abve not found"},
+		},
+		{
+			name:    "unknown top-level key",
+			yaml:    "rule:\n  - name: large\n",
+			wantErr: []string{"field rule not found"},
+		},
+		{
+			name: "every invalid rule is reported",
+			yaml: `rules:
+  - name: large
+    type: amount
+  - name: large
+    type: amount
+    deny_above: 5
+  - name: night
+    type: unusual_hours
+    from: "25:00"
+    to: "05:00"
+`,
+			wantErr: []string{
+				"large: review_above or deny_above is required",
+				"large: duplicate rule name",
+				`night: from: "25:00" is not an HH:MM time`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			rules, err := LoadRules(path, &fakeLister{}, time.UTC)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("loaded %d rules, want an error", len(rules))
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil || len(rules) != tt.rules {
+				t.Fatalf("got %d rules, %v; want %d", len(rules), err, tt.rules)
+			}
+		})
+	}
+
+	if _, err := LoadRules(filepath.Join(t.TempDir(), "missing.yaml"), nil, time.UTC); err == nil {
+		t.Fatal("loading a missing file succeeded")
+	}
+}
@@ -0,0 +1,254 @@
+// Package schedules executes transfers scheduled once or on a recurrence,
+// such as a standing order for rent on the 1st of every month.
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// Frequencies a Rule repeats at.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// maxPeriods bounds the search for an occurrence, so a rule that can never
+// match again, such as the 31st of every February, ends rather than loops.
+const maxPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Rule is the part of an iCalendar RRULE (RFC 5545) schedules support:
+// FREQ, INTERVAL, BYDAY for weekly rules, BYMONTHDAY for monthly ones
+// (negative days count back from the end of the month), and COUNT or UNTIL.
+// Occurrences fall at the time of day of the schedule's start. As in the
+// RFC, a day a month does not have, such as the 31st, is skipped.
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	// Count limits the number of occurrences, and Until the last one. An
+	// Until given as a date includes that whole day.
+	Count     int
+	Until     time.Time
+	untilDate bool
+}
+
+// ParseRule parses a rule such as FREQ=MONTHLY;BYMONTHDAY=1, with or without
+// an RRULE: prefix.
+func ParseRule(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("recurrence: %q is not KEY=VALUE", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("recurrence: %s given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = value
+			default:
+				err = errors.New("must be DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(value)
+		case "COUNT":
+			rule.Count, err = positive(value)
+		case "UNTIL":
+			rule.Until, rule.untilDate, err = parseUntil(value)
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					err = fmt.Errorf("%q is not a weekday, e.g. MO", day)
+					break
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, raw := range strings.Split(value, ",") {
+				day, aerr := strconv.Atoi(raw)
+				if aerr != nil || day == 0 || day < -31 || day > 31 {
+					err = fmt.Errorf("%q is not a day of the month, 1 to 31 or -31 to -1", raw)
+					break
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		default:
+			err = errors.New("is not supported")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("recurrence: %s %v", key, err)
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, errors.New("recurrence: FREQ is required")
+	case rule.Count > 0 && !rule.Until.IsZero():
+		return nil, errors.New("recurrence: COUNT and UNTIL cannot be combined")
+	case len(rule.ByDay) > 0 && rule.Freq != Weekly:
+		return nil, errors.New("recurrence: BYDAY is only supported with FREQ=WEEKLY")
+	case len(rule.ByMonthDay) > 0 && rule.Freq != Monthly:
+		return nil, errors.New("recurrence: BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return rule, nil
+}
+
+func positive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, errors.New("must be a date, YYYYMMDD, or a UTC time, YYYYMMDDTHHMMSSZ")
+}
+
+// Next returns the first occurrence of the rule for a schedule starting at
+// start that is after after, evaluated in loc. It reports false when there
+// are no more occurrences, apart from the limit set by Count, which depends
+// on how many occurrences have passed.
+func (r *Rule) Next(start, after time.Time, loc *time.Location) (time.Time, bool) {
+	start = start.In(loc)
+	after = after.In(loc)
+	hour, minute, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, start.Nanosecond(), loc)
+	}
+
+	// Skip the periods that end before after
+	first := 0
+	switch r.Freq {
+	case Daily:
+		first = daysBetween(start, after) / r.Interval
+	case Weekly:
+		first = daysBetween(weekStart(start), after) / (7 * r.Interval)
+	case Monthly:
+		first = (12*(after.Year()-start.Year()) + int(after.Month()-start.Month())) / r.Interval
+	case Yearly:
+		first = (after.Year() - start.Year()) / r.Interval
+	}
+	first = max(first, 0)
+
+	for period := first; period < first+maxPeriods; period++ {
+		var candidates []time.Time
+		switch r.Freq {
+		case Daily:
+			candidates = []time.Time{at(start.Year(), start.Month(), start.Day()+period*r.Interval)}
+		case Weekly:
+			monday := weekStart(start).AddDate(0, 0, 7*period*r.Interval)
+			days := r.ByDay
+			if len(days) == 0 {
+				days = []time.Weekday{start.Weekday()}
+			}
+			for _, day := range days {
+				offset := (int(day) + 6) % 7
+				candidates = append(candidates, at(monday.Year(), monday.Month(), monday.Day()+offset))
+			}
+		case Monthly:
+			month := time.Date(start.Year(), start.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, loc)
+			days := r.ByMonthDay
+			if len(days) == 0 {
+				days = []int{start.Day()}
+			}
+			last := month.AddDate(0, 1, -1).Day()
+			for _, day := range days {
+				if day < 0 {
+					day += last + 1
+				}
+				if day >= 1 && day <= last {
+					candidates = append(candidates, at(month.Year(), month.Month(), day))
+				}
+			}
+		case Yearly:
+			year := start.Year() + period*r.Interval
+			if t := at(year, start.Month(), start.Day()); t.Day() == start.Day() {
+				candidates = []time.Time{t}
+			}
+		}
+
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+		for _, t := range candidates {
+			if t.Before(start) || !t.After(after) {
+				continue
+			}
+			if r.beyondUntil(t) {
+				return time.Time{}, false
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) beyondUntil(t time.Time) bool {
+	switch {
+	case r.Until.IsZero():
+		return false
+	case r.untilDate:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).After(r.Until)
+	default:
+		return t.After(r.Until)
+	}
+}
+
+// daysBetween counts the calendar days from a to b, ignoring time of day.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// weekStart is the Monday of t's week, the RFC's default week start.
+func weekStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+}
+
+// NextOccurrence returns the schedule's first occurrence after after, or
+// false once it has none left. A schedule without a recurrence occurs once,
+// at its start.
+func NextOccurrence(schedule *models.Schedule, after time.Time, loc *time.Location) (time.Time, bool, error) {
+	if schedule.Recurrence == "" {
+		return schedule.StartAt, schedule.StartAt.After(after), nil
+	}
+	rule, err := ParseRule(schedule.Recurrence)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if rule.Count > 0 && schedule.Occurrences >= rule.Count {
+		return time.Time{}, false, nil
+	}
+	next, ok := rule.Next(schedule.StartAt, after, loc)
+	return next, ok, nil
+}
@@ -0,0 +1,193 @@
+package schedules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr string
+	}{
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{rule: " rrule:freq=weekly;byday=mo,fr;interval=2 "},
+		{rule: "FREQ=DAILY;UNTIL=20261231T235959Z"},
+		{rule: "FREQ=YEARLY;COUNT=5"},
+		{rule: "", wantErr: `"" is not KEY=VALUE`},
+		{rule: "FREQ", wantErr: "is not KEY=VALUE"},
+		{rule: "INTERVAL=2", wantErr: "FREQ is required"},
+		{rule: "FREQ=HOURLY", wantErr: "FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY"},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: "FREQ given twice"},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantErr: "INTERVAL must be a positive integer"},
+		{rule: "FREQ=DAILY;COUNT=-1", wantErr: "COUNT must be a positive integer"},
+		{rule: "FREQ=DAILY;UNTIL=2026-12-31", wantErr: "UNTIL must be a date"},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: "COUNT and UNTIL cannot be combined"},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: `"XX" is not a weekday`},
+		{rule: "FREQ=DAILY;BYDAY=MO", wantErr: "BYDAY is only supported with FREQ=WEEKLY"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: `"32" is not a day of the month`},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: `"0" is not a day of the month`},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: "BYMONTHDAY is only supported with FREQ=MONTHLY"},
+		{rule: "FREQ=MONTHLY;BYSETPOS=-1", wantErr: "BYSETPOS is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseRule(tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// occurrences returns up to n occurrences of rule from start, taking each
+// as the after of the next.
+func occurrences(t *testing.T, rule string, start time.Time, loc *time.Location, n int) []time.Time {
+	t.Helper()
+	r, err := ParseRule(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []time.Time
+	after := start.Add(-time.Nanosecond)
+	for range n {
+		next, ok := r.Next(start, after, loc)
+		if !ok {
+			break
+		}
+		list = append(list, next)
+		after = next
+	}
+	return list
+}
+
+func TestRuleNext(t *testing.T) {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			name: "daily", rule: "FREQ=DAILY;INTERVAL=3", start: at(2026, 2, 26),
+			want: []time.Time{at(2026, 2, 26), at(2026, 3, 1), at(2026, 3, 4), at(2026, 3, 7)},
+		},
+		{
+			name: "weekly on the start's weekday", rule: "FREQ=WEEKLY;INTERVAL=2", start: at(2026, 1, 7),
+			want: []time.Time{at(2026, 1, 7), at(2026, 1, 21), at(2026, 2, 4), at(2026, 2, 18)},
+		},
+		{
+			// 7 January 2026 is a Wednesday
+			name: "weekly on given days", rule: "FREQ=WEEKLY;BYDAY=FR,MO", start: at(2026, 1, 7),
+			want: []time.Time{at(2026, 1, 9), at(2026, 1, 12), at(2026, 1, 16), at(2026, 1, 19)},
+		},
+		{
+			name: "monthly skips months without the day", rule: "FREQ=MONTHLY", start: at(2026, 1, 31),
+			want: []time.Time{at(2026, 1, 31), at(2026, 3, 31), at(2026, 5, 31), at(2026, 7, 31)},
+		},
+		{
+			name: "monthly on several days", rule: "FREQ=MONTHLY;BYMONTHDAY=15,1", start: at(2026, 1, 10),
+			want: []time.Time{at(2026, 1, 15), at(2026, 2, 1), at(2026, 2, 15), at(2026, 3, 1)},
+		},
+		{
+			name: "monthly on the last day", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", start: at(2028, 1, 15),
+			want: []time.Time{at(2028, 1, 31), at(2028, 2, 29), at(2028, 3, 31), at(2028, 4, 30)},
+		},
+		{
+			name: "yearly from a leap day", rule: "FREQ=YEARLY", start: at(2024, 2, 29),
+			want: []time.Time{at(2024, 2, 29), at(2028, 2, 29), at(2032, 2, 29), at(2036, 2, 29)},
+		},
+		{
+			name: "until a date includes that day", rule: "FREQ=DAILY;UNTIL=20260109", start: at(2026, 1, 7),
+			want: []time.Time{at(2026, 1, 7), at(2026, 1, 8), at(2026, 1, 9)},
+		},
+		{
+			name: "until a time", rule: "FREQ=DAILY;UNTIL=20260109T000000Z", start: at(2026, 1, 7),
+			want: []time.Time{at(2026, 1, 7), at(2026, 1, 8)},
+		},
+		{
+			name: "a day that never comes", rule: "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30", start: at(2026, 2, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.start, time.UTC, 4)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// Occurrences keep the start's time of day across daylight saving changes.
+func TestRuleNextKeepsLocalTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no timezone data:", err)
+	}
+	start := time.Date(2026, 3, 28, 9, 0, 0, 0, berlin)
+	got := occurrences(t, "FREQ=DAILY", start, berlin, 3)
+	if len(got) != 3 {
+		t.Fatalf("got %v, want 3 occurrences", got)
+	}
+	for i, occurrence := range got {
+		if want := time.Date(2026, 3, 28+i, 9, 0, 0, 0, berlin); !occurrence.Equal(want) {
+			t.Errorf("occurrence %d = %v, want %v", i, occurrence, want)
+		}
+	}
+	if got[2].Sub(got[1]) != 24*time.Hour || got[1].Sub(got[0]) != 23*time.Hour {
+		t.Errorf("occurrences %v do not follow the clock change", got)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule models.Schedule
+		after    time.Time
+		want     time.Time
+		ok       bool
+		wantErr  bool
+	}{
+		{name: "one-off before its start", schedule: models.Schedule{StartAt: start}, after: start.Add(-time.Hour), want: start, ok: true},
+		{name: "one-off after its start", schedule: models.Schedule{StartAt: start}, after: start, want: start},
+		{
+			name: "recurring", schedule: models.Schedule{StartAt: start, Recurrence: "FREQ=MONTHLY;COUNT=3", Occurrences: 2},
+			after: start.AddDate(0, 1, 0), want: start.AddDate(0, 2, 0), ok: true,
+		},
+		{
+			name: "count reached", schedule: models.Schedule{StartAt: start, Recurrence: "FREQ=MONTHLY;COUNT=3", Occurrences: 3},
+			after: start.AddDate(0, 2, 0),
+		},
+		{name: "invalid rule", schedule: models.Schedule{StartAt: start, Recurrence: "FREQ=SOMETIMES"}, after: start, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := NextOccurrence(&tt.schedule, tt.after, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want an error %v", err, tt.wantErr)
+			}
+			if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+				t.Fatalf("got %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// staleExecution is how long an execution may stay running before the
+// scheduler that started it is assumed to have stopped.
+const staleExecution = 10 * time.Minute
+
+// Store persists schedules and their executions;
+// repository.ScheduleRepository implements it against DynamoDB.
+type Store interface {
+	Create(ctx context.Context, schedule *models.Schedule) error
+	// Get returns nil and no error when the schedule does not exist.
+	Get(ctx context.Context, id string) (*models.Schedule, error)
+	// Update replaces a schedule read with the given updated_at, failing
+	// with repository.ErrConflict if it has changed since.
+	Update(ctx context.Context, schedule *models.Schedule, prevUpdatedAt time.Time) error
+	// List returns the schedules transferring from an account, or every
+	// schedule when accountID is empty, oldest first.
+	List(ctx context.Context, accountID string) ([]models.Schedule, error)
+	// ListDue returns the active schedules whose next attempt is not after
+	// now.
+	ListDue(ctx context.Context, now time.Time) ([]models.Schedule, error)
+	// CreateExecution stores a new execution, keyed by its schedule,
+	// occurrence and attempt, and reports false if it already exists.
+	CreateExecution(ctx context.Context, execution *models.ScheduleExecution) (bool, error)
+	PutExecution(ctx context.Context, execution *models.ScheduleExecution) error
+	// GetExecution returns nil and no error when the execution does not
+	// exist.
+	GetExecution(ctx context.Context, id string) (*models.ScheduleExecution, error)
+	// Executions returns a schedule's executions, oldest first.
+	Executions(ctx context.Context, scheduleID string) ([]models.ScheduleExecution, error)
+}
+
+// Transferrer executes a transfer the way POST /transfers does;
+// handlers.TransactionHandler implements it.
+type Transferrer interface {
+	Transfer(ctx context.Context, transfer *models.Transfer) error
+}
+
+// Scheduler executes due schedules. Each attempt at an occurrence is
+// recorded before the transfer is made, so it is made at most once however
+// many instances run the scheduler.
+type Scheduler struct {
+	store         Store
+	transfers     Transferrer
+	loc           *time.Location
+	interval      time.Duration
+	retryInterval time.Duration
+	maxAttempts   int
+}
+
+func NewScheduler(store Store, transfers Transferrer, loc *time.Location, interval, retryInterval time.Duration, maxAttempts int) *Scheduler {
+	return &Scheduler{
+		store:         store,
+		transfers:     transfers,
+		loc:           loc,
+		interval:      interval,
+		retryInterval: retryInterval,
+		maxAttempts:   maxAttempts,
+	}
+}
+
+// Run executes due schedules now and then every interval until ctx is
+// cancelled. It has the signature of a server.Worker.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("scheduled transfers incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce makes one attempt at every schedule due at now and returns how
+// many it attempted. Occurrences missed while the scheduler was down are
+// caught up one per run. Schedules that fail are retried on the next run.
+func (s *Scheduler) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.store.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	attempted := 0
+	for i := range due {
+		ran, err := s.execute(ctx, &due[i], now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", due[i].ID, err))
+		}
+		if ran {
+			attempted++
+		}
+	}
+	return attempted, errors.Join(errs...)
+}
+
+// execute attempts the schedule's next occurrence and reports whether it
+// made the attempt. If the attempt was already started, by this or another
+// instance, its recorded outcome is applied instead.
+func (s *Scheduler) execute(ctx context.Context, schedule *models.Schedule, now time.Time) (bool, error) {
+	execution := &models.ScheduleExecution{
+		ScheduleID: schedule.ID,
+		Occurrence: *schedule.NextOccurrence,
+		Attempt:    schedule.Attempts + 1,
+		Status:     models.ExecutionRunning,
+		StartedAt:  now.UTC(),
+	}
+	created, err := s.store.CreateExecution(ctx, execution)
+	if err != nil {
+		return false, err
+	}
+	if !created {
+		return false, s.resume(ctx, schedule, execution.ID, now)
+	}
+
+	transfer := &models.Transfer{
+		FromAccountID: schedule.FromAccountID,
+		ToAccountID:   schedule.ToAccountID,
+		Amount:        schedule.Amount,
+		Description:   schedule.Description,
+	}
+	err = s.transfers.Transfer(ctx, transfer)
+	finished := now.UTC()
+	execution.FinishedAt = &finished
+	switch {
+	case err == nil:
+		execution.Status = models.ExecutionSucceeded
+		execution.WithdrawalID = transfer.Withdrawal.ID
+		execution.DepositID = transfer.Deposit.ID
+	case retryable(err) && execution.Attempt < s.maxAttempts:
+		execution.Status = models.ExecutionRetrying
+		execution.Error = err.Error()
+	default:
+		execution.Status = models.ExecutionFailed
+		execution.Error = err.Error()
+	}
+	slog.Info("scheduled transfer attempted", "schedule_id", schedule.ID,
+		"occurrence", execution.Occurrence, "attempt", execution.Attempt, "status", execution.Status, "error", execution.Error)
+
+	if err := s.store.PutExecution(ctx, execution); err != nil {
+		// The next run finds the execution still running and, once it is
+		// stale, gives the occurrence up
+		return true, err
+	}
+	return true, s.advance(ctx, schedule, execution, now)
+}
+
+// retryable reports whether a failed transfer is worth another attempt:
+// the source account lacked funds, or the transaction service was known to
+// be down so nothing was recorded.
+func retryable(err error) bool {
+	return errors.Is(err, products.ErrInsufficientFunds) || errors.Is(err, upstream.ErrCircuitOpen)
+}
+
+// resume applies the outcome of an attempt that was already started. One
+// still running past staleExecution was interrupted before its outcome was
+// recorded; whether its transfer was made is unknown, so the occurrence is
+// given up rather than risk paying it twice.
+func (s *Scheduler) resume(ctx context.Context, schedule *models.Schedule, id string, now time.Time) error {
+	execution, err := s.store.GetExecution(ctx, id)
+	if err != nil || execution == nil {
+		return err
+	}
+	if execution.Status == models.ExecutionRunning {
+		if now.Sub(execution.StartedAt) < staleExecution {
+			return nil
+		}
+		finished := now.UTC()
+		execution.Status = models.ExecutionFailed
+		execution.Error = "interrupted before its outcome was recorded; check the accounts' transactions"
+		execution.FinishedAt = &finished
+		if err := s.store.PutExecution(ctx, execution); err != nil {
+			return err
+		}
+		slog.Warn("scheduled transfer interrupted", "schedule_id", schedule.ID, "occurrence", execution.Occurrence)
+	}
+	return s.advance(ctx, schedule, execution, now)
+}
+
+// advance moves the schedule on from a finished attempt: to a retry of the
+// same occurrence, or to the next occurrence, completing the schedule when
+// there is none. A schedule changed in the meantime, e.g. cancelled, is left
+// alone; if it is still due, the next run applies the attempt again.
+func (s *Scheduler) advance(ctx context.Context, schedule *models.Schedule, execution *models.ScheduleExecution, now time.Time) error {
+	prev := schedule.UpdatedAt
+	if execution.Status == models.ExecutionRetrying {
+		retryAt := now.UTC().Add(s.retryInterval)
+		schedule.Attempts = execution.Attempt
+		schedule.NextAttemptAt = &retryAt
+	} else {
+		schedule.Attempts = 0
+		schedule.Occurrences++
+		next, ok, err := NextOccurrence(schedule, execution.Occurrence, s.loc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			next = next.UTC()
+			schedule.NextOccurrence = &next
+			schedule.NextAttemptAt = &next
+		} else {
+			schedule.Status = models.ScheduleCompleted
+			schedule.NextOccurrence = nil
+			schedule.NextAttemptAt = nil
+		}
+	}
+	schedule.UpdatedAt = now.UTC()
+
+	err := s.store.Update(ctx, schedule, prev)
+	if errors.Is(err, repository.ErrConflict) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/rs/cors"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/handlers"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/middleware"
+)
+
+// HandlerOptions configures the middleware wrapped around the routes.
+type HandlerOptions struct {
+	CORS        config.CORSConfig
+	Auth        config.AuthConfig
+	Idempotency *middleware.IdempotencyStore
+}
+
+// NewHandler builds the complete API handler: request IDs and access logs,
+// CORS, bearer token auth and idempotent POST replay around the routes.
+func NewHandler(routes []Route, opts HandlerOptions) http.Handler {
+	var handler http.Handler = NewMux(routes)
+	handler = middleware.Idempotency(opts.Idempotency, handler)
+	handler = middleware.Auth(opts.Auth, PublicPaths(routes), handler)
+	handler = cors.New(cors.Options{
+		AllowedOrigins: opts.CORS.AllowedOrigins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", logging.RequestIDHeader, middleware.IdempotencyKeyHeader},
+		ExposedHeaders: []string{logging.RequestIDHeader, handlers.NextCursorHeader, middleware.IdempotentReplayHeader},
+	}).Handler(handler)
+
+	// Every request gets an ID and an access log line
+	return middleware.RequestID(middleware.AccessLog(handler))
+}
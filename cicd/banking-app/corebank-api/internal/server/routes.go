@@ -0,0 +1,141 @@
+package server
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/corebank-api/internal/handlers"
+	"github.com/corebank-api/internal/health"
+	"github.com/corebank-api/internal/metrics"
+	"github.com/corebank-api/internal/middleware"
+	"github.com/corebank-api/internal/openapi"
+)
+
+// Route is one method and path served by the API. Path uses the templated
+// form found in the OpenAPI spec, e.g. /accounts/{id}, and doubles as the
+// metrics and span label.
+type Route struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+	// Public routes are served without a bearer token when auth is enabled.
+	Public bool
+	// Undocumented routes are left out of the OpenAPI spec.
+	Undocumented bool
+	// Stream routes hold the response open. They are not traced, since
+	// the tracing response writer hides the write deadline they extend and
+	// a span lasting the whole stream says little.
+	Stream bool
+}
+
+// Handlers groups everything the routes dispatch to.
+type Handlers struct {
+	Accounts        *handlers.AccountHandler
+	Transactions    *handlers.TransactionHandler
+	Statements      *handlers.StatementHandler
+	Holds           *handlers.HoldHandler
+	Schedules       *handlers.ScheduleHandler
+	Webhooks        *handlers.WebhookHandler
+	Streams         *handlers.StreamHandler
+	Reconciliations *handlers.ReconciliationHandler
+	Risk            *handlers.RiskHandler
+	Limits          *handlers.LimitHandler
+	KYC             *handlers.KYCHandler
+	Health          *health.Checker
+}
+
+// Routes returns the API's route table. Every documented route must have a
+// matching operation in the OpenAPI spec.
+func Routes(h Handlers) []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/accounts", Handler: h.Accounts.HandleAccounts},
+		{Method: http.MethodPost, Path: "/accounts", Handler: h.Accounts.HandleAccounts},
+		{Method: http.MethodPost, Path: "/accounts:bulk", Handler: h.Accounts.HandleBulkImport},
+		{Method: http.MethodGet, Path: "/accounts:export", Handler: h.Accounts.HandleExport},
+		{Method: http.MethodGet, Path: "/accounts/{id}", Handler: h.Accounts.HandleAccountByID},
+		{Method: http.MethodPut, Path: "/accounts/{id}", Handler: h.Accounts.HandleAccountByID},
+		{Method: http.MethodPatch, Path: "/accounts/{id}", Handler: h.Accounts.HandleAccountByID},
+		{Method: http.MethodDelete, Path: "/accounts/{id}", Handler: h.Accounts.HandleAccountByID},
+		{Method: http.MethodGet, Path: "/accounts/{id}/history", Handler: h.Accounts.HandleHistory},
+		{Method: http.MethodGet, Path: "/accounts/{id}/events", Handler: h.Streams.HandleAccountEvents, Stream: true},
+		{Method: http.MethodGet, Path: "/accounts/{id}/statements", Handler: h.Statements.HandleStatement},
+		{Method: http.MethodGet, Path: "/accounts/{id}/balance", Handler: h.Holds.HandleBalance},
+		{Method: http.MethodGet, Path: "/accounts/{id}/limits", Handler: h.Limits.HandleGetLimits},
+		{Method: http.MethodPut, Path: "/accounts/{id}/limits", Handler: h.Limits.HandleSetLimits},
+		{Method: http.MethodGet, Path: "/accounts/{id}/kyc", Handler: h.KYC.HandleGetKYC},
+		{Method: http.MethodPost, Path: "/accounts/{id}/kyc", Handler: h.KYC.HandleSubmitKYC},
+		{Method: http.MethodPost, Path: "/accounts/{id}/kyc/decision", Handler: h.KYC.HandleDecideKYC},
+		{Method: http.MethodGet, Path: "/accounts/{id}/holds", Handler: h.Holds.HandleListHolds},
+		{Method: http.MethodPost, Path: "/accounts/{id}/holds", Handler: h.Holds.HandlePlaceHold},
+		{Method: http.MethodGet, Path: "/products", Handler: h.Accounts.HandleProducts},
+
+		{Method: http.MethodGet, Path: "/holds/{id}", Handler: h.Holds.HandleGetHold},
+		{Method: http.MethodPost, Path: "/holds/{id}/capture", Handler: h.Holds.HandleCapture},
+		{Method: http.MethodPost, Path: "/holds/{id}/release", Handler: h.Holds.HandleRelease},
+
+		{Method: http.MethodGet, Path: "/transactions", Handler: h.Transactions.HandleGetTransactions},
+		{Method: http.MethodPost, Path: "/transactions", Handler: h.Transactions.HandleTransactions},
+		{Method: http.MethodPut, Path: "/transactions/{id}", Handler: h.Transactions.HandleTransactionByID},
+		{Method: http.MethodPost, Path: "/transfers", Handler: h.Transactions.HandleTransfers},
+
+		{Method: http.MethodGet, Path: "/schedules", Handler: h.Schedules.HandleListSchedules},
+		{Method: http.MethodPost, Path: "/schedules", Handler: h.Schedules.HandleCreateSchedule},
+		{Method: http.MethodGet, Path: "/schedules/{id}", Handler: h.Schedules.HandleGetSchedule},
+		{Method: http.MethodPost, Path: "/schedules/{id}/cancel", Handler: h.Schedules.HandleCancelSchedule},
+		{Method: http.MethodGet, Path: "/schedules/{id}/executions", Handler: h.Schedules.HandleExecutions},
+
+		{Method: http.MethodGet, Path: "/webhooks", Handler: h.Webhooks.HandleListWebhooks},
+		{Method: http.MethodPost, Path: "/webhooks", Handler: h.Webhooks.HandleCreateWebhook},
+		{Method: http.MethodGet, Path: "/webhooks/{id}", Handler: h.Webhooks.HandleGetWebhook},
+		{Method: http.MethodDelete, Path: "/webhooks/{id}", Handler: h.Webhooks.HandleDeleteWebhook},
+		{Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", Handler: h.Webhooks.HandleDeliveries},
+		{Method: http.MethodPost, Path: "/webhooks/{id}/deliveries/{delivery_id}/redeliver", Handler: h.Webhooks.HandleRedeliver},
+
+		{Method: http.MethodGet, Path: "/reconciliations", Handler: h.Reconciliations.HandleListReconciliations},
+		{Method: http.MethodPost, Path: "/reconciliations", Handler: h.Reconciliations.HandleReconcile},
+		{Method: http.MethodGet, Path: "/reconciliations/{id}", Handler: h.Reconciliations.HandleGetReconciliation},
+		{Method: http.MethodGet, Path: "/risk/decisions", Handler: h.Risk.HandleListDecisions},
+		{Method: http.MethodGet, Path: "/risk/decisions/{id}", Handler: h.Risk.HandleGetDecision},
+		{Method: http.MethodGet, Path: "/kyc", Handler: h.KYC.HandleListKYC},
+
+		// /health is kept as a liveness alias for existing container health checks
+		{Method: http.MethodGet, Path: "/livez", Handler: h.Health.LivenessHandler, Public: true},
+		{Method: http.MethodGet, Path: "/readyz", Handler: h.Health.ReadinessHandler, Public: true},
+		{Method: http.MethodGet, Path: "/health", Handler: h.Health.LivenessHandler, Public: true},
+
+		{Method: http.MethodGet, Path: "/metrics", Handler: metrics.Handler().ServeHTTP, Public: true, Undocumented: true},
+		{Method: http.MethodGet, Path: "/openapi.json", Handler: openapi.SpecHandler, Public: true, Undocumented: true},
+		{Method: http.MethodGet, Path: "/docs", Handler: openapi.DocsHandler, Public: true, Undocumented: true},
+	}
+}
+
+// NewMux registers routes on a new ServeMux, recording request metrics and a
+// server span for each under its templated path.
+func NewMux(routes []Route) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		rt := rt
+		if rt.Stream {
+			mux.Handle(rt.Method+" "+rt.Path, middleware.Metrics(rt.Path, rt.Handler))
+			continue
+		}
+		mux.Handle(rt.Method+" "+rt.Path, otelhttp.NewHandler(middleware.Metrics(rt.Path, rt.Handler), rt.Path,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + rt.Path
+			}),
+		))
+	}
+	return mux
+}
+
+// PublicPaths returns the paths that skip bearer token auth.
+func PublicPaths(routes []Route) []string {
+	var paths []string
+	for _, rt := range routes {
+		if rt.Public {
+			paths = append(paths, rt.Path)
+		}
+	}
+	return paths
+}
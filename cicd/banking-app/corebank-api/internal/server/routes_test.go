@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/openapi"
+)
+
+type spec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+func loadSpec(t *testing.T) spec {
+	t.Helper()
+	var s spec
+	if err := json.Unmarshal(openapi.Spec, &s); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return s
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	s := loadSpec(t)
+
+	documented := make(map[string]bool)
+	for path, item := range s.Paths {
+		for method := range item {
+			if httpMethods[method] {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	served := make(map[string]bool)
+	for _, rt := range Routes(Handlers{}) {
+		if !rt.Undocumented {
+			served[rt.Method+" "+rt.Path] = true
+		}
+	}
+
+	for op := range served {
+		if !documented[op] {
+			t.Errorf("route %s is served but missing from openapi.json", op)
+		}
+	}
+	for op := range documented {
+		if !served[op] {
+			t.Errorf("operation %s is in openapi.json but no route serves it", op)
+		}
+	}
+}
+
+func TestSpecSchemasMatchModels(t *testing.T) {
+	s := loadSpec(t)
+
+	for name, model := range map[string]any{
+		"Account":             models.Account{},
+		"Transaction":         models.Transaction{},
+		"Transfer":            models.Transfer{},
+		"Statement":           models.Statement{},
+		"StatementLine":       models.StatementLine{},
+		"BulkImportResult":    models.BulkImportResult{},
+		"BulkImportRow":       models.BulkImportRow{},
+		"AccountChange":       models.AccountChange{},
+		"Reconciliation":      models.Reconciliation{},
+		"Discrepancy":         models.Discrepancy{},
+		"RiskDecision":        models.RiskDecision{},
+		"RiskRuleResult":      models.RiskRuleResult{},
+		"AccountLimits":       models.AccountLimits{},
+		"LimitStatus":         models.LimitStatus{},
+		"LimitWindow":         models.LimitWindow{},
+		"KYCSubmission":       models.KYCSubmission{},
+		"KYCDecision":         models.KYCDecision{},
+		"KYCRecord":           models.KYCRecord{},
+		"Product":             models.Product{},
+		"Hold":                models.Hold{},
+		"HoldCapture":         models.HoldCapture{},
+		"Balance":             models.Balance{},
+		"Schedule":            models.Schedule{},
+		"ScheduleExecution":   models.ScheduleExecution{},
+		"WebhookSubscription": models.WebhookSubscription{},
+		"WebhookDelivery":     models.WebhookDelivery{},
+		"WebhookEvent":        models.Event{},
+	} {
+		schema, ok := s.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing from openapi.json", name)
+			continue
+		}
+
+		var inSpec []string
+		for prop := range schema.Properties {
+			inSpec = append(inSpec, prop)
+		}
+		sort.Strings(inSpec)
+
+		inModel := jsonFields(reflect.TypeOf(model))
+		if !reflect.DeepEqual(inSpec, inModel) {
+			t.Errorf("schema %s properties %v do not match model fields %v", name, inSpec, inModel)
+		}
+	}
+}
+
+// jsonFields returns the sorted JSON names of a struct's exported fields.
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/health"
+)
+
+// Worker is a background task that runs until its context is cancelled.
+type Worker func(ctx context.Context)
+
+// Server is the API's HTTP server together with the background workers that
+// share its lifetime.
+type Server struct {
+	cfg     config.ServerConfig
+	http    *http.Server
+	checker *health.Checker
+	workers []Worker
+}
+
+// New builds a server with explicit timeouts and header limits.
+func New(cfg config.ServerConfig, handler http.Handler, checker *health.Checker) *Server {
+	return &Server{
+		cfg:     cfg,
+		checker: checker,
+		http: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		},
+	}
+}
+
+// AddWorker registers a background task started by Run and stopped during
+// shutdown.
+func (s *Server) AddWorker(w Worker) {
+	s.workers = append(s.workers, w)
+}
+
+// OnShutdown registers fn to be called when shutdown begins, e.g. to end
+// long-lived responses that would otherwise hold it up until the timeout.
+func (s *Server) OnShutdown(fn func()) {
+	s.http.RegisterOnShutdown(fn)
+}
+
+// Run serves until ctx is cancelled, then shuts down gracefully: readiness is
+// failed first, the listener is closed after the drain delay, in-flight
+// requests get up to ShutdownTimeout to finish, and workers are stopped.
+func (s *Server) Run(ctx context.Context) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var wg sync.WaitGroup
+	for _, w := range s.workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+			w(workerCtx)
+		}(w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Banking API server starting", "addr", s.http.Addr)
+		serveErr <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopWorkers()
+		wg.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutdown signal received, draining", "drain_delay", s.cfg.DrainDelay)
+	s.checker.SetDraining()
+	time.Sleep(s.cfg.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	err := s.http.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("in-flight requests did not finish before the deadline", "error", err)
+	}
+
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		slog.Warn("background workers did not stop before the deadline")
+	}
+
+	if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	slog.Info("server stopped")
+	return err
+}
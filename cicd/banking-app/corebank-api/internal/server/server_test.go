@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/health"
+)
+
+// freePort returns a port nothing is listening on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func testConfig(port string) config.ServerConfig {
+	return config.ServerConfig{
+		Port:              port,
+		ReadHeaderTimeout: time.Second,
+		DrainDelay:        50 * time.Millisecond,
+		ShutdownTimeout:   time.Second,
+	}
+}
+
+// waitForServer polls url until the server answers.
+func waitForServer(t *testing.T, url string) {
+	t.Helper()
+	for range 100 {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server at %s did not start", url)
+}
+
+// Shutdown fails readiness, lets in-flight requests finish and stops the
+// workers before Run returns.
+func TestRunShutsDownGracefully(t *testing.T) {
+	port := freePort(t)
+	base := "http://127.0.0.1:" + port
+	checker := health.NewChecker(time.Second, time.Second)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+	s := New(testConfig(port), mux, checker)
+	var stopped atomic.Bool
+	s.AddWorker(func(ctx context.Context) {
+		<-ctx.Done()
+		stopped.Store(true)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- s.Run(ctx) }()
+	waitForServer(t, base+"/ping")
+
+	slow := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		slow <- string(body)
+	}()
+	<-started
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if report := checker.Ready(context.Background()); report.Status != health.StatusDown {
+		t.Errorf("readiness is %s while draining, want down", report.Status)
+	}
+	close(release)
+
+	if body := <-slow; body != "done" {
+		t.Errorf("in-flight request got %q, want it finished", body)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+	if !stopped.Load() {
+		t.Error("worker was not stopped")
+	}
+}
+
+// A server that cannot listen stops its workers and reports why.
+func TestRunListenError(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	s := New(testConfig(strconv.Itoa(l.Addr().(*net.TCPAddr).Port)), http.NewServeMux(), health.NewChecker(time.Second, time.Second))
+	var stopped atomic.Bool
+	s.AddWorker(func(ctx context.Context) {
+		<-ctx.Done()
+		stopped.Store(true)
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || !stopped.Load() {
+			t.Fatalf("Run = %v with the worker stopped %v, want a listen error", err, stopped.Load())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+}
@@ -0,0 +1,106 @@
+package statements
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// AccountLister lists the accounts the job generates statements for.
+type AccountLister interface {
+	ListAll(ctx context.Context) ([]models.Account, error)
+}
+
+// Job stores every account's statement for the previous month once the month
+// has ended. It is safe to run on several instances: a statement is only
+// stored once.
+type Job struct {
+	accounts  AccountLister
+	store     Store
+	generator *Generator
+	interval  time.Duration
+
+	// done is the last period stored for every account, so later ticks in
+	// the same month do nothing
+	done string
+}
+
+func NewJob(accounts AccountLister, store Store, generator *Generator, interval time.Duration) *Job {
+	return &Job{accounts: accounts, store: store, generator: generator, interval: interval}
+}
+
+// Run generates missing statements now and then every interval until ctx is
+// cancelled. It has the signature of a server.Worker.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		if err := j.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("statement generation incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce stores the statement for the month before now for every account
+// that existed in that month and doesn't have one yet. Accounts that fail
+// are logged and retried on the next run.
+func (j *Job) RunOnce(ctx context.Context, now time.Time) error {
+	now = now.In(j.generator.loc)
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, j.generator.loc)
+	from := to.AddDate(0, -1, 0)
+	period := from.Format(PeriodLayout)
+	if period == j.done {
+		return nil
+	}
+
+	accounts, err := j.accounts.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed, stored int
+	for i := range accounts {
+		account := &accounts[i]
+		if !account.CreatedAt.IsZero() && !account.CreatedAt.Before(to) {
+			continue
+		}
+		created, err := j.generate(ctx, account, period, from, to)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			slog.Warn("failed to generate statement", "account_id", account.ID, "period", period, "error", err)
+			continue
+		}
+		if created {
+			stored++
+		}
+	}
+
+	slog.Info("monthly statements generated", "period", period, "stored", stored, "failed", failed)
+	if failed == 0 {
+		j.done = period
+	}
+	return nil
+}
+
+func (j *Job) generate(ctx context.Context, account *models.Account, period string, from, to time.Time) (bool, error) {
+	existing, err := j.store.Get(ctx, account.ID, period)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	statement, err := j.generator.Generate(ctx, account, from, to)
+	if err != nil {
+		return false, err
+	}
+	statement.Period = period
+	return j.store.Create(ctx, statement)
+}
@@ -0,0 +1,150 @@
+package statements
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// Statement formats accepted by Write.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatPDF  = "pdf"
+)
+
+// ContentType returns the media type of a rendered format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json"
+	}
+}
+
+// Filename suggests a download name for the statement in format.
+func Filename(statement *models.Statement, format string) string {
+	period := statement.Period
+	if period == "" {
+		period = statement.From.Format(dateLayout) + "_" + lastDay(statement).Format(dateLayout)
+	}
+	return fmt.Sprintf("statement-%s-%s.%s", statement.AccountID, period, format)
+}
+
+const dateLayout = "2006-01-02"
+
+// lastDay is the last day a statement covers; To is exclusive.
+func lastDay(statement *models.Statement) time.Time {
+	return statement.To.Add(-time.Nanosecond)
+}
+
+// WriteCSV writes one row per transaction between an opening and a closing
+// balance row.
+func WriteCSV(w io.Writer, statement *models.Statement) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "transaction_id", "type", "description", "amount", "balance"})
+	cw.Write([]string{statement.From.Format(time.RFC3339), "", "opening_balance", "", "", amount(statement.OpeningBalance)})
+	for _, line := range statement.Lines {
+		cw.Write([]string{
+			line.Date.Format(time.RFC3339),
+			line.TransactionID,
+			line.Type,
+			line.Description,
+			amount(line.Amount),
+			amount(line.Balance),
+		})
+	}
+	cw.Write([]string{statement.To.Format(time.RFC3339), "", "closing_balance", "", "", amount(statement.ClosingBalance)})
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePDF renders a printable A4 statement.
+func WritePDF(w io.Writer, statement *models.Statement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCreationDate(statement.GeneratedAt)
+	pdf.SetTitle("Account statement", false)
+	// The core fonts are cp1252; translate so names and descriptions print
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Account statement", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range [][2]string{
+		{"Account", statement.AccountID},
+		{"Owner", statement.Owner},
+		{"Period", statement.From.Format(dateLayout) + " to " + lastDay(statement).Format(dateLayout)},
+		{"Generated", statement.GeneratedAt.UTC().Format(time.RFC3339)},
+	} {
+		pdf.CellFormat(30, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	for _, row := range [][2]string{
+		{"Opening balance", amount(statement.OpeningBalance)},
+		{"Total credits", amount(statement.TotalCredits)},
+		{"Total debits", amount(statement.TotalDebits)},
+		{"Closing balance", amount(statement.ClosingBalance)},
+	} {
+		pdf.CellFormat(40, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 6, row[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	widths := []float64{25, 25, 70, 35, 35}
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, title := range []string{"Date", "Type", "Description", "Amount", "Balance"} {
+			align := "L"
+			if i >= 3 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 7, title, "B", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	header()
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	for _, line := range statement.Lines {
+		if pdf.GetY()+6 > pageHeight-bottom-10 {
+			pdf.AddPage()
+			header()
+		}
+		pdf.CellFormat(widths[0], 6, line.Date.Format(dateLayout), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, line.Type, "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 6, tr(truncate(line.Description, 45)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], 6, amount(line.Amount), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, amount(line.Balance), "", 1, "R", false, 0, "")
+	}
+	if len(statement.Lines) == 0 {
+		pdf.CellFormat(0, 6, "No transactions in this period.", "", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+func amount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
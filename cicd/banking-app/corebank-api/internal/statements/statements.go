@@ -0,0 +1,273 @@
+// Package statements builds account statements from the transaction
+// service's history, renders them as JSON, CSV or PDF, and stores each
+// account's statement for a month once the month has ended.
+package statements
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// PeriodLayout is the format of a statement period, a calendar month.
+const PeriodLayout = "2006-01"
+
+// pageSize is the largest page the transaction service returns.
+const pageSize = 100
+
+// pendingWindow is how far back Pending looks for pending transactions,
+// which settle or fail within days, and pendingPages the most pages it reads
+// to find them, so checking funds costs the same however long an account's
+// history is.
+const (
+	pendingWindow = 30 * 24 * time.Hour
+	pendingPages  = 10
+)
+
+// Store persists monthly statements. repository.StatementRepository
+// implements it against DynamoDB.
+type Store interface {
+	// Create stores a statement unless one is already stored for the
+	// account and period, and reports whether it did.
+	Create(ctx context.Context, statement *models.Statement) (bool, error)
+	// Get returns nil and no error when no statement is stored.
+	Get(ctx context.Context, accountID, period string) (*models.Statement, error)
+}
+
+// Generator builds statements. Day and month boundaries are taken in its
+// location.
+type Generator struct {
+	transactions *upstream.TransactionService
+	loc          *time.Location
+}
+
+func NewGenerator(transactions *upstream.TransactionService, loc *time.Location) *Generator {
+	return &Generator{transactions: transactions, loc: loc}
+}
+
+// Location returns the zone statement boundaries are taken in.
+func (g *Generator) Location() *time.Location {
+	return g.loc
+}
+
+// Month returns the bounds of period, a month such as "2024-05", as a
+// half-open range.
+func (g *Generator) Month(period string) (from, to time.Time, err error) {
+	from, err = time.ParseInLocation(PeriodLayout, period, g.loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("period must be YYYY-MM: %q", period)
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// Generate builds the statement of account for transactions settled in
+// [from, to). Only completed transactions count, dated by when they were
+// processed: deposits are credits, withdrawals and transfers debits. The
+// opening balance is the sum of everything settled before from.
+func (g *Generator) Generate(ctx context.Context, account *models.Account, from, to time.Time) (*models.Statement, error) {
+	txns, err := g.history(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var settled []models.Transaction
+	for _, txn := range txns {
+		if txn.Status == "completed" {
+			settled = append(settled, txn)
+		}
+	}
+	sort.SliceStable(settled, func(i, j int) bool {
+		a, b := settledAt(settled[i]), settledAt(settled[j])
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return settled[i].ID < settled[j].ID
+	})
+
+	statement := &models.Statement{
+		AccountID:   account.ID,
+		Owner:       account.Owner,
+		From:        from.In(g.loc),
+		To:          to.In(g.loc),
+		Lines:       []models.StatementLine{},
+		GeneratedAt: time.Now().UTC(),
+	}
+	balance := 0.0
+	for _, txn := range settled {
+		at := settledAt(txn)
+		if !at.Before(to) {
+			break
+		}
+		amount := signedAmount(txn)
+		if at.Before(from) {
+			balance = round(balance + amount)
+			continue
+		}
+		if len(statement.Lines) == 0 {
+			statement.OpeningBalance = balance
+		}
+
+		balance = round(balance + amount)
+		if amount >= 0 {
+			statement.TotalCredits = round(statement.TotalCredits + amount)
+		} else {
+			statement.TotalDebits = round(statement.TotalDebits - amount)
+		}
+		statement.Lines = append(statement.Lines, models.StatementLine{
+			Date:          at.In(g.loc),
+			TransactionID: txn.ID,
+			Type:          txn.Type,
+			Description:   txn.Description,
+			Amount:        amount,
+			Balance:       balance,
+		})
+	}
+	if len(statement.Lines) == 0 {
+		statement.OpeningBalance = balance
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// DailyBalances returns the account's closing balance for each of the days
+// days starting at from, which should be the start of a day in the
+// generator's location. Balances count the same transactions as statements.
+func (g *Generator) DailyBalances(ctx context.Context, account *models.Account, from time.Time, days int) ([]float64, error) {
+	txns, err := g.history(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var settled []models.Transaction
+	for _, txn := range txns {
+		if txn.Status == "completed" {
+			settled = append(settled, txn)
+		}
+	}
+	sort.SliceStable(settled, func(i, j int) bool {
+		return settledAt(settled[i]).Before(settledAt(settled[j]))
+	})
+
+	balances := make([]float64, days)
+	balance, next := 0.0, 0
+	for day := range balances {
+		end := from.AddDate(0, 0, day+1)
+		for ; next < len(settled) && settledAt(settled[next]).Before(end); next++ {
+			balance = round(balance + signedAmount(settled[next]))
+		}
+		balances[day] = balance
+	}
+	return balances, nil
+}
+
+// Balances returns the account's ledger balance, counting completed
+// transactions, and the net amount of its pending ones. Failed transactions
+// count towards neither.
+func (g *Generator) Balances(ctx context.Context, accountID string) (ledger, pending float64, err error) {
+	txns, err := g.history(ctx, accountID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, txn := range txns {
+		switch txn.Status {
+		case "completed":
+			ledger = round(ledger + signedAmount(txn))
+		case "pending":
+			pending = round(pending + signedAmount(txn))
+		}
+	}
+	return ledger, pending, nil
+}
+
+// Completed returns the account's completed transactions.
+func (g *Generator) Completed(ctx context.Context, accountID string) ([]models.Transaction, error) {
+	txns, err := g.history(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	var completed []models.Transaction
+	for _, txn := range txns {
+		if txn.Status == "completed" {
+			completed = append(completed, txn)
+		}
+	}
+	return completed, nil
+}
+
+// Pending returns the totals of the account's pending deposits and of its
+// pending withdrawals and transfers, both positive. Only transactions made
+// within pendingWindow, and at most pendingPages pages of them, are read.
+func (g *Generator) Pending(ctx context.Context, accountID string) (credits, debits float64, err error) {
+	since := time.Now().Add(-pendingWindow)
+	for offset := 0; ; offset += pageSize {
+		if offset == pendingPages*pageSize {
+			logging.FromContext(ctx).Warn("pending transactions read up to the page limit", "account_id", accountID,
+				"transactions", offset)
+			return credits, debits, nil
+		}
+		page, err := g.transactions.List(ctx, accountID, pageSize, offset)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, txn := range page {
+			if txn.CreatedAt.Before(since) {
+				return credits, debits, nil
+			}
+			if txn.Status != "pending" {
+				continue
+			}
+			if amount := signedAmount(txn); amount >= 0 {
+				credits = round(credits + amount)
+			} else {
+				debits = round(debits - amount)
+			}
+		}
+		if len(page) < pageSize {
+			return credits, debits, nil
+		}
+	}
+}
+
+// history fetches every transaction of the account, paging until the
+// service returns a short page.
+func (g *Generator) history(ctx context.Context, accountID string) ([]models.Transaction, error) {
+	var all []models.Transaction
+	for offset := 0; ; offset += pageSize {
+		page, err := g.transactions.List(ctx, accountID, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// settledAt is when a completed transaction took effect. Transactions
+// completed before the service recorded processed_at fall back to their
+// creation time.
+func settledAt(txn models.Transaction) time.Time {
+	if txn.ProcessedAt != nil {
+		return *txn.ProcessedAt
+	}
+	return txn.CreatedAt
+}
+
+func signedAmount(txn models.Transaction) float64 {
+	if txn.Type == "deposit" {
+		return math.Abs(txn.Amount)
+	}
+	return -math.Abs(txn.Amount)
+}
+
+// round keeps running totals to whole cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
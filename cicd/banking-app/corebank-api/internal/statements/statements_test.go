@@ -0,0 +1,86 @@
+package statements
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/upstream"
+)
+
+// newTestGenerator serves txns, newest first, as the transaction service
+// would, and counts the pages read.
+func newTestGenerator(t *testing.T, txns []models.Transaction) (*Generator, *int) {
+	t.Helper()
+	pages := new(int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*pages++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page := txns[min(offset, len(txns)):]
+		json.NewEncoder(w).Encode(page[:min(limit, len(page))])
+	}))
+	t.Cleanup(server.Close)
+	return NewGenerator(upstream.NewTransactionService(server.URL, server.Client()), time.UTC), pages
+}
+
+func TestPending(t *testing.T) {
+	now := time.Now()
+	txn := func(typ, status string, amount float64, age time.Duration) models.Transaction {
+		return models.Transaction{Type: typ, Status: status, Amount: amount, CreatedAt: now.Add(-age)}
+	}
+	many := func(n int, status string) []models.Transaction {
+		txns := make([]models.Transaction, n)
+		for i := range txns {
+			txns[i] = txn("withdrawal", status, 1, time.Duration(i+1)*time.Second)
+		}
+		return txns
+	}
+	tests := []struct {
+		name            string
+		txns            []models.Transaction
+		credits, debits float64
+		pages           int
+	}{
+		{name: "no transactions", pages: 1},
+		{
+			name: "pending only, by direction",
+			txns: []models.Transaction{
+				txn("deposit", "pending", 100, time.Hour),
+				txn("withdrawal", "pending", 30.10, 2*time.Hour),
+				txn("transfer", "pending", 20.05, 3*time.Hour),
+				txn("deposit", "completed", 500, 4*time.Hour),
+				txn("withdrawal", "failed", 70, 5*time.Hour),
+			},
+			credits: 100, debits: 50.15, pages: 1,
+		},
+		{
+			name: "transactions before the window are not read",
+			txns: []models.Transaction{
+				txn("withdrawal", "pending", 10, time.Hour),
+				txn("withdrawal", "pending", 99, pendingWindow+time.Hour),
+			},
+			debits: 10, pages: 1,
+		},
+		{name: "full pages are followed", txns: many(pageSize+1, "pending"), debits: pageSize + 1, pages: 2},
+		{name: "reading stops at the page limit", txns: many(pendingPages*pageSize+5, "pending"), debits: pendingPages * pageSize, pages: pendingPages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, pages := newTestGenerator(t, tt.txns)
+			credits, debits, err := g.Pending(context.Background(), "acc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if credits != tt.credits || debits != tt.debits || *pages != tt.pages {
+				t.Fatalf("got credits %.2f, debits %.2f in %d pages; want %.2f, %.2f in %d",
+					credits, debits, *pages, tt.credits, tt.debits, tt.pages)
+			}
+		})
+	}
+}
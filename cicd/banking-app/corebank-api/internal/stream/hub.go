@@ -0,0 +1,217 @@
+// Package stream fans account changes out to clients following an account
+// over Server-Sent Events. The hub subscribes to domain events and keeps the
+// most recent messages so a client that reconnects can resume where it left
+// off.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corebank-api/internal/events"
+)
+
+// Message types, sent as the SSE event field.
+const (
+	// MessageAccount carries the account, including its balance, after a
+	// change.
+	MessageAccount = "account"
+	// MessageTransaction carries a transaction that was submitted or whose
+	// status changed.
+	MessageTransaction = "transaction"
+	// MessageAccountDeleted is the last message on an account's stream.
+	MessageAccountDeleted = "account.deleted"
+)
+
+// subscriberBuffer is how many messages a subscriber may fall behind before
+// it is dropped. A dropped client reconnects and resumes from the hub's
+// buffer.
+const subscriberBuffer = 64
+
+// Message is one event on an account's stream.
+type Message struct {
+	// ID is unique to this hub; clients send it back as Last-Event-ID.
+	ID        string
+	Type      string
+	AccountID string
+	Data      json.RawMessage
+
+	seq uint64
+}
+
+// Hub keeps the last messages published across all accounts and delivers new
+// ones to the subscriptions for their account. Message IDs are prefixed with
+// the time the hub started, so IDs from another instance or before a restart
+// are recognised as unknown rather than resumed from the wrong place.
+type Hub struct {
+	epoch string
+	size  int
+
+	mu sync.Mutex
+	// buffer is a ring of the last size messages; next is where the next
+	// message goes once it is full.
+	buffer        []Message
+	next          int
+	seq           uint64
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+}
+
+// NewHub keeps the last size messages for resuming.
+func NewHub(size int) *Hub {
+	return &Hub{
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:          size,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives an account's messages until it is closed, the hub is
+// closed or it falls too far behind, when C is closed.
+type Subscription struct {
+	C <-chan Message
+	// Cursor is the ID of the last message published before the
+	// subscription began; a client sent it resumes from there.
+	Cursor    string
+	c         chan Message
+	accountID string
+	hub       *Hub
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
+
+// Subscribe follows an account. If lastEventID names a message still in the
+// buffer, the account's messages since it are returned to be sent first and
+// resumed is true; otherwise the caller has missed messages, or never had
+// any, and should send the account's current state instead.
+func (h *Hub) Subscribe(accountID, lastEventID string) (sub *Subscription, missed []Message, resumed bool) {
+	c := make(chan Message, subscriberBuffer)
+	sub = &Subscription{C: c, c: c, accountID: accountID, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(c)
+		return sub, nil, false
+	}
+	h.subscriptions[sub] = struct{}{}
+	sub.Cursor = h.id(h.seq)
+
+	seq, ok := h.parseID(lastEventID)
+	if !ok || seq > h.seq || seq+1 < h.oldest() {
+		return sub, nil, false
+	}
+	for i := range len(h.buffer) {
+		m := h.buffer[(h.next+i)%len(h.buffer)]
+		if m.seq > seq && m.AccountID == accountID {
+			missed = append(missed, m)
+		}
+	}
+	return sub, missed, true
+}
+
+// HandleEvent subscribes to domain events and publishes the message each
+// corresponds to on its account's stream.
+func (h *Hub) HandleEvent(_ context.Context, event events.Event) error {
+	var msgType string
+	var data any
+	switch event.Type {
+	case events.AccountCreated, events.AccountUpdated:
+		msgType, data = MessageAccount, event.Account
+	case events.AccountDeleted:
+		msgType, data = MessageAccountDeleted, map[string]string{"id": event.AccountID}
+	case events.TransactionSubmitted, events.TransactionUpdated:
+		msgType, data = MessageTransaction, event.Transaction
+	default:
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message: %w", msgType, err)
+	}
+	h.Publish(event.AccountID, msgType, raw)
+	return nil
+}
+
+// Publish adds a message to the buffer and sends it to the account's
+// subscribers. A subscriber whose channel is full is dropped rather than
+// holding up the publisher.
+func (h *Hub) Publish(accountID, msgType string, data json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.seq++
+	m := Message{ID: h.id(h.seq), Type: msgType, AccountID: accountID, Data: data, seq: h.seq}
+	if len(h.buffer) < h.size {
+		h.buffer = append(h.buffer, m)
+	} else {
+		h.buffer[h.next] = m
+		h.next = (h.next + 1) % h.size
+	}
+
+	for sub := range h.subscriptions {
+		if sub.accountID != accountID {
+			continue
+		}
+		select {
+		case sub.c <- m:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Close ends every subscription, e.g. so open streams do not hold up
+// shutdown, and ignores later messages.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subscriptions {
+		h.drop(sub)
+	}
+}
+
+// drop removes a subscription and closes its channel. h.mu must be held.
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subscriptions[sub]; ok {
+		delete(h.subscriptions, sub)
+		close(sub.c)
+	}
+}
+
+// oldest is the sequence number of the oldest buffered message, or the next
+// one to be published when the buffer is empty. h.mu must be held.
+func (h *Hub) oldest() uint64 {
+	if len(h.buffer) == 0 {
+		return h.seq + 1
+	}
+	return h.buffer[h.next%len(h.buffer)].seq
+}
+
+func (h *Hub) id(seq uint64) string {
+	return h.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseID returns the sequence number of a message ID from this hub.
+func (h *Hub) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,176 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+)
+
+// received drains the messages waiting on sub, and reports whether its
+// channel is closed.
+func received(sub *Subscription) (msgs []Message, closed bool) {
+	for {
+		select {
+		case m, ok := <-sub.C:
+			if !ok {
+				return msgs, true
+			}
+			msgs = append(msgs, m)
+		default:
+			return msgs, false
+		}
+	}
+}
+
+func TestSubscribeByAccount(t *testing.T) {
+	hub := NewHub(10)
+	acc, _, _ := hub.Subscribe("acc", "")
+	again, _, _ := hub.Subscribe("acc", "")
+	other, _, _ := hub.Subscribe("other", "")
+
+	hub.Publish("acc", MessageAccount, json.RawMessage(`{"id":"acc"}`))
+	hub.Publish("third", MessageAccount, json.RawMessage(`{"id":"third"}`))
+
+	for _, sub := range []*Subscription{acc, again} {
+		msgs, closed := received(sub)
+		if len(msgs) != 1 || closed || msgs[0].AccountID != "acc" || string(msgs[0].Data) != `{"id":"acc"}` {
+			t.Fatalf("received %+v (closed %v), want the acc message", msgs, closed)
+		}
+	}
+	if msgs, _ := received(other); len(msgs) != 0 {
+		t.Fatalf("other received %+v, want nothing", msgs)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	hub := NewHub(10)
+	sub, _, _ := hub.Subscribe("acc", "")
+	sub.Close()
+	sub.Close()
+	hub.Publish("acc", MessageAccount, json.RawMessage(`{}`))
+
+	if msgs, closed := received(sub); len(msgs) != 0 || !closed {
+		t.Fatalf("received %+v (closed %v), want a closed channel", msgs, closed)
+	}
+}
+
+// A subscriber that stops reading is dropped once its buffer is full and
+// does not hold up the others.
+func TestSlowSubscriber(t *testing.T) {
+	hub := NewHub(100)
+	slow, _, _ := hub.Subscribe("acc", "")
+	fast, _, _ := hub.Subscribe("acc", "")
+
+	var delivered int
+	for range subscriberBuffer + 1 {
+		hub.Publish("acc", MessageTransaction, json.RawMessage(`{}`))
+		msgs, _ := received(fast)
+		delivered += len(msgs)
+	}
+
+	msgs, closed := received(slow)
+	if len(msgs) != subscriberBuffer || !closed {
+		t.Fatalf("slow subscriber got %d messages (closed %v), want %d then closed", len(msgs), closed, subscriberBuffer)
+	}
+	if _, closed := received(fast); delivered != subscriberBuffer+1 || closed {
+		t.Fatalf("fast subscriber got %d messages (closed %v), want every one", delivered, closed)
+	}
+
+	// The dropped client reconnects and resumes where it got to
+	_, missed, resumed := hub.Subscribe("acc", msgs[len(msgs)-1].ID)
+	if !resumed || len(missed) != 1 {
+		t.Fatalf("resumed %v with %d missed, want the last message", resumed, len(missed))
+	}
+}
+
+func TestResume(t *testing.T) {
+	hub := NewHub(3)
+	first, _, _ := hub.Subscribe("acc", "")
+	for _, account := range []string{"acc", "other", "acc", "acc"} {
+		hub.Publish(account, MessageAccount, json.RawMessage(`{}`))
+	}
+	// The buffer holds the last three: other, acc and acc
+	msgs, _ := received(first)
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantMissed  int
+		wantResumed bool
+	}{
+		{name: "up to date", lastEventID: msgs[2].ID, wantResumed: true},
+		{name: "one behind", lastEventID: msgs[1].ID, wantMissed: 1, wantResumed: true},
+		{name: "from just before the buffer", lastEventID: msgs[0].ID, wantMissed: 2, wantResumed: true},
+		{name: "further back", lastEventID: first.Cursor},
+		{name: "no ID", lastEventID: ""},
+		{name: "from another hub", lastEventID: "0-1"},
+		{name: "not yet published", lastEventID: hub.id(5)},
+		{name: "malformed", lastEventID: hub.epoch + "-x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, resumed := hub.Subscribe("acc", tt.lastEventID)
+			defer sub.Close()
+			if len(missed) != tt.wantMissed || resumed != tt.wantResumed {
+				t.Fatalf("resumed %v with %d missed, want %v with %d", resumed, len(missed), tt.wantResumed, tt.wantMissed)
+			}
+			for _, m := range missed {
+				if m.AccountID != "acc" {
+					t.Fatalf("missed %+v from another account", m)
+				}
+			}
+			if sub.Cursor != msgs[2].ID {
+				t.Fatalf("cursor %s, want the last message %s", sub.Cursor, msgs[2].ID)
+			}
+		})
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	hub := NewHub(10)
+	sub, _, _ := hub.Subscribe("acc", "")
+	ctx := context.Background()
+	for _, event := range []events.Event{
+		events.NewAccountUpdated(&models.Account{ID: "acc", Balance: 10}, nil),
+		events.NewTransactionSubmitted(&models.Transaction{ID: "txn-1", AccountID: "acc"}),
+		events.NewAccountDeleted("acc"),
+		{Type: "Unknown", AccountID: "acc"},
+	} {
+		if err := hub.HandleEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs, _ := received(sub)
+	if len(msgs) != 3 {
+		t.Fatalf("received %+v, want a message per known event", msgs)
+	}
+	var account models.Account
+	json.Unmarshal(msgs[0].Data, &account)
+	if msgs[0].Type != MessageAccount || account.Balance != 10 {
+		t.Fatalf("message %s %s, want the account", msgs[0].Type, msgs[0].Data)
+	}
+	if msgs[1].Type != MessageTransaction || msgs[2].Type != MessageAccountDeleted || string(msgs[2].Data) != `{"id":"acc"}` {
+		t.Fatalf("messages %+v", msgs[1:])
+	}
+}
+
+func TestClose(t *testing.T) {
+	hub := NewHub(10)
+	open, _, _ := hub.Subscribe("acc", "")
+	hub.Close()
+	hub.Publish("acc", MessageAccount, json.RawMessage(`{}`))
+	late, _, resumed := hub.Subscribe("acc", open.Cursor)
+
+	for _, sub := range []*Subscription{open, late} {
+		if msgs, closed := received(sub); len(msgs) != 0 || !closed {
+			t.Fatalf("received %+v (closed %v), want a closed channel", msgs, closed)
+		}
+	}
+	if resumed {
+		t.Fatal("resumed on a closed hub")
+	}
+}
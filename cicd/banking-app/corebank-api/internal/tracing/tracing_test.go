@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// restoreGlobals puts back the global provider and propagator Setup
+// replaces.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		exporter string
+		// exports is whether spans go to an SDK provider
+		exports bool
+		wantErr bool
+	}{
+		{exporter: ""},
+		{exporter: ExporterNone},
+		{exporter: ExporterOTLP, exports: true},
+		{exporter: "OTLP", exports: true},
+		{exporter: "jaeger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			restoreGlobals(t)
+			otel.SetTracerProvider(noop.NewTracerProvider())
+
+			shutdown, err := Setup(context.Background(), tt.exporter, "corebank-api")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Setup succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok != tt.exports {
+				t.Errorf("tracer provider is %T", otel.GetTracerProvider())
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown: %v", err)
+			}
+		})
+	}
+}
+
+// traceparent is propagated even when no exporter is configured.
+func TestSetupPropagatesWithoutExporter(t *testing.T) {
+	restoreGlobals(t)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	if _, err := Setup(context.Background(), ExporterNone, "corebank-api"); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), sc), propagation.HeaderCarrier(header))
+	if want := "00-01020300000000000000000000000000-0405060000000000-01"; header.Get("traceparent") != want {
+		t.Errorf("traceparent = %q, want %q", header.Get("traceparent"), want)
+	}
+}
@@ -0,0 +1,35 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CheckHealth calls the transaction service /health endpoint, which in turn
+// checks its own database.
+func CheckHealth(ctx context.Context, client *http.Client, baseURL string) error {
+	target, err := url.JoinPath(baseURL, "/health")
+	if err != nil {
+		return fmt.Errorf("invalid transaction service URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("transaction service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("transaction service returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/corebank-api/internal/models"
+)
+
+// StatusError is returned when the transaction service answers with a
+// non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("transaction service returned status: %d", e.StatusCode)
+}
+
+// TransactionService is a typed client for the transaction service, for
+// callers that need its responses rather than proxying them.
+type TransactionService struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewTransactionService calls the transaction service at baseURL with client,
+// which should be the shared client from NewClient.
+func NewTransactionService(baseURL string, client *http.Client) *TransactionService {
+	return &TransactionService{baseURL: baseURL, client: client}
+}
+
+// Create records a transaction, which the service always stores as pending.
+func (s *TransactionService) Create(ctx context.Context, txn models.Transaction) (*models.Transaction, error) {
+	body, err := json.Marshal(txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	var created models.Transaction
+	if err := s.do(ctx, http.MethodPost, "/transactions", nil, body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Get returns a transaction by ID.
+func (s *TransactionService) Get(ctx context.Context, id string) (*models.Transaction, error) {
+	var txn models.Transaction
+	if err := s.do(ctx, http.MethodGet, "/transactions/"+url.PathEscape(id), nil, nil, &txn); err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// UpdateStatus moves a transaction to status.
+func (s *TransactionService) UpdateStatus(ctx context.Context, id, status string) (*models.Transaction, error) {
+	var updated models.Transaction
+	query := url.Values{"status": {status}}
+	if err := s.do(ctx, http.MethodPut, "/transactions/"+url.PathEscape(id), query, nil, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// List returns a page of transactions, newest first. An empty accountID
+// lists transactions for every account.
+func (s *TransactionService) List(ctx context.Context, accountID string, limit, offset int) ([]models.Transaction, error) {
+	query := url.Values{
+		"limit":  {strconv.Itoa(limit)},
+		"offset": {strconv.Itoa(offset)},
+	}
+	if accountID != "" {
+		query.Set("account_id", accountID)
+	}
+
+	var txns []models.Transaction
+	if err := s.do(ctx, http.MethodGet, "/transactions", query, nil, &txns); err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+func (s *TransactionService) do(ctx context.Context, method, path string, query url.Values, body []byte, out any) error {
+	target, err := url.JoinPath(s.baseURL, path)
+	if err != nil {
+		return fmt.Errorf("invalid transaction service URL: %w", err)
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction service request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call transaction service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode transaction service response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package upstream
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Transport wraps calls to the transaction service with the circuit breaker,
+// request ID propagation and latency metrics. Base is expected to handle
+// tracing, see NewClient.
+type Transport struct {
+	Base    http.RoundTripper
+	Breaker *Breaker
+}
+
+// NewClient returns the HTTP client shared by every caller of the transaction
+// service, so they all trip and recover the same breaker.
+func NewClient(timeout time.Duration) *http.Client {
+	breaker := NewBreaker(5, 30*time.Second, func(s State) {
+		metrics.SetCircuitState(int(s))
+	})
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &Transport{
+			// otelhttp records a client span and injects traceparent
+			Base:    otelhttp.NewTransport(http.DefaultTransport),
+			Breaker: breaker,
+		},
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Breaker.Allow(); err != nil {
+		metrics.ObserveTransactionService(req.Method, "circuit_open", 0)
+		return nil, err
+	}
+
+	if id := logging.RequestID(req.Context()); id != "" && req.Header.Get(logging.RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Breaker.Record(false)
+		metrics.ObserveTransactionService(req.Method, "error", elapsed)
+		return nil, err
+	}
+
+	t.Breaker.Record(resp.StatusCode < http.StatusInternalServerError)
+	metrics.ObserveTransactionService(req.Method, strconv.Itoa(resp.StatusCode), elapsed)
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package webhooks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corebank-api/pkg/client"
+)
+
+const testSecret = "whsec_test"
+
+var (
+	signedAt = time.Unix(1760875200, 0)
+	body     = []byte(`{"type":"account.created"}`)
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "1760875200.<body>" under the secret
+	want := "t=1760875200,v1=0a72c72c15a993a529f16822dabe3ce100446b276c60c7afe5fffcdc9a644b81"
+	if got := Sign(testSecret, signedAt, body); got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	header := Sign(testSecret, signedAt, body)
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		now       time.Time
+		tolerance time.Duration
+		wantErr   bool
+	}{
+		{name: "valid", header: header, now: signedAt.Add(time.Minute), tolerance: 5 * time.Minute},
+		{name: "spaces after commas", header: strings.ReplaceAll(header, ",", ", "), now: signedAt, tolerance: time.Minute},
+		{name: "any age without a tolerance", header: header, now: signedAt.Add(365 * 24 * time.Hour)},
+		{
+			name: "one of several signatures", now: signedAt, tolerance: time.Minute,
+			header: "t=1760875200,v1=" + strings.Repeat("0", 64) + "," + strings.TrimPrefix(header, "t=1760875200,"),
+		},
+		{name: "body changed", header: header, body: []byte(`{"type":"account.deleted"}`), now: signedAt, wantErr: true},
+		{name: "other secret", secret: "whsec_other", header: header, now: signedAt, wantErr: true},
+		{name: "too old", header: header, now: signedAt.Add(6 * time.Minute), tolerance: 5 * time.Minute, wantErr: true},
+		{name: "from the future", header: header, now: signedAt.Add(-6 * time.Minute), tolerance: 5 * time.Minute, wantErr: true},
+		{name: "time changed", header: strings.Replace(header, "t=1760875200", "t=1760875201", 1), now: signedAt, wantErr: true},
+		{name: "no time", header: strings.TrimPrefix(header, "t=1760875200,"), now: signedAt, wantErr: true},
+		{name: "no signature", header: "t=1760875200", now: signedAt, wantErr: true},
+		{name: "empty", header: "", now: signedAt, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, payload := tt.secret, tt.body
+			if secret == "" {
+				secret = testSecret
+			}
+			if payload == nil {
+				payload = body
+			}
+			err := Verify(secret, tt.header, payload, tt.now, tt.tolerance)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("got %v, want ErrInvalidSignature", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("got %v, want the signature accepted", err)
+			}
+		})
+	}
+}
+
+// Deliveries signed here verify with the SDK receivers use.
+func TestSignVerifiesWithClient(t *testing.T) {
+	header := Sign(testSecret, time.Now(), body)
+	if err := client.VerifyWebhook(testSecret, header, body, time.Minute); err != nil {
+		t.Fatalf("VerifyWebhook = %v", err)
+	}
+	if err := client.VerifyWebhook("whsec_other", header, body, time.Minute); !errors.Is(err, client.ErrInvalidSignature) {
+		t.Fatalf("VerifyWebhook with another secret = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 || a == b {
+		t.Fatalf("secrets %q and %q, want distinct whsec_ secrets of 32 bytes", a, b)
+	}
+}
@@ -0,0 +1,298 @@
+// Package webhooks sends account and transaction events to the URLs partner
+// systems subscribe, signed with each subscription's secret and retried with
+// exponential backoff until they are accepted.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/repository"
+)
+
+// Store persists subscriptions and their deliveries;
+// repository.WebhookRepository implements it against DynamoDB.
+type Store interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	// GetSubscription returns nil and no error when the subscription does
+	// not exist.
+	GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	// ListSubscriptions returns every subscription, oldest first.
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// GetDelivery returns nil and no error when the delivery does not exist.
+	GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	// UpdateDelivery replaces a delivery read with the given updated_at,
+	// failing with repository.ErrConflict if it has changed since.
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery, prevUpdatedAt time.Time) error
+	// Deliveries returns a subscription's deliveries, newest first.
+	Deliveries(ctx context.Context, subscriptionID string) ([]models.WebhookDelivery, error)
+	// ListDue returns the pending deliveries whose next attempt is not
+	// after now, oldest first.
+	ListDue(ctx context.Context, now time.Time) ([]models.WebhookDelivery, error)
+}
+
+// maxErrorBody bounds how much of a subscriber's error response is kept in
+// the delivery log.
+const maxErrorBody = 512
+
+// Dispatcher records a delivery for every subscription to an event and
+// sends them. Each attempt is claimed before it is made, so however many
+// instances run the dispatcher a delivery is sent by one at a time.
+type Dispatcher struct {
+	store          Store
+	client         *http.Client
+	timeout        time.Duration
+	interval       time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxAttempts    int
+}
+
+// NewDispatcher sends deliveries with the given timeout. A failed delivery
+// is retried after initialBackoff, doubling up to maxBackoff, until
+// maxAttempts attempts have failed.
+func NewDispatcher(store Store, timeout, interval, initialBackoff, maxBackoff time.Duration, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		store:          store,
+		client:         &http.Client{Timeout: timeout},
+		timeout:        timeout,
+		interval:       interval,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		maxAttempts:    maxAttempts,
+	}
+}
+
+// HandleEvent subscribes to domain events and publishes the webhook event
+// each corresponds to, under the domain event's ID and time.
+func (d *Dispatcher) HandleEvent(ctx context.Context, event events.Event) error {
+	webhook := models.Event{ID: event.ID, OccurredAt: event.OccurredAt}
+	switch event.Type {
+	case events.AccountCreated:
+		webhook.Type, webhook.Data = models.EventAccountCreated, event.Account
+	case events.AccountUpdated:
+		webhook.Type, webhook.Data = models.EventAccountUpdated, event.Account
+		if c, ok := event.Changed("status"); ok && c.New == models.AccountStatusClosed {
+			webhook.Type = models.EventAccountClosed
+		}
+	case events.AccountDeleted:
+		webhook.Type, webhook.Data = models.EventAccountDeleted, map[string]string{"id": event.AccountID}
+	case events.TransactionSubmitted:
+		webhook.Type, webhook.Data = models.EventTransactionPosted, event.Transaction
+	default:
+		return nil
+	}
+	return d.Publish(ctx, webhook)
+}
+
+// Publish records a delivery of the event to every subscription to its
+// type; the dispatcher sends them on its next run.
+func (d *Dispatcher) Publish(ctx context.Context, event models.Event) error {
+	subscriptions, err := d.store.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	eventType := event.Type
+	var payload []byte
+	var errs []error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Subscribes(eventType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+		}
+		delivery := &models.WebhookDelivery{
+			ID:             uuid.New().String(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			URL:            subscription.URL,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := d.store.CreateDelivery(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("subscription %s: %w", subscription.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Redeliver records a new delivery of a delivery's event to the
+// subscription's current URL, to be sent on the dispatcher's next run. The
+// event keeps its ID.
+func (d *Dispatcher) Redeliver(ctx context.Context, subscription *models.WebhookSubscription, of *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	delivery := &models.WebhookDelivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscription.ID,
+		EventID:        of.EventID,
+		EventType:      of.EventType,
+		URL:            subscription.URL,
+		Payload:        of.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   of.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := d.store.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Run sends due deliveries now and then every interval until ctx is
+// cancelled. It has the signature of a server.Worker.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Warn("webhook deliveries incomplete", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce makes one attempt at every delivery due at now and returns how
+// many it attempted. Deliveries that fail to send are retried after their
+// backoff; ones that fail to be recorded are retried on the next run.
+func (d *Dispatcher) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	due, err := d.store.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	attempted := 0
+	for i := range due {
+		sent, err := d.attempt(ctx, &due[i], now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s: %w", due[i].ID, err))
+		}
+		if sent {
+			attempted++
+		}
+	}
+	return attempted, errors.Join(errs...)
+}
+
+// attempt sends a delivery and records the outcome, reporting whether it
+// made the attempt. The attempt is claimed first by pushing the delivery's
+// next attempt past the time it may take, so a dispatcher that stops
+// midway leaves it to be retried rather than lost.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery, now time.Time) (bool, error) {
+	prev := delivery.UpdatedAt
+	lease := now.UTC().Add(d.timeout + d.interval)
+	delivery.Attempts++
+	delivery.NextAttemptAt = &lease
+	delivery.UpdatedAt = now.UTC()
+	err := d.store.UpdateDelivery(ctx, delivery, prev)
+	if errors.Is(err, repository.ErrConflict) {
+		// Claimed by another dispatcher
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	subscription, err := d.store.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return false, err
+	}
+	statusCode, sendErr := 0, errors.New("subscription was deleted")
+	if subscription != nil {
+		delivery.URL = subscription.URL
+		statusCode, sendErr = d.send(ctx, subscription, delivery, now)
+	}
+
+	claimed := delivery.UpdatedAt
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	switch {
+	case sendErr == nil:
+		delivered := now.UTC()
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &delivered
+		delivery.NextAttemptAt = nil
+	case subscription != nil && delivery.Attempts < d.maxAttempts:
+		retryAt := now.UTC().Add(d.backoff(delivery.Attempts))
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = &retryAt
+	default:
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = nil
+	}
+	delivery.UpdatedAt = time.Now().UTC()
+	slog.Info("webhook delivery attempted", "delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+		"event_type", delivery.EventType, "attempt", delivery.Attempts, "status", delivery.Status, "status_code", statusCode, "error", delivery.LastError)
+
+	if err := d.store.UpdateDelivery(ctx, delivery, claimed); err != nil && !errors.Is(err, repository.ErrConflict) {
+		// The claim expires and the delivery is sent again; receivers
+		// deduplicate on the event ID
+		return true, err
+	}
+	return true, nil
+}
+
+// send posts a delivery's payload, signed at now, and returns the response
+// status. Any status other than 2xx is a failure.
+func (d *Dispatcher) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "corebank-webhooks/1")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the delay before the attempt after the given number of failed
+// ones: initialBackoff, doubling each time, up to maxBackoff.
+func (d *Dispatcher) backoff(failed int) time.Duration {
+	delay := d.initialBackoff
+	for i := 1; i < failed && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxBackoff)
+}
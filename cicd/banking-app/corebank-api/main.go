@@ -2,148 +2,248 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"net/http"
+	"errors"
+	"flag"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/joho/godotenv"
-	"github.com/rs/cors" // Import the CORS package
-
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
 	"github.com/corebank-api/internal/handlers"
+	"github.com/corebank-api/internal/health"
+	"github.com/corebank-api/internal/holds"
+	"github.com/corebank-api/internal/interest"
+	"github.com/corebank-api/internal/limits"
+	"github.com/corebank-api/internal/logging"
+	"github.com/corebank-api/internal/metrics"
+	"github.com/corebank-api/internal/middleware"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/outbox"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/reconcile"
 	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/risk"
+	"github.com/corebank-api/internal/schedules"
+	"github.com/corebank-api/internal/server"
+	"github.com/corebank-api/internal/statements"
+	"github.com/corebank-api/internal/stream"
+	"github.com/corebank-api/internal/tracing"
+	"github.com/corebank-api/internal/upstream"
+	"github.com/corebank-api/internal/webhooks"
 )
 
 func main() {
-	// Load .env file
-	err := godotenv.Load()
+	// Defaults, then config file, then env (.env included), then flags
+	appCfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal("Invalid configuration", err)
 	}
 
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(os.Getenv("AWS_REGION")),
-	)
+	// Structured JSON logging; PII is redacted unless disabled in config
+	slog.SetDefault(logging.New(os.Stdout, logging.Options{
+		Level:     appCfg.Log.Level,
+		RedactPII: appCfg.Log.RedactPII,
+	}))
+	slog.Info("effective configuration", "config", appCfg.YAML())
+
+	// Tracing exporter: otlp, stdout or none (default)
+	shutdownTracing, err := tracing.Setup(context.Background(), appCfg.Tracing.Exporter, "corebank-api")
 	if err != nil {
-		log.Fatalf("Unable to load SDK config: %v", err)
+		fatal("Unable to set up tracing", err)
 	}
+	defer shutdownTracing(context.Background())
 
-	// Create DynamoDB client
-	client := dynamodb.NewFromConfig(cfg)
-	log.Println("Successfully connected to DynamoDB!")
-
-	// Create tables if they don't exist
-	err = createTablesIfNotExist(client)
+	// Create DynamoDB client, shared by every repository
+	client, err := repository.NewDynamoDBClient(context.TODO(), appCfg.AWS, appCfg.DynamoDB)
 	if err != nil {
-		log.Fatalf("Failed to create tables: %v", err)
+		fatal("Unable to load SDK config", err)
 	}
+	slog.Info("Successfully connected to DynamoDB!", "endpoint", appCfg.DynamoDB.Endpoint)
 
-	// Initialize repositories with the same client
-	accountRepo := repository.NewAccountRepository()
+	tables := repository.TablesFromConfig(appCfg.DynamoDB)
+
+	// Create tables if they don't exist. Data migrations are run separately
+	// with `corebank migrate`.
+	err = repository.CreateTables(context.TODO(), client, tables)
 	if err != nil {
-		log.Fatalf("failed to create account repository: %v", err)
+		fatal("Failed to create tables", err)
 	}
 
-	// Get Python service URL from environment or default to localhost
-	// pythonServiceURL := os.Getenv("PYTHON_SERVICE_URL")
-	transactionServiceURL := os.Getenv("TRANSACTION_SERVICE_URL")
-	// if pythonServiceURL == "" {
-	// 	pythonServiceURL = "http://localhost:5000"
-	// }
-
-	// Initialize handlers
-	// accountHandler := handlers.NewAccountHandler(accountRepo, pythonServiceURL)
-	// transactionHandler := handlers.NewTransactionHandler(accountRepo, pythonServiceURL)
-	accountHandler := handlers.NewAccountHandler(accountRepo, transactionServiceURL)
-	transactionHandler := handlers.NewTransactionHandler(accountRepo, transactionServiceURL)
-
-	// Register routes
-	http.HandleFunc("/accounts", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost || r.Method == http.MethodGet {
-			accountHandler.HandleAccounts(w, r)
-			return
+	// Initialize repositories with the same client
+	accountRepo := repository.NewAccountRepository(client, tables.Accounts, tables.AccountHistory, tables.BalancePostings)
+	outboxRepo := repository.NewOutboxRepository(client, tables.Outbox)
+	statementRepo := repository.NewStatementRepository(client, tables.Statements)
+	interestRepo := repository.NewInterestRepository(client, tables.InterestAccruals, tables.InterestPostings)
+	holdRepo := repository.NewHoldRepository(client, tables.Holds)
+	scheduleRepo := repository.NewScheduleRepository(client, tables.Schedules, tables.ScheduleExecutions)
+	webhookRepo := repository.NewWebhookRepository(client, tables.Webhooks, tables.WebhookDeliveries)
+	reconciliationRepo := repository.NewReconciliationRepository(client, tables.Reconciliations)
+	riskRepo := repository.NewRiskDecisionRepository(client, tables.RiskDecisions)
+	limitRepo := repository.NewLimitRepository(client, tables.LimitCounters)
+	kycRepo := repository.NewKYCRepository(client, tables.KYCRecords)
+
+	// One client for all transaction service calls, so they share a circuit breaker
+	transactionServiceURL := appCfg.TransactionService.URL
+	transactionClient := upstream.NewClient(appCfg.TransactionService.Timeout)
+	transactionService := upstream.NewTransactionService(transactionServiceURL, transactionClient)
+	transactionOutbox := outbox.New(outboxRepo, transactionService)
+
+	// Account and transaction changes are published as domain events, and
+	// the initial deposit, webhooks, metrics and the audit log each
+	// subscribe to them. Transactions posted through the outbox are
+	// published once the transaction service has them.
+	dispatcher := webhooks.NewDispatcher(webhookRepo, appCfg.Webhooks.Timeout, appCfg.Webhooks.Interval,
+		appCfg.Webhooks.InitialBackoff, appCfg.Webhooks.MaxBackoff, appCfg.Webhooks.MaxAttempts)
+	bus := events.NewBus()
+	bus.Subscribe("initial_deposit", transactionOutbox.SubmitInitialDeposit, events.AccountCreated)
+	bus.Subscribe("webhooks", dispatcher.HandleEvent)
+	bus.Subscribe("metrics", metrics.RecordEvent, events.AccountCreated)
+	bus.Subscribe("audit", events.AuditLog(slog.Default()))
+	// Clients following an account over SSE are sent its changes
+	hub := stream.NewHub(appCfg.Stream.BufferSize)
+	bus.Subscribe("stream", hub.HandleEvent)
+	if path := appCfg.Events.NDJSONPath; path != "" {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			fatal("Failed to open event log", err)
 		}
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		defer file.Close()
+		bus.Subscribe("ndjson", events.NDJSONSink(file))
+	}
+	transactionOutbox.OnDelivered(func(ctx context.Context, entry *models.OutboxEntry) {
+		bus.Publish(ctx, events.NewTransactionSubmitted(&entry.Transaction))
 	})
 
-	http.HandleFunc("/accounts/", accountHandler.HandleAccountByID)
-
-	http.HandleFunc("/transactions", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			transactionHandler.HandleTransactions(w, r)
-			return
-		}
-		if r.Method == http.MethodGet {
-			transactionHandler.HandleGetTransactions(w, r)
-			return
+	// Statements use day and month boundaries in the configured timezone,
+	// which config validation has already loaded once
+	statementLocation, _ := time.LoadLocation(appCfg.Statements.Timezone)
+	statementGenerator := statements.NewGenerator(transactionService, statementLocation)
+
+	// Account types and their rules; withdrawals and holds are checked
+	// against the account's stored balance after its pending transactions,
+	// less what active holds reserve
+	catalog := products.NewCatalog(appCfg.Products)
+	balances := holds.NewBalances(statementGenerator, holdRepo)
+	accountHandler := handlers.NewAccountHandler(accountRepo, transactionServiceURL, transactionClient, catalog, bus)
+	// Completed transactions are applied to stored balances exactly once
+	poster := posting.NewPoster(accountRepo, balances, catalog)
+	transactionOutbox.SetPoster(poster)
+	// New transactions and transfers are screened by the configured risk
+	// rules, and each decision is logged
+	var riskRules []risk.Rule
+	if path := appCfg.Risk.RulesFile; path != "" {
+		riskRules, err = risk.LoadRules(path, transactionService, statementLocation)
+		if err != nil {
+			fatal("Failed to load risk rules", err)
 		}
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		slog.Info("Loaded risk rules", "file", path, "rules", len(riskRules))
+	}
+	riskEngine := risk.NewEngine(riskRules, riskRepo)
+	// Withdrawals and transfers count against daily and monthly limits,
+	// which reset in each account's timezone and are tighter for customers
+	// not yet verified
+	limiter := limits.NewLimiter(limitRepo, catalog, appCfg.KYC, statementLocation)
+	transactionHandler := handlers.NewTransactionHandler(accountRepo, transactionServiceURL, transactionClient, balances, poster, riskEngine, limiter, bus)
+	statementHandler := handlers.NewStatementHandler(accountRepo, statementRepo, statementGenerator)
+	holdHandler := handlers.NewHoldHandler(accountRepo, holdRepo, balances, transactionOutbox, transactionHandler, appCfg.Holds.DefaultExpiry, appCfg.Holds.MaxExpiry)
+	scheduleHandler := handlers.NewScheduleHandler(accountRepo, scheduleRepo, statementLocation)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo, dispatcher)
+	streamHandler := handlers.NewStreamHandler(accountRepo, hub, appCfg.Stream.Heartbeat)
+	// Stored balances are checked against the balance of completed
+	// transactions on request and, if enabled, every interval
+	reconciler := reconcile.NewReconciler(accountRepo, statementGenerator, poster, reconciliationRepo, bus,
+		appCfg.Reconciliation.Interval, appCfg.Reconciliation.Correct)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciler, reconciliationRepo)
+	riskHandler := handlers.NewRiskHandler(riskRepo)
+	limitHandler := handlers.NewLimitHandler(accountRepo, limiter, bus)
+	kycHandler := handlers.NewKYCHandler(accountRepo, kycRepo, bus)
+
+	// Liveness and readiness probes. Readiness checks DynamoDB and the
+	// transaction service.
+	checker := health.NewChecker(appCfg.Health.CheckTimeout, appCfg.Health.CacheTTL)
+	checker.Register("dynamodb", accountRepo.Ping)
+	checker.Register("transaction_service", func(ctx context.Context) error {
+		return upstream.CheckHealth(ctx, transactionClient, transactionServiceURL)
 	})
 
-	http.HandleFunc("/transactions/", transactionHandler.HandleTransactionByID)
-
-	// http.HandleFunc("/accounts", accountHandler.HandleAccounts)
-	// http.HandleFunc("/accounts/", accountHandler.HandleAccountByID)
-	// http.HandleFunc("/transactions", transactionHandler.HandleTransactions)
-	// http.HandleFunc("/transactions", transactionHandler.HandleGetTransactions)
-	// http.HandleFunc("/transactions/", transactionHandler.HandleTransactionByID)
-
-	// Health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	// Register routes behind request IDs, access logs, CORS, auth and
+	// idempotent POST replay
+	routes := server.Routes(server.Handlers{
+		Accounts:        accountHandler,
+		Transactions:    transactionHandler,
+		Statements:      statementHandler,
+		Holds:           holdHandler,
+		Schedules:       scheduleHandler,
+		Webhooks:        webhookHandler,
+		Streams:         streamHandler,
+		Reconciliations: reconciliationHandler,
+		Risk:            riskHandler,
+		Limits:          limitHandler,
+		KYC:             kycHandler,
+		Health:          checker,
+	})
+	handler := server.NewHandler(routes, server.HandlerOptions{
+		CORS:        appCfg.CORS,
+		Auth:        appCfg.Auth,
+		Idempotency: middleware.NewIdempotencyStore(appCfg.Idempotency.TTL),
 	})
 
-	// Setup CORS middleware
-	corsHandler := cors.New(cors.Options{
-		AllowedOrigins: []string{"http://localhost:5173"}, // Ensure this matches your frontend URL
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Content-Type", "Authorization"},
-	}).Handler(http.DefaultServeMux)
-
-	// Start server with CORS middleware
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Start server and drain it on SIGTERM/SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	srv := server.New(appCfg.Server, handler, checker)
+	// Open streams end when shutdown begins; clients reconnect elsewhere
+	srv.OnShutdown(hub.Close)
+	if appCfg.Statements.Enabled {
+		// Stores last month's statements once the month has ended
+		job := statements.NewJob(accountRepo, statementRepo, statementGenerator, appCfg.Statements.Interval)
+		srv.AddWorker(job.Run)
 	}
-
-	log.Printf("Banking API server starting on port %s...\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler))
-}
-
-func createTablesIfNotExist(client *dynamodb.Client) error {
-	// Check and create BankAccounts table
-	_, err := client.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{
-		TableName: aws.String("BankAccounts"),
-	})
-	if err != nil {
-		_, err = client.CreateTable(context.TODO(), &dynamodb.CreateTableInput{
-			TableName: aws.String("BankAccounts"),
-			AttributeDefinitions: []types.AttributeDefinition{
-				{
-					AttributeName: aws.String("id"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-			},
-			KeySchema: []types.KeySchemaElement{
-				{
-					AttributeName: aws.String("id"),
-					KeyType:       types.KeyTypeHash,
-				},
-			},
-			BillingMode: types.BillingModePayPerRequest,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create accounts table: %w", err)
-		}
+	// Expires holds past their expiry so their funds become available again
+	srv.AddWorker(holds.NewSweeper(holdRepo, appCfg.Holds.SweepInterval).Run)
+	if appCfg.Schedules.Enabled {
+		// Executes due scheduled transfers through the same path as
+		// POST /transfers
+		scheduler := schedules.NewScheduler(scheduleRepo, transactionHandler, statementLocation,
+			appCfg.Schedules.Interval, appCfg.Schedules.RetryInterval, appCfg.Schedules.MaxAttempts)
+		srv.AddWorker(scheduler.Run)
 	}
+	if appCfg.Webhooks.Enabled {
+		// Sends recorded webhook deliveries, retrying failures with backoff
+		srv.AddWorker(dispatcher.Run)
+	}
+	if appCfg.Reconciliation.Enabled {
+		// Reports, and if configured corrects, balances that differ from
+		// the ledger
+		srv.AddWorker(reconciler.Run)
+	}
+	if appCfg.Fees.Enabled {
+		// Charges each product's monthly fee once the month has ended
+		fees := products.NewFeeJob(catalog, accountRepo, transactionOutbox, statementLocation, appCfg.Fees.Interval)
+		srv.AddWorker(fees.Run)
+	}
+	if interestProducts := interest.ProductsFromConfig(appCfg.Interest.Products); appCfg.Interest.Enabled && len(interestProducts) > 0 {
+		// Accrues interest daily on the balances statements report and pays
+		// it through the outbox at the end of each period
+		engine := interest.NewEngine(interestProducts, accountRepo, interestRepo, statementGenerator, transactionOutbox, appCfg.Interest.Interval)
+		srv.AddWorker(engine.Run)
+	}
+	if err := srv.Run(ctx); err != nil {
+		shutdownTracing(context.Background())
+		fatal("server stopped", err)
+	}
+}
 
-	// No longer creating the BankTransactions table, as it's not needed
-
-	return nil
+// fatal logs err and exits the process.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Account statuses. Frozen and closed accounts reject new transactions.
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Account is a bank account.
+type Account struct {
+	ID          string    `json:"id"`
+	Owner       string    `json:"owner"`
+	Email       string    `json:"email"`
+	Balance     float64   `json:"balance"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	AccountType string    `json:"account_type"`
+	Status      string    `json:"status"`
+	// Timezone is the IANA timezone the account's daily and monthly limits
+	// reset in; empty for the server's.
+	Timezone string `json:"timezone,omitempty"`
+	// Limits are the account's own limits, set with SetAccountLimits.
+	Limits *AccountLimits `json:"limits,omitempty"`
+	// KYCStatus is one of the KYC constants; empty is treated as
+	// KYCUnverified.
+	KYCStatus string `json:"kyc_status,omitempty"`
+}
+
+// CreateAccountInput is the body of CreateAccount.
+type CreateAccountInput struct {
+	Owner string `json:"owner"`
+	Email string `json:"email,omitempty"`
+	// AccountType defaults to "checking".
+	AccountType string `json:"account_type,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
+}
+
+// CreateAccount opens an account. The API also records the initial deposit
+// of its product with the transaction service, or queues it if that service
+// is unavailable. An account type missing from the catalog fails with
+// ErrBadRequest. With a customer's token the account is the customer's: an
+// empty Owner defaults to them and another owner fails with ErrForbidden.
+func (c *Client) CreateAccount(ctx context.Context, in CreateAccountInput, opts ...CallOption) (*Account, error) {
+	var account Account
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/accounts", body: in, opts: opts}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetAccount returns the account with the given ID. A missing account is
+// reported as an error matching ErrNotFound.
+func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
+	var account Account
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(id)}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// UpdateAccount replaces the owner, email, account type and timezone of the
+// account with account.ID. Balance, status, limits and KYC status are
+// server-controlled and must be sent as returned by GetAccount; changing
+// them fails with ErrUnprocessable. Changing the owner, account type or
+// timezone requires an admin token and fails with ErrForbidden otherwise.
+func (c *Client) UpdateAccount(ctx context.Context, account Account) (*Account, error) {
+	var updated Account
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/accounts/" + url.PathEscape(account.ID), body: account}, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// AccountPatch changes some fields of an account; nil fields are left as
+// they are. An empty Email or Timezone clears it and an empty AccountType
+// resets it to checking.
+type AccountPatch struct {
+	Owner       *string `json:"owner,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	AccountType *string `json:"account_type,omitempty"`
+	Timezone    *string `json:"timezone,omitempty"`
+}
+
+// PatchAccount applies patch to the account with the given ID. As with
+// UpdateAccount, only an admin may change the owner, account type or
+// timezone.
+func (c *Client) PatchAccount(ctx context.Context, id string, patch AccountPatch) (*Account, error) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("corebank: failed to encode patch: %w", err)
+	}
+
+	var updated Account
+	req := request{
+		method:      http.MethodPatch,
+		path:        "/accounts/" + url.PathEscape(id),
+		raw:         body,
+		contentType: "application/merge-patch+json",
+	}
+	if _, err := c.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// AccountChange records one field of an account changing value.
+type AccountChange struct {
+	Field     string    `json:"field"`
+	Old       string    `json:"old"`
+	New       string    `json:"new"`
+	Actor     string    `json:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// AccountHistory returns the changes made to an account, oldest first.
+func (c *Client) AccountHistory(ctx context.Context, id string) ([]AccountChange, error) {
+	var changes []AccountChange
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(id) + "/history"}, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Product is an account type and the rules accounts of that type follow.
+// Amounts of zero disable the rule.
+type Product struct {
+	AccountType string `json:"account_type"`
+	// OverdraftLimit is how far below MinimumBalance a withdrawal may take
+	// the balance.
+	OverdraftLimit       float64 `json:"overdraft_limit"`
+	MinimumBalance       float64 `json:"minimum_balance"`
+	InitialDeposit       float64 `json:"initial_deposit"`
+	MonthlyFee           float64 `json:"monthly_fee"`
+	MaxTransactionAmount float64 `json:"max_transaction_amount"`
+	// DailyOutflowLimit and MonthlyOutflowLimit are the most withdrawals and
+	// outgoing transfers may take per calendar day and month.
+	DailyOutflowLimit   float64 `json:"daily_outflow_limit"`
+	MonthlyOutflowLimit float64 `json:"monthly_outflow_limit"`
+}
+
+// Products returns the account types accounts can be opened with.
+func (c *Client) Products(ctx context.Context) ([]Product, error) {
+	var products []Product
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/products"}, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// DeleteAccount deletes the account with the given ID.
+func (c *Client) DeleteAccount(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/accounts/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+// ListAccountsOptions selects a page of accounts. Leaving both fields empty
+// returns every account in one response.
+type ListAccountsOptions struct {
+	// Limit is the page size, at most 100. The server default is 50.
+	Limit  int
+	Cursor string
+}
+
+// AccountPage is one page of accounts.
+type AccountPage struct {
+	Accounts []Account
+	// NextCursor fetches the following page; it is empty on the last page.
+	NextCursor string
+}
+
+// ListAccounts returns one page of accounts. See Accounts to iterate over
+// all of them.
+func (c *Client) ListAccounts(ctx context.Context, opts ListAccountsOptions) (*AccountPage, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+
+	var page AccountPage
+	header, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts", query: query}, &page.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = header.Get(nextCursorHeader)
+	return &page, nil
+}
+
+// AccountIterator walks every account page by page.
+//
+//	it := c.Accounts(ctx, 100)
+//	for it.Next() {
+//		account := it.Account()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type AccountIterator struct {
+	ctx      context.Context
+	client   *Client
+	pageSize int
+	cursor   string
+	done     bool
+	page     []Account
+	current  Account
+	err      error
+}
+
+// Accounts returns an iterator over all accounts, fetched pageSize at a time.
+func (c *Client) Accounts(ctx context.Context, pageSize int) *AccountIterator {
+	return &AccountIterator{ctx: ctx, client: c, pageSize: pageSize}
+}
+
+// Next advances to the next account, fetching another page when needed. It
+// returns false at the end or on error; check Err.
+func (it *AccountIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		page, err := it.client.ListAccounts(it.ctx, ListAccountsOptions{Limit: it.pageSize, Cursor: it.cursor})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page.Accounts
+		it.cursor = page.NextCursor
+		it.done = page.NextCursor == ""
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Account returns the account Next advanced to.
+func (it *AccountIterator) Account() Account {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// ImportOptions controls ImportAccounts.
+type ImportOptions struct {
+	// DryRun only validates the rows.
+	DryRun bool
+	// SkipInitialDeposit creates the accounts without the initial deposit
+	// a new account normally gets.
+	SkipInitialDeposit bool
+}
+
+// Outcomes of an imported row.
+const (
+	ImportRowValid   = "valid"
+	ImportRowInvalid = "invalid"
+	ImportRowCreated = "created"
+	ImportRowFailed  = "failed"
+)
+
+// ImportResult reports what an import did with each row.
+type ImportResult struct {
+	DryRun  bool        `json:"dry_run"`
+	Total   int         `json:"total"`
+	Valid   int         `json:"valid"`
+	Invalid int         `json:"invalid"`
+	Created int         `json:"created"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow is the outcome of one account. Line is its 1-based position in
+// the input.
+type ImportRow struct {
+	Line      int      `json:"line"`
+	Status    string   `json:"status"`
+	AccountID string   `json:"account_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// ImportAccounts opens many accounts in one request, at most 10000. Invalid
+// rows are reported in the result rather than failing the whole import.
+func (c *Client) ImportAccounts(ctx context.Context, accounts []CreateAccountInput, opts ImportOptions, callOpts ...CallOption) (*ImportResult, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, account := range accounts {
+		if err := enc.Encode(account); err != nil {
+			return nil, fmt.Errorf("corebank: failed to encode account: %w", err)
+		}
+	}
+
+	query := url.Values{}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if opts.SkipInitialDeposit {
+		query.Set("initial_deposit", "false")
+	}
+
+	var result ImportResult
+	req := request{
+		method:      http.MethodPost,
+		path:        "/accounts:bulk",
+		query:       query,
+		raw:         body.Bytes(),
+		contentType: "application/x-ndjson",
+		opts:        callOpts,
+	}
+	if _, err := c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
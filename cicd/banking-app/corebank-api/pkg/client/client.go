@@ -0,0 +1,295 @@
+// Package client is a Go client for the corebank API.
+//
+// A Client injects the bearer token into every request and retries calls
+// that are safe to repeat. GET, PUT and DELETE are retried on network errors
+// and on 429, 500, 502, 503 and 504. POST requests always carry an
+// Idempotency-Key, reused across attempts, and are retried on network errors
+// and on responses that show the request did not take effect. Failed calls
+// return an *Error carrying the API error code.
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	nextCursorHeader     = "X-Next-Cursor"
+	requestIDHeader      = "X-Request-ID"
+)
+
+// Client calls the corebank API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	userAgent  string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken sets the bearer token sent with every request.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient sets the HTTP client used for requests. Its Timeout bounds
+// each attempt, not the whole call including retries.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how many times a failed call is retried. Zero disables
+// retries.
+func WithRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = n }
+}
+
+// WithBackoff sets the delay before the first retry and the cap it grows to.
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the API at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("corebank: invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("corebank: base URL must be http or https, got %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "corebank-go",
+		maxRetries: 3,
+		minBackoff: 200 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// CallOption configures a single call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey sets the Idempotency-Key of a create call. Use it to
+// make a call safe to repeat across process restarts; otherwise a random key
+// is generated per call.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) { o.idempotencyKey = key }
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// raw is sent as is with contentType instead of encoding body as JSON
+	raw         []byte
+	contentType string
+	opts        []CallOption
+}
+
+// do sends req, retrying where safe, and decodes a successful response into
+// out unless it is nil. It returns the headers of the final response.
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	body, contentType := req.raw, req.contentType
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("corebank: failed to encode request: %w", err)
+		}
+		contentType = "application/json"
+	}
+
+	var co callOptions
+	for _, opt := range req.opts {
+		opt(&co)
+	}
+	if req.method == http.MethodPost && co.idempotencyKey == "" {
+		co.idempotencyKey = newIdempotencyKey()
+	}
+
+	target := c.baseURL.JoinPath(req.path)
+	target.RawQuery = req.query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req.method, target.String(), body, contentType, co.idempotencyKey)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= c.maxRetries {
+				return nil, err
+			}
+			if werr := c.wait(ctx, attempt, ""); werr != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out != nil && resp.StatusCode != http.StatusNoContent {
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					return nil, fmt.Errorf("corebank: failed to decode response: %w", err)
+				}
+			}
+			return resp.Header, nil
+		}
+
+		apiErr := readError(resp)
+		if attempt >= c.maxRetries || !retryable(req.method, resp) {
+			return nil, apiErr
+		}
+		if err := c.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+			return nil, apiErr
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, target string, body []byte, contentType, idempotencyKey string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("corebank: failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("corebank: %s %s: %w", method, httpReq.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// retryable reports whether a failed response is safe and worth retrying.
+// A POST is only retried when the server cannot have applied it: a proxy
+// gave up waiting, the request was throttled, or an earlier attempt with the
+// same key is still running. Its 500 and 503 responses may follow partial
+// work, so they are returned to the caller.
+func retryable(method string, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	case http.StatusInternalServerError, http.StatusServiceUnavailable:
+		return method != http.MethodPost
+	case http.StatusConflict:
+		return method == http.MethodPost && resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// wait sleeps before the next attempt, honouring Retry-After in seconds when
+// the server sent one.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := c.minBackoff << attempt
+	if delay > c.maxBackoff || delay <= 0 {
+		delay = c.maxBackoff
+	}
+	// Full jitter keeps retrying clients from moving in lockstep
+	delay = time.Duration(rand.Int64N(int64(delay) + 1))
+
+	if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+		delay = min(time.Duration(secs)*time.Second, c.maxBackoff)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// readError builds an *Error from a failed response and closes its body.
+func readError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+
+	var body struct {
+		Error     string `json:"error"`
+		Code      string `json:"code"`
+		RequestID string `json:"request_id"`
+		// Errors passed through from the transaction service
+		Detail any `json:"detail"`
+	}
+	if err := json.Unmarshal(raw, &body); err == nil {
+		apiErr.Message = body.Error
+		apiErr.Code = ErrorCode(body.Code)
+		if body.RequestID != "" {
+			apiErr.RequestID = body.RequestID
+		}
+		if apiErr.Message == "" && body.Detail != nil {
+			apiErr.Message = fmt.Sprint(body.Detail)
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+
+	if apiErr.Code == "" {
+		apiErr.Code = codeForStatus(resp.StatusCode)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	cryptorand.Read(b) // never fails on supported platforms
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,2855 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/corebank-api/internal/config"
+	"github.com/corebank-api/internal/events"
+	"github.com/corebank-api/internal/handlers"
+	"github.com/corebank-api/internal/health"
+	"github.com/corebank-api/internal/holds"
+	"github.com/corebank-api/internal/limits"
+	"github.com/corebank-api/internal/middleware"
+	"github.com/corebank-api/internal/models"
+	"github.com/corebank-api/internal/outbox"
+	"github.com/corebank-api/internal/posting"
+	"github.com/corebank-api/internal/products"
+	"github.com/corebank-api/internal/reconcile"
+	"github.com/corebank-api/internal/repository"
+	"github.com/corebank-api/internal/risk"
+	"github.com/corebank-api/internal/schedules"
+	"github.com/corebank-api/internal/server"
+	"github.com/corebank-api/internal/statements"
+	"github.com/corebank-api/internal/stream"
+	"github.com/corebank-api/internal/upstream"
+	"github.com/corebank-api/internal/webhooks"
+	"github.com/corebank-api/pkg/client"
+)
+
+const (
+	testToken = "test-token"
+	// userToken belongs to a customer, "dana", who may only act on their
+	// own accounts
+	userToken = "user-token"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// memStore is an in-memory handlers.AccountStore that pages in ID order.
+type memStore struct {
+	mu       sync.Mutex
+	accounts map[string]models.Account
+	history  map[string][]models.AccountChange
+	postings map[string]models.BalancePosting
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		accounts: make(map[string]models.Account),
+		history:  make(map[string][]models.AccountChange),
+		postings: make(map[string]models.BalancePosting),
+	}
+}
+
+func (s *memStore) Create(_ context.Context, a *models.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[a.ID]; ok {
+		return repository.ErrConflict
+	}
+	if a.AccountType == "" {
+		a.AccountType = "checking"
+	}
+	s.accounts[a.ID] = *a
+	return nil
+}
+
+func (s *memStore) GetByID(_ context.Context, id string) (*models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *memStore) Update(_ context.Context, a *models.Account, changes []models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a.UpdatedAt = time.Now()
+	s.accounts[a.ID] = *a
+	s.history[a.ID] = append(s.history[a.ID], changes...)
+	return nil
+}
+
+func (s *memStore) SetLimits(_ context.Context, id string, limits *models.AccountLimits, change models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	a.Limits = limits
+	a.UpdatedAt = change.ChangedAt
+	s.accounts[id] = a
+	s.history[id] = append(s.history[id], change)
+	return nil
+}
+
+func (s *memStore) SetKYCStatus(_ context.Context, id, old, status string, change models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok || a.KYCLevel() != old {
+		return repository.ErrConflict
+	}
+	a.KYCStatus = status
+	a.UpdatedAt = change.ChangedAt
+	s.accounts[id] = a
+	s.history[id] = append(s.history[id], change)
+	return nil
+}
+
+func (s *memStore) History(_ context.Context, id string) ([]models.AccountChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history[id], nil
+}
+
+func (s *memStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.accounts, id)
+	return nil
+}
+
+func (s *memStore) PostAdjustment(_ context.Context, posting *models.BalancePosting, covered []models.BalancePosting, old float64, change models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[posting.AccountID]
+	if !ok || a.Balance != old {
+		return repository.ErrConflict
+	}
+	all := append([]models.BalancePosting{*posting}, covered...)
+	for _, p := range all {
+		if _, ok := s.postings[p.ID]; ok {
+			return repository.ErrAlreadyPosted
+		}
+	}
+	a.Balance = posting.Balance
+	a.UpdatedAt = change.ChangedAt
+	s.accounts[a.ID] = a
+	s.history[a.ID] = append(s.history[a.ID], change)
+	for _, p := range all {
+		s.postings[p.ID] = p
+	}
+	return nil
+}
+
+func (s *memStore) PostTransaction(_ context.Context, posting *models.BalancePosting, old float64, change models.AccountChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[posting.AccountID]
+	if !ok || a.Balance != old {
+		return repository.ErrConflict
+	}
+	if _, ok := s.postings[posting.ID]; ok {
+		return repository.ErrAlreadyPosted
+	}
+	a.Balance = posting.Balance
+	a.UpdatedAt = change.ChangedAt
+	s.accounts[a.ID] = a
+	s.history[a.ID] = append(s.history[a.ID], change)
+	s.postings[posting.ID] = *posting
+	return nil
+}
+
+func (s *memStore) GetPosting(_ context.Context, id string) (*models.BalancePosting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posting, ok := s.postings[id]
+	if !ok {
+		return nil, nil
+	}
+	return &posting, nil
+}
+
+func (s *memStore) ListAll(_ context.Context) ([]models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted(), nil
+}
+
+func (s *memStore) ListPage(_ context.Context, limit int, cursor string) ([]models.Account, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var page []models.Account
+	for _, a := range s.sorted() {
+		if a.ID > cursor {
+			page = append(page, a)
+		}
+	}
+	if len(page) <= limit {
+		return page, "", nil
+	}
+	page = page[:limit]
+	return page, page[limit-1].ID, nil
+}
+
+func (s *memStore) CreateBatch(_ context.Context, accounts []models.Account) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range accounts {
+		s.accounts[a.ID] = a
+	}
+	return nil, nil
+}
+
+func (s *memStore) sorted() []models.Account {
+	all := make([]models.Account, 0, len(s.accounts))
+	for _, a := range s.accounts {
+		all = append(all, a)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+func (s *memStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.accounts)
+}
+
+// memOutbox is an in-memory outbox.Store.
+type memOutbox struct {
+	mu      sync.Mutex
+	entries map[string]models.OutboxEntry
+}
+
+func (m *memOutbox) Put(_ context.Context, e *models.OutboxEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[e.ID] = *e
+	return nil
+}
+
+func (m *memOutbox) Create(_ context.Context, e *models.OutboxEntry) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[e.ID]; ok {
+		return false, nil
+	}
+	m.entries[e.ID] = *e
+	return true, nil
+}
+
+func (m *memOutbox) Get(_ context.Context, id string) (*models.OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+func (m *memOutbox) List(_ context.Context, status string) ([]models.OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []models.OutboxEntry
+	for _, e := range m.entries {
+		if status == "" || e.Status == status {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// fakeTransactionService mimics the transaction service's HTTP API.
+type memStatements struct {
+	mu         sync.Mutex
+	statements map[string]models.Statement
+}
+
+func (m *memStatements) Create(_ context.Context, st *models.Statement) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := st.AccountID + "#" + st.Period
+	if _, ok := m.statements[key]; ok {
+		return false, nil
+	}
+	m.statements[key] = *st
+	return true, nil
+}
+
+func (m *memStatements) Get(_ context.Context, accountID, period string) (*models.Statement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.statements[accountID+"#"+period]
+	if !ok {
+		return nil, nil
+	}
+	return &st, nil
+}
+
+// memHolds is an in-memory holds.Store.
+type memHolds struct {
+	mu    sync.Mutex
+	holds map[string]models.Hold
+}
+
+func (m *memHolds) Create(_ context.Context, hold *models.Hold) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holds[hold.ID] = *hold
+	return nil
+}
+
+func (m *memHolds) Get(_ context.Context, id string) (*models.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hold, ok := m.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	return &hold, nil
+}
+
+func (m *memHolds) Update(_ context.Context, hold *models.Hold, prevUpdatedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored := m.holds[hold.ID]; !stored.UpdatedAt.Equal(prevUpdatedAt) {
+		return repository.ErrConflict
+	}
+	m.holds[hold.ID] = *hold
+	return nil
+}
+
+func (m *memHolds) ListByAccount(_ context.Context, accountID, status string) ([]models.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.Hold
+	for _, hold := range m.holds {
+		if hold.AccountID == accountID && (status == "" || hold.Status == status) {
+			list = append(list, hold)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *memHolds) ListExpired(_ context.Context, now time.Time) ([]models.Hold, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.Hold
+	for _, hold := range m.holds {
+		if hold.Status == models.HoldActive && !hold.ExpiresAt.After(now) {
+			list = append(list, hold)
+		}
+	}
+	return list, nil
+}
+
+// memSchedules is an in-memory schedules.Store.
+type memSchedules struct {
+	mu         sync.Mutex
+	schedules  map[string]models.Schedule
+	executions map[string]models.ScheduleExecution
+}
+
+func (m *memSchedules) Create(_ context.Context, schedule *models.Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+func (m *memSchedules) Get(_ context.Context, id string) (*models.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedule, ok := m.schedules[id]
+	if !ok {
+		return nil, nil
+	}
+	return &schedule, nil
+}
+
+func (m *memSchedules) Update(_ context.Context, schedule *models.Schedule, prevUpdatedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored := m.schedules[schedule.ID]; !stored.UpdatedAt.Equal(prevUpdatedAt) {
+		return repository.ErrConflict
+	}
+	m.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+func (m *memSchedules) List(_ context.Context, accountID string) ([]models.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.Schedule
+	for _, schedule := range m.schedules {
+		if accountID == "" || schedule.FromAccountID == accountID {
+			list = append(list, schedule)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *memSchedules) ListDue(_ context.Context, now time.Time) ([]models.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []models.Schedule
+	for _, schedule := range m.schedules {
+		if schedule.Status == models.ScheduleActive && !schedule.NextAttemptAt.After(now) {
+			due = append(due, schedule)
+		}
+	}
+	return due, nil
+}
+
+func (m *memSchedules) CreateExecution(_ context.Context, execution *models.ScheduleExecution) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution.ID = repository.ScheduleExecutionID(execution.ScheduleID, execution.Occurrence, execution.Attempt)
+	if _, ok := m.executions[execution.ID]; ok {
+		return false, nil
+	}
+	m.executions[execution.ID] = *execution
+	return true, nil
+}
+
+func (m *memSchedules) PutExecution(_ context.Context, execution *models.ScheduleExecution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *memSchedules) GetExecution(_ context.Context, id string) (*models.ScheduleExecution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, ok := m.executions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &execution, nil
+}
+
+func (m *memSchedules) Executions(_ context.Context, scheduleID string) ([]models.ScheduleExecution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.ScheduleExecution
+	for _, execution := range m.executions {
+		if execution.ScheduleID == scheduleID {
+			list = append(list, execution)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.Before(list[j].StartedAt) })
+	return list, nil
+}
+
+type memReconciliations struct {
+	mu      sync.Mutex
+	reports []models.Reconciliation
+}
+
+func (m *memReconciliations) Create(_ context.Context, report *models.Reconciliation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reports = append(m.reports, *report)
+	return nil
+}
+
+func (m *memReconciliations) Get(_ context.Context, id string) (*models.Reconciliation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, report := range m.reports {
+		if report.ID == id {
+			return &report, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memReconciliations) List(_ context.Context) ([]models.Reconciliation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reports := make([]models.Reconciliation, 0, len(m.reports))
+	for i := len(m.reports) - 1; i >= 0; i-- {
+		reports = append(reports, m.reports[i])
+	}
+	return reports, nil
+}
+
+type memRiskDecisions struct {
+	mu        sync.Mutex
+	decisions []models.RiskDecision
+}
+
+func (m *memRiskDecisions) Create(_ context.Context, decision *models.RiskDecision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions = append(m.decisions, *decision)
+	return nil
+}
+
+func (m *memRiskDecisions) Get(_ context.Context, id string) (*models.RiskDecision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, decision := range m.decisions {
+		if decision.ID == id {
+			return &decision, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memRiskDecisions) List(_ context.Context, accountID, decision string) ([]models.RiskDecision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.RiskDecision
+	for i := len(m.decisions) - 1; i >= 0; i-- {
+		d := m.decisions[i]
+		if (accountID == "" || d.AccountID == accountID) && (decision == "" || d.Decision == decision) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+// memLimits is an in-memory limits.Store.
+type memLimits struct {
+	mu       sync.Mutex
+	used     map[string]float64
+	released map[string]bool
+}
+
+func (m *memLimits) Reserve(_ context.Context, amount float64, counters []repository.CounterLimit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The same checks as LimitRepository.Reserve and its condition
+	// expression
+	for _, c := range counters {
+		if c.Max <= 0 {
+			continue
+		}
+		room := math.Round((c.Max-amount)*100) / 100
+		if used, ok := m.used[c.Counter.ID]; room < 0 || ok && used > room {
+			return repository.ErrLimitReached
+		}
+	}
+	for _, c := range counters {
+		m.used[c.Counter.ID] += amount
+	}
+	return nil
+}
+
+func (m *memLimits) Release(_ context.Context, releaseID string, amount float64, counterIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if releaseID != "" {
+		if m.released[releaseID] {
+			return nil
+		}
+		m.released[releaseID] = true
+	}
+	for _, id := range counterIDs {
+		if _, ok := m.used[id]; ok {
+			m.used[id] -= amount
+		}
+	}
+	return nil
+}
+
+func (m *memLimits) Used(_ context.Context, counterIDs []string) (map[string]float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	used := make(map[string]float64, len(counterIDs))
+	for _, id := range counterIDs {
+		used[id] = m.used[id]
+	}
+	return used, nil
+}
+
+// memKYC is an in-memory handlers.KYCStore.
+type memKYC struct {
+	mu      sync.Mutex
+	records map[string]models.KYCRecord
+}
+
+func (m *memKYC) Put(_ context.Context, record *models.KYCRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[record.AccountID] = *record
+	return nil
+}
+
+func (m *memKYC) Get(_ context.Context, accountID string) (*models.KYCRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.records[accountID]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (m *memKYC) List(_ context.Context, status string) ([]models.KYCRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []models.KYCRecord
+	for _, record := range m.records {
+		if status == "" || record.Status == status {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SubmittedAt.Before(*records[j].SubmittedAt)
+	})
+	return records, nil
+}
+
+// eventLog is the file an events.NDJSONSink writes to.
+type eventLog struct {
+	mu    sync.Mutex
+	lines []byte
+}
+
+func (l *eventLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, p...)
+	return len(p), nil
+}
+
+// events decodes the events written so far.
+func (l *eventLog) events(t *testing.T) []events.Event {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var list []events.Event
+	dec := json.NewDecoder(strings.NewReader(string(l.lines)))
+	for dec.More() {
+		var event events.Event
+		if err := dec.Decode(&event); err != nil {
+			t.Fatalf("event log: %v", err)
+		}
+		list = append(list, event)
+	}
+	return list
+}
+
+// memWebhooks is an in-memory webhooks.Store.
+type memWebhooks struct {
+	mu            sync.Mutex
+	subscriptions map[string]models.WebhookSubscription
+	deliveries    map[string]models.WebhookDelivery
+}
+
+func (m *memWebhooks) CreateSubscription(_ context.Context, subscription *models.WebhookSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+func (m *memWebhooks) GetSubscription(_ context.Context, id string) (*models.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription, ok := m.subscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &subscription, nil
+}
+
+func (m *memWebhooks) ListSubscriptions(_ context.Context) ([]models.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		list = append(list, subscription)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *memWebhooks) DeleteSubscription(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subscriptions, id)
+	return nil
+}
+
+func (m *memWebhooks) CreateDelivery(_ context.Context, delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *memWebhooks) GetDelivery(_ context.Context, id string) (*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+func (m *memWebhooks) UpdateDelivery(_ context.Context, delivery *models.WebhookDelivery, prevUpdatedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored := m.deliveries[delivery.ID]; !stored.UpdatedAt.Equal(prevUpdatedAt) {
+		return repository.ErrConflict
+	}
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *memWebhooks) Deliveries(_ context.Context, subscriptionID string) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []models.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			list = append(list, delivery)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *memWebhooks) ListDue(_ context.Context, now time.Time) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []models.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	return due, nil
+}
+
+type fakeTransactionService struct {
+	mu    sync.Mutex
+	txns  []models.Transaction
+	posts int
+	down  bool
+}
+
+func (f *fakeTransactionService) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeTransactionService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case f.down:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case r.URL.Path == "/health":
+		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	case r.URL.Path == "/transactions" && r.Method == http.MethodPost:
+		var txn models.Transaction
+		json.NewDecoder(r.Body).Decode(&txn)
+		txn.ID = uuid.NewString()
+		txn.Status = "pending"
+		txn.CreatedAt = time.Now()
+		f.txns = append(f.txns, txn)
+		f.posts++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(txn)
+	case r.URL.Path == "/transactions" && r.Method == http.MethodGet:
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 10
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		accountID := r.URL.Query().Get("account_id")
+
+		matched := []models.Transaction{}
+		for i := len(f.txns) - 1; i >= 0; i-- {
+			if accountID == "" || f.txns[i].AccountID == accountID {
+				matched = append(matched, f.txns[i])
+			}
+		}
+		matched = matched[min(offset, len(matched)):]
+		json.NewEncoder(w).Encode(matched[:min(limit, len(matched))])
+	case r.Method == http.MethodGet:
+		id := r.URL.Path[len("/transactions/"):]
+		for i := range f.txns {
+			if f.txns[i].ID == id {
+				json.NewEncoder(w).Encode(f.txns[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "Transaction not found"})
+	case r.Method == http.MethodPut:
+		id := r.URL.Path[len("/transactions/"):]
+		for i := range f.txns {
+			if f.txns[i].ID == id {
+				now := time.Now()
+				f.txns[i].Status = r.URL.Query().Get("status")
+				f.txns[i].ProcessedAt = &now
+				json.NewEncoder(w).Encode(f.txns[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"detail": "Transaction not found"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type testAPI struct {
+	URL        string
+	store      *memStore
+	outbox     *outbox.Outbox
+	txns       *fakeTransactionService
+	statements *memStatements
+	generator  *statements.Generator
+	catalog    *products.Catalog
+	holds      *memHolds
+	schedules  *memSchedules
+	// transfers executes transfers for the scheduler
+	transfers  *handlers.TransactionHandler
+	webhooks   *memWebhooks
+	dispatcher *webhooks.Dispatcher
+	events     *eventLog
+}
+
+// newTestAPI serves the real routes and middleware over an in-memory store
+// and a fake transaction service.
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	txns := &fakeTransactionService{}
+	txnServer := httptest.NewServer(txns)
+	t.Cleanup(txnServer.Close)
+
+	store := newMemStore()
+	httpClient := txnServer.Client()
+	transactions := upstream.NewTransactionService(txnServer.URL, httpClient)
+	ob := outbox.New(&memOutbox{entries: make(map[string]models.OutboxEntry)}, transactions)
+	stmts := &memStatements{statements: make(map[string]models.Statement)}
+	generator := statements.NewGenerator(transactions, time.UTC)
+	catalog := products.NewCatalog([]config.ProductConfig{
+		{AccountType: config.DefaultAccountType, InitialDeposit: 1000},
+		{AccountType: "savings", InitialDeposit: 1000},
+		{AccountType: "basic", InitialDeposit: 100, MinimumBalance: 20, OverdraftLimit: 50, MaxTransactionAmount: 500, MonthlyFee: 5,
+			DailyOutflowLimit: 400, MonthlyOutflowLimit: 1000},
+	})
+	holdStore := &memHolds{holds: make(map[string]models.Hold)}
+	balances := holds.NewBalances(generator, holdStore)
+	scheduleStore := &memSchedules{schedules: make(map[string]models.Schedule), executions: make(map[string]models.ScheduleExecution)}
+	webhookStore := &memWebhooks{subscriptions: make(map[string]models.WebhookSubscription), deliveries: make(map[string]models.WebhookDelivery)}
+	dispatcher := webhooks.NewDispatcher(webhookStore, time.Second, time.Minute, time.Minute, time.Hour, 3)
+	eventLog := &eventLog{}
+	bus := events.NewBus()
+	bus.Subscribe("initial_deposit", ob.SubmitInitialDeposit, events.AccountCreated)
+	bus.Subscribe("webhooks", dispatcher.HandleEvent)
+	bus.Subscribe("ndjson", events.NDJSONSink(eventLog))
+	hub := stream.NewHub(100)
+	bus.Subscribe("stream", hub.HandleEvent)
+	ob.OnDelivered(func(ctx context.Context, entry *models.OutboxEntry) {
+		bus.Publish(ctx, events.NewTransactionSubmitted(&entry.Transaction))
+	})
+	poster := posting.NewPoster(store, balances, catalog)
+	ob.SetPoster(poster)
+	// Large amounts are reviewed and very large ones denied; new accounts,
+	// which every test account is, may not move more than 8000 at once
+	riskRules, err := risk.NewRules([]risk.RuleConfig{
+		{Name: "large-amount", Type: "amount", ReviewAbove: 2000, DenyAbove: 10000},
+		{Name: "new-account", Type: "new_account", Decision: "deny", MaxAge: 24 * time.Hour, MaxAmount: 8000},
+	}, transactions, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	riskDecisions := &memRiskDecisions{}
+	// Customers may take 3000 a day until verified, 6000 while pending
+	kycTiers := config.KYCConfig{
+		Unverified: config.KYCTierConfig{DailyOutflowLimit: 3000},
+		Pending:    config.KYCTierConfig{DailyOutflowLimit: 6000},
+	}
+	limiter := limits.NewLimiter(&memLimits{used: make(map[string]float64), released: make(map[string]bool)}, catalog, kycTiers, time.UTC)
+	transfers := handlers.NewTransactionHandler(store, txnServer.URL, httpClient, balances, poster,
+		risk.NewEngine(riskRules, riskDecisions), limiter, bus)
+	reconciliations := &memReconciliations{}
+	reconciler := reconcile.NewReconciler(store, generator, poster, reconciliations, bus, time.Hour, false)
+	routes := server.Routes(server.Handlers{
+		Accounts:        handlers.NewAccountHandler(store, txnServer.URL, httpClient, catalog, bus),
+		Transactions:    transfers,
+		Statements:      handlers.NewStatementHandler(store, stmts, generator),
+		Holds:           handlers.NewHoldHandler(store, holdStore, balances, ob, transfers, 24*time.Hour, 7*24*time.Hour),
+		Schedules:       handlers.NewScheduleHandler(store, scheduleStore, time.UTC),
+		Webhooks:        handlers.NewWebhookHandler(webhookStore, dispatcher),
+		Streams:         handlers.NewStreamHandler(store, hub, time.Minute),
+		Reconciliations: handlers.NewReconciliationHandler(reconciler, reconciliations),
+		Risk:            handlers.NewRiskHandler(riskDecisions),
+		Limits:          handlers.NewLimitHandler(store, limiter, bus),
+		KYC:             handlers.NewKYCHandler(store, &memKYC{records: make(map[string]models.KYCRecord)}, bus),
+		Health:          health.NewChecker(time.Second, time.Second),
+	})
+	api := httptest.NewServer(server.NewHandler(routes, server.HandlerOptions{
+		Auth: config.AuthConfig{
+			Enabled: true,
+			Tokens: []config.TokenConfig{
+				{Token: testToken, Subject: "tester", Role: config.RoleAdmin},
+				{Token: userToken, Subject: "dana", Role: config.RoleUser},
+			},
+		},
+		Idempotency: middleware.NewIdempotencyStore(time.Hour),
+	}))
+	t.Cleanup(api.Close)
+
+	return &testAPI{URL: api.URL, store: store, outbox: ob, txns: txns, statements: stmts, generator: generator, catalog: catalog, holds: holdStore,
+		schedules: scheduleStore, transfers: transfers, webhooks: webhookStore, dispatcher: dispatcher, events: eventLog}
+}
+
+func newClient(t *testing.T, baseURL string, opts ...client.Option) *client.Client {
+	t.Helper()
+	opts = append([]client.Option{
+		client.WithToken(testToken),
+		client.WithBackoff(time.Millisecond, 5*time.Millisecond),
+	}, opts...)
+	c, err := client.New(baseURL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// settle completes the account's pending deposits, such as its initial
+// deposit, so they count towards what it may spend.
+func settle(t *testing.T, c *client.Client, accountID string) {
+	t.Helper()
+	ctx := context.Background()
+	txns, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: accountID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range txns {
+		if txn.Type == client.TypeDeposit && txn.Status == client.StatusPending {
+			if _, err := c.UpdateTransactionStatus(ctx, txn.ID, client.StatusCompleted); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestAccountCRUD(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	created, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+	if created.ID == "" || created.Owner != "alice" || created.AccountType != "checking" {
+		t.Fatalf("unexpected account %+v", created)
+	}
+
+	got, err := c.GetAccount(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetAccount: %v", err)
+	}
+	if got.ID != created.ID || got.Email != "alice@example.com" {
+		t.Fatalf("GetAccount returned %+v, want %+v", got, created)
+	}
+
+	got.AccountType = "savings"
+	updated, err := c.UpdateAccount(ctx, *got)
+	if err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	if updated.AccountType != "savings" {
+		t.Fatalf("account type = %q, want savings", updated.AccountType)
+	}
+
+	page, err := c.ListAccounts(ctx, client.ListAccountsOptions{})
+	if err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	if len(page.Accounts) != 1 || page.NextCursor != "" {
+		t.Fatalf("ListAccounts returned %+v", page)
+	}
+
+	if err := c.DeleteAccount(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	_, err = c.GetAccount(ctx, created.ID)
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("GetAccount after delete: got %v, want ErrNotFound", err)
+	}
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.RequestID == "" {
+		t.Fatalf("error %#v should carry status and request ID", err)
+	}
+}
+
+func TestAccountOwnership(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	dana := newClient(t, api.URL, client.WithToken(userToken))
+	ctx := context.Background()
+
+	own, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, own.ID)
+	settle(t, c, other.ID)
+
+	// Customers see and change only their own accounts
+	if _, err := dana.GetAccount(ctx, own.ID); err != nil {
+		t.Fatalf("owner reading their account: %v", err)
+	}
+	if _, err := dana.GetAccount(ctx, other.ID); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("reading another customer's account: got %v, want ErrForbidden", err)
+	}
+	owner := "dana"
+	if _, err := dana.PatchAccount(ctx, other.ID, client.AccountPatch{Owner: &owner}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("patching another customer's account: got %v, want ErrForbidden", err)
+	}
+	if err := dana.DeleteAccount(ctx, other.ID); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("deleting another customer's account: got %v, want ErrForbidden", err)
+	}
+	for _, limit := range []int{0, 50} {
+		page, err := dana.ListAccounts(ctx, client.ListAccountsOptions{Limit: limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Accounts) != 1 || page.Accounts[0].ID != own.ID {
+			t.Fatalf("limit %d: customer listed %+v, want only their own account", limit, page.Accounts)
+		}
+	}
+	if page, err := c.ListAccounts(ctx, client.ListAccountsOptions{}); err != nil || len(page.Accounts) != 2 {
+		t.Fatalf("admin listing: %+v %v", page, err)
+	}
+
+	// Money may be sent to another customer's account but not taken from it
+	_, err = dana.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: other.ID, Type: client.TypeWithdrawal, Amount: 10})
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("withdrawing from another customer's account: got %v, want ErrForbidden", err)
+	}
+	_, err = dana.CreateTransfer(ctx, client.TransferInput{FromAccountID: other.ID, ToAccountID: own.ID, Amount: 10})
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("transferring from another customer's account: got %v, want ErrForbidden", err)
+	}
+	if _, err := dana.CreateTransfer(ctx, client.TransferInput{FromAccountID: own.ID, ToAccountID: other.ID, Amount: 10}); err != nil {
+		t.Fatalf("transferring to another customer's account: %v", err)
+	}
+
+	// Another customer's transactions cannot be moved, so neither their
+	// balance nor their outflow changes
+	withdrawal, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: other.ID, Type: client.TypeWithdrawal, Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{client.StatusCompleted, client.StatusFailed} {
+		if _, err := dana.UpdateTransactionStatus(ctx, withdrawal.ID, status); !errors.Is(err, client.ErrForbidden) {
+			t.Fatalf("moving another customer's transaction to %s: got %v, want ErrForbidden", status, err)
+		}
+	}
+	ownWithdrawal, err := dana.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: own.ID, Type: client.TypeWithdrawal, Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dana.UpdateTransactionStatus(ctx, ownWithdrawal.ID, client.StatusFailed); err != nil {
+		t.Fatalf("failing their own transaction: %v", err)
+	}
+
+	// and may list only their own transactions
+	if _, err := dana.ListTransactions(ctx, client.ListTransactionsOptions{}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("listing every transaction: got %v, want ErrForbidden", err)
+	}
+	if _, err := dana.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: other.ID}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("listing another customer's transactions: got %v, want ErrForbidden", err)
+	}
+	txns, err := dana.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: own.ID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range txns {
+		if txn.AccountID != own.ID {
+			t.Fatalf("listed transaction %s of account %s", txn.ID, txn.AccountID)
+		}
+	}
+}
+
+func TestAccountIterator(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	want := make(map[string]bool)
+	for i := 0; i < 7; i++ {
+		a, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: fmt.Sprintf("owner-%d", i)})
+		if err != nil {
+			t.Fatalf("CreateAccount: %v", err)
+		}
+		want[a.ID] = true
+	}
+
+	it := c.Accounts(ctx, 3)
+	seen := make(map[string]bool)
+	for it.Next() {
+		id := it.Account().ID
+		if seen[id] {
+			t.Fatalf("account %s returned twice", id)
+		}
+		seen[id] = true
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("iterated %d accounts, want %d", len(seen), len(want))
+	}
+
+	_, err := c.ListAccounts(ctx, client.ListAccountsOptions{Limit: 500})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("oversized limit: got %v, want ErrBadRequest", err)
+	}
+}
+
+func TestTransactionsAndTransfers(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	from, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "from"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "to"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: from.ID, Amount: 50, Type: client.TypeDeposit})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if txn.Status != client.StatusPending {
+		t.Fatalf("status = %q, want pending", txn.Status)
+	}
+	settle(t, c, from.ID)
+
+	transfer, err := c.CreateTransfer(ctx, client.TransferInput{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 25})
+	if err != nil {
+		t.Fatalf("CreateTransfer: %v", err)
+	}
+	if transfer.Withdrawal == nil || transfer.Withdrawal.AccountID != from.ID || transfer.Withdrawal.Type != client.TypeWithdrawal {
+		t.Fatalf("unexpected withdrawal %+v", transfer.Withdrawal)
+	}
+	if transfer.Deposit == nil || transfer.Deposit.AccountID != to.ID || transfer.Deposit.Amount != 25 {
+		t.Fatalf("unexpected deposit %+v", transfer.Deposit)
+	}
+
+	_, err = c.CreateTransfer(ctx, client.TransferInput{FromAccountID: from.ID, ToAccountID: "missing", Amount: 25})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("transfer to missing account: got %v, want ErrBadRequest", err)
+	}
+
+	// Initial deposit, the deposit above and the transfer withdrawal
+	it := c.Transactions(ctx, client.ListTransactionsOptions{AccountID: from.ID, Limit: 1})
+	var types []string
+	for it.Next() {
+		types = append(types, it.Transaction().Type)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if want := []string{"withdrawal", "deposit", "deposit"}; fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Fatalf("transactions = %v, want %v", types, want)
+	}
+
+	completed, err := c.UpdateTransactionStatus(ctx, txn.ID, client.StatusCompleted)
+	if err != nil {
+		t.Fatalf("UpdateTransactionStatus: %v", err)
+	}
+	if completed.Status != client.StatusCompleted || completed.ProcessedAt == nil {
+		t.Fatalf("unexpected transaction %+v", completed)
+	}
+
+	_, err = c.UpdateTransactionStatus(ctx, uuid.NewString(), client.StatusCompleted)
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("unknown transaction: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL, client.WithToken("wrong"))
+
+	_, err := c.GetAccount(context.Background(), "any")
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("got %v, want ErrUnauthorized", err)
+	}
+}
+
+// lossyTransport delivers the first POST but reports it as failed, as if
+// the connection dropped before the response arrived.
+type lossyTransport struct {
+	mu      sync.Mutex
+	dropped bool
+	keys    []string
+}
+
+func (l *lossyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys = append(l.keys, req.Header.Get("Idempotency-Key"))
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil && req.Method == http.MethodPost && !l.dropped {
+		l.dropped = true
+		resp.Body.Close()
+		return nil, errors.New("connection reset")
+	}
+	return resp, err
+}
+
+func TestRetryReplaysIdempotentCreate(t *testing.T) {
+	api := newTestAPI(t)
+	transport := &lossyTransport{}
+	c := newClient(t, api.URL, client.WithHTTPClient(&http.Client{Transport: transport}))
+
+	account, err := c.CreateAccount(context.Background(), client.CreateAccountInput{Owner: "retry"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+
+	if n := api.store.count(); n != 1 {
+		t.Fatalf("store has %d accounts, want 1", n)
+	}
+	if stored, _ := api.store.GetByID(context.Background(), account.ID); stored == nil {
+		t.Fatalf("returned account %s was not the one stored", account.ID)
+	}
+	if api.txns.posts != 1 {
+		t.Fatalf("initial deposit posted %d times, want 1", api.txns.posts)
+	}
+	if len(transport.keys) != 2 || transport.keys[0] == "" || transport.keys[0] != transport.keys[1] {
+		t.Fatalf("attempts should share one idempotency key, got %q", transport.keys)
+	}
+}
+
+func TestIdempotencyKeyReuse(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+	key := client.WithIdempotencyKey("create-bob")
+
+	first, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "bob"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "bob"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("repeated create returned %s, want replay of %s", again.ID, first.ID)
+	}
+
+	_, err = c.CreateAccount(ctx, client.CreateAccountInput{Owner: "mallory"}, key)
+	if !errors.Is(err, client.ErrIdempotencyMismatch) {
+		t.Fatalf("key reuse with another body: got %v, want ErrIdempotencyMismatch", err)
+	}
+}
+
+// flakyTransport answers the first n requests with 503.
+type flakyTransport struct {
+	mu    sync.Mutex
+	fails int
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	fail := f.fails > 0
+	f.fails--
+	f.mu.Unlock()
+
+	if fail {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusServiceUnavailable)
+		return rec.Result(), nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRetriesIdempotentCalls(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+
+	c := newClient(t, api.URL, client.WithHTTPClient(&http.Client{Transport: &flakyTransport{fails: 2}}))
+	if _, err := c.ListAccounts(ctx, client.ListAccountsOptions{}); err != nil {
+		t.Fatalf("GET should succeed after retries: %v", err)
+	}
+
+	// A 503 on POST may follow partial work, so it is not retried
+	c = newClient(t, api.URL, client.WithHTTPClient(&http.Client{Transport: &flakyTransport{fails: 1}}))
+	_, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "carol"})
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Fatalf("POST: got %v, want ErrUnavailable", err)
+	}
+}
+
+func TestInactiveAccountRejectsTransactions(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dave"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Status != client.AccountStatusActive {
+		t.Fatalf("status = %q, want active", account.Status)
+	}
+
+	stored, _ := api.store.GetByID(ctx, account.ID)
+	stored.Status = models.AccountStatusFrozen
+	api.store.Update(ctx, stored, nil)
+
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 5, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrAccountInactive) {
+		t.Fatalf("transaction on frozen account: got %v, want ErrAccountInactive", err)
+	}
+}
+
+func TestInitialDepositQueuedWhileServiceDown(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	api.txns.setDown(true)
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatalf("CreateAccount should succeed with the deposit queued: %v", err)
+	}
+
+	pending, _ := api.outbox.List(ctx, models.OutboxPending)
+	if len(pending) != 1 || pending[0].Transaction.AccountID != account.ID || pending[0].LastError == "" {
+		t.Fatalf("expected one pending deposit with an error, got %+v", pending)
+	}
+
+	api.txns.setDown(false)
+	redriven, err := api.outbox.Redrive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redriven) != 1 || redriven[0].Status != models.OutboxDelivered || redriven[0].Transaction.ID == "" {
+		t.Fatalf("redrive did not deliver the deposit: %+v", redriven)
+	}
+	if pending, _ := api.outbox.List(ctx, models.OutboxPending); len(pending) != 0 {
+		t.Fatalf("%d entries still pending after redrive", len(pending))
+	}
+}
+
+func TestStatements(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "frank"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil || len(page) != 1 {
+		t.Fatalf("expected the initial deposit, got %v %v", page, err)
+	}
+	settle(t, c, account.ID)
+	withdrawal, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 250, Type: client.TypeWithdrawal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{page[0].ID, withdrawal.ID} {
+		if _, err := c.UpdateTransactionStatus(ctx, id, client.StatusCompleted); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Pending transactions are not on statements
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 40, Type: client.TypeDeposit}); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := c.GetStatement(ctx, account.ID, client.StatementOptions{})
+	if err != nil {
+		t.Fatalf("GetStatement: %v", err)
+	}
+	if st.OpeningBalance != 0 || st.TotalCredits != 1000 || st.TotalDebits != 250 || st.ClosingBalance != 750 {
+		t.Fatalf("unexpected totals %+v", st)
+	}
+	if len(st.Lines) != 2 || st.Lines[0].Balance != 1000 || st.Lines[1].Amount != -250 || st.Lines[1].Balance != 750 {
+		t.Fatalf("unexpected lines %+v", st.Lines)
+	}
+
+	later, err := c.GetStatement(ctx, account.ID, client.StatementOptions{From: time.Now().Add(time.Minute), To: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later.OpeningBalance != 750 || later.ClosingBalance != 750 || len(later.Lines) != 0 {
+		t.Fatalf("later range should carry the balance forward, got %+v", later)
+	}
+
+	_, err = c.GetStatement(ctx, account.ID, client.StatementOptions{Period: "2020-01", From: time.Now()})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("period with from: got %v, want ErrBadRequest", err)
+	}
+
+	// The month-end job stores this month's statement once it is over
+	job := statements.NewJob(api.store, api.statements, api.generator, time.Hour)
+	nextMonth := time.Now().UTC().AddDate(0, 1, 0)
+	if err := job.RunOnce(ctx, nextMonth); err != nil {
+		t.Fatal(err)
+	}
+	period := time.Now().UTC().Format(statements.PeriodLayout)
+	stored, _ := api.statements.Get(ctx, account.ID, period)
+	if stored == nil || stored.ClosingBalance != 750 {
+		t.Fatalf("expected a stored statement for %s, got %+v", period, stored)
+	}
+
+	stored.Owner = "served from store"
+	api.statements.statements[account.ID+"#"+period] = *stored
+	monthly, err := c.GetStatement(ctx, account.ID, client.StatementOptions{Period: period})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monthly.Owner != "served from store" || monthly.Period != period {
+		t.Fatalf("monthly statement was not served from the store: %+v", monthly)
+	}
+}
+
+// Validation, formats and export are covered by the handler tests; this
+// checks the client sends its options and decodes the result.
+func TestImportAccounts(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	inputs := []client.CreateAccountInput{
+		{Owner: "gina", Email: "gina@example.com", AccountType: "savings"},
+		{Owner: "henry", Email: "not an email"},
+	}
+
+	dry, err := c.ImportAccounts(ctx, inputs, client.ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !dry.DryRun || dry.Valid != 1 || dry.Invalid != 1 || api.store.count() != 0 {
+		t.Fatalf("unexpected dry run result %+v", dry)
+	}
+	if dry.Rows[1].Status != client.ImportRowInvalid || dry.Rows[1].Line != 2 || len(dry.Rows[1].Errors) != 1 {
+		t.Fatalf("unexpected row results %+v", dry.Rows)
+	}
+
+	result, err := c.ImportAccounts(ctx, inputs, client.ImportOptions{SkipInitialDeposit: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if result.Created != 1 || result.Rows[0].Status != client.ImportRowCreated {
+		t.Fatalf("unexpected import result %+v", result)
+	}
+	account, err := c.GetAccount(ctx, result.Rows[0].AccountID)
+	if err != nil || account.AccountType != "savings" || account.Status != client.AccountStatusActive {
+		t.Fatalf("imported account %+v, %v", account, err)
+	}
+	if api.txns.posts != 0 {
+		t.Fatalf("%d initial deposits posted, want none", api.txns.posts)
+	}
+}
+
+func TestDomainEvents(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee", Email: "lee@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := "Lee Park"
+	if _, err := c.PatchAccount(ctx, account.ID, client.AccountPatch{Owner: &owner}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteAccount(ctx, account.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial deposit subscriber submits the deposit while the
+	// AccountCreated is being published, so its TransactionSubmitted is
+	// logged first
+	list := api.events.events(t)
+	var types []string
+	for _, event := range list {
+		types = append(types, event.Type)
+		if event.AccountID != account.ID || event.ID == "" || event.OccurredAt.IsZero() {
+			t.Errorf("event %+v is not for account %s or is missing its ID or time", event, account.ID)
+		}
+	}
+	want := []string{events.TransactionSubmitted, events.AccountCreated, events.AccountUpdated, events.AccountDeleted}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+
+	deposit, created, updated := list[0], list[1], list[2]
+	if deposit.Transaction == nil || deposit.Transaction.Type != "deposit" || deposit.Transaction.Amount != 1000 {
+		t.Errorf("deposit event = %+v, want the 1000 initial deposit", deposit)
+	}
+	if created.InitialDeposit != 1000 || created.Account == nil || created.Actor != "tester" || created.RequestID == "" {
+		t.Errorf("AccountCreated = %+v, want the account, its initial deposit, actor and request ID", created)
+	}
+	if change, ok := updated.Changed("owner"); !ok || change.Old != "lee" || change.New != owner {
+		t.Errorf("AccountUpdated changes = %+v, want owner lee -> %s", updated.Changes, owner)
+	}
+}
+
+func TestPatchAccount(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "kim", Email: "kim@example.com", AccountType: "savings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := "Kim Lee"
+	patched, err := c.PatchAccount(ctx, account.ID, client.AccountPatch{Owner: &owner})
+	if err != nil {
+		t.Fatalf("PatchAccount: %v", err)
+	}
+	if patched.Owner != owner || patched.Email != "kim@example.com" || patched.AccountType != "savings" {
+		t.Fatalf("patch changed more than the owner: %+v", patched)
+	}
+
+	// Merge patch null clears the email
+	req, _ := http.NewRequest(http.MethodPatch, api.URL+"/accounts/"+account.ID, strings.NewReader(`{"email": null}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got, _ := c.GetAccount(ctx, account.ID); resp.StatusCode != http.StatusOK || got.Email != "" {
+		t.Fatalf("null email: status %d, account %+v", resp.StatusCode, got)
+	}
+
+	for _, body := range []string{`{"balance": 1000000}`, `{"status": "active"}`, `{"id": "other"}`} {
+		req, _ := http.NewRequest(http.MethodPatch, api.URL+"/accounts/"+account.ID, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testToken)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("PATCH %s: status %d, want 422", body, resp.StatusCode)
+		}
+	}
+
+	got, _ := c.GetAccount(ctx, account.ID)
+	got.Balance = 5000
+	if _, err := c.UpdateAccount(ctx, *got); !errors.Is(err, client.ErrUnprocessable) {
+		t.Fatalf("PUT with a new balance: got %v, want ErrUnprocessable", err)
+	}
+	got.Balance = 0
+	got.AccountType = ""
+	if _, err := c.UpdateAccount(ctx, *got); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("PUT without account_type: got %v, want ErrBadRequest", err)
+	}
+
+	history, err := c.AccountHistory(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("AccountHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Field != "owner" || history[0].Old != "kim" || history[0].Actor != "tester" ||
+		history[1].Field != "email" || history[1].New != "" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
+
+// The rules themselves are covered by the products tests; this checks they
+// are applied to the API's accounts, transactions and transfers.
+func TestProductRules(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	catalog, err := c.Products(ctx)
+	if err != nil || len(catalog) != 3 || catalog[2].AccountType != "basic" || catalog[2].OverdraftLimit != 50 {
+		t.Fatalf("Products: %+v %v", catalog, err)
+	}
+
+	if _, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee", AccountType: "platinum"}); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("unknown account type: got %v, want ErrBadRequest", err)
+	}
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee", AccountType: "basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil || len(page) != 1 || page[0].Amount != 100 {
+		t.Fatalf("expected the product's initial deposit of 100, got %+v %v", page, err)
+	}
+	settle(t, c, account.ID)
+
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 501, Type: client.TypeDeposit})
+	if !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("deposit above the limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	// 100 less 130 leaves -30, the minimum balance of 20 less the overdraft of 50
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 130, Type: client.TypeWithdrawal}); err != nil {
+		t.Fatalf("withdrawal into the overdraft: %v", err)
+	}
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 0.01, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("withdrawal past the overdraft: got %v, want ErrInsufficientFunds", err)
+	}
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "max"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, other.ID)
+	_, err = c.CreateTransfer(ctx, client.TransferInput{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 1})
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("transfer past the overdraft: got %v, want ErrInsufficientFunds", err)
+	}
+	_, err = c.CreateTransfer(ctx, client.TransferInput{FromAccountID: other.ID, ToAccountID: account.ID, Amount: 600})
+	if !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("transfer above the destination's limit: got %v, want ErrLimitExceeded", err)
+	}
+}
+
+func TestHolds(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	// The basic product opens with 100 and may go down to -30
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee", AccountType: "basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, account.ID)
+	hold, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 80, Reference: "auth-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hold.Status != client.HoldActive || hold.ExpiresAt.Before(time.Now().Add(23*time.Hour)) {
+		t.Fatalf("expected an active hold with the default expiry, got %+v", hold)
+	}
+
+	balance, err := c.GetBalance(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.LedgerBalance != 100 || balance.Pending != 0 || balance.Held != 80 || balance.AvailableBalance != 20 {
+		t.Fatalf("unexpected balance %+v", balance)
+	}
+
+	// Withdrawals and further holds only see what the hold leaves available
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 51, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("withdrawal of held funds: got %v, want ErrInsufficientFunds", err)
+	}
+	if _, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 51}); !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("hold on held funds: got %v, want ErrInsufficientFunds", err)
+	}
+	tooLate := time.Now().Add(8 * 24 * time.Hour)
+	if _, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 1, ExpiresAt: &tooLate}); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("expiry past the maximum: got %v, want ErrBadRequest", err)
+	}
+
+	// A partial capture posts a withdrawal and keeps the rest on hold
+	hold, err = c.CaptureHold(ctx, hold.ID, client.CaptureHoldInput{Amount: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hold.Status != client.HoldActive || hold.Captured != 30 || len(hold.Captures) != 1 || hold.Captures[0].TransactionID == "" {
+		t.Fatalf("unexpected hold after partial capture %+v", hold)
+	}
+	if _, err := c.CaptureHold(ctx, hold.ID, client.CaptureHoldInput{Amount: 51}); !errors.Is(err, client.ErrUnprocessable) {
+		t.Fatalf("capture above the remainder: got %v, want ErrUnprocessable", err)
+	}
+	balance, _ = c.GetBalance(ctx, account.ID)
+	if balance.Pending != -30 || balance.PendingDebits != 30 || balance.Held != 50 || balance.AvailableBalance != 20 {
+		t.Fatalf("capturing should move funds from held to pending, got %+v", balance)
+	}
+
+	// Capturing the rest by default completes the hold
+	hold, err = c.CaptureHold(ctx, hold.ID, client.CaptureHoldInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hold.Status != client.HoldCaptured || hold.Captured != 80 {
+		t.Fatalf("unexpected hold after final capture %+v", hold)
+	}
+	if _, err := c.ReleaseHold(ctx, hold.ID); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("release of a captured hold: got %v, want ErrConflict", err)
+	}
+	var captures []models.OutboxEntry
+	entries, _ := api.outbox.List(ctx, "")
+	for _, e := range entries {
+		if e.Source == models.OutboxSourceHoldCapture {
+			captures = append(captures, e)
+		}
+	}
+	if len(captures) != 2 {
+		t.Fatalf("expected two capture withdrawals, got %+v", captures)
+	}
+
+	// Releasing returns the funds
+	released, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released, err = c.ReleaseHold(ctx, released.ID); err != nil || released.Status != client.HoldReleased {
+		t.Fatalf("ReleaseHold: %+v %v", released, err)
+	}
+
+	// The sweeper expires holds past their expiry
+	soon := time.Now().Add(time.Minute)
+	stale, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 5, ExpiresAt: &soon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sweeper := holds.NewSweeper(api.holds, time.Minute)
+	if n, err := sweeper.RunOnce(ctx, time.Now().Add(2*time.Minute)); err != nil || n != 1 {
+		t.Fatalf("RunOnce expired %d holds: %v", n, err)
+	}
+	if stale, err = c.GetHold(ctx, stale.ID); err != nil || stale.Status != client.HoldExpired {
+		t.Fatalf("expected the hold to expire, got %+v %v", stale, err)
+	}
+
+	list, err := c.ListHolds(ctx, account.ID, "")
+	if err != nil || len(list) != 3 {
+		t.Fatalf("ListHolds: %+v %v", list, err)
+	}
+	if active, err := c.ListHolds(ctx, account.ID, client.HoldActive); err != nil || len(active) != 0 {
+		t.Fatalf("ListHolds(active): %+v %v", active, err)
+	}
+}
+
+func TestHoldChecks(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	// A hold may not exceed the account's own transaction limit
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, account.ID)
+	maxAmount, daily := 200.0, 250.0
+	if _, err := c.SetAccountLimits(ctx, account.ID, client.AccountLimits{MaxTransactionAmount: &maxAmount, DailyOutflow: &daily}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 300}); !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("hold above the account's transaction limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	// Captures count against the daily outflow limit, and a rejected
+	// capture leaves the hold as it was
+	first, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.PlaceHold(ctx, account.ID, client.PlaceHoldInput{Amount: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureHold(ctx, first.ID, client.CaptureHoldInput{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureHold(ctx, second.ID, client.CaptureHoldInput{}); !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("capture past the daily limit: got %v, want ErrLimitExceeded", err)
+	}
+	if second, err = c.GetHold(ctx, second.ID); err != nil || second.Status != client.HoldActive || second.Captured != 0 {
+		t.Fatalf("hold after a rejected capture: %+v %v", second, err)
+	}
+	if status, err := c.GetAccountLimits(ctx, account.ID); err != nil || status.Daily.Used != 150 {
+		t.Fatalf("daily outflow after captures: %+v %v", status, err)
+	}
+
+	// The risk rules screen captures: this account is new, so taking more
+	// than 8000 from it is denied
+	rich, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "max"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: rich.ID, Amount: 2000, Type: client.TypeDeposit}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settle(t, c, rich.ID)
+	large, err := c.PlaceHold(ctx, rich.ID, client.PlaceHoldInput{Amount: 9000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureHold(ctx, large.ID, client.CaptureHoldInput{}); !errors.Is(err, client.ErrTransactionDenied) {
+		t.Fatalf("capture denied by the risk rules: got %v, want ErrTransactionDenied", err)
+	}
+
+	// and a customer rejected by KYC cannot capture what they held before
+	if _, err := c.SubmitKYC(ctx, rich.ID, client.KYCSubmission{
+		LegalName: "Max Mustermann", DateOfBirth: "1985-02-01", Nationality: "DE",
+		DocumentType: client.DocumentPassport, DocumentNumber: "C01X00T47", Address: "1 Hauptstrasse, Berlin",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DecideKYC(ctx, rich.ID, client.KYCDecisionInput{Decision: client.KYCRejected, Reason: "document expired"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CaptureHold(ctx, large.ID, client.CaptureHoldInput{Amount: 100}); !errors.Is(err, client.ErrKYCRejected) {
+		t.Fatalf("capture for a rejected customer: got %v, want ErrKYCRejected", err)
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	// The basic product opens with 100 and may go down to -30
+	tenant, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee", AccountType: "basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, tenant.ID)
+	landlord, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "max"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	_, err = c.CreateSchedule(ctx, client.CreateScheduleInput{FromAccountID: tenant.ID, ToAccountID: landlord.ID, Amount: 1, StartAt: &past})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("one-off schedule in the past: got %v, want ErrBadRequest", err)
+	}
+	_, err = c.CreateSchedule(ctx, client.CreateScheduleInput{FromAccountID: tenant.ID, ToAccountID: landlord.ID, Amount: 1, Recurrence: "FREQ=HOURLY"})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("unsupported recurrence: got %v, want ErrBadRequest", err)
+	}
+
+	rent, err := c.CreateSchedule(ctx, client.CreateScheduleInput{
+		FromAccountID: tenant.ID, ToAccountID: landlord.ID, Amount: 60, Description: "rent", Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rent.Status != client.ScheduleActive || rent.NextOccurrence == nil || rent.NextOccurrence.Day() != 1 {
+		t.Fatalf("expected rent due on the 1st, got %+v", rent)
+	}
+
+	scheduler := schedules.NewScheduler(api.schedules, api.transfers, time.UTC, time.Minute, time.Hour, 3)
+	run := func(at time.Time) *client.Schedule {
+		t.Helper()
+		if _, err := scheduler.RunOnce(ctx, at); err != nil {
+			t.Fatal(err)
+		}
+		schedule, err := c.GetSchedule(ctx, rent.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return schedule
+	}
+
+	// Two months are paid, leaving -20; the third would pass the overdraft
+	first := *rent.NextOccurrence
+	rent = run(first)
+	if rent.Occurrences != 1 || !rent.NextOccurrence.Equal(first.AddDate(0, 1, 0)) {
+		t.Fatalf("expected the next month to be due, got %+v", rent)
+	}
+	rent = run(*rent.NextOccurrence)
+	third := *rent.NextOccurrence
+	rent = run(third)
+	if rent.Attempts != 1 || !rent.NextOccurrence.Equal(third) || !rent.NextAttemptAt.Equal(third.Add(time.Hour)) {
+		t.Fatalf("expected a retry in an hour, got %+v", rent)
+	}
+
+	// Running again before the retry is due does nothing
+	if n, err := scheduler.RunOnce(ctx, third.Add(time.Minute)); err != nil || n != 0 {
+		t.Fatalf("RunOnce before the retry attempted %d: %v", n, err)
+	}
+	rent = run(third.Add(time.Hour))
+	rent = run(third.Add(2 * time.Hour))
+	if rent.Attempts != 0 || rent.Occurrences != 3 || !rent.NextOccurrence.Equal(third.AddDate(0, 1, 0)) {
+		t.Fatalf("expected the occurrence to be given up after 3 attempts, got %+v", rent)
+	}
+
+	executions, err := c.ScheduleExecutions(ctx, rent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, e := range executions {
+		statuses = append(statuses, e.Status)
+	}
+	want := []string{client.ExecutionSucceeded, client.ExecutionSucceeded, client.ExecutionRetrying, client.ExecutionRetrying, client.ExecutionFailed}
+	if strings.Join(statuses, ",") != strings.Join(want, ",") || executions[0].WithdrawalID == "" {
+		t.Fatalf("unexpected execution history %+v", executions)
+	}
+	page, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: landlord.ID})
+	if err != nil || len(page) != 3 {
+		t.Fatalf("expected the initial deposit and two rent payments, got %+v %v", page, err)
+	}
+
+	if rent, err = c.CancelSchedule(ctx, rent.ID); err != nil || rent.Status != client.ScheduleCancelled || rent.NextOccurrence != nil {
+		t.Fatalf("CancelSchedule: %+v %v", rent, err)
+	}
+	if _, err := c.CancelSchedule(ctx, rent.ID); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("cancelling twice: got %v, want ErrConflict", err)
+	}
+
+	// A one-off transfer completes its schedule
+	settle(t, c, landlord.ID)
+	soon := time.Now().Add(time.Minute)
+	once, err := c.CreateSchedule(ctx, client.CreateScheduleInput{FromAccountID: landlord.ID, ToAccountID: tenant.ID, Amount: 5, StartAt: &soon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := scheduler.RunOnce(ctx, soon); err != nil || n != 1 {
+		t.Fatalf("RunOnce attempted %d: %v", n, err)
+	}
+	if once, err = c.GetSchedule(ctx, once.ID); err != nil || once.Status != client.ScheduleCompleted {
+		t.Fatalf("expected the one-off schedule to complete, got %+v %v", once, err)
+	}
+
+	list, err := c.ListSchedules(ctx, tenant.ID)
+	if err != nil || len(list) != 1 || list[0].ID != rent.ID {
+		t.Fatalf("ListSchedules: %+v %v", list, err)
+	}
+}
+
+func TestAccountEventStream(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "sam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func(s *client.AccountEventStream, wantType string) json.RawMessage {
+		t.Helper()
+		event, err := s.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if event.Type != wantType || event.ID == "" {
+			t.Fatalf("event = %+v, want a %s event with an ID", event, wantType)
+		}
+		return event.Data
+	}
+
+	// A new stream starts with the account's current state
+	s, err := c.StreamAccountEvents(ctx, account.ID, "")
+	if err != nil {
+		t.Fatalf("StreamAccountEvents: %v", err)
+	}
+	var got client.Account
+	json.Unmarshal(next(s, client.StreamAccount), &got)
+	if got.ID != account.ID || got.Owner != "sam" {
+		t.Fatalf("first event has account %+v", got)
+	}
+
+	txn, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 25, Type: client.TypeDeposit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateTransactionStatus(ctx, txn.ID, client.StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	for _, wantStatus := range []string{client.StatusPending, client.StatusCompleted} {
+		var streamed client.Transaction
+		json.Unmarshal(next(s, client.StreamTransaction), &streamed)
+		if streamed.ID != txn.ID || streamed.Status != wantStatus {
+			t.Fatalf("streamed transaction %+v, want %s with status %s", streamed, txn.ID, wantStatus)
+		}
+	}
+	// Completing the deposit moved the balance
+	json.Unmarshal(next(s, client.StreamAccount), &got)
+	if got.Balance != 25 {
+		t.Fatalf("streamed balance %.2f after the deposit, want 25", got.Balance)
+	}
+	s.Close()
+
+	// Reconnecting with Last-Event-ID resumes with what was missed rather
+	// than the current state
+	owner := "Sam Roe"
+	if _, err := c.PatchAccount(ctx, account.ID, client.AccountPatch{Owner: &owner}); err != nil {
+		t.Fatal(err)
+	}
+	s, err = c.StreamAccountEvents(ctx, account.ID, s.LastEventID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal(next(s, client.StreamAccount), &got)
+	if got.Owner != owner {
+		t.Fatalf("resumed stream sent %+v, want the patched account", got)
+	}
+	if err := c.DeleteAccount(ctx, account.ID); err != nil {
+		t.Fatal(err)
+	}
+	next(s, client.StreamAccountDeleted)
+	if _, err := s.Next(); err != io.EOF {
+		t.Fatalf("Next after account.deleted: got %v, want io.EOF", err)
+	}
+	s.Close()
+
+	// An ID the server does not know falls back to the current state
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = c.StreamAccountEvents(ctx, other.ID, "unknown-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(s, client.StreamAccount)
+	s.Close()
+
+	// Customers may only follow their own accounts
+	dana := newClient(t, api.URL, client.WithToken(userToken))
+	if s, err := dana.StreamAccountEvents(ctx, other.ID, ""); err != nil {
+		t.Fatalf("owner streaming their account: %v", err)
+	} else {
+		s.Close()
+	}
+	unrelated, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dana.StreamAccountEvents(ctx, unrelated.ID, ""); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("streaming another customer's account: got %v, want ErrForbidden", err)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	// The receiver refuses the first delivery, to be retried
+	var mu sync.Mutex
+	var received []client.WebhookEvent
+	var secret string
+	requests := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if err := client.VerifyWebhook(secret, r.Header.Get(client.WebhookSignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("delivery %s: %v", r.Header.Get(client.WebhookDeliveryHeader), err)
+		}
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event client.WebhookEvent
+		json.Unmarshal(body, &event)
+		if event.Type != r.Header.Get(client.WebhookEventHeader) {
+			t.Errorf("event %s sent with header %s", event.Type, r.Header.Get(client.WebhookEventHeader))
+		}
+		received = append(received, event)
+	}))
+	defer receiver.Close()
+
+	_, err := c.CreateWebhook(ctx, client.CreateWebhookInput{URL: "ftp://example.com", EventTypes: []string{client.EventAccountCreated}})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("non-http URL: got %v, want ErrBadRequest", err)
+	}
+	_, err = c.CreateWebhook(ctx, client.CreateWebhookInput{URL: receiver.URL, EventTypes: []string{"account.renamed"}})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("unknown event type: got %v, want ErrBadRequest", err)
+	}
+
+	subscription, err := c.CreateWebhook(ctx, client.CreateWebhookInput{
+		URL: receiver.URL, EventTypes: []string{client.EventAccountCreated, client.EventTransactionPosted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Secret == "" {
+		t.Fatal("expected a generated secret")
+	}
+	secret = subscription.Secret
+	if got, err := c.GetWebhook(ctx, subscription.ID); err != nil || got.Secret != "" {
+		t.Fatalf("GetWebhook must not return the secret: %+v %v", got, err)
+	}
+
+	// Opening an account publishes it and its initial deposit
+	if _, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "hooked"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if n, err := api.dispatcher.RunOnce(ctx, now); err != nil || n != 2 {
+		t.Fatalf("RunOnce attempted %d: %v", n, err)
+	}
+	if n, err := api.dispatcher.RunOnce(ctx, now.Add(30*time.Second)); err != nil || n != 0 {
+		t.Fatalf("RunOnce before the retry attempted %d: %v", n, err)
+	}
+	if n, err := api.dispatcher.RunOnce(ctx, now.Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("retry attempted %d: %v", n, err)
+	}
+
+	mu.Lock()
+	var types []string
+	for _, event := range received {
+		types = append(types, event.Type)
+	}
+	mu.Unlock()
+	sort.Strings(types)
+	if strings.Join(types, ",") != client.EventAccountCreated+","+client.EventTransactionPosted {
+		t.Fatalf("received %v", types)
+	}
+
+	deliveries, err := c.WebhookDeliveries(ctx, subscription.ID)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("WebhookDeliveries: %+v %v", deliveries, err)
+	}
+	attempts := 0
+	for _, d := range deliveries {
+		if d.Status != client.DeliverySucceeded || d.DeliveredAt == nil {
+			t.Fatalf("expected every delivery to succeed, got %+v", d)
+		}
+		attempts += d.Attempts
+	}
+	if attempts != 3 {
+		t.Fatalf("expected one delivery to take two attempts, got %d attempts", attempts)
+	}
+
+	// A redelivery sends the same event again
+	redelivery, err := c.RedeliverWebhook(ctx, subscription.ID, deliveries[0].ID)
+	if err != nil || redelivery.RedeliveryOf != deliveries[0].ID || redelivery.EventID != deliveries[0].EventID {
+		t.Fatalf("RedeliverWebhook: %+v %v", redelivery, err)
+	}
+	if n, err := api.dispatcher.RunOnce(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("redelivery attempted %d: %v", n, err)
+	}
+	mu.Lock()
+	last := received[len(received)-1]
+	mu.Unlock()
+	if last.ID != deliveries[0].EventID {
+		t.Fatalf("redelivered event %s, want %s", last.ID, deliveries[0].EventID)
+	}
+
+	// Nothing is recorded once unsubscribed
+	if err := c.DeleteWebhook(ctx, subscription.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "unhooked"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := api.dispatcher.RunOnce(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("RunOnce after unsubscribing attempted %d: %v", n, err)
+	}
+	if _, err := c.GetWebhook(ctx, subscription.ID); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("GetWebhook after delete: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestReconciliation(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "lee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "kim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pending deposits are not in the ledger balance yet
+	report, err := c.Reconcile(ctx, client.ReconcileInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 2 || report.Failed != 0 || len(report.Discrepancies) != 0 {
+		t.Fatalf("expected no discrepancies while deposits are pending, got %+v", report)
+	}
+
+	// The deposit is completed behind the API's back, so the stored balance
+	// misses it
+	var depositID string
+	complete := func(at time.Time) {
+		api.txns.mu.Lock()
+		defer api.txns.mu.Unlock()
+		for i := range api.txns.txns {
+			if api.txns.txns[i].AccountID == account.ID {
+				api.txns.txns[i].Status = "completed"
+				api.txns.txns[i].ProcessedAt = &at
+				depositID = api.txns.txns[i].ID
+			}
+		}
+	}
+	complete(time.Now())
+
+	// A report-only run leaves the balance alone
+	report, err = c.Reconcile(ctx, client.ReconcileInput{AccountIDs: []string{account.ID, other.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 2 || len(report.Discrepancies) != 1 {
+		t.Fatalf("expected one discrepancy, got %+v", report)
+	}
+	d := report.Discrepancies[0]
+	if d.AccountID != account.ID || d.Recorded != 0 || d.Expected != 1000 || d.Difference != 1000 || d.Corrected {
+		t.Fatalf("unexpected discrepancy %+v", d)
+	}
+	if got, err := c.GetAccount(ctx, account.ID); err != nil || got.Balance != 0 {
+		t.Fatalf("report-only run changed the balance: %+v, %v", got, err)
+	}
+
+	// A deposit completed moments ago may still be on its way to the
+	// balance, so the account is left alone
+	skipped, err := c.Reconcile(ctx, client.ReconcileInput{AccountIDs: []string{account.ID}, Correct: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped.Discrepancies) != 1 || skipped.Discrepancies[0].Corrected || !strings.Contains(skipped.Discrepancies[0].Error, depositID) {
+		t.Fatalf("expected the account to be skipped, got %+v", skipped)
+	}
+
+	// Once it is clearly stuck the correction applies it
+	complete(time.Now().Add(-time.Hour))
+	corrected, err := c.Reconcile(ctx, client.ReconcileInput{AccountIDs: []string{account.ID}, Correct: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrected.Discrepancies) != 1 || !corrected.Discrepancies[0].Corrected || corrected.RequestedBy != "tester" ||
+		!slices.Equal(corrected.Discrepancies[0].Covered, []string{depositID}) {
+		t.Fatalf("expected a corrected discrepancy covering the deposit, got %+v", corrected)
+	}
+	if got, err := c.GetAccount(ctx, account.ID); err != nil || got.Balance != 1000 {
+		t.Fatalf("expected the corrected balance, got %+v, %v", got, err)
+	}
+
+	// and completing the deposit again does not count it twice
+	if _, err := c.UpdateTransactionStatus(ctx, depositID, client.StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.GetAccount(ctx, account.ID); err != nil || got.Balance != 1000 {
+		t.Fatalf("deposit applied again after the correction: %+v, %v", got, err)
+	}
+	history, err := c.AccountHistory(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	if last.Field != "balance" || last.Old != "0.00" || last.New != "1000.00" || last.Actor != "tester" ||
+		!strings.Contains(last.Reason, corrected.ID) {
+		t.Fatalf("unexpected history entry %+v", last)
+	}
+
+	// Reports are kept, newest first
+	got, err := c.GetReconciliation(ctx, corrected.ID)
+	if err != nil || got.ID != corrected.ID || !got.Correct {
+		t.Fatalf("GetReconciliation: %+v, %v", got, err)
+	}
+	reports, err := c.ListReconciliations(ctx)
+	if err != nil || len(reports) != 4 || reports[0].ID != corrected.ID {
+		t.Fatalf("ListReconciliations: %+v, %v", reports, err)
+	}
+	if _, err := c.GetReconciliation(ctx, uuid.NewString()); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("unknown report: got %v, want ErrNotFound", err)
+	}
+	if _, err := c.Reconcile(ctx, client.ReconcileInput{AccountIDs: []string{uuid.NewString()}}); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("unknown account: got %v, want ErrNotFound", err)
+	}
+
+	user := newClient(t, api.URL, client.WithToken(userToken))
+	if _, err := user.Reconcile(ctx, client.ReconcileInput{}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("customer reconcile: got %v, want ErrForbidden", err)
+	}
+}
+
+func TestBalancePosting(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "ines"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := func(want float64) {
+		t.Helper()
+		got, err := c.GetAccount(ctx, account.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Balance != want {
+			t.Fatalf("balance = %.2f, want %.2f", got.Balance, want)
+		}
+	}
+
+	// Pending transactions leave the stored balance alone
+	balance(0)
+	txns, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil || len(txns) != 1 {
+		t.Fatalf("expected the initial deposit, got %v, %v", txns, err)
+	}
+	deposit := txns[0]
+
+	// Completing applies the transaction once, however often it is completed
+	for range 2 {
+		if _, err := c.UpdateTransactionStatus(ctx, deposit.ID, client.StatusCompleted); err != nil {
+			t.Fatal(err)
+		}
+		balance(1000)
+	}
+	history, err := c.AccountHistory(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Field != "balance" || history[0].Old != "0.00" || history[0].New != "1000.00" ||
+		history[0].Actor != "tester" || !strings.Contains(history[0].Reason, deposit.ID) {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	// A posted transaction cannot leave completed
+	for _, status := range []string{client.StatusFailed, client.StatusPending} {
+		if _, err := c.UpdateTransactionStatus(ctx, deposit.ID, status); !errors.Is(err, client.ErrConflict) {
+			t.Fatalf("moving a posted transaction to %s: got %v, want ErrConflict", status, err)
+		}
+	}
+	balance(1000)
+
+	// A transaction submitted as completed is applied straight away
+	withdrawal, err := c.CreateTransaction(ctx, client.CreateTransactionInput{
+		AccountID: account.ID, Amount: 300, Type: client.TypeWithdrawal, Status: client.StatusCompleted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withdrawal.Status != client.StatusCompleted {
+		t.Fatalf("status = %q, want completed", withdrawal.Status)
+	}
+	balance(700)
+
+	// A pending deposit does not count towards what may be withdrawn
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 1000, Type: client.TypeDeposit}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 1500, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("withdrawing a pending deposit: got %v, want ErrInsufficientFunds", err)
+	}
+
+	// Nor does a withdrawal recorded straight in the transaction service
+	// get past the balance when it is completed
+	large := models.Transaction{ID: uuid.NewString(), AccountID: account.ID, Amount: 1500, Type: "withdrawal", Status: "pending", CreatedAt: time.Now()}
+	api.txns.mu.Lock()
+	api.txns.txns = append(api.txns.txns, large)
+	api.txns.mu.Unlock()
+	_, err = c.UpdateTransactionStatus(ctx, large.ID, client.StatusCompleted)
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("completing an uncovered withdrawal: got %v, want ErrInsufficientFunds", err)
+	}
+	balance(700)
+	txns, err = c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range txns {
+		if txn.ID == large.ID && txn.Status != client.StatusFailed {
+			t.Fatalf("uncovered withdrawal has status %q, want failed", txn.Status)
+		}
+	}
+
+	if _, err := c.UpdateTransactionStatus(ctx, uuid.NewString(), client.StatusCompleted); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("unknown transaction: got %v, want ErrNotFound", err)
+	}
+
+	// Each posting is published as an account update
+	var updates int
+	for _, event := range api.events.events(t) {
+		if event.Type == events.AccountUpdated && event.AccountID == account.ID {
+			updates++
+		}
+	}
+	if updates != 2 {
+		t.Fatalf("got %d account.updated events, want one per posted transaction", updates)
+	}
+
+	// Funds are checked against the stored balance, so a transaction the
+	// ledger shows completed but that was never posted does not count
+	unposted, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "ines"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.txns.mu.Lock()
+	for i := range api.txns.txns {
+		if api.txns.txns[i].AccountID == unposted.ID {
+			api.txns.txns[i].Status = "completed"
+		}
+	}
+	api.txns.mu.Unlock()
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: unposted.ID, Amount: 500, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrInsufficientFunds) {
+		t.Fatalf("withdrawing an unposted deposit: got %v, want ErrInsufficientFunds", err)
+	}
+	got, err := c.GetBalance(ctx, unposted.ID)
+	if err != nil || got.LedgerBalance != 0 || got.AvailableBalance != 0 {
+		t.Fatalf("balance of an unposted deposit: %+v %v", got, err)
+	}
+
+	// Outbox entries marked complete, as interest is paid, are completed
+	// and applied on delivery, including when delivery is redriven
+	before, err := c.GetAccount(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.txns.setDown(true)
+	entry := &models.OutboxEntry{
+		ID:     uuid.NewString(),
+		Source: models.OutboxSourceInterest,
+		Transaction: models.Transaction{
+			AccountID: account.ID, Amount: 4.25, Type: "deposit", Description: "interest", Status: "pending",
+		},
+		Actor:    "interest",
+		Complete: true,
+	}
+	if err := api.outbox.Submit(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	balance(before.Balance)
+	api.txns.setDown(false)
+	if _, err := api.outbox.Redrive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	balance(before.Balance + 4.25)
+	delivered, _ := api.outbox.List(ctx, models.OutboxDelivered)
+	var interest *models.OutboxEntry
+	for i := range delivered {
+		if delivered[i].ID == entry.ID {
+			interest = &delivered[i]
+		}
+	}
+	if interest == nil || interest.Transaction.Status != "completed" {
+		t.Fatalf("interest entry not delivered as completed: %+v", interest)
+	}
+	if _, err := api.outbox.Redrive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	balance(before.Balance + 4.25)
+}
+
+func TestRiskRules(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	dana := newClient(t, api.URL, client.WithToken(userToken))
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Allowed transactions go through as submitted, their decision logged
+	// under their ID
+	small, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 50, Type: client.TypeDeposit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decision, err := c.GetRiskDecision(ctx, small.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Decision != client.RiskAllow || decision.TransactionID != small.ID || len(decision.Rules) != 2 || decision.Actor != "tester" {
+		t.Fatalf("unexpected decision %+v", decision)
+	}
+
+	// A large transaction is recorded pending for review, even if submitted
+	// as completed, and only an admin can complete it
+	large, err := c.CreateTransaction(ctx, client.CreateTransactionInput{
+		AccountID: account.ID, Amount: 3000, Type: client.TypeDeposit, Status: client.StatusCompleted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if large.Status != client.StatusPending {
+		t.Fatalf("status = %q, want pending", large.Status)
+	}
+	decision, err = c.GetRiskDecision(ctx, large.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Decision != client.RiskReview || decision.Rules[0].Rule != "large-amount" || decision.Rules[0].Reason == "" ||
+		decision.Rules[1].Decision != client.RiskAllow {
+		t.Fatalf("unexpected decision %+v", decision)
+	}
+	if _, err := dana.UpdateTransactionStatus(ctx, large.ID, client.StatusCompleted); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("completing a reviewed transaction as a customer: got %v, want ErrForbidden", err)
+	}
+	if _, err := c.UpdateTransactionStatus(ctx, large.ID, client.StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// Denied transactions are never recorded; the most severe rule wins
+	recorded, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, amount := range []float64{9000, 20000} {
+		_, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: amount, Type: client.TypeDeposit})
+		if !errors.Is(err, client.ErrTransactionDenied) {
+			t.Fatalf("deposit of %.2f: got %v, want ErrTransactionDenied", amount, err)
+		}
+	}
+	after, err := c.ListTransactions(ctx, client.ListTransactionsOptions{AccountID: account.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(recorded) {
+		t.Fatal("a denied transaction was forwarded to the transaction service")
+	}
+	denied, err := c.ListRiskDecisions(ctx, client.ListRiskDecisionsOptions{AccountID: account.ID, Decision: client.RiskDeny})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(denied) != 2 || denied[0].Amount != 20000 || denied[0].TransactionID != "" || denied[1].Rules[1].Decision != client.RiskDeny {
+		t.Fatalf("unexpected denied decisions %+v", denied)
+	}
+
+	// Transfers are screened as withdrawals from the source account
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer, err := c.CreateTransfer(ctx, client.TransferInput{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 2500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decision, err = c.GetRiskDecision(ctx, transfer.Withdrawal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Decision != client.RiskReview || decision.Type != client.TypeWithdrawal {
+		t.Fatalf("unexpected transfer decision %+v", decision)
+	}
+
+	// The log is for admins only
+	if _, err := dana.ListRiskDecisions(ctx, client.ListRiskDecisionsOptions{}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("listing decisions as a customer: got %v, want ErrForbidden", err)
+	}
+	if _, err := c.GetRiskDecision(ctx, uuid.NewString()); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("unknown decision: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	dana := newClient(t, api.URL, client.WithToken(userToken))
+	ctx := context.Background()
+
+	// basic accounts may take 400 a day and 1000 a month
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dana", AccountType: "basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 450, Type: client.TypeDeposit}); err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, account.ID)
+	// The first outflow of a period is held to the limit too
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 450, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrLimitExceeded) || !strings.Contains(err.Error(), "400.00 of its daily outflow limit") {
+		t.Fatalf("first withdrawal past the daily limit: got %v, want ErrLimitExceeded", err)
+	}
+	withdrawal, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 300, Type: client.TypeWithdrawal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 150, Type: client.TypeWithdrawal})
+	if !errors.Is(err, client.ErrLimitExceeded) || !strings.Contains(err.Error(), "100.00 of its daily outflow limit") {
+		t.Fatalf("withdrawal past the daily limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	status, err := dana.GetAccountLimits(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Daily.Limit != 400 || status.Daily.Used != 300 || status.Daily.Remaining == nil || *status.Daily.Remaining != 100 ||
+		status.Monthly.Used != 300 || *status.Monthly.Remaining != 700 || status.MaxTransactionAmount != 500 || status.Timezone != "UTC" {
+		t.Fatalf("unexpected limits %+v", status)
+	}
+	if !status.Daily.ResetsAt.After(time.Now()) || status.Overrides != nil {
+		t.Fatalf("unexpected limits %+v", status)
+	}
+
+	// A failed withdrawal gives its allowance back, once
+	for i := 0; i < 2; i++ {
+		if _, err := c.UpdateTransactionStatus(ctx, withdrawal.ID, client.StatusFailed); err != nil && i == 0 {
+			t.Fatal(err)
+		}
+	}
+	status, err = c.GetAccountLimits(ctx, account.ID)
+	if err != nil || status.Daily.Used != 0 || *status.Daily.Remaining != 400 {
+		t.Fatalf("limits after the withdrawal failed: %+v %v", status, err)
+	}
+
+	// Transfers count against the source account
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateTransfer(ctx, client.TransferInput{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 250}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreateTransfer(ctx, client.TransferInput{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 200})
+	if !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("transfer past the daily limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	// Only admins override limits; a zero limit removes the product's, but
+	// not the cap on unverified customers
+	none := 0.0
+	if _, err := dana.SetAccountLimits(ctx, account.ID, client.AccountLimits{DailyOutflow: &none}); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("setting limits as a customer: got %v, want ErrForbidden", err)
+	}
+	status, err = c.SetAccountLimits(ctx, account.ID, client.AccountLimits{DailyOutflow: &none})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Daily.Limit != 3000 || status.Overrides == nil || *status.Overrides.DailyOutflow != 0 {
+		t.Fatalf("unexpected limits %+v", status)
+	}
+	if _, err := c.CreateTransfer(ctx, client.TransferInput{FromAccountID: account.ID, ToAccountID: other.ID, Amount: 200}); err != nil {
+		t.Fatalf("transfer without the product's daily limit: %v", err)
+	}
+	negative := -1.0
+	if _, err := c.SetAccountLimits(ctx, account.ID, client.AccountLimits{MonthlyOutflow: &negative}); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("negative limit: got %v, want ErrBadRequest", err)
+	}
+	got, err := c.GetAccount(ctx, account.ID)
+	if err != nil || got.Limits == nil || *got.Limits.DailyOutflow != 0 {
+		t.Fatalf("account limits: %+v %v", got, err)
+	}
+	got.Limits.DailyOutflow = &negative
+	if _, err := c.UpdateAccount(ctx, *got); !errors.Is(err, client.ErrUnprocessable) {
+		t.Fatalf("changing limits with PUT: got %v, want ErrUnprocessable", err)
+	}
+	if status, err = c.SetAccountLimits(ctx, account.ID, client.AccountLimits{}); err != nil || status.Overrides != nil || status.Daily.Limit != 400 {
+		t.Fatalf("removing overrides: %+v %v", status, err)
+	}
+	history, err := c.AccountHistory(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes int
+	for _, change := range history {
+		if change.Field == "limits" {
+			changes++
+		}
+	}
+	if changes != 2 {
+		t.Fatalf("%d limits changes in the history, want 2", changes)
+	}
+
+	// Days and months are taken in the account's timezone
+	zone := "Pacific/Kiritimati"
+	if _, err := c.PatchAccount(ctx, account.ID, client.AccountPatch{Timezone: &zone}); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = c.GetAccountLimits(ctx, account.ID); err != nil || status.Timezone != zone {
+		t.Fatalf("limits after setting the timezone: %+v %v", status, err)
+	}
+	zone = "Mars/Olympus_Mons"
+	if _, err := c.PatchAccount(ctx, account.ID, client.AccountPatch{Timezone: &zone}); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("unknown timezone: got %v, want ErrBadRequest", err)
+	}
+}
+
+func TestKYC(t *testing.T) {
+	api := newTestAPI(t)
+	c := newClient(t, api.URL)
+	dana := newClient(t, api.URL, client.WithToken(userToken))
+	ctx := context.Background()
+
+	account, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "dana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := c.CreateAccount(ctx, client.CreateAccountInput{Owner: "erin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := dana.GetKYC(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.KYCStatus != client.KYCUnverified || record.Status != client.KYCUnverified || record.SubmittedAt != nil {
+		t.Fatalf("new account: %+v, KYC %+v", account, record)
+	}
+
+	// Unverified customers may take 3000 a day
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 5000, Type: client.TypeDeposit}); err != nil {
+		t.Fatal(err)
+	}
+	settle(t, c, account.ID)
+	if _, err := c.CreateTransaction(ctx, client.CreateTransactionInput{AccountID: account.ID, Amount: 2500, Type: client.TypeWithdrawal}); err != nil {
+		t.Fatal(err)
+	}
+	withdraw := client.CreateTransactionInput{AccountID: account.ID, Amount: 600, Type: client.TypeWithdrawal}
+	if _, err := c.CreateTransaction(ctx, withdraw); !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("withdrawal past the unverified limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	// Submitting makes the customer pending, with the pending limits
+	submission := client.KYCSubmission{
+		LegalName:      "Dana Reyes",
+		DateOfBirth:    time.Now().AddDate(1, 0, 0).Format(time.DateOnly),
+		Nationality:    "gbr",
+		DocumentType:   client.DocumentPassport,
+		DocumentNumber: "P123456789",
+		Address:        "1 High Street, London",
+	}
+	if _, err := dana.SubmitKYC(ctx, account.ID, submission); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("future birth date and bad nationality: got %v, want ErrBadRequest", err)
+	}
+	submission.DateOfBirth, submission.Nationality = "1990-04-01", "gb"
+	record, err = dana.SubmitKYC(ctx, account.ID, submission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != client.KYCPending || record.Nationality != "GB" || record.DocumentNumber != "******6789" || record.SubmittedBy != "dana" {
+		t.Fatalf("unexpected submission %+v", record)
+	}
+	if _, err := dana.SubmitKYC(ctx, account.ID, submission); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("submitting twice: got %v, want ErrConflict", err)
+	}
+	status, err := dana.GetAccountLimits(ctx, account.ID)
+	if err != nil || status.KYCStatus != client.KYCPending || status.Daily.Limit != 6000 {
+		t.Fatalf("pending limits: %+v %v", status, err)
+	}
+	if _, err := c.CreateTransaction(ctx, withdraw); err != nil {
+		t.Fatalf("withdrawal within the pending limit: %v", err)
+	}
+
+	// Only admins decide, and see the full document number
+	reject := client.KYCDecisionInput{Decision: client.KYCRejected}
+	if _, err := dana.DecideKYC(ctx, account.ID, reject); !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("deciding as a customer: got %v, want ErrForbidden", err)
+	}
+	if _, err := c.DecideKYC(ctx, account.ID, reject); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("rejecting without a reason: got %v, want ErrBadRequest", err)
+	}
+	if _, err := c.DecideKYC(ctx, other.ID, client.KYCDecisionInput{Decision: client.KYCVerified}); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("deciding without a submission: got %v, want ErrConflict", err)
+	}
+	pending, err := c.ListKYC(ctx, client.KYCPending)
+	if err != nil || len(pending) != 1 || pending[0].AccountID != account.ID || pending[0].DocumentNumber != "P123456789" {
+		t.Fatalf("pending records: %+v %v", pending, err)
+	}
+
+	// Rejected customers can neither transact nor submit again
+	reject.Reason = "document does not match the applicant"
+	if record, err = c.DecideKYC(ctx, account.ID, reject); err != nil || record.Status != client.KYCRejected || record.DecidedBy != "tester" {
+		t.Fatalf("rejecting: %+v %v", record, err)
+	}
+	deposit := client.CreateTransactionInput{AccountID: account.ID, Amount: 10, Type: client.TypeDeposit}
+	if _, err := c.CreateTransaction(ctx, deposit); !errors.Is(err, client.ErrKYCRejected) {
+		t.Fatalf("deposit for a rejected customer: got %v, want ErrKYCRejected", err)
+	}
+	if _, err := c.CreateTransfer(ctx, client.TransferInput{FromAccountID: other.ID, ToAccountID: account.ID, Amount: 10}); !errors.Is(err, client.ErrKYCRejected) {
+		t.Fatalf("transfer to a rejected customer: got %v, want ErrKYCRejected", err)
+	}
+	if _, err := dana.SubmitKYC(ctx, account.ID, submission); !errors.Is(err, client.ErrKYCRejected) {
+		t.Fatalf("submitting after rejection: got %v, want ErrKYCRejected", err)
+	}
+
+	// An admin may overturn the decision; verified customers follow their
+	// product's limits alone
+	if _, err := c.DecideKYC(ctx, account.ID, client.KYCDecisionInput{Decision: client.KYCVerified}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateTransaction(ctx, deposit); err != nil {
+		t.Fatalf("deposit for a verified customer: %v", err)
+	}
+	status, err = c.GetAccountLimits(ctx, account.ID)
+	if err != nil || status.KYCStatus != client.KYCVerified || status.Daily.Limit != 0 {
+		t.Fatalf("verified limits: %+v %v", status, err)
+	}
+
+	history, err := c.AccountHistory(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	for _, change := range history {
+		if change.Field == "kyc_status" {
+			changes = append(changes, change.New)
+			if change.New == client.KYCRejected && change.Reason != reject.Reason {
+				t.Errorf("rejection reason = %q, want %q", change.Reason, reject.Reason)
+			}
+		}
+	}
+	if strings.Join(changes, ",") != "pending,rejected,verified" {
+		t.Fatalf("kyc_status changes = %v", changes)
+	}
+	got, err := c.GetAccount(ctx, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.KYCStatus = client.KYCUnverified
+	if _, err := c.UpdateAccount(ctx, *got); !errors.Is(err, client.ErrUnprocessable) {
+		t.Fatalf("changing the KYC status with PUT: got %v, want ErrUnprocessable", err)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode is the machine-readable code of an API error.
+type ErrorCode string
+
+// Error codes returned by the API.
+const (
+	CodeBadRequest          ErrorCode = "bad_request"
+	CodeUnauthorized        ErrorCode = "unauthorized"
+	CodeForbidden           ErrorCode = "forbidden"
+	CodeNotFound            ErrorCode = "not_found"
+	CodeMethodNotAllowed    ErrorCode = "method_not_allowed"
+	CodeConflict            ErrorCode = "conflict"
+	CodeAccountInactive     ErrorCode = "account_inactive"
+	CodeInsufficientFunds   ErrorCode = "insufficient_funds"
+	CodeLimitExceeded       ErrorCode = "limit_exceeded"
+	CodeTransactionDenied   ErrorCode = "transaction_denied"
+	CodeKYCRejected         ErrorCode = "kyc_rejected"
+	CodeIdempotencyMismatch ErrorCode = "idempotency_key_mismatch"
+	CodeUnprocessable       ErrorCode = "unprocessable"
+	CodeRateLimited         ErrorCode = "rate_limited"
+	CodeInternal            ErrorCode = "internal"
+	CodeUnavailable         ErrorCode = "unavailable"
+)
+
+// Error is a failed API call.
+type Error struct {
+	StatusCode int
+	Code       ErrorCode
+	Message    string
+	// RequestID identifies the request in the server logs.
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("corebank: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	if e.RequestID != "" {
+		msg += " (request_id " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is matches another *Error with the same code, so the sentinel errors below
+// work with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Sentinel errors for use with errors.Is, e.g.
+//
+//	if errors.Is(err, client.ErrNotFound) { ... }
+var (
+	ErrBadRequest          = &Error{Code: CodeBadRequest}
+	ErrUnauthorized        = &Error{Code: CodeUnauthorized}
+	ErrForbidden           = &Error{Code: CodeForbidden}
+	ErrNotFound            = &Error{Code: CodeNotFound}
+	ErrConflict            = &Error{Code: CodeConflict}
+	ErrAccountInactive     = &Error{Code: CodeAccountInactive}
+	ErrInsufficientFunds   = &Error{Code: CodeInsufficientFunds}
+	ErrLimitExceeded       = &Error{Code: CodeLimitExceeded}
+	ErrTransactionDenied   = &Error{Code: CodeTransactionDenied}
+	ErrKYCRejected         = &Error{Code: CodeKYCRejected}
+	ErrIdempotencyMismatch = &Error{Code: CodeIdempotencyMismatch}
+	ErrUnprocessable       = &Error{Code: CodeUnprocessable}
+	ErrRateLimited         = &Error{Code: CodeRateLimited}
+	ErrInternal            = &Error{Code: CodeInternal}
+	ErrUnavailable         = &Error{Code: CodeUnavailable}
+)
+
+// codeForStatus is used for error bodies without a code, such as errors
+// passed through from the transaction service.
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Hold statuses. Only active holds reserve funds.
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// Hold reserves part of an account's balance until it is captured into a
+// withdrawal, released or expires.
+type Hold struct {
+	ID        string  `json:"id"`
+	AccountID string  `json:"account_id"`
+	Amount    float64 `json:"amount"`
+	// Captured is the sum of the captures so far.
+	Captured    float64       `json:"captured"`
+	Reference   string        `json:"reference,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Status      string        `json:"status"`
+	Captures    []HoldCapture `json:"captures"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// HoldCapture is part of a hold converted into a withdrawal. TransactionID
+// is empty if the withdrawal was not recorded straight away; OutboxID
+// identifies it until it is.
+type HoldCapture struct {
+	Amount        float64   `json:"amount"`
+	OutboxID      string    `json:"outbox_id"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	CapturedAt    time.Time `json:"captured_at"`
+}
+
+// PlaceHoldInput is the body of PlaceHold.
+type PlaceHoldInput struct {
+	Amount      float64 `json:"amount"`
+	Reference   string  `json:"reference,omitempty"`
+	Description string  `json:"description,omitempty"`
+	// ExpiresAt defaults to the server's default expiry.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// PlaceHold reserves funds on an account. The amount must be within the
+// account's transaction limit, or it fails with ErrLimitExceeded, and
+// covered by the available balance, or it fails with ErrInsufficientFunds.
+func (c *Client) PlaceHold(ctx context.Context, accountID string, in PlaceHoldInput, opts ...CallOption) (*Hold, error) {
+	var hold Hold
+	req := request{method: http.MethodPost, path: "/accounts/" + url.PathEscape(accountID) + "/holds", body: in, opts: opts}
+	if _, err := c.do(ctx, req, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// GetHold returns the hold with the given ID.
+func (c *Client) GetHold(ctx context.Context, id string) (*Hold, error) {
+	var hold Hold
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/holds/" + url.PathEscape(id)}, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ListHolds returns an account's holds with the given status, or all of them
+// when status is empty, newest first.
+func (c *Client) ListHolds(ctx context.Context, accountID, status string) ([]Hold, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var holds []Hold
+	req := request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(accountID) + "/holds", query: query}
+	if _, err := c.do(ctx, req, &holds); err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// CaptureHoldInput is the body of CaptureHold.
+type CaptureHoldInput struct {
+	// Amount defaults to what remains of the hold.
+	Amount float64 `json:"amount,omitempty"`
+	// Final releases whatever remains after this capture.
+	Final bool `json:"final,omitempty"`
+}
+
+// CaptureHold converts all or part of an active hold into a pending
+// withdrawal. Capturing a hold that is no longer active fails with
+// ErrConflict. The withdrawal counts against the account's outflow limits
+// and is screened by the risk rules, failing with ErrLimitExceeded or
+// ErrTransactionDenied.
+func (c *Client) CaptureHold(ctx context.Context, id string, in CaptureHoldInput, opts ...CallOption) (*Hold, error) {
+	var hold Hold
+	req := request{method: http.MethodPost, path: "/holds/" + url.PathEscape(id) + "/capture", body: in, opts: opts}
+	if _, err := c.do(ctx, req, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ReleaseHold returns what remains of an active hold to the available
+// balance.
+func (c *Client) ReleaseHold(ctx context.Context, id string) (*Hold, error) {
+	var hold Hold
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/holds/" + url.PathEscape(id) + "/release"}, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Balance is an account's balance as of now. LedgerBalance counts completed
+// transactions; AvailableBalance adds pending ones and deducts what active
+// holds reserve.
+type Balance struct {
+	AccountID        string  `json:"account_id"`
+	LedgerBalance    float64 `json:"ledger_balance"`
+	Pending          float64 `json:"pending"`
+	PendingDebits    float64 `json:"pending_debits"`
+	Held             float64 `json:"held"`
+	AvailableBalance float64 `json:"available_balance"`
+}
+
+// GetBalance returns an account's ledger and available balance.
+func (c *Client) GetBalance(ctx context.Context, accountID string) (*Balance, error) {
+	var balance Balance
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(accountID) + "/balance"}, &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// KYC statuses. Customers are unverified until they submit their identity,
+// pending until an admin decides on it, and then verified or rejected.
+// Unverified and pending customers have lower limits, and rejected ones
+// may not transact.
+const (
+	KYCUnverified = "unverified"
+	KYCPending    = "pending"
+	KYCVerified   = "verified"
+	KYCRejected   = "rejected"
+)
+
+// Identity document types.
+const (
+	DocumentPassport       = "passport"
+	DocumentNationalID     = "national_id"
+	DocumentDrivingLicence = "driving_licence"
+)
+
+// KYCSubmission is the body of SubmitKYC.
+type KYCSubmission struct {
+	LegalName string `json:"legal_name"`
+	// DateOfBirth is YYYY-MM-DD.
+	DateOfBirth string `json:"date_of_birth"`
+	// Nationality is an ISO 3166-1 alpha-2 country code.
+	Nationality    string `json:"nationality"`
+	DocumentType   string `json:"document_type"`
+	DocumentNumber string `json:"document_number"`
+	Address        string `json:"address"`
+}
+
+// KYCRecord is an account's KYC status and latest submission. The document
+// number is masked for anyone but an admin.
+type KYCRecord struct {
+	AccountID      string     `json:"account_id"`
+	Status         string     `json:"status"`
+	LegalName      string     `json:"legal_name"`
+	DateOfBirth    string     `json:"date_of_birth"`
+	Nationality    string     `json:"nationality"`
+	DocumentType   string     `json:"document_type"`
+	DocumentNumber string     `json:"document_number"`
+	Address        string     `json:"address"`
+	SubmittedBy    string     `json:"submitted_by,omitempty"`
+	SubmittedAt    *time.Time `json:"submitted_at,omitempty"`
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+}
+
+// KYCDecisionInput is the body of DecideKYC.
+type KYCDecisionInput struct {
+	// Decision is KYCVerified or KYCRejected.
+	Decision string `json:"decision"`
+	// Reason is required to reject.
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetKYC returns the account's KYC status and latest submission.
+func (c *Client) GetKYC(ctx context.Context, accountID string) (*KYCRecord, error) {
+	var record KYCRecord
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(accountID) + "/kyc"}, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// SubmitKYC submits the customer's identity for verification, making them
+// pending. Only unverified customers may submit; others get ErrConflict, or
+// ErrKYCRejected once rejected.
+func (c *Client) SubmitKYC(ctx context.Context, accountID string, in KYCSubmission) (*KYCRecord, error) {
+	var record KYCRecord
+	req := request{method: http.MethodPost, path: "/accounts/" + url.PathEscape(accountID) + "/kyc", body: in}
+	if _, err := c.do(ctx, req, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DecideKYC records a decision on the account's submission. It requires an
+// admin token.
+func (c *Client) DecideKYC(ctx context.Context, accountID string, in KYCDecisionInput) (*KYCRecord, error) {
+	var record KYCRecord
+	req := request{method: http.MethodPost, path: "/accounts/" + url.PathEscape(accountID) + "/kyc/decision", body: in}
+	if _, err := c.do(ctx, req, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListKYC returns the KYC records with the given status, or every record
+// when status is empty, oldest submission first. It requires an admin
+// token.
+func (c *Client) ListKYC(ctx context.Context, status string) ([]KYCRecord, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var records []KYCRecord
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/kyc", query: query}, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
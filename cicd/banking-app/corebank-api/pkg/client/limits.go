@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AccountLimits are limits an account has in place of its product's. A nil
+// limit follows the product and a zero one removes it.
+type AccountLimits struct {
+	MaxTransactionAmount *float64 `json:"max_transaction_amount,omitempty"`
+	DailyOutflow         *float64 `json:"daily_outflow,omitempty"`
+	MonthlyOutflow       *float64 `json:"monthly_outflow,omitempty"`
+}
+
+// LimitStatus is an account's limits and what remains of them. Limits of
+// zero are not enforced.
+type LimitStatus struct {
+	AccountID string `json:"account_id"`
+	// Timezone is the zone days and months are taken in.
+	Timezone string `json:"timezone"`
+	// KYCStatus is the verification level whose limits, if any, cap the
+	// product's.
+	KYCStatus            string      `json:"kyc_status"`
+	MaxTransactionAmount float64     `json:"max_transaction_amount"`
+	Daily                LimitWindow `json:"daily"`
+	Monthly              LimitWindow `json:"monthly"`
+	// Overrides are the account's own limits, if any.
+	Overrides *AccountLimits `json:"overrides,omitempty"`
+}
+
+// LimitWindow is the outflow of the current day or month against its limit.
+type LimitWindow struct {
+	Limit float64 `json:"limit"`
+	Used  float64 `json:"used"`
+	// Remaining is nil when there is no limit.
+	Remaining *float64  `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// GetAccountLimits returns the account's limits and the allowance remaining
+// today and this month.
+func (c *Client) GetAccountLimits(ctx context.Context, accountID string) (*LimitStatus, error) {
+	var status LimitStatus
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(accountID) + "/limits"}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetAccountLimits replaces the account's own limits; empty limits remove
+// them. It requires an admin token.
+func (c *Client) SetAccountLimits(ctx context.Context, accountID string, limits AccountLimits) (*LimitStatus, error) {
+	var status LimitStatus
+	req := request{method: http.MethodPut, path: "/accounts/" + url.PathEscape(accountID) + "/limits", body: limits}
+	if _, err := c.do(ctx, req, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
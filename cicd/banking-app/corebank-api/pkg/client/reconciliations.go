@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Reconciliation is the report of a run comparing stored balances with the
+// balance of each account's completed transactions.
+type Reconciliation struct {
+	ID          string `json:"id"`
+	Correct     bool   `json:"correct"`
+	RequestedBy string `json:"requested_by,omitempty"`
+	// Checked counts the accounts compared and Failed those whose ledger
+	// could not be read.
+	Checked       int           `json:"checked"`
+	Failed        int           `json:"failed"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+}
+
+// Discrepancy is an account whose stored balance differs from the balance
+// of its completed transactions.
+type Discrepancy struct {
+	AccountID  string  `json:"account_id"`
+	Recorded   float64 `json:"recorded"`
+	Expected   float64 `json:"expected"`
+	Difference float64 `json:"difference"`
+	Pending    float64 `json:"pending"`
+	Corrected  bool    `json:"corrected"`
+	// Covered are the IDs of completed transactions the correction applied,
+	// which had never been applied to the balance.
+	Covered []string `json:"covered,omitempty"`
+	// Error is why a correction was not made.
+	Error string `json:"error,omitempty"`
+}
+
+// ReconcileInput is the body of Reconcile.
+type ReconcileInput struct {
+	// AccountIDs limits the run to these accounts; every account if empty.
+	AccountIDs []string `json:"account_ids,omitempty"`
+	// Correct adjusts differing balances to the ledger's.
+	Correct bool `json:"correct"`
+}
+
+// Reconcile runs a reconciliation and returns its report. It requires an
+// admin token.
+func (c *Client) Reconcile(ctx context.Context, in ReconcileInput, opts ...CallOption) (*Reconciliation, error) {
+	var report Reconciliation
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/reconciliations", body: in, opts: opts}, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReconciliations returns every report, newest first.
+func (c *Client) ListReconciliations(ctx context.Context) ([]Reconciliation, error) {
+	var reports []Reconciliation
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/reconciliations"}, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetReconciliation returns the report with the given ID.
+func (c *Client) GetReconciliation(ctx context.Context, id string) (*Reconciliation, error) {
+	var report Reconciliation
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/reconciliations/" + url.PathEscape(id)}, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Risk decisions, from least to most severe.
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskDeny   = "deny"
+)
+
+// RiskDecision is the risk rules' verdict on a submitted transaction. The
+// decision on a recorded transaction has the transaction's ID.
+type RiskDecision struct {
+	ID            string  `json:"id"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	AccountID     string  `json:"account_id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	// Decision is the most severe of the rules' decisions.
+	Decision  string           `json:"decision"`
+	Rules     []RiskRuleResult `json:"rules"`
+	Actor     string           `json:"actor,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// RiskRuleResult is one rule's verdict and, unless it allowed the
+// transaction, why.
+type RiskRuleResult struct {
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ListRiskDecisionsOptions filter ListRiskDecisions; empty fields match any.
+type ListRiskDecisionsOptions struct {
+	AccountID string
+	Decision  string
+}
+
+// ListRiskDecisions returns the logged risk decisions, newest first. It
+// requires an admin token.
+func (c *Client) ListRiskDecisions(ctx context.Context, opts ListRiskDecisionsOptions) ([]RiskDecision, error) {
+	query := url.Values{}
+	if opts.AccountID != "" {
+		query.Set("account_id", opts.AccountID)
+	}
+	if opts.Decision != "" {
+		query.Set("decision", opts.Decision)
+	}
+	var decisions []RiskDecision
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/risk/decisions", query: query}, &decisions); err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// GetRiskDecision returns the decision with the given ID, which for a
+// recorded transaction is the transaction's ID. It requires an admin token.
+func (c *Client) GetRiskDecision(ctx context.Context, id string) (*RiskDecision, error) {
+	var decision RiskDecision
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/risk/decisions/" + url.PathEscape(id)}, &decision); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Schedule statuses.
+const (
+	ScheduleActive    = "active"
+	ScheduleCancelled = "cancelled"
+	ScheduleCompleted = "completed"
+)
+
+// Schedule execution statuses.
+const (
+	ExecutionRunning   = "running"
+	ExecutionSucceeded = "succeeded"
+	ExecutionRetrying  = "retrying"
+	ExecutionFailed    = "failed"
+)
+
+// Schedule is a transfer made once or on every occurrence of a recurrence.
+type Schedule struct {
+	ID            string    `json:"id"`
+	FromAccountID string    `json:"from_account_id"`
+	ToAccountID   string    `json:"to_account_id"`
+	Amount        float64   `json:"amount"`
+	Description   string    `json:"description,omitempty"`
+	Recurrence    string    `json:"recurrence,omitempty"`
+	StartAt       time.Time `json:"start_at"`
+	Status        string    `json:"status"`
+	// NextOccurrence is unset once the schedule is no longer active.
+	// NextAttemptAt is later than NextOccurrence while it is retried.
+	NextOccurrence *time.Time `json:"next_occurrence,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	Attempts       int        `json:"attempts"`
+	Occurrences    int        `json:"occurrences"`
+	CreatedBy      string     `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ScheduleExecution is one attempt at an occurrence of a schedule.
+type ScheduleExecution struct {
+	ID           string     `json:"id"`
+	ScheduleID   string     `json:"schedule_id"`
+	Occurrence   time.Time  `json:"occurrence"`
+	Attempt      int        `json:"attempt"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	WithdrawalID string     `json:"withdrawal_id,omitempty"`
+	DepositID    string     `json:"deposit_id,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// CreateScheduleInput is the body of CreateSchedule.
+type CreateScheduleInput struct {
+	FromAccountID string  `json:"from_account_id"`
+	ToAccountID   string  `json:"to_account_id"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description,omitempty"`
+	// Recurrence is an RRULE such as FREQ=MONTHLY;BYMONTHDAY=1. Leave it
+	// empty for a one-off transfer at StartAt.
+	Recurrence string `json:"recurrence,omitempty"`
+	// StartAt is required without a recurrence and defaults to now with
+	// one.
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// CreateSchedule schedules a one-off or recurring transfer.
+func (c *Client) CreateSchedule(ctx context.Context, in CreateScheduleInput, opts ...CallOption) (*Schedule, error) {
+	var schedule Schedule
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/schedules", body: in, opts: opts}, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetSchedule returns the schedule with the given ID.
+func (c *Client) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	var schedule Schedule
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/schedules/" + url.PathEscape(id)}, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListSchedules returns the schedules transferring from an account, oldest
+// first. Admins may pass an empty accountID to list every schedule.
+func (c *Client) ListSchedules(ctx context.Context, accountID string) ([]Schedule, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("account_id", accountID)
+	}
+	var schedules []Schedule
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/schedules", query: query}, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CancelSchedule stops a schedule's further occurrences. Cancelling a
+// schedule that is no longer active fails with ErrConflict.
+func (c *Client) CancelSchedule(ctx context.Context, id string) (*Schedule, error) {
+	var schedule Schedule
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/schedules/" + url.PathEscape(id) + "/cancel"}, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ScheduleExecutions returns every attempt at a schedule's occurrences,
+// oldest first.
+func (c *Client) ScheduleExecutions(ctx context.Context, id string) ([]ScheduleExecution, error) {
+	var executions []ScheduleExecution
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/schedules/" + url.PathEscape(id) + "/executions"}, &executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Statement summarises an account's completed transactions over a period.
+type Statement struct {
+	AccountID      string          `json:"account_id"`
+	Owner          string          `json:"owner"`
+	Period         string          `json:"period,omitempty"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance float64         `json:"opening_balance"`
+	TotalCredits   float64         `json:"total_credits"`
+	TotalDebits    float64         `json:"total_debits"`
+	ClosingBalance float64         `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// StatementLine is one transaction on a statement. Amount is negative for
+// debits and Balance is the running balance after it.
+type StatementLine struct {
+	Date          time.Time `json:"date"`
+	TransactionID string    `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description,omitempty"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+}
+
+// StatementOptions selects the statement's range: either a Period, a month
+// such as "2024-05", or From and To. The server defaults to the current
+// month so far.
+type StatementOptions struct {
+	Period string
+	From   time.Time
+	// To is exclusive.
+	To time.Time
+}
+
+// GetStatement returns an account's statement.
+func (c *Client) GetStatement(ctx context.Context, accountID string, opts StatementOptions) (*Statement, error) {
+	query := url.Values{}
+	if opts.Period != "" {
+		query.Set("period", opts.Period)
+	}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339))
+	}
+
+	var statement Statement
+	req := request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(accountID) + "/statements", query: query}
+	if _, err := c.do(ctx, req, &statement); err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Transaction types.
+const (
+	TypeDeposit    = "deposit"
+	TypeWithdrawal = "withdrawal"
+	TypeTransfer   = "transfer"
+)
+
+// Transaction statuses.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Transaction is a movement of money recorded by the transaction service.
+type Transaction struct {
+	ID          string     `json:"id"`
+	AccountID   string     `json:"account_id"`
+	Amount      float64    `json:"amount"`
+	Type        string     `json:"type"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// CreateTransactionInput is the body of CreateTransaction.
+type CreateTransactionInput struct {
+	AccountID   string  `json:"account_id"`
+	Amount      float64 `json:"amount"`
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	// Status is StatusPending, the default, or StatusCompleted to apply
+	// the transaction to the balance straight away. Only an admin may
+	// submit a completed deposit.
+	Status string `json:"status,omitempty"`
+}
+
+// CreateTransaction submits a transaction for an existing account. It is
+// recorded as pending unless in.Status is StatusCompleted. Breaking a rule of the account's product fails with
+// ErrLimitExceeded or ErrInsufficientFunds.
+func (c *Client) CreateTransaction(ctx context.Context, in CreateTransactionInput, opts ...CallOption) (*Transaction, error) {
+	var txn Transaction
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/transactions", body: in, opts: opts}, &txn); err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// UpdateTransactionStatus moves a transaction to status. Completing it
+// requires an admin token and applies it to the account's balance; a
+// withdrawal the balance cannot cover is marked failed and fails with
+// ErrInsufficientFunds. A completed transaction applied to the balance
+// stays completed; moving it fails with ErrConflict.
+func (c *Client) UpdateTransactionStatus(ctx context.Context, id, status string) (*Transaction, error) {
+	var txn Transaction
+	req := request{
+		method: http.MethodPut,
+		path:   "/transactions/" + url.PathEscape(id),
+		query:  url.Values{"status": {status}},
+	}
+	if _, err := c.do(ctx, req, &txn); err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// ListTransactionsOptions filters and pages transactions.
+type ListTransactionsOptions struct {
+	// AccountID limits the results to one account.
+	AccountID string
+	// Limit is the page size, at most 100. The server default is 10.
+	Limit  int
+	Offset int
+}
+
+// ListTransactions returns one page of transactions, newest first. See
+// Transactions to iterate over all of them.
+func (c *Client) ListTransactions(ctx context.Context, opts ListTransactionsOptions) ([]Transaction, error) {
+	query := url.Values{}
+	if opts.AccountID != "" {
+		query.Set("account_id", opts.AccountID)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+
+	var txns []Transaction
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/transactions", query: query}, &txns); err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// TransactionIterator walks transactions newest first, page by page. Pages
+// are fetched by offset, so transactions recorded while iterating can shift
+// later pages and cause an entry to be seen twice.
+type TransactionIterator struct {
+	ctx     context.Context
+	client  *Client
+	opts    ListTransactionsOptions
+	done    bool
+	page    []Transaction
+	current Transaction
+	err     error
+}
+
+// Transactions returns an iterator over the transactions matching opts,
+// starting at opts.Offset and fetching opts.Limit at a time.
+func (c *Client) Transactions(ctx context.Context, opts ListTransactionsOptions) *TransactionIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+	return &TransactionIterator{ctx: ctx, client: c, opts: opts}
+}
+
+// Next advances to the next transaction, fetching another page when needed.
+// It returns false at the end or on error; check Err.
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		page, err := it.client.ListTransactions(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.opts.Offset += len(page)
+		it.done = len(page) < it.opts.Limit
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Transaction returns the transaction Next advanced to.
+func (it *TransactionIterator) Transaction() Transaction {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// TransferInput is the body of CreateTransfer.
+type TransferInput struct {
+	FromAccountID string  `json:"from_account_id"`
+	ToAccountID   string  `json:"to_account_id"`
+	Amount        float64 `json:"amount"`
+	Description   string  `json:"description,omitempty"`
+}
+
+// Transfer is a recorded transfer: a withdrawal from one account and a
+// deposit to another, both pending.
+type Transfer struct {
+	FromAccountID string       `json:"from_account_id"`
+	ToAccountID   string       `json:"to_account_id"`
+	Amount        float64      `json:"amount"`
+	Description   string       `json:"description,omitempty"`
+	Withdrawal    *Transaction `json:"withdrawal,omitempty"`
+	Deposit       *Transaction `json:"deposit,omitempty"`
+}
+
+// CreateTransfer moves money between two accounts. The product rules of both
+// apply, as for CreateTransaction.
+func (c *Client) CreateTransfer(ctx context.Context, in TransferInput, opts ...CallOption) (*Transfer, error) {
+	var transfer Transfer
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/transfers", body: in, opts: opts}, &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook event types.
+const (
+	EventAccountCreated    = "account.created"
+	EventAccountUpdated    = "account.updated"
+	EventAccountClosed     = "account.closed"
+	EventAccountDeleted    = "account.deleted"
+	EventTransactionPosted = "transaction.posted"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Headers the API sends with every webhook delivery.
+const (
+	WebhookEventHeader     = "X-Corebank-Event"
+	WebhookDeliveryHeader  = "X-Corebank-Delivery"
+	WebhookSignatureHeader = "X-Corebank-Signature"
+)
+
+// ErrInvalidSignature is returned by VerifyWebhook for a delivery that was
+// not signed with the subscription's secret, or was signed too long ago.
+var ErrInvalidSignature = errors.New("corebank: webhook signature is invalid")
+
+// WebhookSubscription sends events of the given types to URL.
+type WebhookSubscription struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret is only set in the response to CreateWebhook.
+	Secret      string    `json:"secret,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookEvent is the body of a webhook delivery. Redeliveries keep the
+// event's ID.
+type WebhookEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is an Account for account events, apart from account.deleted
+	// which only has the id, and a Transaction for transaction.posted.
+	Data json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	RedeliveryOf   string          `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// CreateWebhookInput is the body of CreateWebhook.
+type CreateWebhookInput struct {
+	URL string `json:"url"`
+	// EventTypes holds the types to send, or "*" for every type.
+	EventTypes []string `json:"event_types"`
+	// Secret signs deliveries; one is generated if it is empty.
+	Secret      string `json:"secret,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateWebhook subscribes a URL to events. It requires an admin token.
+func (c *Client) CreateWebhook(ctx context.Context, in CreateWebhookInput, opts ...CallOption) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/webhooks", body: in, opts: opts}, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListWebhooks returns every subscription, oldest first, without secrets.
+func (c *Client) ListWebhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks"}, &subscriptions); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetWebhook returns the subscription with the given ID, without its
+// secret.
+func (c *Client) GetWebhook(ctx context.Context, id string) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks/" + url.PathEscape(id)}, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// DeleteWebhook unsubscribes. Its delivery log is kept.
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/webhooks/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+// WebhookDeliveries returns a subscription's delivery log, newest first.
+func (c *Client) WebhookDeliveries(ctx context.Context, id string) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks/" + url.PathEscape(id) + "/deliveries"}, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook sends a delivery's event to the subscription again, as a
+// new delivery that is returned.
+func (c *Client) RedeliverWebhook(ctx context.Context, id, deliveryID string) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	path := "/webhooks/" + url.PathEscape(id) + "/deliveries/" + url.PathEscape(deliveryID) + "/redeliver"
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: path}, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// VerifyWebhook checks the X-Corebank-Signature header of a delivery
+// against its raw body, and rejects signatures made more than tolerance
+// ago, or ahead, to stop replays. A zero tolerance accepts any time.
+func VerifyWebhook(secret, signatureHeader string, body []byte, tolerance time.Duration) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(sec, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -68,6 +68,17 @@ async def create_transaction(transaction: schemas.TransactionCreate, db: Session
         logger.error(f"Error creating transaction: {e}")
         raise HTTPException(status_code=500, detail="Failed to create transaction")
 
+@app.get("/transactions/{id}", response_model=schemas.TransactionResponse, tags=["Transactions"])
+async def get_transaction(id: UUID, db: Session = Depends(get_db)):
+    try:
+        service = services.TransactionService(db)
+        return service.get_transaction(id)
+    except HTTPException:
+        raise
+    except Exception as e:
+        logger.error(f"Error retrieving transaction: {e}")
+        raise HTTPException(status_code=500, detail="Unable to retrieve transaction")
+
 @app.put("/transactions/{id}", response_model=schemas.TransactionResponse, tags=["Transactions"])
 async def update_transaction_status(
     id: UUID,
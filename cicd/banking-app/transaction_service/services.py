@@ -45,6 +45,18 @@ class TransactionService:
         self.db.refresh(db_transaction)
         return db_transaction
 
+    def get_transaction(self, id: UUID) -> models.Transaction:
+        """
+        Retrieve a transaction by ID.
+        """
+        db_transaction = self.db.query(models.Transaction).filter(
+            models.Transaction.id == id
+        ).first()
+
+        if not db_transaction:
+            raise HTTPException(status_code=404, detail="Transaction not found")
+        return db_transaction
+
     def update_status(self, id: UUID, status: ValidStatuses) -> models.Transaction:
         """
         Update the status of a transaction.
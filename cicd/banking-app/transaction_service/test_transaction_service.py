@@ -66,6 +66,18 @@ def test_create_transaction(transaction_service, test_transaction_data):
     assert isinstance(transaction.id, uuid.UUID)  # Now checking for UUID
     assert transaction.created_at is not None
 
+def test_get_transaction(transaction_service, test_transaction_data):
+    transaction = transaction_service.create_transaction(test_transaction_data)
+
+    found = transaction_service.get_transaction(transaction.id)
+    assert found.id == transaction.id
+    assert found.account_id == test_transaction_data.account_id
+
+    # Test invalid transaction ID
+    with pytest.raises(HTTPException) as exc_info:
+        transaction_service.get_transaction(uuid.uuid4())
+    assert exc_info.value.status_code == 404
+
 def test_update_status(transaction_service, test_transaction_data):
     transaction = transaction_service.create_transaction(test_transaction_data)
     